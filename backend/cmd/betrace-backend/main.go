@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -17,9 +19,13 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/api"
+	"github.com/betracehq/betrace/backend/internal/config"
+	"github.com/betracehq/betrace/backend/internal/dsl"
 	grpcmiddleware "github.com/betracehq/betrace/backend/internal/grpc/middleware"
 	grpcServices "github.com/betracehq/betrace/backend/internal/grpc/services"
 	"github.com/betracehq/betrace/backend/internal/middleware"
+	"github.com/betracehq/betrace/backend/internal/notify"
 	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/internal/rules"
 	"github.com/betracehq/betrace/backend/internal/services"
@@ -63,8 +69,18 @@ func main() {
 	}
 	log.Printf("✓ Rule store initialized (%d rules recovered)", ruleStore.Count())
 
+	// Load limits (config file optional; BETRACE_LIMITS_* env vars override)
+	cfg, err := config.Load(getEnv("BETRACE_CONFIG_FILE", ""))
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
 	// Create rule engine and load persisted rules
 	engine := rules.NewRuleEngine()
+	engine.SetEvaluationLimits(rules.EvaluationLimits{
+		Timeout:  time.Duration(cfg.Limits.Trace.EvaluationTimeout) * time.Millisecond,
+		MaxSteps: cfg.Limits.Trace.MaxEvaluationSteps,
+	})
 	recoveredRules, err := ruleStore.List()
 	if err != nil {
 		log.Printf("Warning: Failed to load rules: %v", err)
@@ -79,22 +95,176 @@ func main() {
 	}
 	log.Println("✓ Rule engine initialized")
 
-	// Create violation store (in-memory with signing)
-	// Note: Violations are ephemeral - they're meant to be sent to Tempo
+	// Create violation store (signed; durable on disk unless configured otherwise)
 	signatureKey := getEnv("BETRACE_SIGNATURE_KEY", "dev-signature-key-change-in-production")
-	violationStore := services.NewViolationStoreMemory(signatureKey)
-	log.Println("✓ Violation store initialized (in-memory with signing)")
+	violationStore, keyring, err := newViolationStore(cfg.Storage, filepath.Join(dataDir, "violations"), "Violation store", signatureKey, nil)
+	if err != nil {
+		log.Fatalf("Failed to initialize violation store: %v", err)
+	}
+	defer violationStore.Close()
+	if keyring != nil {
+		go rotateSigningKeys(ctx, keyring, time.Duration(cfg.Signing.KeyRotation)*24*time.Hour)
+	}
+
+	// Append violations to the hash-chained ledger
+	var ledger *services.Ledger
+	if cfg.Ledger.Enabled {
+		ledgerDir := ""
+		if cfg.Storage.ViolationBackend != "memory" {
+			ledgerDir = filepath.Join(dataDir, "ledger")
+		}
+		ledger, err = services.NewLedger(ledgerDir, keyring, cfg.Ledger.CheckpointEvery)
+		if err != nil {
+			log.Fatalf("Failed to open violation ledger: %v", err)
+		}
+		defer ledger.Close()
+		ledgerStore, err := services.NewLedgerStore(context.Background(), violationStore, ledger)
+		if err != nil {
+			log.Fatalf("Failed to initialize violation ledger: %v", err)
+		}
+		violationStore = ledgerStore
+		go checkpointLedger(ctx, ledger, time.Duration(cfg.Ledger.CheckpointInterval)*time.Second)
+		log.Printf("✓ Violation ledger verified (%d entries)", ledger.Size())
+	}
+
+	// Group violations into incidents by fingerprint
+	fingerprinter, err := services.NewFingerprinter(cfg.Storage.ViolationGrouping)
+	if err != nil {
+		log.Fatalf("Invalid storage.violation_grouping: %v", err)
+	}
+	incidentStore, err := services.NewIncidentStore(context.Background(), violationStore, fingerprinter)
+	if err != nil {
+		log.Fatalf("Failed to initialize incident store: %v", err)
+	}
+	// As often as the disk store applies retention
+	go pruneIncidents(ctx, incidentStore, time.Minute)
 
 	// Create gRPC services with persistent rule store
 	ruleService := grpcServices.NewRuleService(engine, ruleStore)
+	ruleService.SetVersionStore(ruleStore)
 	healthService := grpcServices.NewHealthService(version)
-	spanService := grpcServices.NewSpanService(engine, violationStore)
-	violationService := grpcServices.NewViolationService(violationStore)
+	spanService := grpcServices.NewSpanService(engine, incidentStore)
+	violationService := grpcServices.NewViolationService(incidentStore)
+
+	// Fan out recorded violations to live watchers (gRPC stream and SSE)
+	violationHub := services.NewViolationHub(services.DefaultSubscriberBuffer)
+	spanService.SetViolationHub(violationHub)
+	violationService.SetViolationHub(violationHub)
+
+	// Snapshot the rule and spans behind each violation so it stays reproducible
+	if cfg.Evidence.Enabled {
+		spanService.SetEvidenceRecorder(services.NewEvidenceRecorder(services.EvidenceLimits{
+			MaxSpans:           cfg.Evidence.MaxSpans,
+			MaxAttributeLength: cfg.Evidence.MaxAttributeLength,
+			RedactAttributes:   cfg.Evidence.RedactAttributes,
+		}))
+	}
+	violationService.SetRuleEngine(engine)
 
-	// Start gRPC server with logging middleware
+	// Keep the would-be violations of shadow rules apart: no ledger,
+	// incidents, notifications or export. They share the signing keys, so
+	// rotation and the published keys cover them too.
+	shadowStore, _, err := newViolationStore(cfg.Storage, filepath.Join(dataDir, "shadow-violations"), "Shadow violation store", signatureKey, keyring)
+	if err != nil {
+		log.Fatalf("Failed to initialize shadow violation store: %v", err)
+	}
+	defer shadowStore.Close()
+	shadowHub := services.NewViolationHub(services.DefaultSubscriberBuffer)
+	spanService.SetShadowStore(shadowStore, shadowHub)
+	violationService.SetShadowStore(shadowStore, shadowHub)
+
+	// Count rule evaluations as compliance evidence
+	evaluationDir := ""
+	if cfg.Storage.ViolationBackend != "memory" {
+		evaluationDir = filepath.Join(dataDir, "evaluations")
+	}
+	evaluationCounter, err := services.NewEvaluationCounter(evaluationDir, services.EvaluationCounterOptions{
+		Retention: time.Duration(cfg.Evaluations.Retention) * 24 * time.Hour,
+		Exemplars: cfg.Evaluations.Exemplars,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load evaluation counts: %v", err)
+	}
+	defer evaluationCounter.Close()
+	go flushEvaluations(ctx, evaluationCounter, time.Duration(cfg.Evaluations.FlushInterval)*time.Second)
+	spanService.SetEvaluationCounter(evaluationCounter)
+	if cfg.Evaluations.MatchHistory > 0 {
+		matchHistory := services.NewMatchHistory(cfg.Evaluations.MatchHistory)
+		spanService.SetMatchHistory(matchHistory)
+		ruleService.SetMatchHistory(matchHistory)
+	}
+	// Backtest rules offline against uploaded, recorded or archived traces
+	var traceArchive *services.TraceArchive
+	if cfg.Backtest.ArchiveTraces {
+		traceArchive, err = services.NewTraceArchive(filepath.Join(dataDir, "traces"), time.Duration(cfg.Backtest.ArchiveRetention)*24*time.Hour)
+		if err != nil {
+			log.Fatalf("Failed to open trace archive: %v", err)
+		}
+		defer traceArchive.Close()
+		spanService.SetTraceArchive(traceArchive)
+	}
+	backtestRunner := services.NewBacktestRunner(ruleStore, services.BacktestOptions{
+		TraceDir: cfg.Backtest.TraceDir,
+		Archive:  traceArchive,
+		Limits: dsl.Limits{
+			MaxSteps: cfg.Limits.Trace.MaxEvaluationSteps,
+			Timeout:  time.Duration(cfg.Limits.Trace.EvaluationTimeout) * time.Millisecond,
+		},
+		Concurrency: cfg.Backtest.Concurrency,
+		MaxJobs:     cfg.Backtest.MaxJobs,
+		MaxPending:  cfg.Backtest.MaxPending,
+	})
+	defer backtestRunner.Close()
+
+	complianceReporter := services.NewComplianceReporter(ruleStore, incidentStore, evaluationCounter, keyring)
+
+	// Export violations as OTLP spans into the traces they were found in
+	if export := cfg.Telemetry.ViolationExport; export.Enabled {
+		exporter, err := observability.NewViolationExporter(ctx, observability.ViolationExportConfig{
+			Endpoint:     export.Endpoint,
+			Insecure:     export.Insecure,
+			Headers:      export.Headers,
+			BatchSize:    export.BatchSize,
+			BatchTimeout: time.Duration(export.BatchTimeout) * time.Millisecond,
+			QueueSize:    export.QueueSize,
+		}, "betrace-backend", version)
+		if err != nil {
+			log.Fatalf("Failed to initialize violation exporter: %v", err)
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := exporter.Shutdown(shutdownCtx); err != nil {
+				log.Printf("Error shutting down violation exporter: %v", err)
+			}
+		}()
+		go exportViolations(ctx, incidentStore, violationHub, exporter)
+		log.Println("✓ Violation export enabled")
+	}
+
+	// Route violations to configured webhooks, email and Alertmanager receivers
+	var notifier *notify.Notifier
+	if receivers := len(cfg.Notifications.Webhooks) + len(cfg.Notifications.Emails) + len(cfg.Notifications.Alertmanagers); receivers > 0 {
+		notifier, err = notify.NewNotifier(filepath.Join(dataDir, "notifications"), signatureKey, notifierConfig(cfg.Notifications))
+		if err != nil {
+			log.Fatalf("Failed to initialize notifier: %v", err)
+		}
+		notifier.Start()
+		defer notifier.Close()
+		go func() {
+			if err := notifier.Run(ctx, incidentStore, violationHub); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Notifier stopped: %v", err)
+			}
+		}()
+		log.Printf("✓ Notifier started (%d receivers)", receivers)
+	}
+
+	// Start gRPC server with logging middleware. The auth interceptor puts
+	// the caller's identity in the context (e.g. as rule version authors).
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			grpcmiddleware.UnaryServerLoggingInterceptor(),
+			middleware.NewAuthMiddleware(os.Getenv("WORKOS_CLIENT_ID")).UnaryServerInterceptor(),
 		),
 	)
 	pb.RegisterRuleServiceServer(grpcServer, ruleService)
@@ -140,9 +310,29 @@ func main() {
 	httpHandler := middleware.BodyLimitMiddleware(10 * 1024 * 1024)(mux)
 	httpHandler = corsMiddleware(httpHandler)
 
-	// Add Prometheus metrics endpoint (bypasses grpc-gateway)
+	// Add Prometheus metrics and SSE endpoints (bypass grpc-gateway)
 	httpMux := http.NewServeMux()
 	httpMux.Handle("/metrics", promhttp.Handler())
+	httpMux.Handle("/v1/violations/stream", corsMiddleware(api.NewViolationStreamHandler(incidentStore, violationHub)))
+	httpMux.Handle("/v1/violations/shadow/stream", corsMiddleware(api.NewViolationStreamHandler(shadowStore, shadowHub)))
+	httpMux.Handle(api.JWKSPath, corsMiddleware(api.NewJWKSHandler(keyring)))
+	if ledger != nil {
+		ledgerHandlers := api.NewLedgerHandlers(ledger)
+		httpMux.Handle(api.LedgerExportPath, corsMiddleware(http.HandlerFunc(ledgerHandlers.Export)))
+		httpMux.Handle(api.LedgerCheckpointsPath, corsMiddleware(http.HandlerFunc(ledgerHandlers.Checkpoints)))
+	}
+	complianceHandlers := api.NewComplianceHandlers(complianceReporter)
+	httpMux.Handle(api.ComplianceEvidencePath, corsMiddleware(http.HandlerFunc(complianceHandlers.Evidence)))
+	httpMux.Handle(api.ComplianceExportPath, corsMiddleware(http.HandlerFunc(complianceHandlers.Export)))
+	httpMux.Handle(api.ComplianceEvaluationsPath, corsMiddleware(http.HandlerFunc(complianceHandlers.Evaluations)))
+	backtestHandlers := api.NewBacktestHandlers(backtestRunner, cfg.Backtest.MaxUploadBytes)
+	httpMux.Handle(api.BacktestsPath, corsMiddleware(http.HandlerFunc(backtestHandlers.Jobs)))
+	httpMux.Handle(api.BacktestPath, corsMiddleware(http.HandlerFunc(backtestHandlers.Job)))
+	if notifier != nil {
+		notificationHandlers := api.NewNotificationHandlers(notifier)
+		httpMux.Handle(api.DeadLettersPath, corsMiddleware(http.HandlerFunc(notificationHandlers.DeadLetters)))
+		httpMux.Handle(api.DeadLettersRedeliverPath, corsMiddleware(http.HandlerFunc(notificationHandlers.Redeliver)))
+	}
 	httpMux.Handle("/", httpHandler)
 
 	httpServer := &http.Server{
@@ -184,12 +374,132 @@ func main() {
 	log.Println("✓ Servers stopped gracefully")
 }
 
+// newViolationStore creates a violation store of the kind selected by
+// storage.violation_backend, kept in dir on disk, and the keyring it signs
+// violations with: keys if set, else a new one. name labels it in the
+// startup log.
+func newViolationStore(cfg config.StorageConfig, dir, name, signatureKey string, keys *services.Keyring) (services.ViolationStore, *services.Keyring, error) {
+	switch cfg.ViolationBackend {
+	case "memory":
+		var store *services.ViolationStoreMemory
+		if keys != nil {
+			store = services.NewViolationStoreMemoryWithKeyring(keys)
+		} else {
+			store = services.NewViolationStoreMemory(signatureKey)
+		}
+		log.Printf("✓ %s initialized (in-memory with signing)", name)
+		return store, store.Keyring(), nil
+	case "disk", "":
+		opts := storage.DiskViolationStoreOptions{
+			MaxViolations:       cfg.MaxViolations,
+			Retention:           time.Duration(cfg.ViolationRetention) * time.Hour,
+			MaintenanceInterval: time.Minute,
+		}
+		var store *services.ViolationStoreDisk
+		var err error
+		if keys != nil {
+			store, err = services.NewViolationStoreDiskWithKeyring(dir, keys, opts)
+		} else {
+			store, err = services.NewViolationStoreDisk(dir, signatureKey, opts)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("✓ %s initialized (on disk with signing, %d violations recovered)", name, store.Count())
+		return store, store.Keyring(), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown violation backend %q (want disk or memory)", cfg.ViolationBackend)
+	}
+}
+
+// rotateSigningKeys rotates the violation signing key once it is older than
+// maxAge, checking hourly until ctx is done. Retired keys keep verifying.
+func rotateSigningKeys(ctx context.Context, keyring *services.Keyring, maxAge time.Duration) {
+	if maxAge <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if rotated, err := keyring.RotateIfOlder(maxAge); err != nil {
+			log.Printf("Failed to rotate violation signing key: %v", err)
+		} else if rotated {
+			log.Printf("✓ Violation signing key rotated (key ID %s)", keyring.Active().ID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkpointLedger cuts a signed ledger checkpoint every interval (when
+// entries were appended since the last one) until ctx is done
+func checkpointLedger(ctx context.Context, ledger *services.Ledger, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := ledger.Checkpoint(); err != nil {
+			log.Printf("Failed to checkpoint violation ledger: %v", err)
+		}
+	}
+}
+
+// flushEvaluations writes changed evaluation counts every interval until ctx
+// is done
+func flushEvaluations(ctx context.Context, counter *services.EvaluationCounter, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := counter.Flush(); err != nil {
+			log.Printf("Failed to write evaluation counts: %v", err)
+		}
+	}
+}
+
+// pruneIncidents drops incidents whose violations retention evicted, every
+// interval until ctx is done
+func pruneIncidents(ctx context.Context, incidents *services.IncidentStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if dropped, err := incidents.Prune(ctx); err != nil {
+			log.Printf("Failed to prune incidents: %v", err)
+		} else if dropped > 0 {
+			log.Printf("Pruned %d incidents with no retained violations", dropped)
+		}
+	}
+}
+
 // corsMiddleware adds CORS headers for browser access
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, traceparent, b3")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, traceparent, b3, Last-Event-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -200,6 +510,116 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// exportViolations exports violations as they are recorded until ctx is
+// done, catching up from the store whenever the exporter falls behind
+func exportViolations(ctx context.Context, store services.ViolationStore, hub *services.ViolationHub, exporter *observability.ViolationExporter) {
+	var cursor string
+	for {
+		err := services.WatchViolations(ctx, store, hub, services.QueryFilters{Cursor: cursor}, func(event services.ViolationEvent) error {
+			exporter.Export(event.Violation)
+			cursor = event.Cursor
+			return nil
+		})
+		if !errors.Is(err, services.ErrSubscriberLagged) {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Violation export stopped: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// notifierConfig converts notification settings to notifier configuration
+func notifierConfig(cfg config.NotificationsConfig) notify.Config {
+	result := notify.Config{
+		Webhooks:      make([]notify.WebhookConfig, len(cfg.Webhooks)),
+		Emails:        make([]notify.EmailConfig, len(cfg.Emails)),
+		Alertmanagers: make([]notify.AlertmanagerConfig, len(cfg.Alertmanagers)),
+	}
+	for i, w := range cfg.Webhooks {
+		result.Webhooks[i] = notify.WebhookConfig{
+			Name:           w.Name,
+			URL:            w.URL,
+			Template:       w.Template,
+			ContentType:    w.ContentType,
+			Headers:        w.Headers,
+			RateLimit:      w.RateLimit,
+			Burst:          w.Burst,
+			MaxAttempts:    w.MaxAttempts,
+			InitialBackoff: time.Duration(w.InitialBackoff) * time.Millisecond,
+			MaxBackoff:     time.Duration(w.MaxBackoff) * time.Millisecond,
+			Timeout:        time.Duration(w.Timeout) * time.Second,
+			QueueSize:      w.QueueSize,
+		}
+	}
+	for i, e := range cfg.Emails {
+		result.Emails[i] = notify.EmailConfig{
+			Name:           e.Name,
+			Host:           e.Host,
+			Port:           e.Port,
+			Username:       e.Username,
+			Password:       e.Password,
+			From:           e.From,
+			To:             e.To,
+			Subject:        e.Subject,
+			Template:       e.Template,
+			MaxAttempts:    e.MaxAttempts,
+			InitialBackoff: time.Duration(e.InitialBackoff) * time.Millisecond,
+			MaxBackoff:     time.Duration(e.MaxBackoff) * time.Millisecond,
+			Timeout:        time.Duration(e.Timeout) * time.Second,
+			QueueSize:      e.QueueSize,
+		}
+	}
+	for i, a := range cfg.Alertmanagers {
+		result.Alertmanagers[i] = notify.AlertmanagerConfig{
+			Name:           a.Name,
+			URL:            a.URL,
+			TraceURL:       a.TraceURL,
+			Labels:         a.Labels,
+			Headers:        a.Headers,
+			ResolveAfter:   time.Duration(a.ResolveAfter) * time.Second,
+			MaxAttempts:    a.MaxAttempts,
+			InitialBackoff: time.Duration(a.InitialBackoff) * time.Millisecond,
+			MaxBackoff:     time.Duration(a.MaxBackoff) * time.Millisecond,
+			Timeout:        time.Duration(a.Timeout) * time.Second,
+			QueueSize:      a.QueueSize,
+		}
+	}
+	if cfg.Route != nil {
+		route := routeConfig(*cfg.Route)
+		result.Route = &route
+	}
+	return result
+}
+
+// routeConfig converts a routing tree node and its children
+func routeConfig(cfg config.RouteConfig) notify.RouteConfig {
+	seconds := func(s *int) *time.Duration {
+		if s == nil {
+			return nil
+		}
+		d := time.Duration(*s) * time.Second
+		return &d
+	}
+	route := notify.RouteConfig{
+		Receiver:       cfg.Receiver,
+		Severities:     cfg.Severities,
+		Tags:           cfg.Tags,
+		Services:       cfg.Services,
+		Frameworks:     cfg.Frameworks,
+		Owners:         cfg.Owners,
+		GroupBy:        cfg.GroupBy,
+		GroupWait:      seconds(cfg.GroupWait),
+		GroupInterval:  seconds(cfg.GroupInterval),
+		RepeatInterval: seconds(cfg.RepeatInterval),
+		Continue:       cfg.Continue,
+	}
+	for _, child := range cfg.Routes {
+		route.Routes = append(route.Routes, routeConfig(child))
+	}
+	return route
+}
+
 // getEnv gets environment variable with default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
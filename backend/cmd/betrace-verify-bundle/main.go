@@ -0,0 +1,74 @@
+// Command betrace-verify-bundle checks a compliance export bundle offline.
+//
+//	curl -o bundle.zip "http://betrace:12011/api/v1/compliance/export?framework=soc2"
+//	curl -o jwks.json http://betrace:12011/.well-known/jwks.json
+//	betrace-verify-bundle -jwks jwks.json bundle.zip
+//
+// It exits non-zero if any file was altered, a violation's signature doesn't
+// verify, or the bundle isn't signed by a key in the JWKS. Without -jwks it
+// trusts the keys inside the bundle, which only shows internal consistency.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+func main() {
+	jwksPath := flag.String("jwks", "", "JWKS file with the signing keys (from /.well-known/jwks.json)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-jwks jwks.json] bundle.zip\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		fail(err)
+	}
+	bundle, err := services.ReadComplianceBundle(file, info.Size())
+	if err != nil {
+		fail(err)
+	}
+
+	keysData := bundle.Files[services.BundleKeysFile]
+	if *jwksPath != "" {
+		if keysData, err = os.ReadFile(*jwksPath); err != nil {
+			fail(err)
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, "! No -jwks given: verifying against the keys inside the bundle")
+	}
+	var keys services.JWKS
+	if err := json.Unmarshal(keysData, &keys); err != nil {
+		fail(fmt.Errorf("invalid JWKS: %w", err))
+	}
+
+	summary, err := services.VerifyComplianceBundle(bundle, keys)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("✓ Bundle of %s signed by %s: %d violation signatures verified\n",
+		summary.Signature.CreatedAt.Format("2006-01-02 15:04:05 MST"), summary.Signature.KeyID, summary.Violations)
+	if len(summary.Unverifiable) > 0 {
+		fmt.Printf("! %d violations are unsigned or HMAC-signed and can't be verified offline: %v\n", len(summary.Unverifiable), summary.Unverifiable)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,66 @@
+// Command betrace-verify-ledger checks a violation ledger export offline.
+//
+//	curl -o export.json "http://betrace:12011/v1/ledger/export?from=1&to=5000"
+//	curl -o jwks.json http://betrace:12011/.well-known/jwks.json
+//	betrace-verify-ledger -jwks jwks.json export.json
+//
+// It exits non-zero if entries are missing, reordered or altered, or if the
+// checkpoint isn't signed by a key in the JWKS.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+func main() {
+	jwksPath := flag.String("jwks", "", "JWKS file with the signing keys (from /.well-known/jwks.json)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -jwks jwks.json export.json\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *jwksPath == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var keys services.JWKS
+	if err := readJSON(*jwksPath, &keys); err != nil {
+		fail(err)
+	}
+	var export services.LedgerExport
+	if err := readJSON(flag.Arg(0), &export); err != nil {
+		fail(err)
+	}
+
+	if err := services.VerifyLedgerExport(export, keys); err != nil {
+		fail(err)
+	}
+	if len(export.Entries) == 0 {
+		fmt.Printf("✓ Checkpoint of %d entries verified (no entries exported)\n", export.Checkpoint.Size)
+		return
+	}
+	fmt.Printf("✓ Entries %d-%d verified against checkpoint of %d entries signed by %s\n",
+		export.Entries[0].Seq, export.Entries[len(export.Entries)-1].Seq, export.Checkpoint.Size, export.Checkpoint.KeyID)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+	os.Exit(1)
+}
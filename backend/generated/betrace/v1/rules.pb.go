@@ -23,24 +23,82 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type Rule struct {
+// ComplianceControl maps a rule to a compliance control it monitors
+type ComplianceControl struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Expression    string                 `protobuf:"bytes,4,opt,name=expression,proto3" json:"expression,omitempty"`
-	Enabled       bool                   `protobuf:"varint,5,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	Severity      string                 `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`
-	Tags          []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Framework     string                 `protobuf:"bytes,1,opt,name=framework,proto3" json:"framework,omitempty"`                  // soc2, hipaa, gdpr, fedramp
+	ControlId     string                 `protobuf:"bytes,2,opt,name=control_id,json=controlId,proto3" json:"control_id,omitempty"` // e.g. CC6.1; empty maps the whole framework
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComplianceControl) Reset() {
+	*x = ComplianceControl{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComplianceControl) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplianceControl) ProtoMessage() {}
+
+func (x *ComplianceControl) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplianceControl.ProtoReflect.Descriptor instead.
+func (*ComplianceControl) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ComplianceControl) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+func (x *ComplianceControl) GetControlId() string {
+	if x != nil {
+		return x.ControlId
+	}
+	return ""
+}
+
+type Rule struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Expression  string                 `protobuf:"bytes,4,opt,name=expression,proto3" json:"expression,omitempty"`
+	Enabled     bool                   `protobuf:"varint,5,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Severity    string                 `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`
+	Tags        []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Controls    []*ComplianceControl   `protobuf:"bytes,10,rep,name=controls,proto3" json:"controls,omitempty"`
+	Version     int32                  `protobuf:"varint,11,opt,name=version,proto3" json:"version,omitempty"` // Incremented by every change; see ListRuleVersions
+	// "enforcing" or "shadow". Shadow rules are evaluated normally, but their
+	// violations go to the shadow violation store and stream.
+	Mode          string `protobuf:"bytes,12,opt,name=mode,proto3" json:"mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Rule) Reset() {
 	*x = Rule{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[0]
+	mi := &file_betrace_v1_rules_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -52,7 +110,7 @@ func (x *Rule) String() string {
 func (*Rule) ProtoMessage() {}
 
 func (x *Rule) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[0]
+	mi := &file_betrace_v1_rules_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -65,7 +123,7 @@ func (x *Rule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Rule.ProtoReflect.Descriptor instead.
 func (*Rule) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{0}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *Rule) GetId() string {
@@ -131,18 +189,42 @@ func (x *Rule) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Rule) GetControls() []*ComplianceControl {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+func (x *Rule) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Rule) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
 type ListRulesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EnabledOnly   bool                   `protobuf:"varint,1,opt,name=enabled_only,json=enabledOnly,proto3" json:"enabled_only,omitempty"`
 	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
 	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Framework     string                 `protobuf:"bytes,4,opt,name=framework,proto3" json:"framework,omitempty"` // Rules mapped to this compliance framework
+	Control       string                 `protobuf:"bytes,5,opt,name=control,proto3" json:"control,omitempty"`     // Rules mapped to this control; requires framework
+	Mode          string                 `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"`           // "enforcing" or "shadow"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListRulesRequest) Reset() {
 	*x = ListRulesRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[1]
+	mi := &file_betrace_v1_rules_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -154,7 +236,7 @@ func (x *ListRulesRequest) String() string {
 func (*ListRulesRequest) ProtoMessage() {}
 
 func (x *ListRulesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[1]
+	mi := &file_betrace_v1_rules_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -167,7 +249,7 @@ func (x *ListRulesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListRulesRequest.ProtoReflect.Descriptor instead.
 func (*ListRulesRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{1}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ListRulesRequest) GetEnabledOnly() bool {
@@ -191,6 +273,27 @@ func (x *ListRulesRequest) GetTags() []string {
 	return nil
 }
 
+func (x *ListRulesRequest) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+func (x *ListRulesRequest) GetControl() string {
+	if x != nil {
+		return x.Control
+	}
+	return ""
+}
+
+func (x *ListRulesRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
 type ListRulesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Rules         []*Rule                `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
@@ -201,7 +304,7 @@ type ListRulesResponse struct {
 
 func (x *ListRulesResponse) Reset() {
 	*x = ListRulesResponse{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[2]
+	mi := &file_betrace_v1_rules_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -213,7 +316,7 @@ func (x *ListRulesResponse) String() string {
 func (*ListRulesResponse) ProtoMessage() {}
 
 func (x *ListRulesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[2]
+	mi := &file_betrace_v1_rules_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -226,7 +329,7 @@ func (x *ListRulesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListRulesResponse.ProtoReflect.Descriptor instead.
 func (*ListRulesResponse) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{2}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ListRulesResponse) GetRules() []*Rule {
@@ -252,7 +355,7 @@ type GetRuleRequest struct {
 
 func (x *GetRuleRequest) Reset() {
 	*x = GetRuleRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[3]
+	mi := &file_betrace_v1_rules_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -264,7 +367,7 @@ func (x *GetRuleRequest) String() string {
 func (*GetRuleRequest) ProtoMessage() {}
 
 func (x *GetRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[3]
+	mi := &file_betrace_v1_rules_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -277,7 +380,7 @@ func (x *GetRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRuleRequest.ProtoReflect.Descriptor instead.
 func (*GetRuleRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{3}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetRuleRequest) GetId() string {
@@ -295,13 +398,15 @@ type CreateRuleRequest struct {
 	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
 	Severity      string                 `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
 	Tags          []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Controls      []*ComplianceControl   `protobuf:"bytes,7,rep,name=controls,proto3" json:"controls,omitempty"`
+	Mode          string                 `protobuf:"bytes,8,opt,name=mode,proto3" json:"mode,omitempty"` // "enforcing" (default) or "shadow"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateRuleRequest) Reset() {
 	*x = CreateRuleRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[4]
+	mi := &file_betrace_v1_rules_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -313,7 +418,7 @@ func (x *CreateRuleRequest) String() string {
 func (*CreateRuleRequest) ProtoMessage() {}
 
 func (x *CreateRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[4]
+	mi := &file_betrace_v1_rules_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -326,7 +431,7 @@ func (x *CreateRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRuleRequest.ProtoReflect.Descriptor instead.
 func (*CreateRuleRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{4}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CreateRuleRequest) GetName() string {
@@ -371,6 +476,20 @@ func (x *CreateRuleRequest) GetTags() []string {
 	return nil
 }
 
+func (x *CreateRuleRequest) GetControls() []*ComplianceControl {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+func (x *CreateRuleRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
 type UpdateRuleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -380,13 +499,15 @@ type UpdateRuleRequest struct {
 	Enabled       bool                   `protobuf:"varint,5,opt,name=enabled,proto3" json:"enabled,omitempty"`
 	Severity      string                 `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`
 	Tags          []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	Controls      []*ComplianceControl   `protobuf:"bytes,8,rep,name=controls,proto3" json:"controls,omitempty"`
+	Mode          string                 `protobuf:"bytes,9,opt,name=mode,proto3" json:"mode,omitempty"` // "enforcing" (default) or "shadow"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateRuleRequest) Reset() {
 	*x = UpdateRuleRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[5]
+	mi := &file_betrace_v1_rules_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -398,7 +519,7 @@ func (x *UpdateRuleRequest) String() string {
 func (*UpdateRuleRequest) ProtoMessage() {}
 
 func (x *UpdateRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[5]
+	mi := &file_betrace_v1_rules_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -411,7 +532,7 @@ func (x *UpdateRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateRuleRequest.ProtoReflect.Descriptor instead.
 func (*UpdateRuleRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{5}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *UpdateRuleRequest) GetId() string {
@@ -463,6 +584,20 @@ func (x *UpdateRuleRequest) GetTags() []string {
 	return nil
 }
 
+func (x *UpdateRuleRequest) GetControls() []*ComplianceControl {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+func (x *UpdateRuleRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
 type DeleteRuleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -472,7 +607,7 @@ type DeleteRuleRequest struct {
 
 func (x *DeleteRuleRequest) Reset() {
 	*x = DeleteRuleRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[6]
+	mi := &file_betrace_v1_rules_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -484,7 +619,7 @@ func (x *DeleteRuleRequest) String() string {
 func (*DeleteRuleRequest) ProtoMessage() {}
 
 func (x *DeleteRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[6]
+	mi := &file_betrace_v1_rules_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -497,7 +632,7 @@ func (x *DeleteRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteRuleRequest.ProtoReflect.Descriptor instead.
 func (*DeleteRuleRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{6}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DeleteRuleRequest) GetId() string {
@@ -516,7 +651,7 @@ type DeleteRuleResponse struct {
 
 func (x *DeleteRuleResponse) Reset() {
 	*x = DeleteRuleResponse{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[7]
+	mi := &file_betrace_v1_rules_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -528,7 +663,7 @@ func (x *DeleteRuleResponse) String() string {
 func (*DeleteRuleResponse) ProtoMessage() {}
 
 func (x *DeleteRuleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[7]
+	mi := &file_betrace_v1_rules_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -541,7 +676,7 @@ func (x *DeleteRuleResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteRuleResponse.ProtoReflect.Descriptor instead.
 func (*DeleteRuleResponse) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{7}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DeleteRuleResponse) GetSuccess() bool {
@@ -560,7 +695,7 @@ type EnableRuleRequest struct {
 
 func (x *EnableRuleRequest) Reset() {
 	*x = EnableRuleRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[8]
+	mi := &file_betrace_v1_rules_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -572,7 +707,7 @@ func (x *EnableRuleRequest) String() string {
 func (*EnableRuleRequest) ProtoMessage() {}
 
 func (x *EnableRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[8]
+	mi := &file_betrace_v1_rules_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -585,7 +720,7 @@ func (x *EnableRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnableRuleRequest.ProtoReflect.Descriptor instead.
 func (*EnableRuleRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{8}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *EnableRuleRequest) GetId() string {
@@ -604,7 +739,7 @@ type DisableRuleRequest struct {
 
 func (x *DisableRuleRequest) Reset() {
 	*x = DisableRuleRequest{}
-	mi := &file_betrace_v1_rules_proto_msgTypes[9]
+	mi := &file_betrace_v1_rules_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -616,7 +751,7 @@ func (x *DisableRuleRequest) String() string {
 func (*DisableRuleRequest) ProtoMessage() {}
 
 func (x *DisableRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_rules_proto_msgTypes[9]
+	mi := &file_betrace_v1_rules_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -629,7 +764,7 @@ func (x *DisableRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DisableRuleRequest.ProtoReflect.Descriptor instead.
 func (*DisableRuleRequest) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{9}
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DisableRuleRequest) GetId() string {
@@ -639,63 +774,1067 @@ func (x *DisableRuleRequest) GetId() string {
 	return ""
 }
 
-var File_betrace_v1_rules_proto protoreflect.FileDescriptor
+type PromoteRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_betrace_v1_rules_proto_rawDesc = "" +
-	"\n" +
-	"\x16betrace/v1/rules.proto\x12\n" +
-	"betrace.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xac\x02\n" +
-	"\x04Rule\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1e\n" +
-	"\n" +
-	"expression\x18\x04 \x01(\tR\n" +
-	"expression\x12\x18\n" +
-	"\aenabled\x18\x05 \x01(\bR\aenabled\x12\x1a\n" +
-	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x12\n" +
-	"\x04tags\x18\a \x03(\tR\x04tags\x129\n" +
-	"\n" +
-	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
-	"\n" +
-	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"e\n" +
-	"\x10ListRulesRequest\x12!\n" +
-	"\fenabled_only\x18\x01 \x01(\bR\venabledOnly\x12\x1a\n" +
-	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x12\n" +
-	"\x04tags\x18\x03 \x03(\tR\x04tags\"\\\n" +
-	"\x11ListRulesResponse\x12&\n" +
-	"\x05rules\x18\x01 \x03(\v2\x10.betrace.v1.RuleR\x05rules\x12\x1f\n" +
-	"\vtotal_count\x18\x02 \x01(\x05R\n" +
-	"totalCount\" \n" +
-	"\x0eGetRuleRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"\xb3\x01\n" +
-	"\x11CreateRuleRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1e\n" +
-	"\n" +
-	"expression\x18\x03 \x01(\tR\n" +
-	"expression\x12\x18\n" +
-	"\aenabled\x18\x04 \x01(\bR\aenabled\x12\x1a\n" +
-	"\bseverity\x18\x05 \x01(\tR\bseverity\x12\x12\n" +
-	"\x04tags\x18\x06 \x03(\tR\x04tags\"\xc3\x01\n" +
-	"\x11UpdateRuleRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1e\n" +
-	"\n" +
-	"expression\x18\x04 \x01(\tR\n" +
-	"expression\x12\x18\n" +
-	"\aenabled\x18\x05 \x01(\bR\aenabled\x12\x1a\n" +
-	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x12\n" +
-	"\x04tags\x18\a \x03(\tR\x04tags\"#\n" +
-	"\x11DeleteRuleRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\".\n" +
-	"\x12DeleteRuleResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"#\n" +
-	"\x11EnableRuleRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"$\n" +
-	"\x12DisableRuleRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id2\x8e\x05\n" +
+func (x *PromoteRuleRequest) Reset() {
+	*x = PromoteRuleRequest{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromoteRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromoteRuleRequest) ProtoMessage() {}
+
+func (x *PromoteRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromoteRuleRequest.ProtoReflect.Descriptor instead.
+func (*PromoteRuleRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PromoteRuleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListRuleMatchesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Outcome       string                 `protobuf:"bytes,2,opt,name=outcome,proto3" json:"outcome,omitempty"`                      // "violated" or "satisfied"; empty = both
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Inclusive
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Exclusive
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                         // Page size (default 100, max 1000)
+	Cursor        string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`                        // next_cursor from the previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRuleMatchesRequest) Reset() {
+	*x = ListRuleMatchesRequest{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRuleMatchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRuleMatchesRequest) ProtoMessage() {}
+
+func (x *ListRuleMatchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRuleMatchesRequest.ProtoReflect.Descriptor instead.
+func (*ListRuleMatchesRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListRuleMatchesRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ListRuleMatchesRequest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *ListRuleMatchesRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ListRuleMatchesRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *ListRuleMatchesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRuleMatchesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type RuleMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraceId       string                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	Service       string                 `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Outcome       string                 `protobuf:"bytes,3,opt,name=outcome,proto3" json:"outcome,omitempty"` // "violated" or "satisfied"
+	MatchedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=matched_at,json=matchedAt,proto3" json:"matched_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleMatch) Reset() {
+	*x = RuleMatch{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleMatch) ProtoMessage() {}
+
+func (x *RuleMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleMatch.ProtoReflect.Descriptor instead.
+func (*RuleMatch) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RuleMatch) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *RuleMatch) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *RuleMatch) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *RuleMatch) GetMatchedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MatchedAt
+	}
+	return nil
+}
+
+type ListRuleMatchesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Matches       []*RuleMatch           `protobuf:"bytes,1,rep,name=matches,proto3" json:"matches,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRuleMatchesResponse) Reset() {
+	*x = ListRuleMatchesResponse{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRuleMatchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRuleMatchesResponse) ProtoMessage() {}
+
+func (x *ListRuleMatchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRuleMatchesResponse.ProtoReflect.Descriptor instead.
+func (*ListRuleMatchesResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListRuleMatchesResponse) GetMatches() []*RuleMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *ListRuleMatchesResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListRuleMatchesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetControlCoverageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Framework     string                 `protobuf:"bytes,1,opt,name=framework,proto3" json:"framework,omitempty"` // Empty = every framework
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetControlCoverageRequest) Reset() {
+	*x = GetControlCoverageRequest{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetControlCoverageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetControlCoverageRequest) ProtoMessage() {}
+
+func (x *GetControlCoverageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetControlCoverageRequest.ProtoReflect.Descriptor instead.
+func (*GetControlCoverageRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetControlCoverageRequest) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+type ControlCoverage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Framework     string                 `protobuf:"bytes,1,opt,name=framework,proto3" json:"framework,omitempty"`
+	ControlId     string                 `protobuf:"bytes,2,opt,name=control_id,json=controlId,proto3" json:"control_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	RuleIds       []string               `protobuf:"bytes,4,rep,name=rule_ids,json=ruleIds,proto3" json:"rule_ids,omitempty"`
+	EnabledRules  int32                  `protobuf:"varint,5,opt,name=enabled_rules,json=enabledRules,proto3" json:"enabled_rules,omitempty"`
+	Covered       bool                   `protobuf:"varint,6,opt,name=covered,proto3" json:"covered,omitempty"` // At least one enabled rule monitors the control
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlCoverage) Reset() {
+	*x = ControlCoverage{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlCoverage) ProtoMessage() {}
+
+func (x *ControlCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlCoverage.ProtoReflect.Descriptor instead.
+func (*ControlCoverage) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ControlCoverage) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+func (x *ControlCoverage) GetControlId() string {
+	if x != nil {
+		return x.ControlId
+	}
+	return ""
+}
+
+func (x *ControlCoverage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ControlCoverage) GetRuleIds() []string {
+	if x != nil {
+		return x.RuleIds
+	}
+	return nil
+}
+
+func (x *ControlCoverage) GetEnabledRules() int32 {
+	if x != nil {
+		return x.EnabledRules
+	}
+	return 0
+}
+
+func (x *ControlCoverage) GetCovered() bool {
+	if x != nil {
+		return x.Covered
+	}
+	return false
+}
+
+type GetControlCoverageResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Controls       []*ControlCoverage     `protobuf:"bytes,1,rep,name=controls,proto3" json:"controls,omitempty"`
+	CoveredCount   int32                  `protobuf:"varint,2,opt,name=covered_count,json=coveredCount,proto3" json:"covered_count,omitempty"`
+	UncoveredCount int32                  `protobuf:"varint,3,opt,name=uncovered_count,json=uncoveredCount,proto3" json:"uncovered_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetControlCoverageResponse) Reset() {
+	*x = GetControlCoverageResponse{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetControlCoverageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetControlCoverageResponse) ProtoMessage() {}
+
+func (x *GetControlCoverageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetControlCoverageResponse.ProtoReflect.Descriptor instead.
+func (*GetControlCoverageResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetControlCoverageResponse) GetControls() []*ControlCoverage {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+func (x *GetControlCoverageResponse) GetCoveredCount() int32 {
+	if x != nil {
+		return x.CoveredCount
+	}
+	return 0
+}
+
+func (x *GetControlCoverageResponse) GetUncoveredCount() int32 {
+	if x != nil {
+		return x.UncoveredCount
+	}
+	return 0
+}
+
+// RuleVersion is an immutable snapshot of a rule recorded by a change
+type RuleVersion struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RuleId          string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Version         int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Change          string                 `protobuf:"bytes,3,opt,name=change,proto3" json:"change,omitempty"` // created, updated, enabled, disabled, promoted or rolled_back
+	Author          string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"` // Authenticated user who made the change ("unknown" without auth)
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	RestoredVersion int32                  `protobuf:"varint,6,opt,name=restored_version,json=restoredVersion,proto3" json:"restored_version,omitempty"` // Version a rollback restored (0 otherwise)
+	Rule            *Rule                  `protobuf:"bytes,7,opt,name=rule,proto3" json:"rule,omitempty"`                                               // The rule as of this version
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RuleVersion) Reset() {
+	*x = RuleVersion{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleVersion) ProtoMessage() {}
+
+func (x *RuleVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleVersion.ProtoReflect.Descriptor instead.
+func (*RuleVersion) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RuleVersion) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *RuleVersion) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RuleVersion) GetChange() string {
+	if x != nil {
+		return x.Change
+	}
+	return ""
+}
+
+func (x *RuleVersion) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *RuleVersion) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *RuleVersion) GetRestoredVersion() int32 {
+	if x != nil {
+		return x.RestoredVersion
+	}
+	return 0
+}
+
+func (x *RuleVersion) GetRule() *Rule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type ListRuleVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRuleVersionsRequest) Reset() {
+	*x = ListRuleVersionsRequest{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRuleVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRuleVersionsRequest) ProtoMessage() {}
+
+func (x *ListRuleVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRuleVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListRuleVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListRuleVersionsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListRuleVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*RuleVersion         `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRuleVersionsResponse) Reset() {
+	*x = ListRuleVersionsResponse{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRuleVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRuleVersionsResponse) ProtoMessage() {}
+
+func (x *ListRuleVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRuleVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListRuleVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListRuleVersionsResponse) GetVersions() []*RuleVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type DiffRuleVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	From          int32                  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"` // Default: the version before to
+	To            int32                  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`     // Default: the latest version
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffRuleVersionsRequest) Reset() {
+	*x = DiffRuleVersionsRequest{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRuleVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRuleVersionsRequest) ProtoMessage() {}
+
+func (x *DiffRuleVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRuleVersionsRequest.ProtoReflect.Descriptor instead.
+func (*DiffRuleVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DiffRuleVersionsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DiffRuleVersionsRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *DiffRuleVersionsRequest) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+// RuleFieldChange is a rule field that differs between two versions. Lists
+// (tags, controls) are compared as comma-separated values.
+type RuleFieldChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleFieldChange) Reset() {
+	*x = RuleFieldChange{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleFieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleFieldChange) ProtoMessage() {}
+
+func (x *RuleFieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleFieldChange.ProtoReflect.Descriptor instead.
+func (*RuleFieldChange) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RuleFieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *RuleFieldChange) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *RuleFieldChange) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+// DiffLine is one line of a line-by-line expression diff
+type DiffLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Op            string                 `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"` // " " unchanged, "-" only in from, "+" only in to
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffLine) Reset() {
+	*x = DiffLine{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffLine) ProtoMessage() {}
+
+func (x *DiffLine) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffLine.ProtoReflect.Descriptor instead.
+func (*DiffLine) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DiffLine) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *DiffLine) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type DiffRuleVersionsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	From           *RuleVersion           `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To             *RuleVersion           `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Changes        []*RuleFieldChange     `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`                                     // Every changed field except the expression
+	ExpressionDiff []*DiffLine            `protobuf:"bytes,4,rep,name=expression_diff,json=expressionDiff,proto3" json:"expression_diff,omitempty"` // Empty if the expression is unchanged
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DiffRuleVersionsResponse) Reset() {
+	*x = DiffRuleVersionsResponse{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRuleVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRuleVersionsResponse) ProtoMessage() {}
+
+func (x *DiffRuleVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRuleVersionsResponse.ProtoReflect.Descriptor instead.
+func (*DiffRuleVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DiffRuleVersionsResponse) GetFrom() *RuleVersion {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *DiffRuleVersionsResponse) GetTo() *RuleVersion {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *DiffRuleVersionsResponse) GetChanges() []*RuleFieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *DiffRuleVersionsResponse) GetExpressionDiff() []*DiffLine {
+	if x != nil {
+		return x.ExpressionDiff
+	}
+	return nil
+}
+
+type RollbackRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // Version to restore
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackRuleRequest) Reset() {
+	*x = RollbackRuleRequest{}
+	mi := &file_betrace_v1_rules_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackRuleRequest) ProtoMessage() {}
+
+func (x *RollbackRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_rules_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackRuleRequest.ProtoReflect.Descriptor instead.
+func (*RollbackRuleRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_rules_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RollbackRuleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RollbackRuleRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+var File_betrace_v1_rules_proto protoreflect.FileDescriptor
+
+const file_betrace_v1_rules_proto_rawDesc = "" +
+	"\n" +
+	"\x16betrace/v1/rules.proto\x12\n" +
+	"betrace.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"P\n" +
+	"\x11ComplianceControl\x12\x1c\n" +
+	"\tframework\x18\x01 \x01(\tR\tframework\x12\x1d\n" +
+	"\n" +
+	"control_id\x18\x02 \x01(\tR\tcontrolId\"\x95\x03\n" +
+	"\x04Rule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x04 \x01(\tR\n" +
+	"expression\x12\x18\n" +
+	"\aenabled\x18\x05 \x01(\bR\aenabled\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\bcontrols\x18\n" +
+	" \x03(\v2\x1d.betrace.v1.ComplianceControlR\bcontrols\x12\x18\n" +
+	"\aversion\x18\v \x01(\x05R\aversion\x12\x12\n" +
+	"\x04mode\x18\f \x01(\tR\x04mode\"\xb1\x01\n" +
+	"\x10ListRulesRequest\x12!\n" +
+	"\fenabled_only\x18\x01 \x01(\bR\venabledOnly\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x1c\n" +
+	"\tframework\x18\x04 \x01(\tR\tframework\x12\x18\n" +
+	"\acontrol\x18\x05 \x01(\tR\acontrol\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\"\\\n" +
+	"\x11ListRulesResponse\x12&\n" +
+	"\x05rules\x18\x01 \x03(\v2\x10.betrace.v1.RuleR\x05rules\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\" \n" +
+	"\x0eGetRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x82\x02\n" +
+	"\x11CreateRuleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x03 \x01(\tR\n" +
+	"expression\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\x12\x1a\n" +
+	"\bseverity\x18\x05 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x129\n" +
+	"\bcontrols\x18\a \x03(\v2\x1d.betrace.v1.ComplianceControlR\bcontrols\x12\x12\n" +
+	"\x04mode\x18\b \x01(\tR\x04mode\"\x92\x02\n" +
+	"\x11UpdateRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x04 \x01(\tR\n" +
+	"expression\x12\x18\n" +
+	"\aenabled\x18\x05 \x01(\bR\aenabled\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x129\n" +
+	"\bcontrols\x18\b \x03(\v2\x1d.betrace.v1.ComplianceControlR\bcontrols\x12\x12\n" +
+	"\x04mode\x18\t \x01(\tR\x04mode\"#\n" +
+	"\x11DeleteRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\".\n" +
+	"\x12DeleteRuleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"#\n" +
+	"\x11EnableRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"$\n" +
+	"\x12DisableRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"$\n" +
+	"\x12PromoteRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xe2\x01\n" +
+	"\x16ListRuleMatchesRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aoutcome\x18\x02 \x01(\tR\aoutcome\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\"\x95\x01\n" +
+	"\tRuleMatch\x12\x19\n" +
+	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12\x18\n" +
+	"\aservice\x18\x02 \x01(\tR\aservice\x12\x18\n" +
+	"\aoutcome\x18\x03 \x01(\tR\aoutcome\x129\n" +
+	"\n" +
+	"matched_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tmatchedAt\"\x8c\x01\n" +
+	"\x17ListRuleMatchesResponse\x12/\n" +
+	"\amatches\x18\x01 \x03(\v2\x15.betrace.v1.RuleMatchR\amatches\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"9\n" +
+	"\x19GetControlCoverageRequest\x12\x1c\n" +
+	"\tframework\x18\x01 \x01(\tR\tframework\"\xbc\x01\n" +
+	"\x0fControlCoverage\x12\x1c\n" +
+	"\tframework\x18\x01 \x01(\tR\tframework\x12\x1d\n" +
+	"\n" +
+	"control_id\x18\x02 \x01(\tR\tcontrolId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x19\n" +
+	"\brule_ids\x18\x04 \x03(\tR\aruleIds\x12#\n" +
+	"\renabled_rules\x18\x05 \x01(\x05R\fenabledRules\x12\x18\n" +
+	"\acovered\x18\x06 \x01(\bR\acovered\"\xa3\x01\n" +
+	"\x1aGetControlCoverageResponse\x127\n" +
+	"\bcontrols\x18\x01 \x03(\v2\x1b.betrace.v1.ControlCoverageR\bcontrols\x12#\n" +
+	"\rcovered_count\x18\x02 \x01(\x05R\fcoveredCount\x12'\n" +
+	"\x0funcovered_count\x18\x03 \x01(\x05R\x0euncoveredCount\"\xfc\x01\n" +
+	"\vRuleVersion\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x16\n" +
+	"\x06change\x18\x03 \x01(\tR\x06change\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12)\n" +
+	"\x10restored_version\x18\x06 \x01(\x05R\x0frestoredVersion\x12$\n" +
+	"\x04rule\x18\a \x01(\v2\x10.betrace.v1.RuleR\x04rule\")\n" +
+	"\x17ListRuleVersionsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"O\n" +
+	"\x18ListRuleVersionsResponse\x123\n" +
+	"\bversions\x18\x01 \x03(\v2\x17.betrace.v1.RuleVersionR\bversions\"M\n" +
+	"\x17DiffRuleVersionsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x05R\x02to\"K\n" +
+	"\x0fRuleFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\".\n" +
+	"\bDiffLine\x12\x0e\n" +
+	"\x02op\x18\x01 \x01(\tR\x02op\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"\xe6\x01\n" +
+	"\x18DiffRuleVersionsResponse\x12+\n" +
+	"\x04from\x18\x01 \x01(\v2\x17.betrace.v1.RuleVersionR\x04from\x12'\n" +
+	"\x02to\x18\x02 \x01(\v2\x17.betrace.v1.RuleVersionR\x02to\x125\n" +
+	"\achanges\x18\x03 \x03(\v2\x1b.betrace.v1.RuleFieldChangeR\achanges\x12=\n" +
+	"\x0fexpression_diff\x18\x04 \x03(\v2\x14.betrace.v1.DiffLineR\x0eexpressionDiff\"?\n" +
+	"\x13RollbackRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion2\xd9\n" +
+	"\n" +
 	"\vRuleService\x12[\n" +
 	"\tListRules\x12\x1c.betrace.v1.ListRulesRequest\x1a\x1d.betrace.v1.ListRulesResponse\"\x11\x82\xd3\xe4\x93\x02\v\x12\t/v1/rules\x12O\n" +
 	"\aGetRule\x12\x1a.betrace.v1.GetRuleRequest\x1a\x10.betrace.v1.Rule\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/rules/{id}\x12S\n" +
@@ -707,7 +1846,13 @@ const file_betrace_v1_rules_proto_rawDesc = "" +
 	"DeleteRule\x12\x1d.betrace.v1.DeleteRuleRequest\x1a\x1e.betrace.v1.DeleteRuleResponse\"\x16\x82\xd3\xe4\x93\x02\x10*\x0e/v1/rules/{id}\x12\\\n" +
 	"\n" +
 	"EnableRule\x12\x1d.betrace.v1.EnableRuleRequest\x1a\x10.betrace.v1.Rule\"\x1d\x82\xd3\xe4\x93\x02\x17\"\x15/v1/rules/{id}/enable\x12_\n" +
-	"\vDisableRule\x12\x1e.betrace.v1.DisableRuleRequest\x1a\x10.betrace.v1.Rule\"\x1e\x82\xd3\xe4\x93\x02\x18\"\x16/v1/rules/{id}/disableBCZAgithub.com/betracehq/betrace/backend/generated/betrace/v1;betraceb\x06proto3"
+	"\vDisableRule\x12\x1e.betrace.v1.DisableRuleRequest\x1a\x10.betrace.v1.Rule\"\x1e\x82\xd3\xe4\x93\x02\x18\"\x16/v1/rules/{id}/disable\x12z\n" +
+	"\x0fListRuleMatches\x12\".betrace.v1.ListRuleMatchesRequest\x1a#.betrace.v1.ListRuleMatchesResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/rules/{id}/matches\x12\x7f\n" +
+	"\x12GetControlCoverage\x12%.betrace.v1.GetControlCoverageRequest\x1a&.betrace.v1.GetControlCoverageResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/rules:coverage\x12_\n" +
+	"\vPromoteRule\x12\x1e.betrace.v1.PromoteRuleRequest\x1a\x10.betrace.v1.Rule\"\x1e\x82\xd3\xe4\x93\x02\x18\"\x16/v1/rules/{id}:promote\x12~\n" +
+	"\x10ListRuleVersions\x12#.betrace.v1.ListRuleVersionsRequest\x1a$.betrace.v1.ListRuleVersionsResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/rules/{id}/versions\x12\x83\x01\n" +
+	"\x10DiffRuleVersions\x12#.betrace.v1.DiffRuleVersionsRequest\x1a$.betrace.v1.DiffRuleVersionsResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/v1/rules/{id}/versions:diff\x12e\n" +
+	"\fRollbackRule\x12\x1f.betrace.v1.RollbackRuleRequest\x1a\x10.betrace.v1.Rule\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/rules/{id}:rollbackBCZAgithub.com/betracehq/betrace/backend/generated/betrace/v1;betraceb\x06proto3"
 
 var (
 	file_betrace_v1_rules_proto_rawDescOnce sync.Once
@@ -721,43 +1866,86 @@ func file_betrace_v1_rules_proto_rawDescGZIP() []byte {
 	return file_betrace_v1_rules_proto_rawDescData
 }
 
-var file_betrace_v1_rules_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_betrace_v1_rules_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_betrace_v1_rules_proto_goTypes = []any{
-	(*Rule)(nil),                  // 0: betrace.v1.Rule
-	(*ListRulesRequest)(nil),      // 1: betrace.v1.ListRulesRequest
-	(*ListRulesResponse)(nil),     // 2: betrace.v1.ListRulesResponse
-	(*GetRuleRequest)(nil),        // 3: betrace.v1.GetRuleRequest
-	(*CreateRuleRequest)(nil),     // 4: betrace.v1.CreateRuleRequest
-	(*UpdateRuleRequest)(nil),     // 5: betrace.v1.UpdateRuleRequest
-	(*DeleteRuleRequest)(nil),     // 6: betrace.v1.DeleteRuleRequest
-	(*DeleteRuleResponse)(nil),    // 7: betrace.v1.DeleteRuleResponse
-	(*EnableRuleRequest)(nil),     // 8: betrace.v1.EnableRuleRequest
-	(*DisableRuleRequest)(nil),    // 9: betrace.v1.DisableRuleRequest
-	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*ComplianceControl)(nil),          // 0: betrace.v1.ComplianceControl
+	(*Rule)(nil),                       // 1: betrace.v1.Rule
+	(*ListRulesRequest)(nil),           // 2: betrace.v1.ListRulesRequest
+	(*ListRulesResponse)(nil),          // 3: betrace.v1.ListRulesResponse
+	(*GetRuleRequest)(nil),             // 4: betrace.v1.GetRuleRequest
+	(*CreateRuleRequest)(nil),          // 5: betrace.v1.CreateRuleRequest
+	(*UpdateRuleRequest)(nil),          // 6: betrace.v1.UpdateRuleRequest
+	(*DeleteRuleRequest)(nil),          // 7: betrace.v1.DeleteRuleRequest
+	(*DeleteRuleResponse)(nil),         // 8: betrace.v1.DeleteRuleResponse
+	(*EnableRuleRequest)(nil),          // 9: betrace.v1.EnableRuleRequest
+	(*DisableRuleRequest)(nil),         // 10: betrace.v1.DisableRuleRequest
+	(*PromoteRuleRequest)(nil),         // 11: betrace.v1.PromoteRuleRequest
+	(*ListRuleMatchesRequest)(nil),     // 12: betrace.v1.ListRuleMatchesRequest
+	(*RuleMatch)(nil),                  // 13: betrace.v1.RuleMatch
+	(*ListRuleMatchesResponse)(nil),    // 14: betrace.v1.ListRuleMatchesResponse
+	(*GetControlCoverageRequest)(nil),  // 15: betrace.v1.GetControlCoverageRequest
+	(*ControlCoverage)(nil),            // 16: betrace.v1.ControlCoverage
+	(*GetControlCoverageResponse)(nil), // 17: betrace.v1.GetControlCoverageResponse
+	(*RuleVersion)(nil),                // 18: betrace.v1.RuleVersion
+	(*ListRuleVersionsRequest)(nil),    // 19: betrace.v1.ListRuleVersionsRequest
+	(*ListRuleVersionsResponse)(nil),   // 20: betrace.v1.ListRuleVersionsResponse
+	(*DiffRuleVersionsRequest)(nil),    // 21: betrace.v1.DiffRuleVersionsRequest
+	(*RuleFieldChange)(nil),            // 22: betrace.v1.RuleFieldChange
+	(*DiffLine)(nil),                   // 23: betrace.v1.DiffLine
+	(*DiffRuleVersionsResponse)(nil),   // 24: betrace.v1.DiffRuleVersionsResponse
+	(*RollbackRuleRequest)(nil),        // 25: betrace.v1.RollbackRuleRequest
+	(*timestamppb.Timestamp)(nil),      // 26: google.protobuf.Timestamp
 }
 var file_betrace_v1_rules_proto_depIdxs = []int32{
-	10, // 0: betrace.v1.Rule.created_at:type_name -> google.protobuf.Timestamp
-	10, // 1: betrace.v1.Rule.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 2: betrace.v1.ListRulesResponse.rules:type_name -> betrace.v1.Rule
-	1,  // 3: betrace.v1.RuleService.ListRules:input_type -> betrace.v1.ListRulesRequest
-	3,  // 4: betrace.v1.RuleService.GetRule:input_type -> betrace.v1.GetRuleRequest
-	4,  // 5: betrace.v1.RuleService.CreateRule:input_type -> betrace.v1.CreateRuleRequest
-	5,  // 6: betrace.v1.RuleService.UpdateRule:input_type -> betrace.v1.UpdateRuleRequest
-	6,  // 7: betrace.v1.RuleService.DeleteRule:input_type -> betrace.v1.DeleteRuleRequest
-	8,  // 8: betrace.v1.RuleService.EnableRule:input_type -> betrace.v1.EnableRuleRequest
-	9,  // 9: betrace.v1.RuleService.DisableRule:input_type -> betrace.v1.DisableRuleRequest
-	2,  // 10: betrace.v1.RuleService.ListRules:output_type -> betrace.v1.ListRulesResponse
-	0,  // 11: betrace.v1.RuleService.GetRule:output_type -> betrace.v1.Rule
-	0,  // 12: betrace.v1.RuleService.CreateRule:output_type -> betrace.v1.Rule
-	0,  // 13: betrace.v1.RuleService.UpdateRule:output_type -> betrace.v1.Rule
-	7,  // 14: betrace.v1.RuleService.DeleteRule:output_type -> betrace.v1.DeleteRuleResponse
-	0,  // 15: betrace.v1.RuleService.EnableRule:output_type -> betrace.v1.Rule
-	0,  // 16: betrace.v1.RuleService.DisableRule:output_type -> betrace.v1.Rule
-	10, // [10:17] is the sub-list for method output_type
-	3,  // [3:10] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	26, // 0: betrace.v1.Rule.created_at:type_name -> google.protobuf.Timestamp
+	26, // 1: betrace.v1.Rule.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: betrace.v1.Rule.controls:type_name -> betrace.v1.ComplianceControl
+	1,  // 3: betrace.v1.ListRulesResponse.rules:type_name -> betrace.v1.Rule
+	0,  // 4: betrace.v1.CreateRuleRequest.controls:type_name -> betrace.v1.ComplianceControl
+	0,  // 5: betrace.v1.UpdateRuleRequest.controls:type_name -> betrace.v1.ComplianceControl
+	26, // 6: betrace.v1.ListRuleMatchesRequest.start_time:type_name -> google.protobuf.Timestamp
+	26, // 7: betrace.v1.ListRuleMatchesRequest.end_time:type_name -> google.protobuf.Timestamp
+	26, // 8: betrace.v1.RuleMatch.matched_at:type_name -> google.protobuf.Timestamp
+	13, // 9: betrace.v1.ListRuleMatchesResponse.matches:type_name -> betrace.v1.RuleMatch
+	16, // 10: betrace.v1.GetControlCoverageResponse.controls:type_name -> betrace.v1.ControlCoverage
+	26, // 11: betrace.v1.RuleVersion.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 12: betrace.v1.RuleVersion.rule:type_name -> betrace.v1.Rule
+	18, // 13: betrace.v1.ListRuleVersionsResponse.versions:type_name -> betrace.v1.RuleVersion
+	18, // 14: betrace.v1.DiffRuleVersionsResponse.from:type_name -> betrace.v1.RuleVersion
+	18, // 15: betrace.v1.DiffRuleVersionsResponse.to:type_name -> betrace.v1.RuleVersion
+	22, // 16: betrace.v1.DiffRuleVersionsResponse.changes:type_name -> betrace.v1.RuleFieldChange
+	23, // 17: betrace.v1.DiffRuleVersionsResponse.expression_diff:type_name -> betrace.v1.DiffLine
+	2,  // 18: betrace.v1.RuleService.ListRules:input_type -> betrace.v1.ListRulesRequest
+	4,  // 19: betrace.v1.RuleService.GetRule:input_type -> betrace.v1.GetRuleRequest
+	5,  // 20: betrace.v1.RuleService.CreateRule:input_type -> betrace.v1.CreateRuleRequest
+	6,  // 21: betrace.v1.RuleService.UpdateRule:input_type -> betrace.v1.UpdateRuleRequest
+	7,  // 22: betrace.v1.RuleService.DeleteRule:input_type -> betrace.v1.DeleteRuleRequest
+	9,  // 23: betrace.v1.RuleService.EnableRule:input_type -> betrace.v1.EnableRuleRequest
+	10, // 24: betrace.v1.RuleService.DisableRule:input_type -> betrace.v1.DisableRuleRequest
+	12, // 25: betrace.v1.RuleService.ListRuleMatches:input_type -> betrace.v1.ListRuleMatchesRequest
+	15, // 26: betrace.v1.RuleService.GetControlCoverage:input_type -> betrace.v1.GetControlCoverageRequest
+	11, // 27: betrace.v1.RuleService.PromoteRule:input_type -> betrace.v1.PromoteRuleRequest
+	19, // 28: betrace.v1.RuleService.ListRuleVersions:input_type -> betrace.v1.ListRuleVersionsRequest
+	21, // 29: betrace.v1.RuleService.DiffRuleVersions:input_type -> betrace.v1.DiffRuleVersionsRequest
+	25, // 30: betrace.v1.RuleService.RollbackRule:input_type -> betrace.v1.RollbackRuleRequest
+	3,  // 31: betrace.v1.RuleService.ListRules:output_type -> betrace.v1.ListRulesResponse
+	1,  // 32: betrace.v1.RuleService.GetRule:output_type -> betrace.v1.Rule
+	1,  // 33: betrace.v1.RuleService.CreateRule:output_type -> betrace.v1.Rule
+	1,  // 34: betrace.v1.RuleService.UpdateRule:output_type -> betrace.v1.Rule
+	8,  // 35: betrace.v1.RuleService.DeleteRule:output_type -> betrace.v1.DeleteRuleResponse
+	1,  // 36: betrace.v1.RuleService.EnableRule:output_type -> betrace.v1.Rule
+	1,  // 37: betrace.v1.RuleService.DisableRule:output_type -> betrace.v1.Rule
+	14, // 38: betrace.v1.RuleService.ListRuleMatches:output_type -> betrace.v1.ListRuleMatchesResponse
+	17, // 39: betrace.v1.RuleService.GetControlCoverage:output_type -> betrace.v1.GetControlCoverageResponse
+	1,  // 40: betrace.v1.RuleService.PromoteRule:output_type -> betrace.v1.Rule
+	20, // 41: betrace.v1.RuleService.ListRuleVersions:output_type -> betrace.v1.ListRuleVersionsResponse
+	24, // 42: betrace.v1.RuleService.DiffRuleVersions:output_type -> betrace.v1.DiffRuleVersionsResponse
+	1,  // 43: betrace.v1.RuleService.RollbackRule:output_type -> betrace.v1.Rule
+	31, // [31:44] is the sub-list for method output_type
+	18, // [18:31] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_betrace_v1_rules_proto_init() }
@@ -771,7 +1959,7 @@ func file_betrace_v1_rules_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_betrace_v1_rules_proto_rawDesc), len(file_betrace_v1_rules_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
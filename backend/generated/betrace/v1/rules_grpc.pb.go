@@ -19,13 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RuleService_ListRules_FullMethodName   = "/betrace.v1.RuleService/ListRules"
-	RuleService_GetRule_FullMethodName     = "/betrace.v1.RuleService/GetRule"
-	RuleService_CreateRule_FullMethodName  = "/betrace.v1.RuleService/CreateRule"
-	RuleService_UpdateRule_FullMethodName  = "/betrace.v1.RuleService/UpdateRule"
-	RuleService_DeleteRule_FullMethodName  = "/betrace.v1.RuleService/DeleteRule"
-	RuleService_EnableRule_FullMethodName  = "/betrace.v1.RuleService/EnableRule"
-	RuleService_DisableRule_FullMethodName = "/betrace.v1.RuleService/DisableRule"
+	RuleService_ListRules_FullMethodName          = "/betrace.v1.RuleService/ListRules"
+	RuleService_GetRule_FullMethodName            = "/betrace.v1.RuleService/GetRule"
+	RuleService_CreateRule_FullMethodName         = "/betrace.v1.RuleService/CreateRule"
+	RuleService_UpdateRule_FullMethodName         = "/betrace.v1.RuleService/UpdateRule"
+	RuleService_DeleteRule_FullMethodName         = "/betrace.v1.RuleService/DeleteRule"
+	RuleService_EnableRule_FullMethodName         = "/betrace.v1.RuleService/EnableRule"
+	RuleService_DisableRule_FullMethodName        = "/betrace.v1.RuleService/DisableRule"
+	RuleService_ListRuleMatches_FullMethodName    = "/betrace.v1.RuleService/ListRuleMatches"
+	RuleService_GetControlCoverage_FullMethodName = "/betrace.v1.RuleService/GetControlCoverage"
+	RuleService_PromoteRule_FullMethodName        = "/betrace.v1.RuleService/PromoteRule"
+	RuleService_ListRuleVersions_FullMethodName   = "/betrace.v1.RuleService/ListRuleVersions"
+	RuleService_DiffRuleVersions_FullMethodName   = "/betrace.v1.RuleService/DiffRuleVersions"
+	RuleService_RollbackRule_FullMethodName       = "/betrace.v1.RuleService/RollbackRule"
 )
 
 // RuleServiceClient is the client API for RuleService service.
@@ -48,6 +54,23 @@ type RuleServiceClient interface {
 	EnableRule(ctx context.Context, in *EnableRuleRequest, opts ...grpc.CallOption) (*Rule, error)
 	// DisableRule disables an enabled rule
 	DisableRule(ctx context.Context, in *DisableRuleRequest, opts ...grpc.CallOption) (*Rule, error)
+	// ListRuleMatches returns the traces a rule's when clause recently matched
+	// and whether they violated it, newest first
+	ListRuleMatches(ctx context.Context, in *ListRuleMatchesRequest, opts ...grpc.CallOption) (*ListRuleMatchesResponse, error)
+	// GetControlCoverage lists compliance controls with the rules monitoring
+	// them, including catalog controls no rule covers
+	GetControlCoverage(ctx context.Context, in *GetControlCoverageRequest, opts ...grpc.CallOption) (*GetControlCoverageResponse, error)
+	// PromoteRule switches a shadow rule to enforcing, so its violations go
+	// to the real violation store and stream
+	PromoteRule(ctx context.Context, in *PromoteRuleRequest, opts ...grpc.CallOption) (*Rule, error)
+	// ListRuleVersions returns every recorded version of a rule, oldest first.
+	// History outlives the rule: it is kept after the rule is deleted.
+	ListRuleVersions(ctx context.Context, in *ListRuleVersionsRequest, opts ...grpc.CallOption) (*ListRuleVersionsResponse, error)
+	// DiffRuleVersions compares two versions of a rule
+	DiffRuleVersions(ctx context.Context, in *DiffRuleVersionsRequest, opts ...grpc.CallOption) (*DiffRuleVersionsResponse, error)
+	// RollbackRule restores an earlier version of a rule as a new version,
+	// keeping the rule's current mode and enabled state
+	RollbackRule(ctx context.Context, in *RollbackRuleRequest, opts ...grpc.CallOption) (*Rule, error)
 }
 
 type ruleServiceClient struct {
@@ -128,6 +151,66 @@ func (c *ruleServiceClient) DisableRule(ctx context.Context, in *DisableRuleRequ
 	return out, nil
 }
 
+func (c *ruleServiceClient) ListRuleMatches(ctx context.Context, in *ListRuleMatchesRequest, opts ...grpc.CallOption) (*ListRuleMatchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRuleMatchesResponse)
+	err := c.cc.Invoke(ctx, RuleService_ListRuleMatches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleServiceClient) GetControlCoverage(ctx context.Context, in *GetControlCoverageRequest, opts ...grpc.CallOption) (*GetControlCoverageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetControlCoverageResponse)
+	err := c.cc.Invoke(ctx, RuleService_GetControlCoverage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleServiceClient) PromoteRule(ctx context.Context, in *PromoteRuleRequest, opts ...grpc.CallOption) (*Rule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Rule)
+	err := c.cc.Invoke(ctx, RuleService_PromoteRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleServiceClient) ListRuleVersions(ctx context.Context, in *ListRuleVersionsRequest, opts ...grpc.CallOption) (*ListRuleVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRuleVersionsResponse)
+	err := c.cc.Invoke(ctx, RuleService_ListRuleVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleServiceClient) DiffRuleVersions(ctx context.Context, in *DiffRuleVersionsRequest, opts ...grpc.CallOption) (*DiffRuleVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffRuleVersionsResponse)
+	err := c.cc.Invoke(ctx, RuleService_DiffRuleVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleServiceClient) RollbackRule(ctx context.Context, in *RollbackRuleRequest, opts ...grpc.CallOption) (*Rule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Rule)
+	err := c.cc.Invoke(ctx, RuleService_RollbackRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RuleServiceServer is the server API for RuleService service.
 // All implementations must embed UnimplementedRuleServiceServer
 // for forward compatibility.
@@ -148,6 +231,23 @@ type RuleServiceServer interface {
 	EnableRule(context.Context, *EnableRuleRequest) (*Rule, error)
 	// DisableRule disables an enabled rule
 	DisableRule(context.Context, *DisableRuleRequest) (*Rule, error)
+	// ListRuleMatches returns the traces a rule's when clause recently matched
+	// and whether they violated it, newest first
+	ListRuleMatches(context.Context, *ListRuleMatchesRequest) (*ListRuleMatchesResponse, error)
+	// GetControlCoverage lists compliance controls with the rules monitoring
+	// them, including catalog controls no rule covers
+	GetControlCoverage(context.Context, *GetControlCoverageRequest) (*GetControlCoverageResponse, error)
+	// PromoteRule switches a shadow rule to enforcing, so its violations go
+	// to the real violation store and stream
+	PromoteRule(context.Context, *PromoteRuleRequest) (*Rule, error)
+	// ListRuleVersions returns every recorded version of a rule, oldest first.
+	// History outlives the rule: it is kept after the rule is deleted.
+	ListRuleVersions(context.Context, *ListRuleVersionsRequest) (*ListRuleVersionsResponse, error)
+	// DiffRuleVersions compares two versions of a rule
+	DiffRuleVersions(context.Context, *DiffRuleVersionsRequest) (*DiffRuleVersionsResponse, error)
+	// RollbackRule restores an earlier version of a rule as a new version,
+	// keeping the rule's current mode and enabled state
+	RollbackRule(context.Context, *RollbackRuleRequest) (*Rule, error)
 	mustEmbedUnimplementedRuleServiceServer()
 }
 
@@ -179,6 +279,24 @@ func (UnimplementedRuleServiceServer) EnableRule(context.Context, *EnableRuleReq
 func (UnimplementedRuleServiceServer) DisableRule(context.Context, *DisableRuleRequest) (*Rule, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DisableRule not implemented")
 }
+func (UnimplementedRuleServiceServer) ListRuleMatches(context.Context, *ListRuleMatchesRequest) (*ListRuleMatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRuleMatches not implemented")
+}
+func (UnimplementedRuleServiceServer) GetControlCoverage(context.Context, *GetControlCoverageRequest) (*GetControlCoverageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetControlCoverage not implemented")
+}
+func (UnimplementedRuleServiceServer) PromoteRule(context.Context, *PromoteRuleRequest) (*Rule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteRule not implemented")
+}
+func (UnimplementedRuleServiceServer) ListRuleVersions(context.Context, *ListRuleVersionsRequest) (*ListRuleVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRuleVersions not implemented")
+}
+func (UnimplementedRuleServiceServer) DiffRuleVersions(context.Context, *DiffRuleVersionsRequest) (*DiffRuleVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffRuleVersions not implemented")
+}
+func (UnimplementedRuleServiceServer) RollbackRule(context.Context, *RollbackRuleRequest) (*Rule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackRule not implemented")
+}
 func (UnimplementedRuleServiceServer) mustEmbedUnimplementedRuleServiceServer() {}
 func (UnimplementedRuleServiceServer) testEmbeddedByValue()                     {}
 
@@ -326,6 +444,114 @@ func _RuleService_DisableRule_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RuleService_ListRuleMatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRuleMatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleServiceServer).ListRuleMatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleService_ListRuleMatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleServiceServer).ListRuleMatches(ctx, req.(*ListRuleMatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleService_GetControlCoverage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetControlCoverageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleServiceServer).GetControlCoverage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleService_GetControlCoverage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleServiceServer).GetControlCoverage(ctx, req.(*GetControlCoverageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleService_PromoteRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleServiceServer).PromoteRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleService_PromoteRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleServiceServer).PromoteRule(ctx, req.(*PromoteRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleService_ListRuleVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRuleVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleServiceServer).ListRuleVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleService_ListRuleVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleServiceServer).ListRuleVersions(ctx, req.(*ListRuleVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleService_DiffRuleVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffRuleVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleServiceServer).DiffRuleVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleService_DiffRuleVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleServiceServer).DiffRuleVersions(ctx, req.(*DiffRuleVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleService_RollbackRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleServiceServer).RollbackRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleService_RollbackRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleServiceServer).RollbackRule(ctx, req.(*RollbackRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RuleService_ServiceDesc is the grpc.ServiceDesc for RuleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -361,6 +587,30 @@ var RuleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DisableRule",
 			Handler:    _RuleService_DisableRule_Handler,
 		},
+		{
+			MethodName: "ListRuleMatches",
+			Handler:    _RuleService_ListRuleMatches_Handler,
+		},
+		{
+			MethodName: "GetControlCoverage",
+			Handler:    _RuleService_GetControlCoverage_Handler,
+		},
+		{
+			MethodName: "PromoteRule",
+			Handler:    _RuleService_PromoteRule_Handler,
+		},
+		{
+			MethodName: "ListRuleVersions",
+			Handler:    _RuleService_ListRuleVersions_Handler,
+		},
+		{
+			MethodName: "DiffRuleVersions",
+			Handler:    _RuleService_DiffRuleVersions_Handler,
+		},
+		{
+			MethodName: "RollbackRule",
+			Handler:    _RuleService_RollbackRule_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "betrace/v1/rules.proto",
@@ -186,7 +186,8 @@ type Span struct {
 	EndTime       int64                  `protobuf:"varint,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Unix nanoseconds
 	DurationMs    int64                  `protobuf:"varint,7,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
 	Attributes    map[string]string      `protobuf:"bytes,8,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"` // Simplified from SpanStatus
+	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`                               // Simplified from SpanStatus
+	ServiceName   string                 `protobuf:"bytes,10,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"` // OTel resource service.name; defaults to the "service.name" attribute
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -284,6 +285,13 @@ func (x *Span) GetStatus() string {
 	return ""
 }
 
+func (x *Span) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
 type SpanStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          StatusCode             `protobuf:"varint,1,opt,name=code,proto3,enum=betrace.v1.StatusCode" json:"code,omitempty"`
@@ -347,7 +355,7 @@ const file_betrace_v1_spans_proto_rawDesc = "" +
 	"\x13IngestSpansResponse\x12\x1a\n" +
 	"\baccepted\x18\x01 \x01(\x05R\baccepted\x12\x1a\n" +
 	"\brejected\x18\x02 \x01(\x05R\brejected\x12\x16\n" +
-	"\x06errors\x18\x03 \x03(\tR\x06errors\"\xe8\x02\n" +
+	"\x06errors\x18\x03 \x03(\tR\x06errors\"\x8b\x03\n" +
 	"\x04Span\x12\x19\n" +
 	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12\x17\n" +
 	"\aspan_id\x18\x02 \x01(\tR\x06spanId\x12$\n" +
@@ -361,7 +369,9 @@ const file_betrace_v1_spans_proto_rawDesc = "" +
 	"\n" +
 	"attributes\x18\b \x03(\v2 .betrace.v1.Span.AttributesEntryR\n" +
 	"attributes\x12\x16\n" +
-	"\x06status\x18\t \x01(\tR\x06status\x1a=\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12!\n" +
+	"\fservice_name\x18\n" +
+	" \x01(\tR\vserviceName\x1a=\n" +
 	"\x0fAttributesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"R\n" +
@@ -27,9 +27,18 @@ type ListViolationsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
 	TraceId       string                 `protobuf:"bytes,2,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
-	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	EndTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
-	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Inclusive
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Exclusive
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                         // Page size (default 100, max 1000)
+	Severity      string                 `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`
+	Tags          []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`                                  // Violation must carry every tag
+	ServiceName   string                 `protobuf:"bytes,8,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"` // Any referenced span from this service
+	Cursor        string                 `protobuf:"bytes,9,opt,name=cursor,proto3" json:"cursor,omitempty"`                              // next_cursor from the previous page
+	Order         string                 `protobuf:"bytes,10,opt,name=order,proto3" json:"order,omitempty"`                               // "newest" (default) or "oldest"
+	Fingerprint   string                 `protobuf:"bytes,11,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`                   // Violations of one incident
+	Status        string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`                             // Triage status ("open" includes never-triaged)
+	Assignee      string                 `protobuf:"bytes,13,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Shadow        bool                   `protobuf:"varint,14,opt,name=shadow,proto3" json:"shadow,omitempty"` // List the would-be violations of shadow rules instead
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -99,17 +108,210 @@ func (x *ListViolationsRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *ListViolationsRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListViolationsRequest) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *ListViolationsRequest) GetShadow() bool {
+	if x != nil {
+		return x.Shadow
+	}
+	return false
+}
+
+type WatchViolationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`      // Violation must carry every tag
+	Cursor        string                 `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`  // Resume after this event's cursor
+	Shadow        bool                   `protobuf:"varint,5,opt,name=shadow,proto3" json:"shadow,omitempty"` // Watch the would-be violations of shadow rules instead
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchViolationsRequest) Reset() {
+	*x = WatchViolationsRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchViolationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchViolationsRequest) ProtoMessage() {}
+
+func (x *WatchViolationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchViolationsRequest.ProtoReflect.Descriptor instead.
+func (*WatchViolationsRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WatchViolationsRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *WatchViolationsRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *WatchViolationsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *WatchViolationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *WatchViolationsRequest) GetShadow() bool {
+	if x != nil {
+		return x.Shadow
+	}
+	return false
+}
+
+// ViolationEvent is one streamed violation
+type ViolationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Violation     *Violation             `protobuf:"bytes,1,opt,name=violation,proto3" json:"violation,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"` // Pass as WatchViolationsRequest.cursor to resume after this event
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ViolationEvent) Reset() {
+	*x = ViolationEvent{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ViolationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViolationEvent) ProtoMessage() {}
+
+func (x *ViolationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViolationEvent.ProtoReflect.Descriptor instead.
+func (*ViolationEvent) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ViolationEvent) GetViolation() *Violation {
+	if x != nil {
+		return x.Violation
+	}
+	return nil
+}
+
+func (x *ViolationEvent) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type ListViolationsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Violations    []*Violation           `protobuf:"bytes,1,rep,name=violations,proto3" json:"violations,omitempty"`
-	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"` // Matches across all pages
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`  // Empty on the last page
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListViolationsResponse) Reset() {
 	*x = ListViolationsResponse{}
-	mi := &file_betrace_v1_violations_proto_msgTypes[1]
+	mi := &file_betrace_v1_violations_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -121,7 +323,7 @@ func (x *ListViolationsResponse) String() string {
 func (*ListViolationsResponse) ProtoMessage() {}
 
 func (x *ListViolationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_violations_proto_msgTypes[1]
+	mi := &file_betrace_v1_violations_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -134,7 +336,7 @@ func (x *ListViolationsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListViolationsResponse.ProtoReflect.Descriptor instead.
 func (*ListViolationsResponse) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{1}
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ListViolationsResponse) GetViolations() []*Violation {
@@ -151,24 +353,54 @@ func (x *ListViolationsResponse) GetTotalCount() int32 {
 	return 0
 }
 
+func (x *ListViolationsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
 type Violation struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
-	RuleName      string                 `protobuf:"bytes,3,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
-	TraceId       string                 `protobuf:"bytes,4,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
-	SpanId        string                 `protobuf:"bytes,5,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Severity      string                 `protobuf:"bytes,7,opt,name=severity,proto3" json:"severity,omitempty"`
-	Message       string                 `protobuf:"bytes,8,opt,name=message,proto3" json:"message,omitempty"`
-	Context       map[string]string      `protobuf:"bytes,9,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RuleId    string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	RuleName  string                 `protobuf:"bytes,3,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
+	TraceId   string                 `protobuf:"bytes,4,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanId    string                 `protobuf:"bytes,5,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Severity  string                 `protobuf:"bytes,7,opt,name=severity,proto3" json:"severity,omitempty"`
+	Message   string                 `protobuf:"bytes,8,opt,name=message,proto3" json:"message,omitempty"`
+	Context   map[string]string      `protobuf:"bytes,9,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Why the rule fired (unset if not captured)
+	Explanation *RuleExplanation `protobuf:"bytes,10,opt,name=explanation,proto3" json:"explanation,omitempty"`
+	// Spans that caused the violation, with their role
+	SpanRefs []*SpanReference `protobuf:"bytes,11,rep,name=span_refs,json=spanRefs,proto3" json:"span_refs,omitempty"`
+	// Tags copied from the rule
+	Tags []string `protobuf:"bytes,12,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Incident grouping: hash of the rule ID and group_keys values
+	Fingerprint string            `protobuf:"bytes,13,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	GroupKeys   map[string]string `protobuf:"bytes,14,rep,name=group_keys,json=groupKeys,proto3" json:"group_keys,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Triage state: open, acknowledged, resolved, false-positive, suppressed
+	Status   string              `protobuf:"bytes,15,opt,name=status,proto3" json:"status,omitempty"`
+	Assignee string              `protobuf:"bytes,16,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Comments []*ViolationComment `protobuf:"bytes,17,rep,name=comments,proto3" json:"comments,omitempty"`
+	// Every status change, oldest first
+	StatusHistory []*StatusChange        `protobuf:"bytes,18,rep,name=status_history,json=statusHistory,proto3" json:"status_history,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // Last triage change (unset if never triaged)
+	// base64url Ed25519 signature of the violation's canonical encoding
+	// (hex HMAC-SHA256 for violations recorded before Ed25519 signing)
+	Signature      string `protobuf:"bytes,20,opt,name=signature,proto3" json:"signature,omitempty"`
+	SignatureKeyId string `protobuf:"bytes,21,opt,name=signature_key_id,json=signatureKeyId,proto3" json:"signature_key_id,omitempty"` // JWKS kid of the signing key (empty for HMAC)
+	// The rule and spans the violation was detected on (unset if not captured)
+	Evidence      *EvidenceSnapshot `protobuf:"bytes,22,opt,name=evidence,proto3" json:"evidence,omitempty"`
+	RuleVersion   int32             `protobuf:"varint,23,opt,name=rule_version,json=ruleVersion,proto3" json:"rule_version,omitempty"` // Version of the rule that produced the violation (0 if unknown)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Violation) Reset() {
 	*x = Violation{}
-	mi := &file_betrace_v1_violations_proto_msgTypes[2]
+	mi := &file_betrace_v1_violations_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -180,7 +412,7 @@ func (x *Violation) String() string {
 func (*Violation) ProtoMessage() {}
 
 func (x *Violation) ProtoReflect() protoreflect.Message {
-	mi := &file_betrace_v1_violations_proto_msgTypes[2]
+	mi := &file_betrace_v1_violations_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -193,7 +425,7 @@ func (x *Violation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Violation.ProtoReflect.Descriptor instead.
 func (*Violation) Descriptor() ([]byte, []int) {
-	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{2}
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Violation) GetId() string {
@@ -259,40 +491,1817 @@ func (x *Violation) GetContext() map[string]string {
 	return nil
 }
 
-var File_betrace_v1_violations_proto protoreflect.FileDescriptor
+func (x *Violation) GetExplanation() *RuleExplanation {
+	if x != nil {
+		return x.Explanation
+	}
+	return nil
+}
 
-const file_betrace_v1_violations_proto_rawDesc = "" +
-	"\n" +
-	"\x1bbetrace/v1/violations.proto\x12\n" +
-	"betrace.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd3\x01\n" +
-	"\x15ListViolationsRequest\x12\x17\n" +
-	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x19\n" +
-	"\btrace_id\x18\x02 \x01(\tR\atraceId\x129\n" +
-	"\n" +
-	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
-	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
-	"\x05limit\x18\x05 \x01(\x05R\x05limit\"p\n" +
-	"\x16ListViolationsResponse\x125\n" +
-	"\n" +
-	"violations\x18\x01 \x03(\v2\x15.betrace.v1.ViolationR\n" +
-	"violations\x12\x1f\n" +
-	"\vtotal_count\x18\x02 \x01(\x05R\n" +
-	"totalCount\"\xef\x02\n" +
-	"\tViolation\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
-	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x1b\n" +
-	"\trule_name\x18\x03 \x01(\tR\bruleName\x12\x19\n" +
-	"\btrace_id\x18\x04 \x01(\tR\atraceId\x12\x17\n" +
-	"\aspan_id\x18\x05 \x01(\tR\x06spanId\x128\n" +
-	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1a\n" +
-	"\bseverity\x18\a \x01(\tR\bseverity\x12\x18\n" +
-	"\amessage\x18\b \x01(\tR\amessage\x12<\n" +
-	"\acontext\x18\t \x03(\v2\".betrace.v1.Violation.ContextEntryR\acontext\x1a:\n" +
-	"\fContextEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x83\x01\n" +
+func (x *Violation) GetSpanRefs() []*SpanReference {
+	if x != nil {
+		return x.SpanRefs
+	}
+	return nil
+}
+
+func (x *Violation) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Violation) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *Violation) GetGroupKeys() map[string]string {
+	if x != nil {
+		return x.GroupKeys
+	}
+	return nil
+}
+
+func (x *Violation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Violation) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *Violation) GetComments() []*ViolationComment {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+func (x *Violation) GetStatusHistory() []*StatusChange {
+	if x != nil {
+		return x.StatusHistory
+	}
+	return nil
+}
+
+func (x *Violation) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Violation) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Violation) GetSignatureKeyId() string {
+	if x != nil {
+		return x.SignatureKeyId
+	}
+	return ""
+}
+
+func (x *Violation) GetEvidence() *EvidenceSnapshot {
+	if x != nil {
+		return x.Evidence
+	}
+	return nil
+}
+
+func (x *Violation) GetRuleVersion() int32 {
+	if x != nil {
+		return x.RuleVersion
+	}
+	return 0
+}
+
+// EvidenceSnapshot preserves the rule and a bounded, redacted copy of the
+// spans a violation was detected on. Covered by the violation's signature.
+type EvidenceSnapshot struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RuleExpression   string                 `protobuf:"bytes,1,opt,name=rule_expression,json=ruleExpression,proto3" json:"rule_expression,omitempty"`
+	ExpressionDigest string                 `protobuf:"bytes,2,opt,name=expression_digest,json=expressionDigest,proto3" json:"expression_digest,omitempty"` // sha256:<hex> of rule_expression
+	// Referenced spans first, then their ancestors, then the rest of the
+	// trace, up to the snapshot limit; ordered by start time
+	Spans               []*EvidenceSpan `protobuf:"bytes,3,rep,name=spans,proto3" json:"spans,omitempty"`
+	TotalSpans          int32           `protobuf:"varint,4,opt,name=total_spans,json=totalSpans,proto3" json:"total_spans,omitempty"`                            // Spans in the trace at detection
+	TruncatedAttributes int32           `protobuf:"varint,5,opt,name=truncated_attributes,json=truncatedAttributes,proto3" json:"truncated_attributes,omitempty"` // Attribute values cut to the length limit
+	RedactedAttributes  []string        `protobuf:"bytes,6,rep,name=redacted_attributes,json=redactedAttributes,proto3" json:"redacted_attributes,omitempty"`     // Attributes whose values read [REDACTED]
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *EvidenceSnapshot) Reset() {
+	*x = EvidenceSnapshot{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvidenceSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvidenceSnapshot) ProtoMessage() {}
+
+func (x *EvidenceSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvidenceSnapshot.ProtoReflect.Descriptor instead.
+func (*EvidenceSnapshot) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EvidenceSnapshot) GetRuleExpression() string {
+	if x != nil {
+		return x.RuleExpression
+	}
+	return ""
+}
+
+func (x *EvidenceSnapshot) GetExpressionDigest() string {
+	if x != nil {
+		return x.ExpressionDigest
+	}
+	return ""
+}
+
+func (x *EvidenceSnapshot) GetSpans() []*EvidenceSpan {
+	if x != nil {
+		return x.Spans
+	}
+	return nil
+}
+
+func (x *EvidenceSnapshot) GetTotalSpans() int32 {
+	if x != nil {
+		return x.TotalSpans
+	}
+	return 0
+}
+
+func (x *EvidenceSnapshot) GetTruncatedAttributes() int32 {
+	if x != nil {
+		return x.TruncatedAttributes
+	}
+	return 0
+}
+
+func (x *EvidenceSnapshot) GetRedactedAttributes() []string {
+	if x != nil {
+		return x.RedactedAttributes
+	}
+	return nil
+}
+
+// EvidenceSpan is a span as captured in an evidence snapshot
+type EvidenceSpan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraceId       string                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanId        string                 `protobuf:"bytes,2,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	ParentSpanId  string                 `protobuf:"bytes,3,opt,name=parent_span_id,json=parentSpanId,proto3" json:"parent_span_id,omitempty"`
+	OperationName string                 `protobuf:"bytes,4,opt,name=operation_name,json=operationName,proto3" json:"operation_name,omitempty"`
+	ServiceName   string                 `protobuf:"bytes,5,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Duration      int64                  `protobuf:"varint,8,opt,name=duration,proto3" json:"duration,omitempty"` // nanoseconds
+	Attributes    map[string]string      `protobuf:"bytes,9,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status        string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvidenceSpan) Reset() {
+	*x = EvidenceSpan{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvidenceSpan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvidenceSpan) ProtoMessage() {}
+
+func (x *EvidenceSpan) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvidenceSpan.ProtoReflect.Descriptor instead.
+func (*EvidenceSpan) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EvidenceSpan) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *EvidenceSpan) GetSpanId() string {
+	if x != nil {
+		return x.SpanId
+	}
+	return ""
+}
+
+func (x *EvidenceSpan) GetParentSpanId() string {
+	if x != nil {
+		return x.ParentSpanId
+	}
+	return ""
+}
+
+func (x *EvidenceSpan) GetOperationName() string {
+	if x != nil {
+		return x.OperationName
+	}
+	return ""
+}
+
+func (x *EvidenceSpan) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *EvidenceSpan) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *EvidenceSpan) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *EvidenceSpan) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *EvidenceSpan) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *EvidenceSpan) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// ViolationComment is a note left on a violation during triage
+type ViolationComment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Author        string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ViolationComment) Reset() {
+	*x = ViolationComment{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ViolationComment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViolationComment) ProtoMessage() {}
+
+func (x *ViolationComment) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViolationComment.ProtoReflect.Descriptor instead.
+func (*ViolationComment) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ViolationComment) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ViolationComment) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ViolationComment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// StatusChange records one status transition of a violation
+type StatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	ChangedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusChange) Reset() {
+	*x = StatusChange{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusChange) ProtoMessage() {}
+
+func (x *StatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusChange.ProtoReflect.Descriptor instead.
+func (*StatusChange) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StatusChange) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *StatusChange) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *StatusChange) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *StatusChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StatusChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+type UpdateViolationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViolationIds  []string               `protobuf:"bytes,1,rep,name=violation_ids,json=violationIds,proto3" json:"violation_ids,omitempty"` // Max 1000
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`   // Recorded in the status history
+	Comment       string                 `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"` // Optional comment added to each violation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateViolationStatusRequest) Reset() {
+	*x = UpdateViolationStatusRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateViolationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateViolationStatusRequest) ProtoMessage() {}
+
+func (x *UpdateViolationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateViolationStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateViolationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateViolationStatusRequest) GetViolationIds() []string {
+	if x != nil {
+		return x.ViolationIds
+	}
+	return nil
+}
+
+func (x *UpdateViolationStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateViolationStatusRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *UpdateViolationStatusRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type AssignViolationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViolationIds  []string               `protobuf:"bytes,1,rep,name=violation_ids,json=violationIds,proto3" json:"violation_ids,omitempty"` // Max 1000
+	Assignee      string                 `protobuf:"bytes,2,opt,name=assignee,proto3" json:"assignee,omitempty"`                             // Empty clears the assignee
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignViolationsRequest) Reset() {
+	*x = AssignViolationsRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignViolationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignViolationsRequest) ProtoMessage() {}
+
+func (x *AssignViolationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignViolationsRequest.ProtoReflect.Descriptor instead.
+func (*AssignViolationsRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AssignViolationsRequest) GetViolationIds() []string {
+	if x != nil {
+		return x.ViolationIds
+	}
+	return nil
+}
+
+func (x *AssignViolationsRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+type UpdateViolationStatusResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Updated       []*Violation              `protobuf:"bytes,1,rep,name=updated,proto3" json:"updated,omitempty"`
+	Failures      []*ViolationUpdateFailure `protobuf:"bytes,2,rep,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateViolationStatusResponse) Reset() {
+	*x = UpdateViolationStatusResponse{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateViolationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateViolationStatusResponse) ProtoMessage() {}
+
+func (x *UpdateViolationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateViolationStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateViolationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateViolationStatusResponse) GetUpdated() []*Violation {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *UpdateViolationStatusResponse) GetFailures() []*ViolationUpdateFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+// ViolationUpdateFailure explains why one violation of a bulk update was not changed
+type ViolationUpdateFailure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViolationId   string                 `protobuf:"bytes,1,opt,name=violation_id,json=violationId,proto3" json:"violation_id,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ViolationUpdateFailure) Reset() {
+	*x = ViolationUpdateFailure{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ViolationUpdateFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViolationUpdateFailure) ProtoMessage() {}
+
+func (x *ViolationUpdateFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViolationUpdateFailure.ProtoReflect.Descriptor instead.
+func (*ViolationUpdateFailure) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ViolationUpdateFailure) GetViolationId() string {
+	if x != nil {
+		return x.ViolationId
+	}
+	return ""
+}
+
+func (x *ViolationUpdateFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type AddViolationCommentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViolationId   string                 `protobuf:"bytes,1,opt,name=violation_id,json=violationId,proto3" json:"violation_id,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddViolationCommentRequest) Reset() {
+	*x = AddViolationCommentRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddViolationCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddViolationCommentRequest) ProtoMessage() {}
+
+func (x *AddViolationCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddViolationCommentRequest.ProtoReflect.Descriptor instead.
+func (*AddViolationCommentRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AddViolationCommentRequest) GetViolationId() string {
+	if x != nil {
+		return x.ViolationId
+	}
+	return ""
+}
+
+func (x *AddViolationCommentRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type VerifyViolationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViolationId   string                 `protobuf:"bytes,1,opt,name=violation_id,json=violationId,proto3" json:"violation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyViolationRequest) Reset() {
+	*x = VerifyViolationRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyViolationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyViolationRequest) ProtoMessage() {}
+
+func (x *VerifyViolationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyViolationRequest.ProtoReflect.Descriptor instead.
+func (*VerifyViolationRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *VerifyViolationRequest) GetViolationId() string {
+	if x != nil {
+		return x.ViolationId
+	}
+	return ""
+}
+
+type VerifyViolationResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Valid     bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`             // Verified with the signing keys (false when signing is disabled)
+	Error     string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`              // Why verification failed
+	Algorithm string                 `protobuf:"bytes,3,opt,name=algorithm,proto3" json:"algorithm,omitempty"`      // EdDSA (Ed25519)
+	KeyId     string                 `protobuf:"bytes,4,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"` // JWKS kid of the signing key
+	Signature string                 `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Canonical encoding the signature covers (EdDSA only): JSON of the
+	// violation's detection-time fields; triage state is not signed
+	SignedPayload []byte     `protobuf:"bytes,6,opt,name=signed_payload,json=signedPayload,proto3" json:"signed_payload,omitempty"`
+	Violation     *Violation `protobuf:"bytes,7,opt,name=violation,proto3" json:"violation,omitempty"` // Unset when the stored violation failed verification
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyViolationResponse) Reset() {
+	*x = VerifyViolationResponse{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyViolationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyViolationResponse) ProtoMessage() {}
+
+func (x *VerifyViolationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyViolationResponse.ProtoReflect.Descriptor instead.
+func (*VerifyViolationResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VerifyViolationResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyViolationResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *VerifyViolationResponse) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *VerifyViolationResponse) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *VerifyViolationResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *VerifyViolationResponse) GetSignedPayload() []byte {
+	if x != nil {
+		return x.SignedPayload
+	}
+	return nil
+}
+
+func (x *VerifyViolationResponse) GetViolation() *Violation {
+	if x != nil {
+		return x.Violation
+	}
+	return nil
+}
+
+type ReproduceViolationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViolationId   string                 `protobuf:"bytes,1,opt,name=violation_id,json=violationId,proto3" json:"violation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReproduceViolationRequest) Reset() {
+	*x = ReproduceViolationRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReproduceViolationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReproduceViolationRequest) ProtoMessage() {}
+
+func (x *ReproduceViolationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReproduceViolationRequest.ProtoReflect.Descriptor instead.
+func (*ReproduceViolationRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReproduceViolationRequest) GetViolationId() string {
+	if x != nil {
+		return x.ViolationId
+	}
+	return ""
+}
+
+type ReproduceViolationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The captured rule expression matched the captured spans. Together with
+	// signature_valid this shows the violation follows from signed evidence.
+	Reproduced              bool             `protobuf:"varint,1,opt,name=reproduced,proto3" json:"reproduced,omitempty"`
+	Error                   string           `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"` // Why reproduction or verification failed
+	SignatureValid          bool             `protobuf:"varint,3,opt,name=signature_valid,json=signatureValid,proto3" json:"signature_valid,omitempty"`
+	ExpressionDigest        string           `protobuf:"bytes,4,opt,name=expression_digest,json=expressionDigest,proto3" json:"expression_digest,omitempty"`                        // Digest of the expression the violation was detected with
+	CurrentExpressionDigest string           `protobuf:"bytes,5,opt,name=current_expression_digest,json=currentExpressionDigest,proto3" json:"current_expression_digest,omitempty"` // Digest of the expression loaded now (empty if the rule was deleted)
+	Explanation             *RuleExplanation `protobuf:"bytes,6,opt,name=explanation,proto3" json:"explanation,omitempty"`                                                          // The captured rule explained against the snapshot
+	Violation               *Violation       `protobuf:"bytes,7,opt,name=violation,proto3" json:"violation,omitempty"`                                                              // Unset when the stored violation failed verification
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ReproduceViolationResponse) Reset() {
+	*x = ReproduceViolationResponse{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReproduceViolationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReproduceViolationResponse) ProtoMessage() {}
+
+func (x *ReproduceViolationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReproduceViolationResponse.ProtoReflect.Descriptor instead.
+func (*ReproduceViolationResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReproduceViolationResponse) GetReproduced() bool {
+	if x != nil {
+		return x.Reproduced
+	}
+	return false
+}
+
+func (x *ReproduceViolationResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ReproduceViolationResponse) GetSignatureValid() bool {
+	if x != nil {
+		return x.SignatureValid
+	}
+	return false
+}
+
+func (x *ReproduceViolationResponse) GetExpressionDigest() string {
+	if x != nil {
+		return x.ExpressionDigest
+	}
+	return ""
+}
+
+func (x *ReproduceViolationResponse) GetCurrentExpressionDigest() string {
+	if x != nil {
+		return x.CurrentExpressionDigest
+	}
+	return ""
+}
+
+func (x *ReproduceViolationResponse) GetExplanation() *RuleExplanation {
+	if x != nil {
+		return x.Explanation
+	}
+	return nil
+}
+
+func (x *ReproduceViolationResponse) GetViolation() *Violation {
+	if x != nil {
+		return x.Violation
+	}
+	return nil
+}
+
+// Incident groups violations sharing a fingerprint
+type Incident struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Fingerprint       string                 `protobuf:"bytes,1,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	RuleId            string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	RuleName          string                 `protobuf:"bytes,3,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
+	Severity          string                 `protobuf:"bytes,4,opt,name=severity,proto3" json:"severity,omitempty"`
+	GroupKeys         map[string]string      `protobuf:"bytes,5,rep,name=group_keys,json=groupKeys,proto3" json:"group_keys,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	FirstSeen         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Count             int64                  `protobuf:"varint,8,opt,name=count,proto3" json:"count,omitempty"`
+	LatestViolationId string                 `protobuf:"bytes,9,opt,name=latest_violation_id,json=latestViolationId,proto3" json:"latest_violation_id,omitempty"`
+	// Most recent distinct traces, newest first
+	SampleTraceIds []string `protobuf:"bytes,10,rep,name=sample_trace_ids,json=sampleTraceIds,proto3" json:"sample_trace_ids,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Incident) Reset() {
+	*x = Incident{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Incident) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Incident) ProtoMessage() {}
+
+func (x *Incident) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Incident.ProtoReflect.Descriptor instead.
+func (*Incident) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Incident) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *Incident) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *Incident) GetRuleName() string {
+	if x != nil {
+		return x.RuleName
+	}
+	return ""
+}
+
+func (x *Incident) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Incident) GetGroupKeys() map[string]string {
+	if x != nil {
+		return x.GroupKeys
+	}
+	return nil
+}
+
+func (x *Incident) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return nil
+}
+
+func (x *Incident) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Incident) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Incident) GetLatestViolationId() string {
+	if x != nil {
+		return x.LatestViolationId
+	}
+	return ""
+}
+
+func (x *Incident) GetSampleTraceIds() []string {
+	if x != nil {
+		return x.SampleTraceIds
+	}
+	return nil
+}
+
+type ListIncidentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`  // Incidents last seen at or after
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"` // Default 100, max 1000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIncidentsRequest) Reset() {
+	*x = ListIncidentsRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIncidentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIncidentsRequest) ProtoMessage() {}
+
+func (x *ListIncidentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIncidentsRequest.ProtoReflect.Descriptor instead.
+func (*ListIncidentsRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListIncidentsRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *ListIncidentsRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ListIncidentsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListIncidentsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListIncidentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Incidents     []*Incident            `protobuf:"bytes,1,rep,name=incidents,proto3" json:"incidents,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIncidentsResponse) Reset() {
+	*x = ListIncidentsResponse{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIncidentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIncidentsResponse) ProtoMessage() {}
+
+func (x *ListIncidentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIncidentsResponse.ProtoReflect.Descriptor instead.
+func (*ListIncidentsResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListIncidentsResponse) GetIncidents() []*Incident {
+	if x != nil {
+		return x.Incidents
+	}
+	return nil
+}
+
+func (x *ListIncidentsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type GetIncidentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fingerprint   string                 `protobuf:"bytes,1,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIncidentRequest) Reset() {
+	*x = GetIncidentRequest{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIncidentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIncidentRequest) ProtoMessage() {}
+
+func (x *GetIncidentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIncidentRequest.ProtoReflect.Descriptor instead.
+func (*GetIncidentRequest) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetIncidentRequest) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+type GetIncidentResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Incident *Incident              `protobuf:"bytes,1,opt,name=incident,proto3" json:"incident,omitempty"`
+	// Newest violations of the incident (page through more with
+	// ListViolations and the fingerprint filter)
+	RecentViolations []*Violation `protobuf:"bytes,2,rep,name=recent_violations,json=recentViolations,proto3" json:"recent_violations,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetIncidentResponse) Reset() {
+	*x = GetIncidentResponse{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIncidentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIncidentResponse) ProtoMessage() {}
+
+func (x *GetIncidentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIncidentResponse.ProtoReflect.Descriptor instead.
+func (*GetIncidentResponse) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetIncidentResponse) GetIncident() *Incident {
+	if x != nil {
+		return x.Incident
+	}
+	return nil
+}
+
+func (x *GetIncidentResponse) GetRecentViolations() []*Violation {
+	if x != nil {
+		return x.RecentViolations
+	}
+	return nil
+}
+
+// SpanReference references a span involved in a violation
+type SpanReference struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraceId       string                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanId        string                 `protobuf:"bytes,2,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	ServiceName   string                 `protobuf:"bytes,3,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"` // trigger, forbidden, missing-expected
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpanReference) Reset() {
+	*x = SpanReference{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpanReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpanReference) ProtoMessage() {}
+
+func (x *SpanReference) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpanReference.ProtoReflect.Descriptor instead.
+func (*SpanReference) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SpanReference) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *SpanReference) GetSpanId() string {
+	if x != nil {
+		return x.SpanId
+	}
+	return ""
+}
+
+func (x *SpanReference) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *SpanReference) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+// RuleExplanation describes why a rule did or did not fire on a trace
+type RuleExplanation struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Violated bool                   `protobuf:"varint,1,opt,name=violated,proto3" json:"violated,omitempty"`
+	When     *ExplanationNode       `protobuf:"bytes,2,opt,name=when,proto3" json:"when,omitempty"`
+	Always   *ExplanationNode       `protobuf:"bytes,3,opt,name=always,proto3" json:"always,omitempty"`
+	Never    *ExplanationNode       `protobuf:"bytes,4,opt,name=never,proto3" json:"never,omitempty"`
+	// Spans that satisfied the when clause
+	WhenSpanIds []string `protobuf:"bytes,5,rep,name=when_span_ids,json=whenSpanIds,proto3" json:"when_span_ids,omitempty"`
+	// Always sub-conditions that had no match
+	UnsatisfiedAlways []string `protobuf:"bytes,6,rep,name=unsatisfied_always,json=unsatisfiedAlways,proto3" json:"unsatisfied_always,omitempty"`
+	// Never sub-conditions that matched
+	MatchedNever  []string `protobuf:"bytes,7,rep,name=matched_never,json=matchedNever,proto3" json:"matched_never,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleExplanation) Reset() {
+	*x = RuleExplanation{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleExplanation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleExplanation) ProtoMessage() {}
+
+func (x *RuleExplanation) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleExplanation.ProtoReflect.Descriptor instead.
+func (*RuleExplanation) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RuleExplanation) GetViolated() bool {
+	if x != nil {
+		return x.Violated
+	}
+	return false
+}
+
+func (x *RuleExplanation) GetWhen() *ExplanationNode {
+	if x != nil {
+		return x.When
+	}
+	return nil
+}
+
+func (x *RuleExplanation) GetAlways() *ExplanationNode {
+	if x != nil {
+		return x.Always
+	}
+	return nil
+}
+
+func (x *RuleExplanation) GetNever() *ExplanationNode {
+	if x != nil {
+		return x.Never
+	}
+	return nil
+}
+
+func (x *RuleExplanation) GetWhenSpanIds() []string {
+	if x != nil {
+		return x.WhenSpanIds
+	}
+	return nil
+}
+
+func (x *RuleExplanation) GetUnsatisfiedAlways() []string {
+	if x != nil {
+		return x.UnsatisfiedAlways
+	}
+	return nil
+}
+
+func (x *RuleExplanation) GetMatchedNever() []string {
+	if x != nil {
+		return x.MatchedNever
+	}
+	return nil
+}
+
+// ExplanationNode is one node of a clause's evaluation tree
+type ExplanationNode struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Kind             string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // or, and, not, has, count
+	Expression       string                 `protobuf:"bytes,2,opt,name=expression,proto3" json:"expression,omitempty"`
+	Result           bool                   `protobuf:"varint,3,opt,name=result,proto3" json:"result,omitempty"`
+	Error            string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	MatchedSpanIds   []string               `protobuf:"bytes,5,rep,name=matched_span_ids,json=matchedSpanIds,proto3" json:"matched_span_ids,omitempty"`
+	MatchedSpanCount int32                  `protobuf:"varint,6,opt,name=matched_span_count,json=matchedSpanCount,proto3" json:"matched_span_count,omitempty"`
+	Children         []*ExplanationNode     `protobuf:"bytes,7,rep,name=children,proto3" json:"children,omitempty"`
+	Operation        string                 `protobuf:"bytes,8,opt,name=operation,proto3" json:"operation,omitempty"` // span checks only: operation name checked
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExplanationNode) Reset() {
+	*x = ExplanationNode{}
+	mi := &file_betrace_v1_violations_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplanationNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplanationNode) ProtoMessage() {}
+
+func (x *ExplanationNode) ProtoReflect() protoreflect.Message {
+	mi := &file_betrace_v1_violations_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplanationNode.ProtoReflect.Descriptor instead.
+func (*ExplanationNode) Descriptor() ([]byte, []int) {
+	return file_betrace_v1_violations_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExplanationNode) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ExplanationNode) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *ExplanationNode) GetResult() bool {
+	if x != nil {
+		return x.Result
+	}
+	return false
+}
+
+func (x *ExplanationNode) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ExplanationNode) GetMatchedSpanIds() []string {
+	if x != nil {
+		return x.MatchedSpanIds
+	}
+	return nil
+}
+
+func (x *ExplanationNode) GetMatchedSpanCount() int32 {
+	if x != nil {
+		return x.MatchedSpanCount
+	}
+	return 0
+}
+
+func (x *ExplanationNode) GetChildren() []*ExplanationNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *ExplanationNode) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+var File_betrace_v1_violations_proto protoreflect.FileDescriptor
+
+const file_betrace_v1_violations_proto_rawDesc = "" +
+	"\n" +
+	"\x1bbetrace/v1/violations.proto\x12\n" +
+	"betrace.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc2\x03\n" +
+	"\x15ListViolationsRequest\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x19\n" +
+	"\btrace_id\x18\x02 \x01(\tR\atraceId\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12!\n" +
+	"\fservice_name\x18\b \x01(\tR\vserviceName\x12\x16\n" +
+	"\x06cursor\x18\t \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05order\x18\n" +
+	" \x01(\tR\x05order\x12 \n" +
+	"\vfingerprint\x18\v \x01(\tR\vfingerprint\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x12\x1a\n" +
+	"\bassignee\x18\r \x01(\tR\bassignee\x12\x16\n" +
+	"\x06shadow\x18\x0e \x01(\bR\x06shadow\"\x91\x01\n" +
+	"\x16WatchViolationsRequest\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12\x16\n" +
+	"\x06shadow\x18\x05 \x01(\bR\x06shadow\"]\n" +
+	"\x0eViolationEvent\x123\n" +
+	"\tviolation\x18\x01 \x01(\v2\x15.betrace.v1.ViolationR\tviolation\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\x91\x01\n" +
+	"\x16ListViolationsResponse\x125\n" +
+	"\n" +
+	"violations\x18\x01 \x03(\v2\x15.betrace.v1.ViolationR\n" +
+	"violations\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"\xae\b\n" +
+	"\tViolation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x1b\n" +
+	"\trule_name\x18\x03 \x01(\tR\bruleName\x12\x19\n" +
+	"\btrace_id\x18\x04 \x01(\tR\atraceId\x12\x17\n" +
+	"\aspan_id\x18\x05 \x01(\tR\x06spanId\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1a\n" +
+	"\bseverity\x18\a \x01(\tR\bseverity\x12\x18\n" +
+	"\amessage\x18\b \x01(\tR\amessage\x12<\n" +
+	"\acontext\x18\t \x03(\v2\".betrace.v1.Violation.ContextEntryR\acontext\x12=\n" +
+	"\vexplanation\x18\n" +
+	" \x01(\v2\x1b.betrace.v1.RuleExplanationR\vexplanation\x126\n" +
+	"\tspan_refs\x18\v \x03(\v2\x19.betrace.v1.SpanReferenceR\bspanRefs\x12\x12\n" +
+	"\x04tags\x18\f \x03(\tR\x04tags\x12 \n" +
+	"\vfingerprint\x18\r \x01(\tR\vfingerprint\x12C\n" +
+	"\n" +
+	"group_keys\x18\x0e \x03(\v2$.betrace.v1.Violation.GroupKeysEntryR\tgroupKeys\x12\x16\n" +
+	"\x06status\x18\x0f \x01(\tR\x06status\x12\x1a\n" +
+	"\bassignee\x18\x10 \x01(\tR\bassignee\x128\n" +
+	"\bcomments\x18\x11 \x03(\v2\x1c.betrace.v1.ViolationCommentR\bcomments\x12?\n" +
+	"\x0estatus_history\x18\x12 \x03(\v2\x18.betrace.v1.StatusChangeR\rstatusHistory\x129\n" +
+	"\n" +
+	"updated_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1c\n" +
+	"\tsignature\x18\x14 \x01(\tR\tsignature\x12(\n" +
+	"\x10signature_key_id\x18\x15 \x01(\tR\x0esignatureKeyId\x128\n" +
+	"\bevidence\x18\x16 \x01(\v2\x1c.betrace.v1.EvidenceSnapshotR\bevidence\x12!\n" +
+	"\frule_version\x18\x17 \x01(\x05R\vruleVersion\x1a:\n" +
+	"\fContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a<\n" +
+	"\x0eGroupKeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9d\x02\n" +
+	"\x10EvidenceSnapshot\x12'\n" +
+	"\x0frule_expression\x18\x01 \x01(\tR\x0eruleExpression\x12+\n" +
+	"\x11expression_digest\x18\x02 \x01(\tR\x10expressionDigest\x12.\n" +
+	"\x05spans\x18\x03 \x03(\v2\x18.betrace.v1.EvidenceSpanR\x05spans\x12\x1f\n" +
+	"\vtotal_spans\x18\x04 \x01(\x05R\n" +
+	"totalSpans\x121\n" +
+	"\x14truncated_attributes\x18\x05 \x01(\x05R\x13truncatedAttributes\x12/\n" +
+	"\x13redacted_attributes\x18\x06 \x03(\tR\x12redactedAttributes\"\xe1\x03\n" +
+	"\fEvidenceSpan\x12\x19\n" +
+	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12\x17\n" +
+	"\aspan_id\x18\x02 \x01(\tR\x06spanId\x12$\n" +
+	"\x0eparent_span_id\x18\x03 \x01(\tR\fparentSpanId\x12%\n" +
+	"\x0eoperation_name\x18\x04 \x01(\tR\roperationName\x12!\n" +
+	"\fservice_name\x18\x05 \x01(\tR\vserviceName\x129\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1a\n" +
+	"\bduration\x18\b \x01(\x03R\bduration\x12H\n" +
+	"\n" +
+	"attributes\x18\t \x03(\v2(.betrace.v1.EvidenceSpan.AttributesEntryR\n" +
+	"attributes\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"y\n" +
+	"\x10ViolationComment\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9b\x01\n" +
+	"\fStatusChange\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x129\n" +
+	"\n" +
+	"changed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tchangedAt\"\x9a\x01\n" +
+	"\x1cUpdateViolationStatusRequest\x12#\n" +
+	"\rviolation_ids\x18\x01 \x03(\tR\fviolationIds\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x18\n" +
+	"\acomment\x18\x05 \x01(\tR\acommentJ\x04\b\x03\x10\x04R\x05actor\"g\n" +
+	"\x17AssignViolationsRequest\x12#\n" +
+	"\rviolation_ids\x18\x01 \x03(\tR\fviolationIds\x12\x1a\n" +
+	"\bassignee\x18\x02 \x01(\tR\bassigneeJ\x04\b\x03\x10\x04R\x05actor\"\x90\x01\n" +
+	"\x1dUpdateViolationStatusResponse\x12/\n" +
+	"\aupdated\x18\x01 \x03(\v2\x15.betrace.v1.ViolationR\aupdated\x12>\n" +
+	"\bfailures\x18\x02 \x03(\v2\".betrace.v1.ViolationUpdateFailureR\bfailures\"Q\n" +
+	"\x16ViolationUpdateFailure\x12!\n" +
+	"\fviolation_id\x18\x01 \x01(\tR\vviolationId\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"`\n" +
+	"\x1aAddViolationCommentRequest\x12!\n" +
+	"\fviolation_id\x18\x01 \x01(\tR\vviolationId\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04textJ\x04\b\x02\x10\x03R\x05actor\";\n" +
+	"\x16VerifyViolationRequest\x12!\n" +
+	"\fviolation_id\x18\x01 \x01(\tR\vviolationId\"\xf4\x01\n" +
+	"\x17VerifyViolationResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1c\n" +
+	"\talgorithm\x18\x03 \x01(\tR\talgorithm\x12\x15\n" +
+	"\x06key_id\x18\x04 \x01(\tR\x05keyId\x12\x1c\n" +
+	"\tsignature\x18\x05 \x01(\tR\tsignature\x12%\n" +
+	"\x0esigned_payload\x18\x06 \x01(\fR\rsignedPayload\x123\n" +
+	"\tviolation\x18\a \x01(\v2\x15.betrace.v1.ViolationR\tviolation\">\n" +
+	"\x19ReproduceViolationRequest\x12!\n" +
+	"\fviolation_id\x18\x01 \x01(\tR\vviolationId\"\xd8\x02\n" +
+	"\x1aReproduceViolationResponse\x12\x1e\n" +
+	"\n" +
+	"reproduced\x18\x01 \x01(\bR\n" +
+	"reproduced\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12'\n" +
+	"\x0fsignature_valid\x18\x03 \x01(\bR\x0esignatureValid\x12+\n" +
+	"\x11expression_digest\x18\x04 \x01(\tR\x10expressionDigest\x12:\n" +
+	"\x19current_expression_digest\x18\x05 \x01(\tR\x17currentExpressionDigest\x12=\n" +
+	"\vexplanation\x18\x06 \x01(\v2\x1b.betrace.v1.RuleExplanationR\vexplanation\x123\n" +
+	"\tviolation\x18\a \x01(\v2\x15.betrace.v1.ViolationR\tviolation\"\xe4\x03\n" +
+	"\bIncident\x12 \n" +
+	"\vfingerprint\x18\x01 \x01(\tR\vfingerprint\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x1b\n" +
+	"\trule_name\x18\x03 \x01(\tR\bruleName\x12\x1a\n" +
+	"\bseverity\x18\x04 \x01(\tR\bseverity\x12B\n" +
+	"\n" +
+	"group_keys\x18\x05 \x03(\v2#.betrace.v1.Incident.GroupKeysEntryR\tgroupKeys\x129\n" +
+	"\n" +
+	"first_seen\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x127\n" +
+	"\tlast_seen\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x14\n" +
+	"\x05count\x18\b \x01(\x03R\x05count\x12.\n" +
+	"\x13latest_violation_id\x18\t \x01(\tR\x11latestViolationId\x12(\n" +
+	"\x10sample_trace_ids\x18\n" +
+	" \x03(\tR\x0esampleTraceIds\x1a<\n" +
+	"\x0eGroupKeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x01\n" +
+	"\x14ListIncidentsRequest\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"l\n" +
+	"\x15ListIncidentsResponse\x122\n" +
+	"\tincidents\x18\x01 \x03(\v2\x14.betrace.v1.IncidentR\tincidents\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\"6\n" +
+	"\x12GetIncidentRequest\x12 \n" +
+	"\vfingerprint\x18\x01 \x01(\tR\vfingerprint\"\x8b\x01\n" +
+	"\x13GetIncidentResponse\x120\n" +
+	"\bincident\x18\x01 \x01(\v2\x14.betrace.v1.IncidentR\bincident\x12B\n" +
+	"\x11recent_violations\x18\x02 \x03(\v2\x15.betrace.v1.ViolationR\x10recentViolations\"z\n" +
+	"\rSpanReference\x12\x19\n" +
+	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12\x17\n" +
+	"\aspan_id\x18\x02 \x01(\tR\x06spanId\x12!\n" +
+	"\fservice_name\x18\x03 \x01(\tR\vserviceName\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\"\xbe\x02\n" +
+	"\x0fRuleExplanation\x12\x1a\n" +
+	"\bviolated\x18\x01 \x01(\bR\bviolated\x12/\n" +
+	"\x04when\x18\x02 \x01(\v2\x1b.betrace.v1.ExplanationNodeR\x04when\x123\n" +
+	"\x06always\x18\x03 \x01(\v2\x1b.betrace.v1.ExplanationNodeR\x06always\x121\n" +
+	"\x05never\x18\x04 \x01(\v2\x1b.betrace.v1.ExplanationNodeR\x05never\x12\"\n" +
+	"\rwhen_span_ids\x18\x05 \x03(\tR\vwhenSpanIds\x12-\n" +
+	"\x12unsatisfied_always\x18\x06 \x03(\tR\x11unsatisfiedAlways\x12#\n" +
+	"\rmatched_never\x18\a \x03(\tR\fmatchedNever\"\xa2\x02\n" +
+	"\x0fExplanationNode\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x02 \x01(\tR\n" +
+	"expression\x12\x16\n" +
+	"\x06result\x18\x03 \x01(\bR\x06result\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12(\n" +
+	"\x10matched_span_ids\x18\x05 \x03(\tR\x0ematchedSpanIds\x12,\n" +
+	"\x12matched_span_count\x18\x06 \x01(\x05R\x10matchedSpanCount\x127\n" +
+	"\bchildren\x18\a \x03(\v2\x1b.betrace.v1.ExplanationNodeR\bchildren\x12\x1c\n" +
+	"\toperation\x18\b \x01(\tR\toperation2\x84\t\n" +
 	"\x10ViolationService\x12o\n" +
-	"\x0eListViolations\x12!.betrace.v1.ListViolationsRequest\x1a\".betrace.v1.ListViolationsResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/violationsBCZAgithub.com/betracehq/betrace/backend/generated/betrace/v1;betraceb\x06proto3"
+	"\x0eListViolations\x12!.betrace.v1.ListViolationsRequest\x1a\".betrace.v1.ListViolationsResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/violations\x12S\n" +
+	"\x0fWatchViolations\x12\".betrace.v1.WatchViolationsRequest\x1a\x1a.betrace.v1.ViolationEvent0\x01\x12k\n" +
+	"\rListIncidents\x12 .betrace.v1.ListIncidentsRequest\x1a!.betrace.v1.ListIncidentsResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/incidents\x12s\n" +
+	"\vGetIncident\x12\x1e.betrace.v1.GetIncidentRequest\x1a\x1f.betrace.v1.GetIncidentResponse\"#\x82\xd3\xe4\x93\x02\x1d\x12\x1b/v1/incidents/{fingerprint}\x12\x94\x01\n" +
+	"\x15UpdateViolationStatus\x12(.betrace.v1.UpdateViolationStatusRequest\x1a).betrace.v1.UpdateViolationStatusResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/violations:updateStatus\x12\x84\x01\n" +
+	"\x10AssignViolations\x12#.betrace.v1.AssignViolationsRequest\x1a).betrace.v1.UpdateViolationStatusResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/violations:assign\x12\x87\x01\n" +
+	"\x13AddViolationComment\x12&.betrace.v1.AddViolationCommentRequest\x1a\x15.betrace.v1.Violation\"1\x82\xd3\xe4\x93\x02+:\x01*\"&/v1/violations/{violation_id}/comments\x12\x88\x01\n" +
+	"\x0fVerifyViolation\x12\".betrace.v1.VerifyViolationRequest\x1a#.betrace.v1.VerifyViolationResponse\",\x82\xd3\xe4\x93\x02&\x12$/v1/violations/{violation_id}:verify\x12\x94\x01\n" +
+	"\x12ReproduceViolation\x12%.betrace.v1.ReproduceViolationRequest\x1a&.betrace.v1.ReproduceViolationResponse\"/\x82\xd3\xe4\x93\x02)\x12'/v1/violations/{violation_id}:reproduceBCZAgithub.com/betracehq/betrace/backend/generated/betrace/v1;betraceb\x06proto3"
 
 var (
 	file_betrace_v1_violations_proto_rawDescOnce sync.Once
@@ -306,27 +2315,99 @@ func file_betrace_v1_violations_proto_rawDescGZIP() []byte {
 	return file_betrace_v1_violations_proto_rawDescData
 }
 
-var file_betrace_v1_violations_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_betrace_v1_violations_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_betrace_v1_violations_proto_goTypes = []any{
-	(*ListViolationsRequest)(nil),  // 0: betrace.v1.ListViolationsRequest
-	(*ListViolationsResponse)(nil), // 1: betrace.v1.ListViolationsResponse
-	(*Violation)(nil),              // 2: betrace.v1.Violation
-	nil,                            // 3: betrace.v1.Violation.ContextEntry
-	(*timestamppb.Timestamp)(nil),  // 4: google.protobuf.Timestamp
+	(*ListViolationsRequest)(nil),         // 0: betrace.v1.ListViolationsRequest
+	(*WatchViolationsRequest)(nil),        // 1: betrace.v1.WatchViolationsRequest
+	(*ViolationEvent)(nil),                // 2: betrace.v1.ViolationEvent
+	(*ListViolationsResponse)(nil),        // 3: betrace.v1.ListViolationsResponse
+	(*Violation)(nil),                     // 4: betrace.v1.Violation
+	(*EvidenceSnapshot)(nil),              // 5: betrace.v1.EvidenceSnapshot
+	(*EvidenceSpan)(nil),                  // 6: betrace.v1.EvidenceSpan
+	(*ViolationComment)(nil),              // 7: betrace.v1.ViolationComment
+	(*StatusChange)(nil),                  // 8: betrace.v1.StatusChange
+	(*UpdateViolationStatusRequest)(nil),  // 9: betrace.v1.UpdateViolationStatusRequest
+	(*AssignViolationsRequest)(nil),       // 10: betrace.v1.AssignViolationsRequest
+	(*UpdateViolationStatusResponse)(nil), // 11: betrace.v1.UpdateViolationStatusResponse
+	(*ViolationUpdateFailure)(nil),        // 12: betrace.v1.ViolationUpdateFailure
+	(*AddViolationCommentRequest)(nil),    // 13: betrace.v1.AddViolationCommentRequest
+	(*VerifyViolationRequest)(nil),        // 14: betrace.v1.VerifyViolationRequest
+	(*VerifyViolationResponse)(nil),       // 15: betrace.v1.VerifyViolationResponse
+	(*ReproduceViolationRequest)(nil),     // 16: betrace.v1.ReproduceViolationRequest
+	(*ReproduceViolationResponse)(nil),    // 17: betrace.v1.ReproduceViolationResponse
+	(*Incident)(nil),                      // 18: betrace.v1.Incident
+	(*ListIncidentsRequest)(nil),          // 19: betrace.v1.ListIncidentsRequest
+	(*ListIncidentsResponse)(nil),         // 20: betrace.v1.ListIncidentsResponse
+	(*GetIncidentRequest)(nil),            // 21: betrace.v1.GetIncidentRequest
+	(*GetIncidentResponse)(nil),           // 22: betrace.v1.GetIncidentResponse
+	(*SpanReference)(nil),                 // 23: betrace.v1.SpanReference
+	(*RuleExplanation)(nil),               // 24: betrace.v1.RuleExplanation
+	(*ExplanationNode)(nil),               // 25: betrace.v1.ExplanationNode
+	nil,                                   // 26: betrace.v1.Violation.ContextEntry
+	nil,                                   // 27: betrace.v1.Violation.GroupKeysEntry
+	nil,                                   // 28: betrace.v1.EvidenceSpan.AttributesEntry
+	nil,                                   // 29: betrace.v1.Incident.GroupKeysEntry
+	(*timestamppb.Timestamp)(nil),         // 30: google.protobuf.Timestamp
 }
 var file_betrace_v1_violations_proto_depIdxs = []int32{
-	4, // 0: betrace.v1.ListViolationsRequest.start_time:type_name -> google.protobuf.Timestamp
-	4, // 1: betrace.v1.ListViolationsRequest.end_time:type_name -> google.protobuf.Timestamp
-	2, // 2: betrace.v1.ListViolationsResponse.violations:type_name -> betrace.v1.Violation
-	4, // 3: betrace.v1.Violation.timestamp:type_name -> google.protobuf.Timestamp
-	3, // 4: betrace.v1.Violation.context:type_name -> betrace.v1.Violation.ContextEntry
-	0, // 5: betrace.v1.ViolationService.ListViolations:input_type -> betrace.v1.ListViolationsRequest
-	1, // 6: betrace.v1.ViolationService.ListViolations:output_type -> betrace.v1.ListViolationsResponse
-	6, // [6:7] is the sub-list for method output_type
-	5, // [5:6] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	30, // 0: betrace.v1.ListViolationsRequest.start_time:type_name -> google.protobuf.Timestamp
+	30, // 1: betrace.v1.ListViolationsRequest.end_time:type_name -> google.protobuf.Timestamp
+	4,  // 2: betrace.v1.ViolationEvent.violation:type_name -> betrace.v1.Violation
+	4,  // 3: betrace.v1.ListViolationsResponse.violations:type_name -> betrace.v1.Violation
+	30, // 4: betrace.v1.Violation.timestamp:type_name -> google.protobuf.Timestamp
+	26, // 5: betrace.v1.Violation.context:type_name -> betrace.v1.Violation.ContextEntry
+	24, // 6: betrace.v1.Violation.explanation:type_name -> betrace.v1.RuleExplanation
+	23, // 7: betrace.v1.Violation.span_refs:type_name -> betrace.v1.SpanReference
+	27, // 8: betrace.v1.Violation.group_keys:type_name -> betrace.v1.Violation.GroupKeysEntry
+	7,  // 9: betrace.v1.Violation.comments:type_name -> betrace.v1.ViolationComment
+	8,  // 10: betrace.v1.Violation.status_history:type_name -> betrace.v1.StatusChange
+	30, // 11: betrace.v1.Violation.updated_at:type_name -> google.protobuf.Timestamp
+	5,  // 12: betrace.v1.Violation.evidence:type_name -> betrace.v1.EvidenceSnapshot
+	6,  // 13: betrace.v1.EvidenceSnapshot.spans:type_name -> betrace.v1.EvidenceSpan
+	30, // 14: betrace.v1.EvidenceSpan.start_time:type_name -> google.protobuf.Timestamp
+	30, // 15: betrace.v1.EvidenceSpan.end_time:type_name -> google.protobuf.Timestamp
+	28, // 16: betrace.v1.EvidenceSpan.attributes:type_name -> betrace.v1.EvidenceSpan.AttributesEntry
+	30, // 17: betrace.v1.ViolationComment.created_at:type_name -> google.protobuf.Timestamp
+	30, // 18: betrace.v1.StatusChange.changed_at:type_name -> google.protobuf.Timestamp
+	4,  // 19: betrace.v1.UpdateViolationStatusResponse.updated:type_name -> betrace.v1.Violation
+	12, // 20: betrace.v1.UpdateViolationStatusResponse.failures:type_name -> betrace.v1.ViolationUpdateFailure
+	4,  // 21: betrace.v1.VerifyViolationResponse.violation:type_name -> betrace.v1.Violation
+	24, // 22: betrace.v1.ReproduceViolationResponse.explanation:type_name -> betrace.v1.RuleExplanation
+	4,  // 23: betrace.v1.ReproduceViolationResponse.violation:type_name -> betrace.v1.Violation
+	29, // 24: betrace.v1.Incident.group_keys:type_name -> betrace.v1.Incident.GroupKeysEntry
+	30, // 25: betrace.v1.Incident.first_seen:type_name -> google.protobuf.Timestamp
+	30, // 26: betrace.v1.Incident.last_seen:type_name -> google.protobuf.Timestamp
+	30, // 27: betrace.v1.ListIncidentsRequest.since:type_name -> google.protobuf.Timestamp
+	18, // 28: betrace.v1.ListIncidentsResponse.incidents:type_name -> betrace.v1.Incident
+	18, // 29: betrace.v1.GetIncidentResponse.incident:type_name -> betrace.v1.Incident
+	4,  // 30: betrace.v1.GetIncidentResponse.recent_violations:type_name -> betrace.v1.Violation
+	25, // 31: betrace.v1.RuleExplanation.when:type_name -> betrace.v1.ExplanationNode
+	25, // 32: betrace.v1.RuleExplanation.always:type_name -> betrace.v1.ExplanationNode
+	25, // 33: betrace.v1.RuleExplanation.never:type_name -> betrace.v1.ExplanationNode
+	25, // 34: betrace.v1.ExplanationNode.children:type_name -> betrace.v1.ExplanationNode
+	0,  // 35: betrace.v1.ViolationService.ListViolations:input_type -> betrace.v1.ListViolationsRequest
+	1,  // 36: betrace.v1.ViolationService.WatchViolations:input_type -> betrace.v1.WatchViolationsRequest
+	19, // 37: betrace.v1.ViolationService.ListIncidents:input_type -> betrace.v1.ListIncidentsRequest
+	21, // 38: betrace.v1.ViolationService.GetIncident:input_type -> betrace.v1.GetIncidentRequest
+	9,  // 39: betrace.v1.ViolationService.UpdateViolationStatus:input_type -> betrace.v1.UpdateViolationStatusRequest
+	10, // 40: betrace.v1.ViolationService.AssignViolations:input_type -> betrace.v1.AssignViolationsRequest
+	13, // 41: betrace.v1.ViolationService.AddViolationComment:input_type -> betrace.v1.AddViolationCommentRequest
+	14, // 42: betrace.v1.ViolationService.VerifyViolation:input_type -> betrace.v1.VerifyViolationRequest
+	16, // 43: betrace.v1.ViolationService.ReproduceViolation:input_type -> betrace.v1.ReproduceViolationRequest
+	3,  // 44: betrace.v1.ViolationService.ListViolations:output_type -> betrace.v1.ListViolationsResponse
+	2,  // 45: betrace.v1.ViolationService.WatchViolations:output_type -> betrace.v1.ViolationEvent
+	20, // 46: betrace.v1.ViolationService.ListIncidents:output_type -> betrace.v1.ListIncidentsResponse
+	22, // 47: betrace.v1.ViolationService.GetIncident:output_type -> betrace.v1.GetIncidentResponse
+	11, // 48: betrace.v1.ViolationService.UpdateViolationStatus:output_type -> betrace.v1.UpdateViolationStatusResponse
+	11, // 49: betrace.v1.ViolationService.AssignViolations:output_type -> betrace.v1.UpdateViolationStatusResponse
+	4,  // 50: betrace.v1.ViolationService.AddViolationComment:output_type -> betrace.v1.Violation
+	15, // 51: betrace.v1.ViolationService.VerifyViolation:output_type -> betrace.v1.VerifyViolationResponse
+	17, // 52: betrace.v1.ViolationService.ReproduceViolation:output_type -> betrace.v1.ReproduceViolationResponse
+	44, // [44:53] is the sub-list for method output_type
+	35, // [35:44] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_betrace_v1_violations_proto_init() }
@@ -340,7 +2421,7 @@ func file_betrace_v1_violations_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_betrace_v1_violations_proto_rawDesc), len(file_betrace_v1_violations_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ViolationService_ListViolations_FullMethodName = "/betrace.v1.ViolationService/ListViolations"
+	ViolationService_ListViolations_FullMethodName        = "/betrace.v1.ViolationService/ListViolations"
+	ViolationService_WatchViolations_FullMethodName       = "/betrace.v1.ViolationService/WatchViolations"
+	ViolationService_ListIncidents_FullMethodName         = "/betrace.v1.ViolationService/ListIncidents"
+	ViolationService_GetIncident_FullMethodName           = "/betrace.v1.ViolationService/GetIncident"
+	ViolationService_UpdateViolationStatus_FullMethodName = "/betrace.v1.ViolationService/UpdateViolationStatus"
+	ViolationService_AssignViolations_FullMethodName      = "/betrace.v1.ViolationService/AssignViolations"
+	ViolationService_AddViolationComment_FullMethodName   = "/betrace.v1.ViolationService/AddViolationComment"
+	ViolationService_VerifyViolation_FullMethodName       = "/betrace.v1.ViolationService/VerifyViolation"
+	ViolationService_ReproduceViolation_FullMethodName    = "/betrace.v1.ViolationService/ReproduceViolation"
 )
 
 // ViolationServiceClient is the client API for ViolationService service.
@@ -30,6 +38,30 @@ const (
 type ViolationServiceClient interface {
 	// ListViolations returns violations that match the query
 	ListViolations(ctx context.Context, in *ListViolationsRequest, opts ...grpc.CallOption) (*ListViolationsResponse, error)
+	// WatchViolations streams violations as they are recorded. With a cursor,
+	// violations recorded after it are replayed first (oldest first), so a
+	// client can reconnect without gaps. Over HTTP, use the Server-Sent Events
+	// endpoint GET /v1/violations/stream instead.
+	WatchViolations(ctx context.Context, in *WatchViolationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ViolationEvent], error)
+	// ListIncidents returns violation groups, most recently seen first
+	ListIncidents(ctx context.Context, in *ListIncidentsRequest, opts ...grpc.CallOption) (*ListIncidentsResponse, error)
+	// GetIncident returns one violation group and its most recent violations
+	GetIncident(ctx context.Context, in *GetIncidentRequest, opts ...grpc.CallOption) (*GetIncidentResponse, error)
+	// UpdateViolationStatus moves violations to a new triage status.
+	// Each violation is updated independently; failures are reported per ID.
+	UpdateViolationStatus(ctx context.Context, in *UpdateViolationStatusRequest, opts ...grpc.CallOption) (*UpdateViolationStatusResponse, error)
+	// AssignViolations sets (or clears) the assignee of violations
+	AssignViolations(ctx context.Context, in *AssignViolationsRequest, opts ...grpc.CallOption) (*UpdateViolationStatusResponse, error)
+	// AddViolationComment appends a triage comment to a violation
+	AddViolationComment(ctx context.Context, in *AddViolationCommentRequest, opts ...grpc.CallOption) (*Violation, error)
+	// VerifyViolation checks a violation's signature and returns the signed
+	// payload, so it can also be checked offline against the public keys at
+	// GET /.well-known/jwks.json
+	VerifyViolation(ctx context.Context, in *VerifyViolationRequest, opts ...grpc.CallOption) (*VerifyViolationResponse, error)
+	// ReproduceViolation re-runs the rule expression captured with a violation
+	// against its evidence snapshot, so the violation can be shown to follow
+	// from the recorded spans after the trace has expired
+	ReproduceViolation(ctx context.Context, in *ReproduceViolationRequest, opts ...grpc.CallOption) (*ReproduceViolationResponse, error)
 }
 
 type violationServiceClient struct {
@@ -50,6 +82,95 @@ func (c *violationServiceClient) ListViolations(ctx context.Context, in *ListVio
 	return out, nil
 }
 
+func (c *violationServiceClient) WatchViolations(ctx context.Context, in *WatchViolationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ViolationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ViolationService_ServiceDesc.Streams[0], ViolationService_WatchViolations_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchViolationsRequest, ViolationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ViolationService_WatchViolationsClient = grpc.ServerStreamingClient[ViolationEvent]
+
+func (c *violationServiceClient) ListIncidents(ctx context.Context, in *ListIncidentsRequest, opts ...grpc.CallOption) (*ListIncidentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIncidentsResponse)
+	err := c.cc.Invoke(ctx, ViolationService_ListIncidents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *violationServiceClient) GetIncident(ctx context.Context, in *GetIncidentRequest, opts ...grpc.CallOption) (*GetIncidentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIncidentResponse)
+	err := c.cc.Invoke(ctx, ViolationService_GetIncident_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *violationServiceClient) UpdateViolationStatus(ctx context.Context, in *UpdateViolationStatusRequest, opts ...grpc.CallOption) (*UpdateViolationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateViolationStatusResponse)
+	err := c.cc.Invoke(ctx, ViolationService_UpdateViolationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *violationServiceClient) AssignViolations(ctx context.Context, in *AssignViolationsRequest, opts ...grpc.CallOption) (*UpdateViolationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateViolationStatusResponse)
+	err := c.cc.Invoke(ctx, ViolationService_AssignViolations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *violationServiceClient) AddViolationComment(ctx context.Context, in *AddViolationCommentRequest, opts ...grpc.CallOption) (*Violation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Violation)
+	err := c.cc.Invoke(ctx, ViolationService_AddViolationComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *violationServiceClient) VerifyViolation(ctx context.Context, in *VerifyViolationRequest, opts ...grpc.CallOption) (*VerifyViolationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyViolationResponse)
+	err := c.cc.Invoke(ctx, ViolationService_VerifyViolation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *violationServiceClient) ReproduceViolation(ctx context.Context, in *ReproduceViolationRequest, opts ...grpc.CallOption) (*ReproduceViolationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReproduceViolationResponse)
+	err := c.cc.Invoke(ctx, ViolationService_ReproduceViolation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ViolationServiceServer is the server API for ViolationService service.
 // All implementations must embed UnimplementedViolationServiceServer
 // for forward compatibility.
@@ -58,6 +179,30 @@ func (c *violationServiceClient) ListViolations(ctx context.Context, in *ListVio
 type ViolationServiceServer interface {
 	// ListViolations returns violations that match the query
 	ListViolations(context.Context, *ListViolationsRequest) (*ListViolationsResponse, error)
+	// WatchViolations streams violations as they are recorded. With a cursor,
+	// violations recorded after it are replayed first (oldest first), so a
+	// client can reconnect without gaps. Over HTTP, use the Server-Sent Events
+	// endpoint GET /v1/violations/stream instead.
+	WatchViolations(*WatchViolationsRequest, grpc.ServerStreamingServer[ViolationEvent]) error
+	// ListIncidents returns violation groups, most recently seen first
+	ListIncidents(context.Context, *ListIncidentsRequest) (*ListIncidentsResponse, error)
+	// GetIncident returns one violation group and its most recent violations
+	GetIncident(context.Context, *GetIncidentRequest) (*GetIncidentResponse, error)
+	// UpdateViolationStatus moves violations to a new triage status.
+	// Each violation is updated independently; failures are reported per ID.
+	UpdateViolationStatus(context.Context, *UpdateViolationStatusRequest) (*UpdateViolationStatusResponse, error)
+	// AssignViolations sets (or clears) the assignee of violations
+	AssignViolations(context.Context, *AssignViolationsRequest) (*UpdateViolationStatusResponse, error)
+	// AddViolationComment appends a triage comment to a violation
+	AddViolationComment(context.Context, *AddViolationCommentRequest) (*Violation, error)
+	// VerifyViolation checks a violation's signature and returns the signed
+	// payload, so it can also be checked offline against the public keys at
+	// GET /.well-known/jwks.json
+	VerifyViolation(context.Context, *VerifyViolationRequest) (*VerifyViolationResponse, error)
+	// ReproduceViolation re-runs the rule expression captured with a violation
+	// against its evidence snapshot, so the violation can be shown to follow
+	// from the recorded spans after the trace has expired
+	ReproduceViolation(context.Context, *ReproduceViolationRequest) (*ReproduceViolationResponse, error)
 	mustEmbedUnimplementedViolationServiceServer()
 }
 
@@ -71,6 +216,30 @@ type UnimplementedViolationServiceServer struct{}
 func (UnimplementedViolationServiceServer) ListViolations(context.Context, *ListViolationsRequest) (*ListViolationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListViolations not implemented")
 }
+func (UnimplementedViolationServiceServer) WatchViolations(*WatchViolationsRequest, grpc.ServerStreamingServer[ViolationEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchViolations not implemented")
+}
+func (UnimplementedViolationServiceServer) ListIncidents(context.Context, *ListIncidentsRequest) (*ListIncidentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIncidents not implemented")
+}
+func (UnimplementedViolationServiceServer) GetIncident(context.Context, *GetIncidentRequest) (*GetIncidentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIncident not implemented")
+}
+func (UnimplementedViolationServiceServer) UpdateViolationStatus(context.Context, *UpdateViolationStatusRequest) (*UpdateViolationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateViolationStatus not implemented")
+}
+func (UnimplementedViolationServiceServer) AssignViolations(context.Context, *AssignViolationsRequest) (*UpdateViolationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignViolations not implemented")
+}
+func (UnimplementedViolationServiceServer) AddViolationComment(context.Context, *AddViolationCommentRequest) (*Violation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddViolationComment not implemented")
+}
+func (UnimplementedViolationServiceServer) VerifyViolation(context.Context, *VerifyViolationRequest) (*VerifyViolationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyViolation not implemented")
+}
+func (UnimplementedViolationServiceServer) ReproduceViolation(context.Context, *ReproduceViolationRequest) (*ReproduceViolationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReproduceViolation not implemented")
+}
 func (UnimplementedViolationServiceServer) mustEmbedUnimplementedViolationServiceServer() {}
 func (UnimplementedViolationServiceServer) testEmbeddedByValue()                          {}
 
@@ -110,6 +279,143 @@ func _ViolationService_ListViolations_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ViolationService_WatchViolations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchViolationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ViolationServiceServer).WatchViolations(m, &grpc.GenericServerStream[WatchViolationsRequest, ViolationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ViolationService_WatchViolationsServer = grpc.ServerStreamingServer[ViolationEvent]
+
+func _ViolationService_ListIncidents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIncidentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).ListIncidents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_ListIncidents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).ListIncidents(ctx, req.(*ListIncidentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ViolationService_GetIncident_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIncidentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).GetIncident(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_GetIncident_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).GetIncident(ctx, req.(*GetIncidentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ViolationService_UpdateViolationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateViolationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).UpdateViolationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_UpdateViolationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).UpdateViolationStatus(ctx, req.(*UpdateViolationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ViolationService_AssignViolations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignViolationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).AssignViolations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_AssignViolations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).AssignViolations(ctx, req.(*AssignViolationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ViolationService_AddViolationComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddViolationCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).AddViolationComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_AddViolationComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).AddViolationComment(ctx, req.(*AddViolationCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ViolationService_VerifyViolation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyViolationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).VerifyViolation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_VerifyViolation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).VerifyViolation(ctx, req.(*VerifyViolationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ViolationService_ReproduceViolation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReproduceViolationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViolationServiceServer).ReproduceViolation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ViolationService_ReproduceViolation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViolationServiceServer).ReproduceViolation(ctx, req.(*ReproduceViolationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ViolationService_ServiceDesc is the grpc.ServiceDesc for ViolationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -121,7 +427,41 @@ var ViolationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListViolations",
 			Handler:    _ViolationService_ListViolations_Handler,
 		},
+		{
+			MethodName: "ListIncidents",
+			Handler:    _ViolationService_ListIncidents_Handler,
+		},
+		{
+			MethodName: "GetIncident",
+			Handler:    _ViolationService_GetIncident_Handler,
+		},
+		{
+			MethodName: "UpdateViolationStatus",
+			Handler:    _ViolationService_UpdateViolationStatus_Handler,
+		},
+		{
+			MethodName: "AssignViolations",
+			Handler:    _ViolationService_AssignViolations_Handler,
+		},
+		{
+			MethodName: "AddViolationComment",
+			Handler:    _ViolationService_AddViolationComment_Handler,
+		},
+		{
+			MethodName: "VerifyViolation",
+			Handler:    _ViolationService_VerifyViolation_Handler,
+		},
+		{
+			MethodName: "ReproduceViolation",
+			Handler:    _ViolationService_ReproduceViolation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchViolations",
+			Handler:       _ViolationService_WatchViolations_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "betrace/v1/violations.proto",
 }
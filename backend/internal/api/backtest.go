@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+// Backtest endpoints
+const (
+	BacktestsPath = "/v1/backtests"  // GET lists jobs, POST starts one
+	BacktestPath  = "/v1/backtests/" // GET /v1/backtests/{id}, POST /v1/backtests/{id}:cancel
+)
+
+// backtestCancelSuffix marks the cancel action on a job
+const backtestCancelSuffix = ":cancel"
+
+// maxBacktestFormField bounds the non-file fields of an upload
+const maxBacktestFormField = 1 << 16
+
+// BacktestHandlers serve backtest jobs
+type BacktestHandlers struct {
+	runner         *services.BacktestRunner
+	maxUploadBytes int64
+}
+
+// NewBacktestHandlers creates handlers for backtest jobs, accepting
+// uploads of up to maxUploadBytes
+func NewBacktestHandlers(runner *services.BacktestRunner, maxUploadBytes int64) *BacktestHandlers {
+	return &BacktestHandlers{runner: runner, maxUploadBytes: maxUploadBytes}
+}
+
+// Jobs handles GET /v1/backtests (every kept job, newest first) and
+// POST /v1/backtests, which starts a job and returns it with 202 Accepted.
+//
+// A JSON body is a services.BacktestRequest for the directory and archive
+// sources. To backtest an OTLP/JSON file, POST multipart/form-data with the
+// file as "traces" and ruleId or expression (and optionally samples) as
+// fields.
+func (h *BacktestHandlers) Jobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, map[string]interface{}{"backtests": h.runner.List()})
+		return
+	case http.MethodPost:
+	default:
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadBytes)
+	var req services.BacktestRequest
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		req, err = readBacktestUpload(r)
+	} else if err = json.NewDecoder(r.Body).Decode(&req); err == nil && req.Source == services.BacktestSourceUpload {
+		err = errors.New(`upload traces as multipart/form-data, with the OTLP/JSON file as "traces"`)
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondError(w, fmt.Sprintf("upload exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.runner.Start(req)
+	if errors.Is(err, services.ErrInvalidBacktest) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrBacktestQueueFull) {
+		respondError(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Location", BacktestPath+job.ID)
+	respondJSON(w, http.StatusAccepted, job)
+}
+
+// readBacktestUpload reads a multipart backtest, decoding the "traces" file
+// as it streams in
+func readBacktestUpload(r *http.Request) (services.BacktestRequest, error) {
+	req := services.BacktestRequest{Source: services.BacktestSourceUpload}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return req, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return req, nil
+		}
+		if err != nil {
+			return req, err
+		}
+
+		if part.FormName() == "traces" {
+			spans, err := services.DecodeOTLPJSON(part)
+			if err != nil {
+				return req, err
+			}
+			req.Spans = append(req.Spans, spans...)
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, maxBacktestFormField))
+		if err != nil {
+			return req, err
+		}
+		switch part.FormName() {
+		case "ruleId":
+			req.RuleID = string(value)
+		case "expression":
+			req.Expression = string(value)
+		case "samples":
+			if req.Samples, err = strconv.Atoi(string(value)); err != nil {
+				return req, fmt.Errorf("invalid samples: %w", err)
+			}
+		}
+	}
+}
+
+// Job handles GET /v1/backtests/{id}, returning a job with its progress and
+// the counts so far, and POST /v1/backtests/{id}:cancel, which stops it
+func (h *BacktestHandlers) Job(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, BacktestPath)
+	id, cancel := strings.CutSuffix(id, backtestCancelSuffix)
+
+	var job services.BacktestJob
+	var err error
+	switch {
+	case cancel && r.Method == http.MethodPost:
+		job, err = h.runner.Cancel(id)
+	case !cancel && r.Method == http.MethodGet:
+		job, err = h.runner.Get(id)
+	default:
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if errors.Is(err, services.ErrBacktestNotFound) {
+		respondError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, job)
+}
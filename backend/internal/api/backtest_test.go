@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+const backtestUpload = `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},"scopeSpans":[{"spans":[
+	{"traceId":"t1","spanId":"s1","name":"payment","startTimeUnixNano":"1","endTimeUnixNano":"2"},
+	{"traceId":"t2","spanId":"s2","name":"payment","startTimeUnixNano":"1","endTimeUnixNano":"2"},
+	{"traceId":"t2","spanId":"s3","name":"auth","startTimeUnixNano":"1","endTimeUnixNano":"2"}
+]}]}]}`
+
+// multipartBacktest builds an upload of traces with the given fields
+func multipartBacktest(t *testing.T, traces string, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	file, _ := form.CreateFormFile("traces", "traces.json")
+	file.Write([]byte(traces))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, BacktestsPath, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestBacktestHandlers_UploadPollAndCancel(t *testing.T) {
+	runner := services.NewBacktestRunner(nil, services.BacktestOptions{})
+	defer runner.Close()
+	h := NewBacktestHandlers(runner, 1<<20)
+
+	rec := httptest.NewRecorder()
+	h.Jobs(rec, multipartBacktest(t, backtestUpload, map[string]string{"expression": "when { payment } always { auth }"}))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body)
+	}
+	var job services.BacktestJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Invalid job: %v", err)
+	}
+	if job.Source != services.BacktestSourceUpload || rec.Header().Get("Location") != BacktestPath+job.ID {
+		t.Errorf("Expected an upload job and its location, got %+v (%s)", job, rec.Header().Get("Location"))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.Done() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		rec = httptest.NewRecorder()
+		h.Job(rec, httptest.NewRequest(http.MethodGet, BacktestPath+job.ID, nil))
+		json.Unmarshal(rec.Body.Bytes(), &job)
+	}
+	if job.State != services.BacktestSucceeded || job.Result.Matched != 2 || job.Result.Violated != 1 || job.Result.ViolationSamples[0] != "t1" {
+		t.Errorf("Expected 1 violation in 2 matches, got %s %+v", job.State, job.Result)
+	}
+
+	// Canceling a finished job leaves it as it was
+	rec = httptest.NewRecorder()
+	h.Job(rec, httptest.NewRequest(http.MethodPost, BacktestPath+job.ID+":cancel", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"state":"succeeded"`) {
+		t.Errorf("Expected the finished job unchanged, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Jobs(rec, httptest.NewRequest(http.MethodGet, BacktestsPath, nil))
+	if !strings.Contains(rec.Body.String(), job.ID) {
+		t.Errorf("Expected the job listed, got %s", rec.Body)
+	}
+
+	for _, path := range []string{BacktestPath + "missing", BacktestPath + "missing:cancel"} {
+		method := http.MethodGet
+		if strings.HasSuffix(path, ":cancel") {
+			method = http.MethodPost
+		}
+		rec = httptest.NewRecorder()
+		h.Job(rec, httptest.NewRequest(method, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected 404, got %d", method, path, rec.Code)
+		}
+	}
+}
+
+func TestBacktestHandlers_RejectsBadRequests(t *testing.T) {
+	runner := services.NewBacktestRunner(nil, services.BacktestOptions{})
+	defer runner.Close()
+	h := NewBacktestHandlers(runner, 1024)
+
+	for name, tc := range map[string]struct {
+		req  *http.Request
+		want int
+	}{
+		"invalid OTLP/JSON":  {multipartBacktest(t, `{"resourceSpans":`, map[string]string{"expression": "when { a } always { b }"}), http.StatusBadRequest},
+		"invalid expression": {multipartBacktest(t, backtestUpload, map[string]string{"expression": "when {"}), http.StatusBadRequest},
+		"too large":          {multipartBacktest(t, backtestUpload+strings.Repeat(" ", 2048), map[string]string{"expression": "when { a } always { b }"}), http.StatusRequestEntityTooLarge},
+		"upload as JSON":     {httptest.NewRequest(http.MethodPost, BacktestsPath, strings.NewReader(`{"expression":"when { a } always { b }","source":"upload"}`)), http.StatusBadRequest},
+		"disabled directory": {httptest.NewRequest(http.MethodPost, BacktestsPath, strings.NewReader(`{"expression":"when { a } always { b }","source":"directory","path":"traces"}`)), http.StatusBadRequest},
+		"wrong method":       {httptest.NewRequest(http.MethodDelete, BacktestsPath, nil), http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		h.Jobs(rec, tc.req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", name, tc.want, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+// Compliance endpoints
+const (
+	ComplianceEvidencePath    = "/api/v1/compliance/evidence"
+	ComplianceExportPath      = "/api/v1/compliance/export"
+	ComplianceEvaluationsPath = "/api/v1/compliance/evaluations"
+)
+
+// ComplianceHandlers serve compliance evidence built from rules, their
+// evaluation counts and violations
+type ComplianceHandlers struct {
+	reporter *services.ComplianceReporter
+}
+
+// NewComplianceHandlers creates handlers for compliance evidence and exports
+func NewComplianceHandlers(reporter *services.ComplianceReporter) *ComplianceHandlers {
+	return &ComplianceHandlers{reporter: reporter}
+}
+
+// Evidence handles GET /api/v1/compliance/evidence?framework=soc2&control=CC6.1&since=...&until=...
+//
+// Every parameter is optional: since and until are RFC 3339 times (default
+// the last 30 days) and control requires framework. Returns the matching
+// controls with the rules mapped to them, their evaluation counts and
+// violation counts.
+func (h *ComplianceHandlers) Evidence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseComplianceQuery(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := h.reporter.Evidence(r.Context(), q)
+	if errors.Is(err, services.ErrInvalidComplianceQuery) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, report)
+}
+
+// Export handles GET or POST /api/v1/compliance/export with the parameters
+// of Evidence, returning a zip bundle of the evidence report, the violations
+// with their signatures, a CSV manifest and the public keys. Verify it
+// offline with services.VerifyComplianceBundle (or the
+// betrace-verify-bundle command).
+func (h *ComplianceHandlers) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseComplianceQuery(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bundle, err := h.reporter.Export(r.Context(), q)
+	if errors.Is(err, services.ErrInvalidComplianceQuery) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	name := "betrace-compliance"
+	if q.Framework != "" {
+		name += "-" + q.Framework
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.zip"`, name, time.Now().UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Evaluations handles GET /api/v1/compliance/evaluations with the
+// parameters of Evidence plus rule_id and service, returning the hourly
+// evaluation counts of the selected rules with sampled passing trace IDs
+func (h *ComplianceHandlers) Evaluations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseComplianceQuery(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := h.reporter.Evaluations(r.Context(), services.EvaluationQuery{
+		ComplianceQuery: q,
+		RuleID:          r.URL.Query().Get("rule_id"),
+		Service:         r.URL.Query().Get("service"),
+	})
+	if errors.Is(err, services.ErrInvalidComplianceQuery) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, report)
+}
+
+// parseComplianceQuery reads framework, control, since and until
+func parseComplianceQuery(r *http.Request) (services.ComplianceQuery, error) {
+	query := r.URL.Query()
+	q := services.ComplianceQuery{
+		Framework: query.Get("framework"),
+		Control:   query.Get("control"),
+	}
+	for name, t := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s: want an RFC 3339 time", name)
+		}
+		*t = parsed
+	}
+	return q, nil
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// staticRules lists a fixed set of rules
+type staticRules []models.Rule
+
+func (r staticRules) List() ([]models.Rule, error) { return r, nil }
+
+func TestComplianceHandlers(t *testing.T) {
+	store := services.NewViolationStoreMemory("test-key")
+	if _, err := store.Record(context.Background(), models.Violation{RuleID: "mfa", Message: "no MFA"}, nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	rules := staticRules{{ID: "mfa", Expression: "when { admin_login } always { mfa }", Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}}}}
+	counter, err := services.NewEvaluationCounter("", services.EvaluationCounterOptions{Exemplars: 1})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	counter.Record("mfa", "admin", "trace-ok", time.Now(), services.EvaluationPassed)
+	h := NewComplianceHandlers(services.NewComplianceReporter(rules, store, counter, store.Keyring()))
+
+	rec := httptest.NewRecorder()
+	h.Evidence(rec, httptest.NewRequest(http.MethodGet, ComplianceEvidencePath+"?framework=soc2&control=CC6.1&since=2020-01-01T00:00:00Z", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var report services.ComplianceReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid report: %v", err)
+	}
+	if len(report.Controls) != 1 || report.Controls[0].Violations != 1 {
+		t.Errorf("Expected CC6.1 with 1 violation, got %+v", report.Controls)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Export(rec, httptest.NewRequest(http.MethodPost, ComplianceExportPath+"?framework=soc2", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("Expected a zip, got %d %s: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	bundle, err := services.ReadComplianceBundle(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("Invalid bundle: %v", err)
+	}
+	if summary, err := services.VerifyComplianceBundle(bundle, store.Keyring().JWKS()); err != nil || summary.Violations != 1 {
+		t.Errorf("Expected the downloaded bundle to verify, got %+v (%v)", summary, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Evaluations(rec, httptest.NewRequest(http.MethodGet, ComplianceEvaluationsPath+"?framework=soc2&service=admin", nil))
+	var evaluations services.EvaluationReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &evaluations); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("Expected an evaluation report, got %d: %s", rec.Code, rec.Body)
+	}
+	if evaluations.Passed != 1 || len(evaluations.Buckets) != 1 || evaluations.Buckets[0].PassExemplars[0] != "trace-ok" {
+		t.Errorf("Expected one passing evaluation of the admin service, got %+v", evaluations)
+	}
+
+	for _, query := range []string{"?framework=iso", "?control=CC6.1", "?since=yesterday"} {
+		rec := httptest.NewRecorder()
+		h.Evidence(rec, httptest.NewRequest(http.MethodGet, ComplianceEvidencePath+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+
+	// The legacy server answers 501 until compliance handlers are configured
+	server := NewServer("test")
+	rec = httptest.NewRecorder()
+	server.handleComplianceEvidence(rec, httptest.NewRequest(http.MethodGet, ComplianceEvidencePath, nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without handlers, got %d", rec.Code)
+	}
+	server.SetComplianceHandlers(h)
+	rec = httptest.NewRecorder()
+	server.handleComplianceEvidence(rec, httptest.NewRequest(http.MethodGet, ComplianceEvidencePath, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with handlers, got %d", rec.Code)
+	}
+}
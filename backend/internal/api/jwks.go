@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+// JWKSPath is where the violation signing keys are published
+const JWKSPath = "/.well-known/jwks.json"
+
+// jwksMaxAge lets verifiers cache the key set; rotation adds keys without
+// removing old ones, so a stale cache only misses the newest key
+const jwksMaxAge = "max-age=300"
+
+// JWKSHandler publishes the public keys that sign violations
+type JWKSHandler struct {
+	keys *services.Keyring
+}
+
+// NewJWKSHandler creates a handler serving keys as a JSON Web Key Set
+func NewJWKSHandler(keys *services.Keyring) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// ServeHTTP handles GET /.well-known/jwks.json
+//
+// Every key that signed violations is listed, retired ones included, with
+// its kid matching the violations' signatureKeyId. Without signing
+// configured the set is empty.
+func (h *JWKSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	set := services.JWKS{Keys: []services.JWK{}}
+	if h.keys != nil {
+		set = h.keys.JWKS()
+	}
+	w.Header().Set("Cache-Control", jwksMaxAge)
+	respondJSON(w, http.StatusOK, set)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+func getJWKS(t *testing.T, keys *services.Keyring) services.JWKS {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	NewJWKSHandler(keys).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var set services.JWKS
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+		t.Fatalf("Invalid JWKS %s: %v", rec.Body, err)
+	}
+	return set
+}
+
+func TestJWKS_ListsRetiredKeys(t *testing.T) {
+	keys := services.NewEphemeralKeyring()
+	retired := keys.Active().ID
+	if _, err := keys.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+
+	set := getJWKS(t, keys)
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != retired || set.Keys[1].KeyID != keys.Active().ID {
+		t.Fatalf("Expected retired and active keys, got %+v", set.Keys)
+	}
+	for _, key := range set.Keys {
+		if key.KeyType != "OKP" || key.Curve != "Ed25519" || key.Algorithm != "EdDSA" || key.Use != "sig" || key.X == "" {
+			t.Errorf("Unexpected JWK %+v", key)
+		}
+	}
+}
+
+func TestJWKS_SigningDisabled(t *testing.T) {
+	if set := getJWKS(t, nil); set.Keys == nil || len(set.Keys) != 0 {
+		t.Errorf("Expected an empty key set, got %+v", set)
+	}
+
+	rec := httptest.NewRecorder()
+	NewJWKSHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, JWKSPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
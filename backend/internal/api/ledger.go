@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+// Ledger endpoints
+const (
+	LedgerExportPath      = "/v1/ledger/export"
+	LedgerCheckpointsPath = "/v1/ledger/checkpoints"
+)
+
+// LedgerHandlers serve the violation ledger for audits
+type LedgerHandlers struct {
+	ledger *services.Ledger
+}
+
+// NewLedgerHandlers creates handlers for ledger exports and checkpoints
+func NewLedgerHandlers(ledger *services.Ledger) *LedgerHandlers {
+	return &LedgerHandlers{ledger: ledger}
+}
+
+// Export handles GET /v1/ledger/export?from=1&to=100
+//
+// Returns entries from..to (1-based, inclusive; to defaults to the latest,
+// at most services.MaxLedgerExport) with inclusion proofs against a signed
+// checkpoint. Verify offline with services.VerifyLedgerExport (or the
+// betrace-verify-ledger command) and the keys at /.well-known/jwks.json.
+func (h *LedgerHandlers) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := parseSeq(query.Get("from"), 1)
+	if err != nil {
+		respondError(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseSeq(query.Get("to"), 0)
+	if err != nil {
+		respondError(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.ledger.Export(from, to)
+	if errors.Is(err, services.ErrLedgerRange) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, export)
+}
+
+// Checkpoints handles GET /v1/ledger/checkpoints, oldest first
+func (h *LedgerHandlers) Checkpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"size":        h.ledger.Size(),
+		"checkpoints": h.ledger.Checkpoints(),
+	})
+}
+
+// parseSeq parses a ledger sequence number, returning def if empty
+func parseSeq(value string, def uint64) (uint64, error) {
+	if value == "" {
+		return def, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
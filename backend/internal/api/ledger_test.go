@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestLedgerHandlers_Export(t *testing.T) {
+	keys := services.NewEphemeralKeyring()
+	ledger, err := services.NewLedger("", keys, 0)
+	if err != nil {
+		t.Fatalf("NewLedger failed: %v", err)
+	}
+	for _, id := range []string{"v-1", "v-2", "v-3"} {
+		if _, err := ledger.Append(models.Violation{ID: id}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	h := NewLedgerHandlers(ledger)
+
+	rec := httptest.NewRecorder()
+	h.Export(rec, httptest.NewRequest(http.MethodGet, LedgerExportPath+"?from=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var export services.LedgerExport
+	if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatalf("Invalid export: %v", err)
+	}
+	if len(export.Entries) != 2 || export.Entries[0].ViolationID != "v-2" {
+		t.Errorf("Expected entries 2..3, got %+v", export.Entries)
+	}
+	if err := services.VerifyLedgerExport(export, keys.JWKS()); err != nil {
+		t.Errorf("Expected the exported JSON to verify: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Checkpoints(rec, httptest.NewRequest(http.MethodGet, LedgerCheckpointsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rec.Code)
+	}
+
+	for _, query := range []string{"?from=0", "?from=4", "?from=x", "?to=9"} {
+		rec := httptest.NewRecorder()
+		h.Export(rec, httptest.NewRequest(http.MethodGet, LedgerExportPath+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/betracehq/betrace/backend/internal/notify"
+)
+
+// Notification endpoints
+const (
+	DeadLettersPath          = "/v1/notifications/dead-letters"
+	DeadLettersRedeliverPath = "/v1/notifications/dead-letters:redeliver"
+)
+
+// NotificationHandlers serve the notifier's dead-letter queue
+type NotificationHandlers struct {
+	notifier *notify.Notifier
+}
+
+// NewNotificationHandlers creates handlers for inspecting and redelivering
+// dead letters
+func NewNotificationHandlers(notifier *notify.Notifier) *NotificationHandlers {
+	return &NotificationHandlers{notifier: notifier}
+}
+
+// DeadLetters handles GET /v1/notifications/dead-letters, returning every
+// notification that could not be delivered, oldest first
+func (h *NotificationHandlers) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	letters, err := h.notifier.DeadLetters().List()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if letters == nil {
+		letters = []notify.DeadLetter{}
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"deadLetters": letters})
+}
+
+// Redeliver handles POST /v1/notifications/dead-letters:redeliver, which
+// re-queues every dead letter for its receiver. Letters for receivers no
+// longer configured stay in the queue.
+func (h *NotificationHandlers) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requeued, err := h.notifier.RedeliverDeadLetters()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"requeued": requeued})
+}
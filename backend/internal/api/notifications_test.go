@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/notify"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestNotificationHandlers_ListAndRedeliverDeadLetters(t *testing.T) {
+	delivered := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+
+	n, err := notify.NewNotifier(t.TempDir(), "test-key", notify.Config{Webhooks: []notify.WebhookConfig{{Name: "hook", URL: server.URL}}})
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	n.Start()
+	defer n.Close()
+	h := NewNotificationHandlers(n)
+
+	rec := httptest.NewRecorder()
+	h.DeadLetters(rec, httptest.NewRequest(http.MethodGet, DeadLettersPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"deadLetters":[]}`+"\n" {
+		t.Errorf("Expected no dead letters, got %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, endpoint := range []string{"hook", "removed"} {
+		v := models.Violation{ID: "v-" + endpoint, RuleID: "rule-1"}
+		n.DeadLetters().Append(notify.DeadLetter{Endpoint: endpoint, ViolationIDs: []string{v.ID}, Notification: notify.Notification{Violations: []models.Violation{v}}})
+	}
+
+	rec = httptest.NewRecorder()
+	h.DeadLetters(rec, httptest.NewRequest(http.MethodGet, DeadLettersPath, nil))
+	var listed struct {
+		DeadLetters []notify.DeadLetter `json:"deadLetters"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil || len(listed.DeadLetters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %s (%v)", rec.Body, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Redeliver(rec, httptest.NewRequest(http.MethodPost, DeadLettersRedeliverPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"requeued":1}`+"\n" {
+		t.Errorf("Expected 1 requeued, got %d: %s", rec.Code, rec.Body)
+	}
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the redelivery")
+	}
+
+	letters, _ := n.DeadLetters().List()
+	if len(letters) != 1 || letters[0].Endpoint != "removed" {
+		t.Errorf("Expected only the removed receiver's letter kept, got %+v", letters)
+	}
+
+	for name, call := range map[string]func(){
+		"list":      func() { h.DeadLetters(rec, httptest.NewRequest(http.MethodPost, DeadLettersPath, nil)) },
+		"redeliver": func() { h.Redeliver(rec, httptest.NewRequest(http.MethodGet, DeadLettersRedeliverPath, nil)) },
+	} {
+		rec = httptest.NewRecorder()
+		call()
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %d", name, rec.Code)
+		}
+	}
+}
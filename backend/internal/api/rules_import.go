@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/betracehq/betrace/backend/internal/observability"
+	"github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/pkg/models"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
@@ -108,6 +110,7 @@ func (h *RuleHandlers) ImportRules(w http.ResponseWriter, r *http.Request) {
 			Expression:  strings.TrimSpace(yamlRule.Condition), // Use condition as expression
 			Enabled:     true, // Enable by default
 		}
+		rule.Controls, rule.Tags = importComplianceFrameworks(yamlRule.ComplianceFrameworks)
 
 		// Generate ID if not provided
 		if rule.ID == "" {
@@ -155,6 +158,24 @@ func (h *RuleHandlers) ImportRules(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// importComplianceFrameworks maps compliance_frameworks entries to controls.
+// Entries name a framework ("SOC 2") or a control ("soc2:CC6.1"); entries
+// naming unsupported frameworks ("PCI-DSS") are kept as tags.
+func importComplianceFrameworks(entries []string) ([]models.ComplianceControl, []string) {
+	var mappings []models.ComplianceControl
+	var tags []string
+	for _, entry := range entries {
+		name, controlID, _ := strings.Cut(entry, ":")
+		if _, ok := observability.ParseComplianceFramework(strings.TrimSpace(name)); !ok {
+			tags = append(tags, entry)
+			continue
+		}
+		mappings = append(mappings, models.ComplianceControl{Framework: strings.TrimSpace(name), ControlID: controlID})
+	}
+	controls, _ := services.NormalizeControls(mappings) // Frameworks were checked above
+	return controls, tags
+}
+
 // ImportResults represents the response from bulk import
 type ImportResults struct {
 	Total     int           `json:"total"`
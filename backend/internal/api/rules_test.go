@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/betracehq/betrace/backend/internal/services"
@@ -360,3 +362,74 @@ func TestRulesAPI_WithTracer(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 }
+
+// TestValidateRule_ExplainsSampleTrace verifies explain mode on validation
+func TestValidateRule_ExplainsSampleTrace(t *testing.T) {
+	handlers := NewRuleHandlers(services.NewRuleStore(), nil)
+
+	body, _ := json.Marshal(ValidateRuleRequest{
+		Expression: "when { payment } always { fraud_check }",
+		Spans: []models.Span{
+			{SpanID: "span-1", TraceID: "trace-1", OperationName: "payment"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/rules/validate", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handlers.ValidateRule(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var resp ValidateRuleResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !resp.Valid {
+		t.Fatalf("Expected valid rule, got error: %s", resp.Error)
+	}
+	if resp.Explanation == nil {
+		t.Fatal("Expected explanation for sample trace")
+	}
+	if !resp.Explanation.Violated {
+		t.Error("Expected sample trace to violate the rule")
+	}
+	if len(resp.Explanation.UnsatisfiedAlways) != 1 || resp.Explanation.UnsatisfiedAlways[0] != "fraud_check" {
+		t.Errorf("Expected UnsatisfiedAlways=[fraud_check], got %v", resp.Explanation.UnsatisfiedAlways)
+	}
+}
+
+func TestImportRules_MapsComplianceFrameworks(t *testing.T) {
+	store := services.NewRuleStore()
+	handlers := NewRuleHandlers(store, nil)
+
+	body := `rules:
+  - id: phi-audit
+    name: PHI access is audited
+    condition: when { phi.access } always { audit.log }
+    compliance_frameworks:
+      - HIPAA:164.312(b)
+      - SOC 2
+      - PCI-DSS
+`
+	req := httptest.NewRequest(http.MethodPost, "/api/rules/import", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.ImportRules(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	rule, err := store.Get(req.Context(), "phi-audit")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	wantControls := []models.ComplianceControl{{Framework: "hipaa", ControlID: "164.312(b)"}, {Framework: "soc2"}}
+	if !reflect.DeepEqual(rule.Controls, wantControls) {
+		t.Errorf("Expected controls %v, got %v", wantControls, rule.Controls)
+	}
+	if !reflect.DeepEqual(rule.Tags, []string{"PCI-DSS"}) {
+		t.Errorf("Expected the unsupported framework as a tag, got %v", rule.Tags)
+	}
+}
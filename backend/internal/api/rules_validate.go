@@ -5,13 +5,23 @@ import (
 	"net/http"
 
 	"github.com/betracehq/betrace/backend/internal/dsl"
+	"github.com/betracehq/betrace/backend/internal/rules"
+	"github.com/betracehq/betrace/backend/pkg/models"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// explainLimits bounds explaining an expression against a sample trace, like
+// the rule engine bounds evaluation
+var explainLimits = dsl.Limits{
+	Timeout:  rules.DefaultEvaluationTimeout,
+	MaxSteps: rules.DefaultMaxEvaluationSteps,
+}
+
 // ValidateRuleRequest is the request body for rule validation
 type ValidateRuleRequest struct {
-	Expression string `json:"expression"`
+	Expression string        `json:"expression"`
+	Spans      []models.Span `json:"spans,omitempty"` // Optional sample trace to explain against
 }
 
 // ValidateRuleResponse is the response for rule validation
@@ -19,6 +29,10 @@ type ValidateRuleResponse struct {
 	Valid  bool   `json:"valid"`
 	Error  string `json:"error,omitempty"`
 	AST    string `json:"ast,omitempty"` // String representation of parsed AST (for debugging)
+
+	// Explain mode result for the sample trace (only when spans were given)
+	Explanation      *models.RuleExplanation `json:"explanation,omitempty"`
+	ExplanationError string                  `json:"explanationError,omitempty"`
 }
 
 // ValidateRule handles POST /api/rules/validate
@@ -74,11 +88,26 @@ func (h *RuleHandlers) ValidateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return success with AST representation for debugging
-	astString := formatAST(ast)
-	respondJSON(w, http.StatusOK, ValidateRuleResponse{
+	response := ValidateRuleResponse{
 		Valid: true,
-		AST:   astString,
-	})
+		AST:   formatAST(ast),
+	}
+
+	// Explain the rule against the sample trace, if one was given
+	if len(req.Spans) > 0 {
+		spans := make([]*models.Span, len(req.Spans))
+		for i := range req.Spans {
+			spans[i] = &req.Spans[i]
+		}
+		explanation, err := dsl.NewEvaluator().ExplainContext(ctx, ast, spans, explainLimits)
+		if err != nil {
+			response.ExplanationError = err.Error()
+		} else {
+			response.Explanation = explanation
+		}
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
 
 // formatAST creates a simple string representation of the AST for debugging
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/betracehq/betrace/backend/internal/middleware"
 	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/internal/rules"
+	"github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/pkg/models"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -21,6 +23,9 @@ type Server struct {
 	startTime time.Time
 	version   string
 	auth      *middleware.AuthMiddleware
+
+	compliance *ComplianceHandlers    // nil until SetComplianceHandlers
+	matches    *services.MatchHistory // nil until SetMatchHistory
 }
 
 // NewServer creates a new API server
@@ -34,6 +39,16 @@ func NewServer(version string) *Server {
 	}
 }
 
+// SetComplianceHandlers serves the compliance endpoints with handlers
+func (s *Server) SetComplianceHandlers(handlers *ComplianceHandlers) {
+	s.compliance = handlers
+}
+
+// SetMatchHistory serves rule match history from history
+func (s *Server) SetMatchHistory(history *services.MatchHistory) {
+	s.matches = history
+}
+
 // RegisterRoutes registers all HTTP routes
 func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	// Health & Metrics
@@ -50,8 +65,9 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/evaluate/batch", s.handleEvaluateBatch)
 
 	// Compliance API
-	mux.HandleFunc("/api/v1/compliance/evidence", s.handleComplianceEvidence)
-	mux.HandleFunc("/api/v1/compliance/export", s.handleComplianceExport)
+	mux.HandleFunc(ComplianceEvidencePath, s.handleComplianceEvidence)
+	mux.HandleFunc(ComplianceExportPath, s.handleComplianceExport)
+	mux.HandleFunc(ComplianceEvaluationsPath, s.handleComplianceEvaluations)
 }
 
 // Middleware wraps handlers with common functionality
@@ -273,9 +289,57 @@ func (s *Server) handleDisableRule(w http.ResponseWriter, r *http.Request, ruleI
 	json.NewEncoder(w).Encode(rule.Rule)
 }
 
+// handleRuleMatches handles GET /api/v1/rules/{id}/matches?outcome=&since=&until=&limit=&cursor=
+// (since and until are RFC 3339), returning the rule's matches newest first
 func (s *Server) handleRuleMatches(w http.ResponseWriter, r *http.Request, ruleID string) {
-	// TODO: Implement rule matches query (requires storing match history)
-	respondError(w, "Not implemented yet", http.StatusNotImplemented)
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.matches == nil {
+		respondError(w, "Rule match history is not configured", http.StatusNotImplemented)
+		return
+	}
+	if _, exists := s.engine.GetRule(ruleID); !exists {
+		respondError(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := services.MatchFilter{
+		Outcome: query.Get("outcome"),
+		Cursor:  query.Get("cursor"),
+		Limit:   defaultViolationPageSize,
+	}
+	for name, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(w, "Invalid "+name+": "+value, http.StatusBadRequest)
+				return
+			}
+			*t = parsed
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			respondError(w, "Invalid limit: "+limitStr, http.StatusBadRequest)
+			return
+		}
+		filter.Limit = min(limit, maxViolationPageSize)
+	}
+
+	page, err := s.matches.Query(ruleID, filter)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"matches":    page.Matches,
+		"total":      page.TotalCount,
+		"nextCursor": page.NextCursor,
+	})
 }
 
 // Evaluation handlers
@@ -309,6 +373,11 @@ func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
 		"duration":    duration.Seconds() * 1000, // milliseconds
 	}
 
+	// Explain mode: why each matched rule fired
+	if wantsExplanation(r) {
+		response["explanations"] = s.explainMatches(r.Context(), matches, []*models.Span{&span})
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
@@ -329,6 +398,7 @@ func (s *Server) handleEvaluateBatch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	results := make([]map[string]interface{}, 0, len(request.Spans))
+	explain := wantsExplanation(r)
 
 	for _, span := range request.Spans {
 		startTime := time.Now()
@@ -345,6 +415,9 @@ func (s *Server) handleEvaluateBatch(w http.ResponseWriter, r *http.Request) {
 			result["error"] = err.Error()
 		} else {
 			result["matches"] = matches
+			if explain {
+				result["explanations"] = s.explainMatches(r.Context(), matches, []*models.Span{&span})
+			}
 		}
 
 		results = append(results, result)
@@ -358,6 +431,25 @@ func (s *Server) handleEvaluateBatch(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// wantsExplanation reports whether the request asked for explain mode (?explain=true)
+func wantsExplanation(r *http.Request) bool {
+	return r.URL.Query().Get("explain") == "true"
+}
+
+// explainMatches explains each matched rule against the evaluated spans,
+// within the engine's evaluation limits
+func (s *Server) explainMatches(ctx context.Context, ruleIDs []string, spans []*models.Span) map[string]*models.RuleExplanation {
+	explanations := make(map[string]*models.RuleExplanation, len(ruleIDs))
+	for _, ruleID := range ruleIDs {
+		explanation, err := s.engine.ExplainRule(ctx, ruleID, spans)
+		if err != nil {
+			continue // Rule unloaded since evaluation, or explaining it was aborted
+		}
+		explanations[ruleID] = explanation
+	}
+	return explanations
+}
+
 // Validation handlers
 func (s *Server) handleValidateRule(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -366,7 +458,8 @@ func (s *Server) handleValidateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Expression string `json:"expression"`
+		Expression string        `json:"expression"`
+		Spans      []models.Span `json:"spans,omitempty"` // Optional sample trace to explain against
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, "Invalid request body", http.StatusBadRequest)
@@ -396,29 +489,48 @@ func (s *Server) handleValidateRule(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"valid": true,
 	}
+
+	// Explain the expression against the sample trace, if one was given
+	if len(req.Spans) > 0 {
+		spans := make([]*models.Span, len(req.Spans))
+		for i := range req.Spans {
+			spans[i] = &req.Spans[i]
+		}
+		explanation, err := s.engine.ExplainExpression(r.Context(), req.Expression, spans)
+		if err != nil {
+			response["explanationError"] = err.Error()
+		} else {
+			response["explanation"] = explanation
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
 // Compliance handlers
 func (s *Server) handleComplianceEvidence(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if s.compliance == nil {
+		respondError(w, "Compliance reporting is not configured", http.StatusNotImplemented)
 		return
 	}
-
-	// TODO: Implement compliance evidence query (requires Tempo integration)
-	respondError(w, "Not implemented yet - query Tempo directly via Grafana", http.StatusNotImplemented)
+	s.compliance.Evidence(w, r)
 }
 
 func (s *Server) handleComplianceExport(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if s.compliance == nil {
+		respondError(w, "Compliance reporting is not configured", http.StatusNotImplemented)
 		return
 	}
+	s.compliance.Export(w, r)
+}
 
-	// TODO: Implement compliance export (requires Tempo integration)
-	respondError(w, "Not implemented yet - use Grafana export or Tempo API", http.StatusNotImplemented)
+func (s *Server) handleComplianceEvaluations(w http.ResponseWriter, r *http.Request) {
+	if s.compliance == nil {
+		respondError(w, "Compliance reporting is not configured", http.StatusNotImplemented)
+		return
+	}
+	s.compliance.Evaluations(w, r)
 }
 
 // Helper functions
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/internal/observability"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// TestHandleEvaluate_Explain verifies ?explain=true returns explanations for matches
+func TestHandleEvaluate_Explain(t *testing.T) {
+	if err := observability.InitMetrics(); err != nil {
+		t.Fatalf("Failed to init metrics: %v", err)
+	}
+
+	server := NewServer("test")
+	if err := server.engine.LoadRule(models.Rule{
+		ID:         "admin-mfa",
+		Expression: "when { admin_login } always { mfa_verified }",
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	body, _ := json.Marshal(models.Span{SpanID: "span-1", TraceID: "trace-1", OperationName: "admin_login"})
+
+	for _, explain := range []bool{false, true} {
+		url := "/api/v1/evaluate"
+		if explain {
+			url += "?explain=true"
+		}
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		server.handleEvaluate(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var resp struct {
+			Matches      []string                           `json:"matches"`
+			Explanations map[string]*models.RuleExplanation `json:"explanations"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(resp.Matches) != 1 || resp.Matches[0] != "admin-mfa" {
+			t.Fatalf("Expected matches=[admin-mfa], got %v", resp.Matches)
+		}
+
+		if !explain {
+			if resp.Explanations != nil {
+				t.Errorf("Expected no explanations without ?explain=true, got %v", resp.Explanations)
+			}
+			continue
+		}
+
+		explanation := resp.Explanations["admin-mfa"]
+		if explanation == nil {
+			t.Fatal("Expected explanation for admin-mfa")
+		}
+		if len(explanation.WhenSpanIDs) != 1 || explanation.WhenSpanIDs[0] != "span-1" {
+			t.Errorf("Expected WhenSpanIDs=[span-1], got %v", explanation.WhenSpanIDs)
+		}
+		if len(explanation.UnsatisfiedAlways) != 1 || explanation.UnsatisfiedAlways[0] != "mfa_verified" {
+			t.Errorf("Expected UnsatisfiedAlways=[mfa_verified], got %v", explanation.UnsatisfiedAlways)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+)
+
+// sseHeartbeatInterval keeps idle streams alive through proxies
+const sseHeartbeatInterval = 15 * time.Second
+
+// ViolationStreamHandler serves live violations as Server-Sent Events
+type ViolationStreamHandler struct {
+	store     services.ViolationStore
+	hub       *services.ViolationHub
+	heartbeat time.Duration
+}
+
+// NewViolationStreamHandler creates an SSE handler streaming violations published to hub
+func NewViolationStreamHandler(store services.ViolationStore, hub *services.ViolationHub) *ViolationStreamHandler {
+	return &ViolationStreamHandler{
+		store:     store,
+		hub:       hub,
+		heartbeat: sseHeartbeatInterval,
+	}
+}
+
+// ServeHTTP handles GET /v1/violations/stream
+//
+// Filters: ruleId, severity, tag (repeatable; all must match). Each event is
+// "event: violation" with the violation as JSON data and its resume cursor as
+// the event ID. Reconnecting with Last-Event-ID (sent automatically by
+// EventSource) or ?cursor= replays the violations recorded since. A client
+// that falls behind receives "event: lagged" and should reconnect.
+func (h *ViolationStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filters := services.QueryFilters{
+		RuleID:   query.Get("ruleId"),
+		Severity: query.Get("severity"),
+		Tags:     query["tag"],
+		Cursor:   query.Get("cursor"),
+	}
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		filters.Cursor = lastEventID
+	}
+	if err := filters.Validate(); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	stream := &sseWriter{w: w, rc: rc}
+	if err := stream.write("retry: 2000\n\n"); err != nil {
+		return
+	}
+
+	ctx := r.Context()
+	// The heartbeat goroutine must stop writing before the handler returns
+	var heartbeats sync.WaitGroup
+	done := make(chan struct{})
+	defer heartbeats.Wait()
+	defer close(done)
+	heartbeats.Add(1)
+	go func() {
+		defer heartbeats.Done()
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if stream.write(": keepalive\n\n") != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	err := services.WatchViolations(ctx, h.store, h.hub, filters, func(event services.ViolationEvent) error {
+		data, err := json.Marshal(event.Violation)
+		if err != nil {
+			return err
+		}
+		return stream.write(fmt.Sprintf("id: %s\nevent: violation\ndata: %s\n\n", event.Cursor, data))
+	})
+	if errors.Is(err, services.ErrSubscriberLagged) {
+		stream.write(fmt.Sprintf("event: lagged\ndata: %q\n\n", err.Error()))
+	}
+}
+
+// sseWriter serializes event and heartbeat writes and flushes each one
+type sseWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (s *sseWriter) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := io.WriteString(s.w, frame); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
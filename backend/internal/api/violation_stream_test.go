@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// sseEvent is one parsed Server-Sent Event
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSEEvent reads the next event, skipping comments and retry hints
+func readSSEEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.event != "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestViolationStream_ReplaysAndStreams(t *testing.T) {
+	store := services.NewViolationStoreMemory("test-signature-key")
+	hub := services.NewViolationHub(10)
+	server := httptest.NewServer(NewViolationStreamHandler(store, hub))
+	defer server.Close()
+
+	ctx := context.Background()
+	base := time.Now()
+	first, _ := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "m", CreatedAt: base}, nil)
+	second, _ := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "m", CreatedAt: base.Add(time.Second)}, nil)
+
+	// First connection: live only
+	resp, err := http.Get(server.URL + "?ruleId=rule-1")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	waitForHubSubscribers(t, hub, 1)
+	hub.Publish(first)
+
+	event := readSSEEvent(t, reader)
+	var got models.Violation
+	if err := json.Unmarshal([]byte(event.data), &got); err != nil {
+		t.Fatalf("Invalid event data: %v", err)
+	}
+	if event.event != "violation" || got.ID != first.ID || event.id == "" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	resp.Body.Close()
+
+	// Reconnect with Last-Event-ID: the violation recorded meanwhile is replayed
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?ruleId=rule-1", nil)
+	req.Header.Set("Last-Event-ID", event.id)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	event = readSSEEvent(t, bufio.NewReader(resp.Body))
+	if err := json.Unmarshal([]byte(event.data), &got); err != nil {
+		t.Fatalf("Invalid event data: %v", err)
+	}
+	if got.ID != second.ID {
+		t.Errorf("Expected replay of %s, got %s", second.ID, got.ID)
+	}
+}
+
+func TestViolationStream_Heartbeat(t *testing.T) {
+	handler := NewViolationStreamHandler(services.NewViolationStoreMemory("test-signature-key"), services.NewViolationHub(1))
+	handler.heartbeat = 10 * time.Millisecond
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		if line == ": keepalive\n" {
+			return
+		}
+	}
+}
+
+func TestViolationStream_InvalidCursor(t *testing.T) {
+	handler := NewViolationStreamHandler(services.NewViolationStoreMemory("test-signature-key"), services.NewViolationHub(1))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/violations/stream?cursor=!!", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
+
+func waitForHubSubscribers(t *testing.T, hub *services.ViolationHub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d subscribers, got %d", n, hub.Subscribers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
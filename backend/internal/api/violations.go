@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/betracehq/betrace/backend/internal/services"
@@ -13,19 +15,30 @@ import (
 
 // ViolationHandlers provides HTTP handlers for violation API
 type ViolationHandlers struct {
-	store  *services.ViolationStoreMemory
+	store  services.ViolationStore
 	tracer trace.Tracer
 }
 
 // NewViolationHandlers creates violation API handlers
-func NewViolationHandlers(store *services.ViolationStoreMemory, tracer trace.Tracer) *ViolationHandlers {
+func NewViolationHandlers(store services.ViolationStore, tracer trace.Tracer) *ViolationHandlers {
 	return &ViolationHandlers{
 		store:  store,
 		tracer: tracer,
 	}
 }
 
+// Page size bounds for GetViolations
+const (
+	defaultViolationPageSize = 100
+	maxViolationPageSize     = 1000
+)
+
 // GetViolations handles GET /api/violations
+//
+// Filters: ruleId, severity, traceId, service, fingerprint, status, assignee,
+// tag (repeatable; all must match), since/until (RFC 3339). Paging: limit,
+// order (newest|oldest) and cursor (nextCursor of the previous page). total
+// counts all matches.
 func (h *ViolationHandlers) GetViolations(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -37,25 +50,53 @@ func (h *ViolationHandlers) GetViolations(w http.ResponseWriter, r *http.Request
 	}
 
 	// Parse query parameters
+	query := r.URL.Query()
 	filters := services.QueryFilters{
-		RuleID:   r.URL.Query().Get("ruleId"),
-		Severity: r.URL.Query().Get("severity"),
-		Limit:    100,
-	}
-
-	// Parse 'since' parameter
-	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		RuleID:      query.Get("ruleId"),
+		Severity:    query.Get("severity"),
+		TraceID:     query.Get("traceId"),
+		ServiceName: query.Get("service"),
+		Fingerprint: query.Get("fingerprint"),
+		Status:      query.Get("status"),
+		Assignee:    query.Get("assignee"),
+		Tags:        query["tag"],
+		Cursor:      query.Get("cursor"),
+		Order:       query.Get("order"),
+		Limit:       defaultViolationPageSize,
+	}
+
+	// Parse 'since' and 'until' parameters (invalid values are ignored)
+	if sinceStr := query.Get("since"); sinceStr != "" {
 		if since, err := time.Parse(time.RFC3339, sinceStr); err == nil {
 			filters.Since = since
 		}
 	}
+	if untilStr := query.Get("until"); untilStr != "" {
+		if until, err := time.Parse(time.RFC3339, untilStr); err == nil {
+			filters.Until = until
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			respondError(w, "Invalid limit: "+limitStr, http.StatusBadRequest)
+			return
+		}
+		filters.Limit = min(limit, maxViolationPageSize)
+	}
 
 	// Query violations
-	violations, err := h.store.Query(ctx, filters)
+	page, err := h.store.QueryPage(ctx, filters)
+	if errors.Is(err, services.ErrInvalidQuery) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		respondError(w, "Failed to query violations: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	violations := page.Violations
 
 	// Add span attributes
 	if h.tracer != nil {
@@ -69,7 +110,8 @@ func (h *ViolationHandlers) GetViolations(w http.ResponseWriter, r *http.Request
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"violations": violations,
-		"total":      len(violations),
+		"total":      page.TotalCount,
+		"nextCursor": page.NextCursor,
 	})
 }
 
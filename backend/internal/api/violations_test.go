@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/pkg/models"
@@ -146,9 +147,103 @@ func TestGetViolations_FilterBySeverity(t *testing.T) {
 }
 
 func TestGetViolations_FilterBySince(t *testing.T) {
-	t.Skip("TODO: 'since' filtering not yet implemented in memory storage")
-	// TODO: Add this test once memory.QueryViolations supports 'since' parameter
-	// Currently QueryViolations(ruleID, severity, limit) doesn't have 'since'
+	handlers, store := setupTestHandlers()
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		v := models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "Test", CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		if _, err := store.Record(context.Background(), v, nil); err != nil {
+			t.Fatalf("Failed to record violation: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/violations?since=2025-01-01T13:00:00Z&until=2025-01-01T14:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	handlers.GetViolations(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response struct {
+		Violations []models.Violation `json:"violations"`
+		Total      int                `json:"total"`
+	}
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Total != 1 || len(response.Violations) != 1 {
+		t.Fatalf("Expected 1 violation in [13:00, 14:00), got total=%d violations=%d", response.Total, len(response.Violations))
+	}
+	if !response.Violations[0].CreatedAt.Equal(base.Add(time.Hour)) {
+		t.Errorf("Expected the 13:00 violation, got %v", response.Violations[0].CreatedAt)
+	}
+}
+
+func TestGetViolations_CursorPagination(t *testing.T) {
+	handlers, store := setupTestHandlers()
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		v := models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "Test", CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if _, err := store.Record(context.Background(), v, nil); err != nil {
+			t.Fatalf("Failed to record violation: %v", err)
+		}
+	}
+
+	type pageResponse struct {
+		Violations []models.Violation `json:"violations"`
+		Total      int                `json:"total"`
+		NextCursor string             `json:"nextCursor"`
+	}
+
+	var seen []time.Time
+	url := "/api/violations?limit=2"
+	for pages := 0; pages < 5; pages++ {
+		w := httptest.NewRecorder()
+		handlers.GetViolations(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var page pageResponse
+		json.NewDecoder(w.Body).Decode(&page)
+		if page.Total != 5 {
+			t.Errorf("Expected total=5 on every page, got %d", page.Total)
+		}
+		for _, v := range page.Violations {
+			seen = append(seen, v.CreatedAt)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		url = "/api/violations?limit=2&cursor=" + page.NextCursor
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("Expected 5 violations across pages, got %d", len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		if !seen[i].Before(seen[i-1]) {
+			t.Errorf("Expected newest first, got %v after %v", seen[i], seen[i-1])
+		}
+	}
+}
+
+func TestGetViolations_InvalidPaging(t *testing.T) {
+	handlers, _ := setupTestHandlers()
+
+	for _, url := range []string{
+		"/api/violations?cursor=not-a-cursor",
+		"/api/violations?order=sideways",
+		"/api/violations?limit=-1",
+	} {
+		w := httptest.NewRecorder()
+		handlers.GetViolations(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, w.Code)
+		}
+	}
 }
 
 func TestGetViolations_InvalidSinceParameter(t *testing.T) {
@@ -13,6 +13,14 @@ type Config struct {
 	GRPC    GRPCConfig    `mapstructure:"grpc"`
 	Storage StorageConfig `mapstructure:"storage"`
 	Limits  LimitsConfig  `mapstructure:"limits"`
+
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Telemetry     TelemetryConfig     `mapstructure:"telemetry"`
+	Signing       SigningConfig       `mapstructure:"signing"`
+	Ledger        LedgerConfig        `mapstructure:"ledger"`
+	Evidence      EvidenceConfig      `mapstructure:"evidence"`
+	Evaluations   EvaluationsConfig   `mapstructure:"evaluations"`
+	Backtest      BacktestConfig      `mapstructure:"backtest"`
 }
 
 // HTTPConfig contains HTTP server settings
@@ -39,10 +47,153 @@ type GRPCConfig struct {
 	KeepaliveTimeout     int `mapstructure:"keepalive_timeout"`        // seconds, default 20
 }
 
-// StorageConfig contains storage limits
+// StorageConfig contains storage backends and limits
 type StorageConfig struct {
-	MaxViolations int `mapstructure:"max_violations"` // Maximum violations in memory
-	MaxRules      int `mapstructure:"max_rules"`      // Maximum rules (enforced by engine)
+	MaxViolations      int      `mapstructure:"max_violations"`      // Maximum violations retained (oldest evicted first)
+	MaxRules           int      `mapstructure:"max_rules"`           // Maximum rules (enforced by engine)
+	ViolationBackend   string   `mapstructure:"violation_backend"`   // "disk" (durable) or "memory" (development)
+	ViolationRetention int      `mapstructure:"violation_retention"` // Hours to keep violations, 0 = forever (disk only)
+	ViolationGrouping  []string `mapstructure:"violation_grouping"`  // Incident grouping keys: service, operation, attribute:<name>
+}
+
+// SigningConfig configures the Ed25519 keys that sign violations. Keys live
+// in the violation store (data dir for disk, memory otherwise) and are
+// published at /.well-known/jwks.json. An empty BETRACE_SIGNATURE_KEY
+// disables signing.
+type SigningConfig struct {
+	KeyRotation int `mapstructure:"key_rotation"` // Days before a new signing key takes over, 0 = never; default 90
+}
+
+// LedgerConfig configures the hash-chained violation ledger, kept next to
+// the violation store (data dir for disk, memory otherwise)
+type LedgerConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	CheckpointEvery    int  `mapstructure:"checkpoint_every"`    // Entries between signed checkpoints, default 1000
+	CheckpointInterval int  `mapstructure:"checkpoint_interval"` // Seconds between checkpoints of new entries, default 3600
+}
+
+// EvidenceConfig bounds the snapshot of spans stored with each violation
+type EvidenceConfig struct {
+	Enabled            bool     `mapstructure:"enabled"`
+	MaxSpans           int      `mapstructure:"max_spans"`            // Spans per snapshot, default 100
+	MaxAttributeLength int      `mapstructure:"max_attribute_length"` // Bytes per attribute value, default 1024
+	RedactAttributes   []string `mapstructure:"redact_attributes"`    // Attribute names stored as [REDACTED]; a trailing * matches a prefix
+}
+
+// EvaluationsConfig configures the per-rule, per-service counts of trace
+// evaluations kept as compliance evidence, stored next to the violation
+// store (data dir for disk, memory otherwise)
+type EvaluationsConfig struct {
+	Retention     int `mapstructure:"retention"`      // Days of hourly counts kept, default 90
+	Exemplars     int `mapstructure:"exemplars"`      // Passing trace IDs sampled per rule, service and hour, default 5; 0 = none
+	FlushInterval int `mapstructure:"flush_interval"` // Seconds between writes of changed counts, default 60
+	MatchHistory  int `mapstructure:"match_history"`  // Recent matches kept in memory per rule, default 1000; 0 = off
+}
+
+// BacktestConfig configures backtests of rules against recorded traces
+type BacktestConfig struct {
+	TraceDir         string `mapstructure:"trace_dir"`         // Root of "directory" sources (OTLP/JSON files); "" disables them
+	ArchiveTraces    bool   `mapstructure:"archive_traces"`    // Archive evaluated traces in the data dir as the "archive" source
+	ArchiveRetention int    `mapstructure:"archive_retention"` // Days of archived traces kept, default 7
+	MaxUploadBytes   int64  `mapstructure:"max_upload_bytes"`  // OTLP/JSON upload size, default 100MB
+	Concurrency      int    `mapstructure:"concurrency"`       // Jobs running at once, default 2
+	MaxJobs          int    `mapstructure:"max_jobs"`          // Finished jobs kept in memory, default 100
+	MaxPending       int    `mapstructure:"max_pending"`       // Queued plus running jobs, default 10
+}
+
+// NotificationsConfig configures outbound violation notifications
+type NotificationsConfig struct {
+	Webhooks      []WebhookConfig      `mapstructure:"webhooks"`
+	Emails        []EmailConfig        `mapstructure:"emails"`
+	Alertmanagers []AlertmanagerConfig `mapstructure:"alertmanagers"`
+	Route         *RouteConfig         `mapstructure:"route"` // Unset: every violation goes to every receiver immediately
+}
+
+// WebhookConfig configures one webhook endpoint. Requests are signed with
+// BETRACE_SIGNATURE_KEY; zero values use the notifier's defaults.
+type WebhookConfig struct {
+	Name           string            `mapstructure:"name"`
+	URL            string            `mapstructure:"url"`
+	Template       string            `mapstructure:"template"`     // Go text/template for the body, default JSON envelope
+	ContentType    string            `mapstructure:"content_type"` // default application/json
+	Headers        map[string]string `mapstructure:"headers"`
+	RateLimit      float64           `mapstructure:"rate_limit"`      // Requests per second, 0 = unlimited
+	Burst          int               `mapstructure:"burst"`           // default 1
+	MaxAttempts    int               `mapstructure:"max_attempts"`    // default 5
+	InitialBackoff int               `mapstructure:"initial_backoff"` // Milliseconds, default 1000 (doubles per retry)
+	MaxBackoff     int               `mapstructure:"max_backoff"`     // Milliseconds, default 60000
+	Timeout        int               `mapstructure:"timeout"`         // Seconds per attempt, default 10
+	QueueSize      int               `mapstructure:"queue_size"`      // Pending deliveries, default 1000
+}
+
+// EmailConfig configures one email receiver sending through SMTP (STARTTLS
+// when offered); zero values use the notifier's defaults.
+type EmailConfig struct {
+	Name           string   `mapstructure:"name"`
+	Host           string   `mapstructure:"host"`
+	Port           int      `mapstructure:"port"` // default 587
+	Username       string   `mapstructure:"username"`
+	Password       string   `mapstructure:"password"`
+	From           string   `mapstructure:"from"`
+	To             []string `mapstructure:"to"`
+	Subject        string   `mapstructure:"subject"`         // Go text/template for the subject line
+	Template       string   `mapstructure:"template"`        // Go text/template for the plain-text body
+	MaxAttempts    int      `mapstructure:"max_attempts"`    // default 5
+	InitialBackoff int      `mapstructure:"initial_backoff"` // Milliseconds, default 1000 (doubles per retry)
+	MaxBackoff     int      `mapstructure:"max_backoff"`     // Milliseconds, default 60000
+	Timeout        int      `mapstructure:"timeout"`         // Seconds per attempt, default 10
+	QueueSize      int      `mapstructure:"queue_size"`      // Pending deliveries, default 1000
+}
+
+// AlertmanagerConfig configures one Prometheus Alertmanager receiver.
+// Violations are posted to /api/v2/alerts as one alert per incident.
+type AlertmanagerConfig struct {
+	Name           string            `mapstructure:"name"`
+	URL            string            `mapstructure:"url"`       // Base URL, e.g. http://alertmanager:9093
+	TraceURL       string            `mapstructure:"trace_url"` // Go text/template for trace links, e.g. https://grafana/explore?traceId={{.TraceID}}
+	Labels         map[string]string `mapstructure:"labels"`    // Added to every alert
+	Headers        map[string]string `mapstructure:"headers"`
+	ResolveAfter   int               `mapstructure:"resolve_after"`   // Seconds without violations before an incident resolves, default 900
+	MaxAttempts    int               `mapstructure:"max_attempts"`    // default 5
+	InitialBackoff int               `mapstructure:"initial_backoff"` // Milliseconds, default 1000 (doubles per retry)
+	MaxBackoff     int               `mapstructure:"max_backoff"`     // Milliseconds, default 60000
+	Timeout        int               `mapstructure:"timeout"`         // Seconds per attempt, default 10
+	QueueSize      int               `mapstructure:"queue_size"`      // Pending deliveries, default 1000
+}
+
+// RouteConfig is one node of the notification routing tree (like
+// Alertmanager's). Unset fields are inherited from the parent route.
+type RouteConfig struct {
+	Receiver       string        `mapstructure:"receiver"`        // Webhook, email or Alertmanager name
+	Severities     []string      `mapstructure:"severities"`      // Match any of these rule severities
+	Tags           []string      `mapstructure:"tags"`            // Match rules carrying every tag
+	Services       []string      `mapstructure:"services"`        // Match violations in any of these services
+	Frameworks     []string      `mapstructure:"frameworks"`      // Match any compliance framework tag (pci, soc2, ...)
+	Owners         []string      `mapstructure:"owners"`          // Match any "owner:<name>" rule tag
+	GroupBy        []string      `mapstructure:"group_by"`        // rule_id, severity, service, fingerprint
+	GroupWait      *int          `mapstructure:"group_wait"`      // Seconds, root default 30
+	GroupInterval  *int          `mapstructure:"group_interval"`  // Seconds, root default 300
+	RepeatInterval *int          `mapstructure:"repeat_interval"` // Seconds, root default 0 = no reminders
+	Continue       bool          `mapstructure:"continue"`        // Keep matching sibling routes
+	Routes         []RouteConfig `mapstructure:"routes"`
+}
+
+// TelemetryConfig configures telemetry about violations sent back into the
+// observability pipeline
+type TelemetryConfig struct {
+	ViolationExport ViolationExportConfig `mapstructure:"violation_export"`
+}
+
+// ViolationExportConfig configures exporting violations as OTLP spans into
+// the traces they were found in, independently of BeTrace's own tracing
+type ViolationExportConfig struct {
+	Enabled      bool              `mapstructure:"enabled"`
+	Endpoint     string            `mapstructure:"endpoint"` // OTLP gRPC host:port, default OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure     bool              `mapstructure:"insecure"` // Plaintext gRPC, default true
+	Headers      map[string]string `mapstructure:"headers"`
+	BatchSize    int               `mapstructure:"batch_size"`    // Spans per export, default 512
+	BatchTimeout int               `mapstructure:"batch_timeout"` // Milliseconds, default 5000
+	QueueSize    int               `mapstructure:"queue_size"`    // Spans buffered before dropping, default 2048
 }
 
 // LimitsConfig contains application-level limits
@@ -72,7 +223,8 @@ type RuleLimits struct {
 // TraceLimits for trace evaluation
 type TraceLimits struct {
 	MaxSpansPerTrace   int `mapstructure:"max_spans_per_trace"`    // For evaluation context
-	EvaluationTimeout  int `mapstructure:"evaluation_timeout"`     // Milliseconds
+	EvaluationTimeout  int `mapstructure:"evaluation_timeout"`     // Milliseconds, per rule per trace
+	MaxEvaluationSteps int64 `mapstructure:"max_evaluation_steps"` // Span visits per rule per trace
 }
 
 // Load reads configuration from file and environment variables
@@ -129,6 +281,9 @@ func setDefaults(v *viper.Viper) {
 	// Storage limits
 	v.SetDefault("storage.max_violations", 1000000) // 1M violations (~500MB)
 	v.SetDefault("storage.max_rules", 100000)       // 100K rules (~90MB) - also enforced by engine
+	v.SetDefault("storage.violation_backend", "disk")
+	v.SetDefault("storage.violation_retention", 720) // 30 days
+	v.SetDefault("storage.violation_grouping", []string{"service"})
 
 	// Span limits (no vendor limits - pure application layer)
 	v.SetDefault("limits.spans.max_batch_size", 1000)
@@ -145,4 +300,39 @@ func setDefaults(v *viper.Viper) {
 	// Trace evaluation limits
 	v.SetDefault("limits.trace.max_spans_per_trace", 10000)
 	v.SetDefault("limits.trace.evaluation_timeout", 5000) // 5 seconds
+	v.SetDefault("limits.trace.max_evaluation_steps", 10000000) // ~1000 span scans of a 10K-span trace
+
+	// Violation export (OTel SDK batch defaults)
+	v.SetDefault("telemetry.violation_export.enabled", false)
+	v.SetDefault("telemetry.violation_export.insecure", true)
+	v.SetDefault("telemetry.violation_export.batch_size", 512)
+	v.SetDefault("telemetry.violation_export.batch_timeout", 5000)
+	v.SetDefault("telemetry.violation_export.queue_size", 2048)
+
+	// Signing defaults
+	v.SetDefault("signing.key_rotation", 90)
+
+	// Ledger defaults
+	v.SetDefault("ledger.enabled", true)
+	v.SetDefault("ledger.checkpoint_every", 1000)
+	v.SetDefault("ledger.checkpoint_interval", 3600)
+
+	// Evidence defaults
+	v.SetDefault("evidence.enabled", true)
+	v.SetDefault("evidence.max_spans", 100)
+	v.SetDefault("evidence.max_attribute_length", 1024)
+
+	// Evaluation count defaults
+	v.SetDefault("evaluations.retention", 90)
+	v.SetDefault("evaluations.exemplars", 5)
+	v.SetDefault("evaluations.flush_interval", 60)
+	v.SetDefault("evaluations.match_history", 1000)
+
+	// Backtest defaults
+	v.SetDefault("backtest.archive_traces", false)
+	v.SetDefault("backtest.archive_retention", 7)
+	v.SetDefault("backtest.max_upload_bytes", 104857600) // 100MB
+	v.SetDefault("backtest.concurrency", 2)
+	v.SetDefault("backtest.max_jobs", 100)
+	v.SetDefault("backtest.max_pending", 10)
 }
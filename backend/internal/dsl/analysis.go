@@ -0,0 +1,133 @@
+package dsl
+
+import (
+	"sort"
+	"strings"
+)
+
+// RequiredOperations returns the operation names a trace must contain for the
+// rule's when clause to match. A trace that contains none of the returned
+// names can never trigger the rule, so the rule engine can skip it entirely.
+//
+// The second return value is false when no such guarantee can be made (e.g. the
+// when clause uses "not" or a count comparison that can hold with zero spans).
+// Callers must then evaluate the rule against every trace.
+func RequiredOperations(rule *Rule) ([]string, bool) {
+	if rule == nil || rule.When == nil {
+		return nil, false
+	}
+
+	names, ok := requiredAnyOf(rule.When)
+	if !ok || len(names) == 0 {
+		return nil, false
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, true
+}
+
+// requiredAnyOf returns a set of operation names such that the condition can
+// only be true if at least one of them is present in the trace
+func requiredAnyOf(cond *Condition) (map[string]struct{}, bool) {
+	if cond == nil || len(cond.Or) == 0 {
+		return nil, false
+	}
+
+	// OR: every branch must be constrained, and any branch's names may satisfy it
+	union := make(map[string]struct{})
+	for _, orTerm := range cond.Or {
+		names, ok := requiredAnyOfAnd(orTerm)
+		if !ok {
+			return nil, false
+		}
+		for name := range names {
+			union[name] = struct{}{}
+		}
+	}
+
+	return union, true
+}
+
+// requiredAnyOfAnd picks the most selective constrained term of an AND chain
+func requiredAnyOfAnd(orTerm *OrTerm) (map[string]struct{}, bool) {
+	var best map[string]struct{}
+	for _, andTerm := range orTerm.And {
+		names, ok := requiredAnyOfTerm(andTerm)
+		if !ok {
+			continue
+		}
+		if best == nil || len(names) < len(best) {
+			best = names
+		}
+	}
+
+	return best, best != nil
+}
+
+// requiredAnyOfTerm analyzes a single (optionally negated) term
+func requiredAnyOfTerm(andTerm *AndTerm) (map[string]struct{}, bool) {
+	// A negated term can be satisfied by the absence of spans
+	if andTerm.Not || andTerm.Term == nil {
+		return nil, false
+	}
+
+	term := andTerm.Term
+	if term.Grouped != nil {
+		return requiredAnyOf(term.Grouped)
+	}
+
+	if term.SpanCheck == nil {
+		return nil, false
+	}
+
+	// Existence checks (with or without .where() / comparison) need the span
+	if has := term.SpanCheck.Has; has != nil {
+		return map[string]struct{}{strings.Join(has.OpName, "."): {}}, true
+	}
+
+	if count := term.SpanCheck.Count; count != nil && countRequiresSpan(count) {
+		return map[string]struct{}{strings.Join(count.OpName, "."): {}}, true
+	}
+
+	return nil, false
+}
+
+// countRequiresSpan reports whether count(op) <operator> right implies count(op) >= 1
+func countRequiresSpan(check *CountCheck) bool {
+	if check.Right == nil {
+		return false
+	}
+
+	// count(a) > count(b) implies count(a) >= 1 because counts are never negative
+	if check.Right.Count != nil {
+		return check.Operator == ">"
+	}
+
+	value := check.Right.Value
+	if value == nil {
+		return false
+	}
+
+	var n float64
+	switch {
+	case value.Int != nil:
+		n = float64(*value.Int)
+	case value.Number != nil:
+		n = *value.Number
+	default:
+		return false
+	}
+
+	switch check.Operator {
+	case ">":
+		return n >= 0
+	case ">=", "==":
+		return n > 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,107 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredOperations(t *testing.T) {
+	tests := []struct {
+		name      string
+		dsl       string
+		want      []string
+		indexable bool
+	}{
+		{
+			name:      "simple existence",
+			dsl:       `when { payment } always { fraud_check }`,
+			want:      []string{"payment"},
+			indexable: true,
+		},
+		{
+			name:      "dotted operation with where",
+			dsl:       `when { payment.charge.where(amount > 1000) } always { fraud_check }`,
+			want:      []string{"payment.charge"},
+			indexable: true,
+		},
+		{
+			name:      "and picks one required operation",
+			dsl:       `when { payment and refund } always { audit }`,
+			want:      []string{"payment"},
+			indexable: true,
+		},
+		{
+			name:      "and skips negated term",
+			dsl:       `when { not admin and payment } always { audit }`,
+			want:      []string{"payment"},
+			indexable: true,
+		},
+		{
+			name:      "or unions branches",
+			dsl:       `when { payment or refund } always { audit }`,
+			want:      []string{"payment", "refund"},
+			indexable: true,
+		},
+		{
+			name:      "grouped condition",
+			dsl:       `when { (payment or refund) and customer } always { audit }`,
+			want:      []string{"customer"},
+			indexable: true,
+		},
+		{
+			name:      "count greater than literal",
+			dsl:       `when { count(http_retry) > 3 } always { alert }`,
+			want:      []string{"http_retry"},
+			indexable: true,
+		},
+		{
+			name:      "count greater than count",
+			dsl:       `when { count(http_request) > count(http_response) } always { alert }`,
+			want:      []string{"http_request"},
+			indexable: true,
+		},
+		{
+			name:      "count equal zero can match empty trace",
+			dsl:       `when { count(heartbeat) == 0 } always { alert }`,
+			indexable: false,
+		},
+		{
+			name:      "count not equal is unconstrained",
+			dsl:       `when { count(http_request) != count(http_response) } never { orphaned }`,
+			indexable: false,
+		},
+		{
+			name:      "negation alone is unconstrained",
+			dsl:       `when { not payment } always { audit }`,
+			indexable: false,
+		},
+		{
+			name:      "or with unconstrained branch",
+			dsl:       `when { payment or not refund } always { audit }`,
+			indexable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.dsl)
+			require.NoError(t, err)
+
+			got, ok := RequiredOperations(rule)
+			assert.Equal(t, tt.indexable, ok)
+			if tt.indexable {
+				assert.Equal(t, tt.want, got)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
+
+func TestRequiredOperations_NilRule(t *testing.T) {
+	got, ok := RequiredOperations(nil)
+	assert.False(t, ok)
+	assert.Nil(t, got)
+}
@@ -235,8 +235,162 @@ func BenchmarkEvaluatorThroughput(b *testing.B) {
 	})
 }
 
+// Benchmark compiled program performance with different trace sizes
+// (compare with BenchmarkEvaluator)
+func BenchmarkCompiledEvaluator(b *testing.B) {
+	program := mustCompileBenchmarkRule(b, `when { payment.where(amount > 1000) }
+always { fraud_check }
+never { bypass }`)
+
+	benchmarks := []struct {
+		name      string
+		spanCount int
+	}{
+		{name: "SmallTrace_10spans", spanCount: 10},
+		{name: "MediumTrace_100spans", spanCount: 100},
+		{name: "LargeTrace_1000spans", spanCount: 1000},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			spans := createBenchmarkSpans(bm.spanCount)
+
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := program.Evaluate(spans)
+				if err != nil {
+					b.Fatalf("Evaluation failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Benchmark compiled programs with realistic patterns
+// (compare with BenchmarkEvaluatorPatterns)
+func BenchmarkCompiledEvaluatorPatterns(b *testing.B) {
+	patterns := []struct {
+		name string
+		dsl  string
+		span string
+		attr map[string]interface{}
+	}{
+		{
+			name: "BasicExistence",
+			dsl:  `when { payment } always { fraud_check }`,
+			span: "payment",
+			attr: nil,
+		},
+		{
+			name: "WhereClause",
+			dsl:  `when { payment.where(amount > 1000) } always { fraud_check }`,
+			span: "payment",
+			attr: map[string]interface{}{"amount": 5000},
+		},
+		{
+			name: "ChainedWhere",
+			dsl:  `when { payment.where(amount > 1000).where(currency == USD) } always { fraud_check }`,
+			span: "payment",
+			attr: map[string]interface{}{"amount": 5000, "currency": "USD"},
+		},
+		{
+			name: "CountComparison",
+			dsl:  `when { count(http_retry) > 3 } always { alert }`,
+			span: "http_retry",
+			attr: nil,
+		},
+	}
+
+	for _, pattern := range patterns {
+		b.Run(pattern.name, func(b *testing.B) {
+			program := mustCompileBenchmarkRule(b, pattern.dsl)
+
+			spans := []*models.Span{
+				createBenchmarkSpan(pattern.span, pattern.attr),
+				createBenchmarkSpan("fraud_check", nil),
+			}
+
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := program.Evaluate(spans)
+				if err != nil {
+					b.Fatalf("Evaluation failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Benchmark compiled count operations (compare with BenchmarkCountOperations)
+func BenchmarkCompiledCountOperations(b *testing.B) {
+	program := mustCompileBenchmarkRule(b, `when { count(http_request) != count(http_response) } never { orphaned }`)
+
+	spanCounts := []struct {
+		name      string
+		requests  int
+		responses int
+	}{
+		{name: "10req_10resp", requests: 10, responses: 10},
+		{name: "100req_100resp", requests: 100, responses: 100},
+		{name: "1000req_1000resp", requests: 1000, responses: 1000},
+	}
+
+	for _, sc := range spanCounts {
+		b.Run(sc.name, func(b *testing.B) {
+			spans := make([]*models.Span, 0, sc.requests+sc.responses)
+			for i := 0; i < sc.requests; i++ {
+				spans = append(spans, createBenchmarkSpan("http_request", nil))
+			}
+			for i := 0; i < sc.responses; i++ {
+				spans = append(spans, createBenchmarkSpan("http_response", nil))
+			}
+
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := program.Evaluate(spans)
+				if err != nil {
+					b.Fatalf("Evaluation failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Benchmark compiled program throughput (compare with BenchmarkEvaluatorThroughput)
+func BenchmarkCompiledEvaluatorThroughput(b *testing.B) {
+	program := mustCompileBenchmarkRule(b, `when { payment.where(amount > 1000) } always { fraud_check }`)
+	spans := createBenchmarkSpans(50)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := program.Evaluate(spans)
+			if err != nil {
+				b.Fatalf("Evaluation failed: %v", err)
+			}
+		}
+	})
+}
+
 // Helper functions
 
+func mustCompileBenchmarkRule(b *testing.B, dsl string) *Program {
+	b.Helper()
+
+	rule, err := Parse(dsl)
+	if err != nil {
+		b.Fatalf("Parse failed: %v", err)
+	}
+	program, err := Compile(rule)
+	if err != nil {
+		b.Fatalf("Compile failed: %v", err)
+	}
+	return program
+}
+
 func createBenchmarkSpans(count int) []*models.Span {
 	spans := make([]*models.Span, count)
 	for i := 0; i < count; i++ {
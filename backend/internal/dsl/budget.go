@@ -0,0 +1,108 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrEvaluationAborted is returned (wrapped) when a rule evaluation stops
+// before producing a result, because its context was cancelled, it ran out of
+// time, or it exhausted its step budget. An aborted evaluation says nothing
+// about whether the rule matched.
+var ErrEvaluationAborted = errors.New("evaluation aborted")
+
+// ErrStepBudgetExceeded is wrapped alongside ErrEvaluationAborted when an
+// evaluation exceeds Limits.MaxSteps
+var ErrStepBudgetExceeded = errors.New("step budget exceeded")
+
+// Limits bounds the work done by a single rule evaluation.
+// The zero value means no limits.
+type Limits struct {
+	// MaxSteps caps the number of span visits. Every scan of the trace by a
+	// span check costs one step per span, so the cost of a rule grows with
+	// both its number of terms and the trace size. 0 means unlimited.
+	MaxSteps int64
+
+	// Timeout caps the wall-clock time of the evaluation. Exceeding it aborts
+	// with an error wrapping context.DeadlineExceeded. 0 means no timeout.
+	Timeout time.Duration
+}
+
+// clockCheckInterval is how many cancellation checkpoints pass between reads
+// of the clock and ctx, which keeps per-span checkpoints cheap
+const clockCheckInterval = 16
+
+// budget tracks the work done by one evaluation. A nil budget is unlimited,
+// so evaluations without a deadline or step limit pay only a nil check.
+//
+// A budget belongs to a single evaluation and is not safe for concurrent use.
+type budget struct {
+	ctx      context.Context
+	done     <-chan struct{}
+	maxSteps int64
+	steps    int64
+	timeout  time.Duration
+	deadline time.Time
+	checks   int
+}
+
+// newBudget returns a budget for ctx and limits, or nil if nothing can abort evaluation
+func newBudget(ctx context.Context, limits Limits) *budget {
+	done := ctx.Done()
+	if done == nil && limits.MaxSteps <= 0 && limits.Timeout <= 0 {
+		return nil
+	}
+
+	b := &budget{
+		ctx:      ctx,
+		done:     done,
+		maxSteps: limits.MaxSteps,
+		timeout:  limits.Timeout,
+	}
+	if limits.Timeout > 0 {
+		b.deadline = time.Now().Add(limits.Timeout)
+	}
+	return b
+}
+
+// step charges n span visits and reports whether evaluation must stop.
+// step(0) is a cancellation checkpoint; it only looks at the clock and ctx
+// every clockCheckInterval calls.
+func (b *budget) step(n int) error {
+	if b == nil {
+		return nil
+	}
+
+	b.steps += int64(n)
+	if b.maxSteps > 0 && b.steps > b.maxSteps {
+		return fmt.Errorf("%w: %w (limit %d)", ErrEvaluationAborted, ErrStepBudgetExceeded, b.maxSteps)
+	}
+
+	if n == 0 {
+		b.checks++
+		if b.checks%clockCheckInterval != 0 {
+			return nil
+		}
+	}
+
+	if b.timeout > 0 && time.Now().After(b.deadline) {
+		return fmt.Errorf("%w: timeout of %s exceeded: %w", ErrEvaluationAborted, b.timeout, context.DeadlineExceeded)
+	}
+
+	if b.done != nil {
+		select {
+		case <-b.done:
+			return abortedError(b.ctx)
+		default:
+		}
+	}
+
+	return nil
+}
+
+// abortedError wraps the context's error as an aborted evaluation
+func abortedError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrEvaluationAborted, ctx.Err())
+}
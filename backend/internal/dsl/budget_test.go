@@ -0,0 +1,158 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// budgetEvaluators runs the same rule through both evaluation paths
+func budgetEvaluators(t *testing.T, dsl string) map[string]func(ctx context.Context, spans []*models.Span, limits Limits) (bool, error) {
+	t.Helper()
+
+	rule, err := Parse(dsl)
+	require.NoError(t, err)
+	program, err := Compile(rule)
+	require.NoError(t, err)
+	evaluator := NewEvaluator()
+
+	return map[string]func(ctx context.Context, spans []*models.Span, limits Limits) (bool, error){
+		"evaluator": func(ctx context.Context, spans []*models.Span, limits Limits) (bool, error) {
+			return evaluator.EvaluateRuleContext(ctx, rule, spans, limits)
+		},
+		"compiled": func(ctx context.Context, spans []*models.Span, limits Limits) (bool, error) {
+			return program.EvaluateContext(ctx, spans, limits)
+		},
+	}
+}
+
+func budgetTrace(n int) []*models.Span {
+	spans := make([]*models.Span, 0, n)
+	for i := 0; i < n; i++ {
+		spans = append(spans, &models.Span{
+			OperationName: "http",
+			Attributes:    map[string]string{"path": fmt.Sprintf("/api/v1/items/%d", i)},
+		})
+	}
+	return spans
+}
+
+func TestEvaluateContext_WithinLimits(t *testing.T) {
+	spans := budgetTrace(100)
+
+	for name, eval := range budgetEvaluators(t, `when { http } always { auth }`) {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			// when scans 100 spans, always scans 100 spans
+			result, err := eval(ctx, spans, Limits{MaxSteps: 200, Timeout: time.Minute})
+			require.NoError(t, err)
+			assert.True(t, result)
+		})
+	}
+}
+
+func TestEvaluateContext_StepBudgetExceeded(t *testing.T) {
+	spans := budgetTrace(100)
+
+	for name, eval := range budgetEvaluators(t, `when { http } always { auth }`) {
+		t.Run(name, func(t *testing.T) {
+			result, err := eval(context.Background(), spans, Limits{MaxSteps: 199})
+			require.Error(t, err)
+			assert.False(t, result)
+			assert.True(t, errors.Is(err, ErrEvaluationAborted))
+			assert.True(t, errors.Is(err, ErrStepBudgetExceeded))
+		})
+	}
+}
+
+func TestEvaluateContext_CountChecksChargeSteps(t *testing.T) {
+	spans := budgetTrace(100)
+
+	for name, eval := range budgetEvaluators(t, `when { count(http) > count(auth) } always { auth }`) {
+		t.Run(name, func(t *testing.T) {
+			_, err := eval(context.Background(), spans, Limits{MaxSteps: 150})
+			assert.True(t, errors.Is(err, ErrStepBudgetExceeded))
+		})
+	}
+}
+
+func TestEvaluateContext_Cancelled(t *testing.T) {
+	spans := budgetTrace(10)
+
+	for name, eval := range budgetEvaluators(t, `when { http } always { auth }`) {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			result, err := eval(ctx, spans, Limits{})
+			require.Error(t, err)
+			assert.False(t, result)
+			assert.True(t, errors.Is(err, ErrEvaluationAborted))
+			assert.True(t, errors.Is(err, context.Canceled))
+		})
+	}
+}
+
+func TestEvaluateContext_Timeout(t *testing.T) {
+	// Every span reaches the (deliberately slow) regex filter
+	spans := budgetTrace(20000)
+
+	for name, eval := range budgetEvaluators(t, `when { http.where(path matches "^(/[a-z0-9]+)+/(x|y)+$") } always { auth }`) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			result, err := eval(context.Background(), spans, Limits{Timeout: time.Millisecond})
+			elapsed := time.Since(start)
+
+			require.Error(t, err)
+			assert.False(t, result)
+			assert.True(t, errors.Is(err, ErrEvaluationAborted))
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+			assert.Less(t, elapsed, time.Second, "evaluation should stop soon after the timeout")
+		})
+	}
+}
+
+func TestExplainContext_Limits(t *testing.T) {
+	rule, err := Parse(`when { http } always { auth }`)
+	require.NoError(t, err)
+	spans := budgetTrace(100)
+	evaluator := NewEvaluator()
+
+	// Evaluating scans the trace twice, and the explain pass twice more
+	explanation, err := evaluator.ExplainContext(context.Background(), rule, spans, Limits{MaxSteps: 400})
+	require.NoError(t, err)
+	assert.True(t, explanation.Violated)
+
+	_, err = evaluator.ExplainContext(context.Background(), rule, spans, Limits{MaxSteps: 399})
+	assert.True(t, errors.Is(err, ErrStepBudgetExceeded))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = evaluator.ExplainContext(ctx, rule, spans, Limits{})
+	assert.True(t, errors.Is(err, ErrEvaluationAborted))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestEvaluateContext_NoLimitsMatchesEvaluate(t *testing.T) {
+	rule, err := Parse(`when { http.where(path matches "^/api/v1/") } never { auth }`)
+	require.NoError(t, err)
+	program, err := Compile(rule)
+	require.NoError(t, err)
+
+	spans := append(budgetTrace(10), &models.Span{OperationName: "auth"})
+
+	want, err := program.Evaluate(spans)
+	require.NoError(t, err)
+	got, err := program.EvaluateContext(context.Background(), spans, Limits{})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.True(t, got)
+}
@@ -0,0 +1,629 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// Program is a rule compiled into a tree of closures.
+//
+// Compilation does the work the Evaluator repeats on every call: operation
+// names are joined once, literals are unquoted and parsed once, and regexes
+// for the matches operator are compiled once. A Program is immutable after
+// Compile returns and is safe for concurrent use.
+//
+// Program.Evaluate has the same semantics as Evaluator.EvaluateRule.
+type Program struct {
+	when   traceFn
+	always traceFn
+	never  traceFn
+
+	hasAlways bool
+	hasNever  bool
+}
+
+// traceFn evaluates a condition against a whole trace, charging its scans to b
+type traceFn func(b *budget, spans []*models.Span) (bool, error)
+
+// spanFn evaluates a .where() condition against a single span
+type spanFn func(span *models.Span) (bool, error)
+
+// Compile turns a parsed rule into an evaluation program
+func Compile(rule *Rule) (*Program, error) {
+	if rule == nil {
+		return nil, fmt.Errorf("rule is nil")
+	}
+
+	return &Program{
+		when:      compileCondition(rule.When),
+		always:    compileCondition(rule.Always),
+		never:     compileCondition(rule.Never),
+		hasAlways: rule.Always != nil,
+		hasNever:  rule.Never != nil,
+	}, nil
+}
+
+// Evaluate evaluates the program against a trace and reports whether the rule is violated
+func (p *Program) Evaluate(spans []*models.Span) (bool, error) {
+	return p.EvaluateContext(context.Background(), spans, Limits{})
+}
+
+// Outcome is the result of evaluating a rule against a trace
+type Outcome int
+
+const (
+	OutcomeSkipped  Outcome = iota // The when clause didn't match; the rule doesn't apply
+	OutcomePassed                  // The when clause matched and always/never held
+	OutcomeViolated                // The when clause matched and always/never didn't hold
+)
+
+// EvaluateContext evaluates the program like Evaluate, but stops with an
+// error wrapping ErrEvaluationAborted when ctx is done or limits are exceeded
+func (p *Program) EvaluateContext(ctx context.Context, spans []*models.Span, limits Limits) (bool, error) {
+	outcome, err := p.EvaluateOutcome(ctx, spans, limits)
+	return outcome == OutcomeViolated, err
+}
+
+// EvaluateOutcome evaluates the program like EvaluateContext, telling traces
+// the rule doesn't apply to apart from traces that satisfy it
+func (p *Program) EvaluateOutcome(ctx context.Context, spans []*models.Span, limits Limits) (Outcome, error) {
+	// Semantic validation: at least one of always/never must be present
+	if !p.hasAlways && !p.hasNever {
+		return OutcomeSkipped, fmt.Errorf("rule must have at least one 'always' or 'never' clause")
+	}
+
+	if ctx.Err() != nil {
+		return OutcomeSkipped, abortedError(ctx)
+	}
+	b := newBudget(ctx, limits)
+
+	whenMatched, err := p.when(b, spans)
+	if err != nil {
+		return OutcomeSkipped, fmt.Errorf("when clause evaluation failed: %w", err)
+	}
+	if !whenMatched {
+		return OutcomeSkipped, nil
+	}
+
+	if p.hasAlways {
+		alwaysMatched, err := p.always(b, spans)
+		if err != nil {
+			return OutcomeSkipped, fmt.Errorf("always clause evaluation failed: %w", err)
+		}
+		if !alwaysMatched {
+			return OutcomeViolated, nil // VIOLATION: always clause not satisfied
+		}
+	}
+
+	if p.hasNever {
+		neverMatched, err := p.never(b, spans)
+		if err != nil {
+			return OutcomeSkipped, fmt.Errorf("never clause evaluation failed: %w", err)
+		}
+		if neverMatched {
+			return OutcomeViolated, nil // VIOLATION: never clause matched
+		}
+	}
+
+	return OutcomePassed, nil
+}
+
+// compileCondition compiles a Condition (OR of AND terms)
+func compileCondition(cond *Condition) traceFn {
+	if cond == nil {
+		return func(*budget, []*models.Span) (bool, error) {
+			return false, fmt.Errorf("condition is nil")
+		}
+	}
+
+	terms := make([]traceFn, len(cond.Or))
+	for i, orTerm := range cond.Or {
+		terms[i] = compileOrTerm(orTerm)
+	}
+
+	return func(b *budget, spans []*models.Span) (bool, error) {
+		for _, term := range terms {
+			result, err := term(b, spans)
+			if err != nil {
+				return false, err
+			}
+			if result {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// compileOrTerm compiles an OrTerm (AND of terms)
+func compileOrTerm(orTerm *OrTerm) traceFn {
+	terms := make([]traceFn, len(orTerm.And))
+	for i, andTerm := range orTerm.And {
+		terms[i] = compileAndTerm(andTerm)
+	}
+
+	return func(b *budget, spans []*models.Span) (bool, error) {
+		for _, term := range terms {
+			result, err := term(b, spans)
+			if err != nil {
+				return false, err
+			}
+			if !result {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// compileAndTerm compiles an AndTerm (optional NOT + term)
+func compileAndTerm(andTerm *AndTerm) traceFn {
+	term := compileTerm(andTerm.Term)
+	if !andTerm.Not {
+		return term
+	}
+
+	return func(b *budget, spans []*models.Span) (bool, error) {
+		result, err := term(b, spans)
+		if err != nil {
+			return false, err
+		}
+		return !result, nil
+	}
+}
+
+// compileTerm compiles a Term (grouped condition or span check)
+func compileTerm(term *Term) traceFn {
+	if term.Grouped != nil {
+		return compileCondition(term.Grouped)
+	}
+
+	if term.SpanCheck != nil {
+		if term.SpanCheck.Count != nil {
+			return compileCountCheck(term.SpanCheck.Count)
+		}
+		if term.SpanCheck.Has != nil {
+			return compileHasCheck(term.SpanCheck.Has)
+		}
+		return failTrace(fmt.Errorf("span check has no count or has"))
+	}
+
+	return failTrace(fmt.Errorf("term has no grouped or span check"))
+}
+
+// compileCountCheck compiles count(op) <operator> expression
+func compileCountCheck(check *CountCheck) traceFn {
+	name := strings.Join(check.OpName, ".")
+
+	// Right side is either another count or a static value
+	if check.Right != nil && check.Right.Count != nil {
+		rightName := strings.Join(check.Right.Count.OpName, ".")
+		return func(b *budget, spans []*models.Span) (bool, error) {
+			if err := b.step(2 * len(spans)); err != nil {
+				return false, err
+			}
+			left := countOperand(countSpans(name, spans))
+			right := countOperand(countSpans(rightName, spans))
+			return compareDynamic(left, right, check.Operator)
+		}
+	}
+
+	right, err := compileStaticExpression(check.Right, "count check right side")
+	if err != nil {
+		return failTrace(err)
+	}
+	cmp := newComparison(check.Operator, right)
+
+	return func(b *budget, spans []*models.Span) (bool, error) {
+		if err := b.step(len(spans)); err != nil {
+			return false, err
+		}
+		return cmp.evalCount(countSpans(name, spans))
+	}
+}
+
+// compileHasCheck compiles operation_name with optional .where() chain or comparison
+func compileHasCheck(check *HasCheck) traceFn {
+	name := strings.Join(check.OpName, ".")
+
+	// .where() chain: every filter must match the same span
+	if check.Where != nil {
+		filters := make([]spanFn, 0, 1+len(check.Where.ChainedWhere))
+		filters = append(filters, compileWhereCondition(check.Where.First.Condition))
+		for _, chained := range check.Where.ChainedWhere {
+			filters = append(filters, compileWhereCondition(chained.Condition))
+		}
+
+		return func(b *budget, spans []*models.Span) (bool, error) {
+			if err := b.step(len(spans)); err != nil {
+				return false, err
+			}
+			for _, span := range spans {
+				if span.OperationName != name {
+					continue
+				}
+				// Filters may run expensive regexes: check for cancellation per span
+				if err := b.step(0); err != nil {
+					return false, err
+				}
+				if spanMatchesAll(span, filters) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+
+	// Plain existence check
+	if check.Comparison == nil {
+		return func(b *budget, spans []*models.Span) (bool, error) {
+			if err := b.step(len(spans)); err != nil {
+				return false, err
+			}
+			for _, span := range spans {
+				if span.OperationName == name {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+
+	// Direct comparison: left side is the span's existence (1.0), so the
+	// result doesn't depend on which span matched
+	right, err := compileStaticExpression(check.Comparison.Right, "comparison right side")
+	cmp := newComparison(check.Comparison.Operator, right)
+	return func(b *budget, spans []*models.Span) (bool, error) {
+		if err := b.step(len(spans)); err != nil {
+			return false, err
+		}
+		for _, span := range spans {
+			if span.OperationName != name {
+				continue
+			}
+			if err != nil {
+				continue // Skip spans that cause errors
+			}
+			matched, cmpErr := cmp.evalCount(1)
+			if cmpErr != nil {
+				continue // Skip spans that cause errors
+			}
+			if matched {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// spanMatchesAll reports whether a span satisfies every filter (errors count as no match)
+func spanMatchesAll(span *models.Span, filters []spanFn) bool {
+	for _, filter := range filters {
+		matched, err := filter(span)
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// compileWhereCondition compiles a WhereCondition (OR of AND terms)
+func compileWhereCondition(cond *WhereCondition) spanFn {
+	terms := make([]spanFn, len(cond.Or))
+	for i, orTerm := range cond.Or {
+		terms[i] = compileWhereAndTerm(orTerm)
+	}
+
+	return func(span *models.Span) (bool, error) {
+		for _, term := range terms {
+			result, err := term(span)
+			if err != nil {
+				return false, err
+			}
+			if result {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// compileWhereAndTerm compiles a WhereAndTerm (AND of atomic terms)
+func compileWhereAndTerm(term *WhereAndTerm) spanFn {
+	terms := make([]spanFn, len(term.And))
+	for i, atomic := range term.And {
+		terms[i] = compileWhereAtomicTerm(atomic)
+	}
+
+	return func(span *models.Span) (bool, error) {
+		for _, term := range terms {
+			result, err := term(span)
+			if err != nil {
+				return false, err
+			}
+			if !result {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// compileWhereAtomicTerm compiles a comparison, span ref, bool ident or grouped condition
+func compileWhereAtomicTerm(term *WhereAtomicTerm) spanFn {
+	var fn spanFn
+
+	switch {
+	case term.Grouped != nil:
+		fn = compileWhereCondition(term.Grouped)
+	case term.Comparison != nil:
+		fn = compileWhereComparison(term.Comparison)
+	case term.SpanRef != nil:
+		// Span reference - NOT IMPLEMENTED YET (errors are not negated)
+		return failSpan(fmt.Errorf("span references in where clauses not yet implemented"))
+	case term.BoolIdent != nil:
+		attrName := *term.BoolIdent
+		fn = func(span *models.Span) (bool, error) {
+			return span.Attributes[attrName] == "true", nil
+		}
+	default:
+		return failSpan(fmt.Errorf("where atomic term has no content"))
+	}
+
+	if !term.Not {
+		return fn
+	}
+
+	return func(span *models.Span) (bool, error) {
+		result, err := fn(span)
+		if err != nil {
+			return false, err
+		}
+		return !result, nil
+	}
+}
+
+// compileWhereComparison compiles an attribute comparison (scoped to the span)
+func compileWhereComparison(comp *WhereComparison) spanFn {
+	// Strip quotes from attribute name once (for dotted names like "data.contains_pii")
+	attrName := unquote(comp.Attribute)
+
+	// Count expressions have no trace context inside .where()
+	right, err := compileStaticExpression(comp.Right, "where comparison right side")
+	if err != nil {
+		return failSpan(err)
+	}
+	cmp := newComparison(comp.Operator, right)
+
+	return func(span *models.Span) (bool, error) {
+		value, ok := span.Attributes[attrName]
+		return cmp.evalAttribute(value, ok)
+	}
+}
+
+// compileStaticExpression resolves an Expression that must not depend on the trace
+func compileStaticExpression(expr *Expression, context string) (interface{}, error) {
+	if expr == nil {
+		return nil, fmt.Errorf("%s: expression has no value", context)
+	}
+
+	if expr.Value != nil {
+		value, err := literalValue(expr.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", context, err)
+		}
+		return value, nil
+	}
+
+	if expr.Count != nil {
+		return nil, fmt.Errorf("%s: count expressions require trace context", context)
+	}
+
+	if expr.Path != nil {
+		return nil, fmt.Errorf("%s: path expressions not yet implemented", context)
+	}
+
+	return nil, fmt.Errorf("%s: expression has no value", context)
+}
+
+// literalValue converts a Value node to the same concrete value Evaluator.getValue returns
+func literalValue(val *Value) (interface{}, error) {
+	switch {
+	case val.String != nil:
+		return unquote(*val.String), nil
+	case val.Number != nil:
+		return *val.Number, nil
+	case val.Int != nil:
+		return float64(*val.Int), nil
+	case val.Bool != nil:
+		return *val.Bool, nil
+	case val.Ident != nil:
+		return *val.Ident, nil
+	case val.List != nil:
+		return val.List, nil
+	default:
+		return nil, fmt.Errorf("value has no content")
+	}
+}
+
+// unquote removes surrounding double quotes if present
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// countSpans counts spans with the given (pre-joined) operation name
+func countSpans(name string, spans []*models.Span) int {
+	count := 0
+	for _, span := range spans {
+		if span.OperationName == name {
+			count++
+		}
+	}
+	return count
+}
+
+func failTrace(err error) traceFn {
+	return func(*budget, []*models.Span) (bool, error) {
+		return false, err
+	}
+}
+
+func failSpan(err error) spanFn {
+	return func(*models.Span) (bool, error) {
+		return false, err
+	}
+}
+
+// operand is a value prepared for comparison: its string form and, when it
+// parses as a number, its numeric form
+type operand struct {
+	str   string
+	num   float64
+	isNum bool
+}
+
+func newOperand(v interface{}) operand {
+	num, isNum := toFloat64(v)
+	return operand{str: toString(v), num: num, isNum: isNum}
+}
+
+func countOperand(count int) operand {
+	return operand{str: strconv.Itoa(count), num: float64(count), isNum: true}
+}
+
+// comparison is an operator bound to a static right-hand side
+type comparison struct {
+	operator string
+	right    operand
+	list     []string
+	isList   bool
+	regex    *regexp.Regexp
+	regexErr error
+}
+
+func newComparison(operator string, right interface{}) *comparison {
+	c := &comparison{
+		operator: operator,
+		right:    newOperand(right),
+	}
+	c.list, c.isList = right.([]string)
+
+	// Bind the regex at compile time; a bad pattern only fails when evaluated
+	if operator == "matches" {
+		regex, err := regexp.Compile(c.right.str)
+		if err != nil {
+			c.regexErr = fmt.Errorf("invalid regex pattern: %w", err)
+		} else {
+			c.regex = regex
+		}
+	}
+
+	return c
+}
+
+// evalAttribute compares a span attribute (present or missing) against the right side
+func (c *comparison) evalAttribute(value string, present bool) (bool, error) {
+	left := operand{str: value}
+	// The numeric form only matters when the right side is numeric too
+	if present && c.right.isNum {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			left.num, left.isNum = f, true
+		}
+	}
+	return c.eval(left)
+}
+
+// evalCount compares a span count against the right side
+func (c *comparison) evalCount(count int) (bool, error) {
+	return c.eval(countOperand(count))
+}
+
+func (c *comparison) eval(left operand) (bool, error) {
+	switch c.operator {
+	case "in":
+		if !c.isList {
+			return false, nil
+		}
+		for _, item := range c.list {
+			if item == left.str {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "matches":
+		if c.regexErr != nil {
+			return false, c.regexErr
+		}
+		return c.regex.MatchString(left.str), nil
+	case "contains":
+		return strings.Contains(left.str, c.right.str), nil
+	default:
+		return compareOperands(left, c.right, c.operator)
+	}
+}
+
+// compareDynamic compares two operands computed at evaluation time
+func compareDynamic(left, right operand, operator string) (bool, error) {
+	switch operator {
+	case "in":
+		return false, nil // Right side is never a list
+	case "matches":
+		regex, err := sharedRegexCache.get(right.str)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		return regex.MatchString(left.str), nil
+	case "contains":
+		return strings.Contains(left.str, right.str), nil
+	default:
+		return compareOperands(left, right, operator)
+	}
+}
+
+// compareOperands implements ==, !=, <, <=, >, >= with the Evaluator's
+// numeric-then-string semantics
+func compareOperands(left, right operand, operator string) (bool, error) {
+	var cmp int
+	if left.isNum && right.isNum {
+		// Equality uses float equality (NaN is never equal), ordering uses cmp
+		switch operator {
+		case "==":
+			return left.num == right.num, nil
+		case "!=":
+			return left.num != right.num, nil
+		}
+		switch {
+		case left.num < right.num:
+			cmp = -1
+		case left.num > right.num:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(left.str, right.str)
+	}
+
+	switch operator {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	default:
+		return false, fmt.Errorf("unknown operator: %s", operator)
+	}
+}
@@ -0,0 +1,157 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompiledProgramMatchesEvaluator checks that the compiled program and the
+// AST-walking evaluator agree on hand-picked edge cases
+func TestCompiledProgramMatchesEvaluator(t *testing.T) {
+	spans := []*models.Span{
+		createBenchmarkSpan("payment", map[string]interface{}{
+			"amount": 1500, "currency": "USD", "verified": true, "ratio": "NaN",
+			"path": "/api/admin/users", "data.contains_pii": true,
+		}),
+		createBenchmarkSpan("fraud_check", map[string]interface{}{"score": 0.2}),
+		createBenchmarkSpan("http_request", nil),
+		createBenchmarkSpan("http_request", nil),
+		createBenchmarkSpan("http_response", nil),
+	}
+
+	rules := []string{
+		`when { payment } always { fraud_check }`,
+		`when { payment.where(amount > 1000) } always { refund }`,
+		`when { payment.where(amount > 1000).where(currency == USD) } never { fraud_check }`,
+		`when { payment.where(currency in [USD, EUR]) } never { fraud_check.where(score < 0.3) }`,
+		`when { payment.where(path matches "^/api/admin") } never { fraud_check }`,
+		`when { payment.where(path matches "[invalid") } never { fraud_check }`,
+		`when { payment.where(path contains admin) } never { fraud_check }`,
+		`when { payment.where(verified) and not payment.where(not verified) } never { fraud_check }`,
+		`when { payment.where("data.contains_pii" == true) } never { fraud_check }`,
+		`when { payment.where(ratio == 1) } never { fraud_check }`,
+		`when { payment.where(ratio >= 1) } never { fraud_check }`,
+		`when { payment.where(missing == "") } never { fraud_check }`,
+		`when { payment.where(amount > count(http_request)) } never { fraud_check }`,
+		`when { count(http_request) > 1 } always { count(http_response) >= 2 }`,
+		`when { count(http_request) != count(http_response) } never { fraud_check }`,
+		`when { count(http_request) == 2.0 } never { fraud_check }`,
+		`when { payment == 1 } never { fraud_check }`,
+		`when { (payment or refund) and not chargeback } always { fraud_check and audit }`,
+		`when { payment }`,
+	}
+
+	evaluator := NewEvaluator()
+	for _, dsl := range rules {
+		t.Run(dsl, func(t *testing.T) {
+			rule, err := Parse(dsl)
+			require.NoError(t, err)
+
+			program, err := Compile(rule)
+			require.NoError(t, err)
+
+			want, wantErr := evaluator.EvaluateRule(rule, spans)
+			got, gotErr := program.Evaluate(spans)
+
+			assert.Equal(t, want, got)
+			if wantErr != nil {
+				require.Error(t, gotErr)
+				assert.Equal(t, wantErr.Error(), gotErr.Error())
+			} else {
+				assert.NoError(t, gotErr)
+			}
+		})
+	}
+}
+
+// TestCompiledProgramDifferential runs fuzzer-generated rules against random
+// traces and requires identical results from both evaluation strategies
+func TestCompiledProgramDifferential(t *testing.T) {
+	seed := getSeedFromEnv(t)
+	fuzzer := NewDSLFuzzer(seed)
+	rng := rand.New(rand.NewSource(seed))
+	evaluator := NewEvaluator()
+
+	for i := 0; i < 500; i++ {
+		dsl := fuzzer.nextGoodDSL()
+		rule, err := Parse(dsl)
+		if err != nil {
+			continue // Generator occasionally emits grammar it can't parse
+		}
+
+		program, err := Compile(rule)
+		require.NoError(t, err)
+
+		for j := 0; j < 5; j++ {
+			spans := randomTrace(fuzzer, rng)
+
+			want, wantErr := evaluator.EvaluateRule(rule, spans)
+			got, gotErr := program.Evaluate(spans)
+
+			require.Equal(t, want, got, "seed=%d rule=%q", seed, dsl)
+			require.Equal(t, wantErr != nil, gotErr != nil, "seed=%d rule=%q", seed, dsl)
+		}
+	}
+}
+
+func TestCompile_NilRule(t *testing.T) {
+	_, err := Compile(nil)
+	assert.Error(t, err)
+}
+
+func TestProgram_EvaluateOutcome(t *testing.T) {
+	rule, err := Parse("when { payment } always { auth }")
+	require.NoError(t, err)
+	program, err := Compile(rule)
+	require.NoError(t, err)
+
+	trace := func(ops ...string) []*models.Span {
+		spans := make([]*models.Span, len(ops))
+		for i, op := range ops {
+			spans[i] = &models.Span{SpanID: op, OperationName: op}
+		}
+		return spans
+	}
+	for name, tc := range map[string]struct {
+		spans []*models.Span
+		want  Outcome
+	}{
+		"when not matched": {trace("login"), OutcomeSkipped},
+		"always held":      {trace("payment", "auth"), OutcomePassed},
+		"always violated":  {trace("payment"), OutcomeViolated},
+	} {
+		outcome, err := program.EvaluateOutcome(context.Background(), tc.spans, Limits{})
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.want, outcome, name)
+
+		outcome, err = NewEvaluator().EvaluateRuleOutcome(context.Background(), rule, tc.spans, Limits{})
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.want, outcome, "evaluator: "+name)
+	}
+}
+
+// randomTrace builds a trace from the fuzzer's vocabulary so rules actually match
+func randomTrace(fuzzer *DSLFuzzer, rng *rand.Rand) []*models.Span {
+	values := []string{"0", "500", "1000.5", "9999", "USD", "EUR", "gold", "true", "false", "ERROR", "OK", ""}
+
+	spans := make([]*models.Span, rng.Intn(8))
+	for i := range spans {
+		attrs := make(map[string]string)
+		for k := rng.Intn(4); k > 0; k-- {
+			attrs[fuzzer.randomAttribute()] = values[rng.Intn(len(values))]
+		}
+		spans[i] = &models.Span{
+			SpanID:        fmt.Sprintf("span-%d", i),
+			TraceID:       "trace-diff",
+			OperationName: fuzzer.randomOpName(),
+			Attributes:    attrs,
+		}
+	}
+	return spans
+}
@@ -1,79 +1,103 @@
 package dsl
 
 import (
+	"context"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/betracehq/betrace/backend/pkg/models"
 )
 
-// Evaluator evaluates BeTraceDSL rules against OpenTelemetry traces
+// Evaluator evaluates BeTraceDSL rules against OpenTelemetry traces.
+//
+// An Evaluator holds no per-evaluation state and is safe for concurrent use
+// by multiple goroutines.
 type Evaluator struct {
-	// Cache compiled regexes for matches operator
-	regexCache map[string]*regexp.Regexp
+	// Compiled regexes for matches operator (shared, bounded, thread-safe)
+	regexCache *regexCache
 }
 
 // NewEvaluator creates a new DSL evaluator
 func NewEvaluator() *Evaluator {
 	return &Evaluator{
-		regexCache: make(map[string]*regexp.Regexp),
+		regexCache: sharedRegexCache,
 	}
 }
 
 // EvaluateRule evaluates a complete when-always-never rule against a trace
 func (e *Evaluator) EvaluateRule(rule *Rule, spans []*models.Span) (bool, error) {
+	return e.EvaluateRuleContext(context.Background(), rule, spans, Limits{})
+}
+
+// EvaluateRuleContext evaluates a rule like EvaluateRule, but stops with an
+// error wrapping ErrEvaluationAborted when ctx is done or limits are exceeded
+func (e *Evaluator) EvaluateRuleContext(ctx context.Context, rule *Rule, spans []*models.Span, limits Limits) (bool, error) {
+	outcome, err := e.EvaluateRuleOutcome(ctx, rule, spans, limits)
+	return outcome == OutcomeViolated, err
+}
+
+// EvaluateRuleOutcome evaluates a rule like EvaluateRuleContext, telling
+// traces the rule doesn't apply to from traces where it held
+func (e *Evaluator) EvaluateRuleOutcome(ctx context.Context, rule *Rule, spans []*models.Span, limits Limits) (Outcome, error) {
 	// Semantic validation: at least one of always/never must be present
 	if rule.Always == nil && rule.Never == nil {
-		return false, fmt.Errorf("rule must have at least one 'always' or 'never' clause")
+		return OutcomeSkipped, fmt.Errorf("rule must have at least one 'always' or 'never' clause")
 	}
 
+	if ctx.Err() != nil {
+		return OutcomeSkipped, abortedError(ctx)
+	}
+	return e.evaluateOutcome(newBudget(ctx, limits), rule, spans)
+}
+
+// evaluateOutcome evaluates a validated rule, charging its work to b
+func (e *Evaluator) evaluateOutcome(b *budget, rule *Rule, spans []*models.Span) (Outcome, error) {
 	// Evaluate when clause
-	whenMatched, err := e.evaluateCondition(rule.When, spans)
+	whenMatched, err := e.evaluateCondition(b, rule.When, spans)
 	if err != nil {
-		return false, fmt.Errorf("when clause evaluation failed: %w", err)
+		return OutcomeSkipped, fmt.Errorf("when clause evaluation failed: %w", err)
 	}
 
 	// If when clause doesn't match, rule doesn't apply
 	if !whenMatched {
-		return false, nil
+		return OutcomeSkipped, nil
 	}
 
 	// When clause matched - check always/never clauses
 	if rule.Always != nil {
-		alwaysMatched, err := e.evaluateCondition(rule.Always, spans)
+		alwaysMatched, err := e.evaluateCondition(b, rule.Always, spans)
 		if err != nil {
-			return false, fmt.Errorf("always clause evaluation failed: %w", err)
+			return OutcomeSkipped, fmt.Errorf("always clause evaluation failed: %w", err)
 		}
 		if !alwaysMatched {
-			return true, nil // VIOLATION: always clause not satisfied
+			return OutcomeViolated, nil // VIOLATION: always clause not satisfied
 		}
 	}
 
 	if rule.Never != nil {
-		neverMatched, err := e.evaluateCondition(rule.Never, spans)
+		neverMatched, err := e.evaluateCondition(b, rule.Never, spans)
 		if err != nil {
-			return false, fmt.Errorf("never clause evaluation failed: %w", err)
+			return OutcomeSkipped, fmt.Errorf("never clause evaluation failed: %w", err)
 		}
 		if neverMatched {
-			return true, nil // VIOLATION: never clause matched
+			return OutcomeViolated, nil // VIOLATION: never clause matched
 		}
 	}
 
 	// All constraints satisfied - no violation
-	return false, nil
+	return OutcomePassed, nil
 }
 
 // evaluateCondition evaluates a Condition (OR of AND terms)
-func (e *Evaluator) evaluateCondition(cond *Condition, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateCondition(b *budget, cond *Condition, spans []*models.Span) (bool, error) {
 	if cond == nil {
 		return false, fmt.Errorf("condition is nil")
 	}
 
 	// OR terms - at least one must be true
 	for _, orTerm := range cond.Or {
-		result, err := e.evaluateOrTerm(orTerm, spans)
+		result, err := e.evaluateOrTerm(b, orTerm, spans)
 		if err != nil {
 			return false, err
 		}
@@ -86,10 +110,10 @@ func (e *Evaluator) evaluateCondition(cond *Condition, spans []*models.Span) (bo
 }
 
 // evaluateOrTerm evaluates an OrTerm (AND of terms)
-func (e *Evaluator) evaluateOrTerm(orTerm *OrTerm, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateOrTerm(b *budget, orTerm *OrTerm, spans []*models.Span) (bool, error) {
 	// AND terms - all must be true
 	for _, andTerm := range orTerm.And {
-		result, err := e.evaluateAndTerm(andTerm, spans)
+		result, err := e.evaluateAndTerm(b, andTerm, spans)
 		if err != nil {
 			return false, err
 		}
@@ -102,8 +126,8 @@ func (e *Evaluator) evaluateOrTerm(orTerm *OrTerm, spans []*models.Span) (bool,
 }
 
 // evaluateAndTerm evaluates an AndTerm (optional NOT + term)
-func (e *Evaluator) evaluateAndTerm(andTerm *AndTerm, spans []*models.Span) (bool, error) {
-	result, err := e.evaluateTerm(andTerm.Term, spans)
+func (e *Evaluator) evaluateAndTerm(b *budget, andTerm *AndTerm, spans []*models.Span) (bool, error) {
+	result, err := e.evaluateTerm(b, andTerm.Term, spans)
 	if err != nil {
 		return false, err
 	}
@@ -117,38 +141,41 @@ func (e *Evaluator) evaluateAndTerm(andTerm *AndTerm, spans []*models.Span) (boo
 }
 
 // evaluateTerm evaluates a Term (grouped condition or span check)
-func (e *Evaluator) evaluateTerm(term *Term, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateTerm(b *budget, term *Term, spans []*models.Span) (bool, error) {
 	if term.Grouped != nil {
-		return e.evaluateCondition(term.Grouped, spans)
+		return e.evaluateCondition(b, term.Grouped, spans)
 	}
 
 	if term.SpanCheck != nil {
-		return e.evaluateSpanCheck(term.SpanCheck, spans)
+		return e.evaluateSpanCheck(b, term.SpanCheck, spans)
 	}
 
 	return false, fmt.Errorf("term has no grouped or span check")
 }
 
 // evaluateSpanCheck evaluates a SpanCheck (count or has)
-func (e *Evaluator) evaluateSpanCheck(check *SpanCheck, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateSpanCheck(b *budget, check *SpanCheck, spans []*models.Span) (bool, error) {
 	if check.Count != nil {
-		return e.evaluateCountCheck(check.Count, spans)
+		return e.evaluateCountCheck(b, check.Count, spans)
 	}
 
 	if check.Has != nil {
-		return e.evaluateHasCheck(check.Has, spans)
+		return e.evaluateHasCheck(b, check.Has, spans)
 	}
 
 	return false, fmt.Errorf("span check has no count or has")
 }
 
 // evaluateCountCheck evaluates a CountCheck (count(op) > N)
-func (e *Evaluator) evaluateCountCheck(check *CountCheck, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateCountCheck(b *budget, check *CountCheck, spans []*models.Span) (bool, error) {
 	// Get left side count
+	if err := b.step(len(spans)); err != nil {
+		return false, err
+	}
 	leftCount := e.countMatchingSpans(check.OpName, spans)
 
 	// Evaluate right side expression
-	rightValue, err := e.evaluateExpression(check.Right, spans)
+	rightValue, err := e.evaluateExpression(b, check.Right, spans)
 	if err != nil {
 		return false, fmt.Errorf("count check right side: %w", err)
 	}
@@ -158,12 +185,16 @@ func (e *Evaluator) evaluateCountCheck(check *CountCheck, spans []*models.Span)
 }
 
 // evaluateHasCheck evaluates a HasCheck (operation_name with optional where)
-func (e *Evaluator) evaluateHasCheck(check *HasCheck, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateHasCheck(b *budget, check *HasCheck, spans []*models.Span) (bool, error) {
 	opName := strings.Join(check.OpName, ".")
 
 	// Check if uses .where() chain syntax
 	if check.Where != nil {
-		return e.evaluateWhereChain(opName, check.Where, spans)
+		return e.evaluateWhereChain(b, opName, check.Where, spans)
+	}
+
+	if err := b.step(len(spans)); err != nil {
+		return false, err
 	}
 
 	// Simple operation name check (with optional comparison)
@@ -190,10 +221,19 @@ func (e *Evaluator) evaluateHasCheck(check *HasCheck, spans []*models.Span) (boo
 }
 
 // evaluateWhereChain evaluates operation_name.where() with optional chaining
-func (e *Evaluator) evaluateWhereChain(opName string, chain *WhereChain, spans []*models.Span) (bool, error) {
+func (e *Evaluator) evaluateWhereChain(b *budget, opName string, chain *WhereChain, spans []*models.Span) (bool, error) {
+	if err := b.step(len(spans)); err != nil {
+		return false, err
+	}
+
 	// Find spans with matching operation name
 	for _, span := range spans {
 		if span.OperationName == opName {
+			// Filters may run expensive regexes: check for cancellation per span
+			if err := b.step(0); err != nil {
+				return false, err
+			}
+
 			// Check first where filter
 			matched, err := e.evaluateWhereFilter(chain.First, span, opName)
 			if err != nil {
@@ -330,12 +370,15 @@ func (e *Evaluator) evaluateComparisonOnSpan(comp *Comparison, span *models.Span
 }
 
 // evaluateExpression evaluates an Expression (literal, count, or path)
-func (e *Evaluator) evaluateExpression(expr *Expression, spans []*models.Span) (interface{}, error) {
+func (e *Evaluator) evaluateExpression(b *budget, expr *Expression, spans []*models.Span) (interface{}, error) {
 	if expr.Value != nil {
 		return e.getValue(expr.Value)
 	}
 
 	if expr.Count != nil {
+		if err := b.step(len(spans)); err != nil {
+			return nil, err
+		}
 		count := e.countMatchingSpans(expr.Count.OpName, spans)
 		return float64(count), nil
 	}
@@ -506,15 +549,9 @@ func (e *Evaluator) matches(left, right interface{}) (bool, error) {
 	leftStr := toString(left)
 	pattern := toString(right)
 
-	// Check cache
-	regex, ok := e.regexCache[pattern]
-	if !ok {
-		var err error
-		regex, err = regexp.Compile(pattern)
-		if err != nil {
-			return false, fmt.Errorf("invalid regex pattern: %w", err)
-		}
-		e.regexCache[pattern] = regex
+	regex, err := e.regexCache.get(pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid regex pattern: %w", err)
 	}
 
 	return regex.MatchString(leftStr), nil
@@ -0,0 +1,377 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// maxExplainedSpans caps the span IDs recorded per explanation node
+const maxExplainedSpans = 100
+
+// Explanation node kinds
+const (
+	ExplainKindOr    = "or"
+	ExplainKindAnd   = "and"
+	ExplainKindNot   = "not"
+	ExplainKindHas   = "has"
+	ExplainKindCount = "count"
+)
+
+// Explain evaluates a rule against a trace like EvaluateRule and also returns
+// an evaluation tree recording each clause's result, the spans that satisfied
+// the when clause, and which always/never sub-conditions caused a violation.
+//
+// Unlike EvaluateRule, Explain does not short-circuit, so every node of the
+// tree carries a result. It is meant for traces already known to violate and
+// for interactive debugging, not for the hot evaluation path.
+func (e *Evaluator) Explain(rule *Rule, spans []*models.Span) (*models.RuleExplanation, error) {
+	return e.ExplainContext(context.Background(), rule, spans, Limits{})
+}
+
+// ExplainContext explains a rule like Explain, but stops with an error
+// wrapping ErrEvaluationAborted when ctx is done or limits are exceeded.
+// The evaluation and the explain pass share one budget.
+func (e *Evaluator) ExplainContext(ctx context.Context, rule *Rule, spans []*models.Span, limits Limits) (*models.RuleExplanation, error) {
+	if rule.Always == nil && rule.Never == nil {
+		return nil, fmt.Errorf("rule must have at least one 'always' or 'never' clause")
+	}
+	if ctx.Err() != nil {
+		return nil, abortedError(ctx)
+	}
+	b := newBudget(ctx, limits)
+
+	outcome, err := e.evaluateOutcome(b, rule, spans)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &models.RuleExplanation{Violated: outcome == OutcomeViolated}
+	if explanation.When, err = e.explainCondition(b, rule.When, spans); err != nil {
+		return nil, err
+	}
+	explanation.WhenSpanIDs = satisfyingSpanIDs(explanation.When, true)
+
+	if rule.Always != nil {
+		if explanation.Always, err = e.explainCondition(b, rule.Always, spans); err != nil {
+			return nil, err
+		}
+		if explanation.When.Result {
+			// Always sub-conditions whose result kept the clause from holding
+			for _, leaf := range deciding(explanation.Always, true) {
+				explanation.UnsatisfiedAlways = append(explanation.UnsatisfiedAlways, polarized(leaf, !leaf.Result))
+			}
+		}
+	}
+
+	if rule.Never != nil {
+		if explanation.Never, err = e.explainCondition(b, rule.Never, spans); err != nil {
+			return nil, err
+		}
+		if explanation.When.Result {
+			// Never sub-conditions whose result made the clause hold
+			for _, leaf := range deciding(explanation.Never, false) {
+				explanation.MatchedNever = append(explanation.MatchedNever, polarized(leaf, leaf.Result))
+			}
+		}
+	}
+
+	return explanation, nil
+}
+
+// explainCondition builds the tree for a Condition (OR of AND terms)
+func (e *Evaluator) explainCondition(b *budget, cond *Condition, spans []*models.Span) (*models.ExplanationNode, error) {
+	if len(cond.Or) == 1 {
+		return e.explainOrTerm(b, cond.Or[0], spans)
+	}
+
+	node := &models.ExplanationNode{Kind: ExplainKindOr, Expression: formatCondition(cond)}
+	for _, orTerm := range cond.Or {
+		child, err := e.explainOrTerm(b, orTerm, spans)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+		node.Result = node.Result || child.Result
+	}
+	return node, nil
+}
+
+// explainOrTerm builds the tree for an OrTerm (AND of terms)
+func (e *Evaluator) explainOrTerm(b *budget, orTerm *OrTerm, spans []*models.Span) (*models.ExplanationNode, error) {
+	if len(orTerm.And) == 1 {
+		return e.explainAndTerm(b, orTerm.And[0], spans)
+	}
+
+	node := &models.ExplanationNode{Kind: ExplainKindAnd, Expression: formatOrTerm(orTerm), Result: true}
+	for _, andTerm := range orTerm.And {
+		child, err := e.explainAndTerm(b, andTerm, spans)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+		node.Result = node.Result && child.Result
+	}
+	return node, nil
+}
+
+// explainAndTerm builds the tree for an AndTerm (optional NOT + term)
+func (e *Evaluator) explainAndTerm(b *budget, andTerm *AndTerm, spans []*models.Span) (*models.ExplanationNode, error) {
+	child, err := e.explainTerm(b, andTerm.Term, spans)
+	if err != nil || !andTerm.Not {
+		return child, err
+	}
+
+	return &models.ExplanationNode{
+		Kind:       ExplainKindNot,
+		Expression: formatAndTerm(andTerm),
+		Result:     !child.Result && child.Error == "",
+		Children:   []*models.ExplanationNode{child},
+	}, nil
+}
+
+// explainTerm builds the tree for a Term (grouped condition or span check).
+// Only an aborted evaluation is returned as an error; other errors are
+// recorded on the node.
+func (e *Evaluator) explainTerm(b *budget, term *Term, spans []*models.Span) (*models.ExplanationNode, error) {
+	if term.Grouped != nil {
+		return e.explainCondition(b, term.Grouped, spans)
+	}
+
+	node := &models.ExplanationNode{Expression: formatTerm(term)}
+	switch {
+	case term.SpanCheck != nil && term.SpanCheck.Count != nil:
+		node.Kind = ExplainKindCount
+		check := term.SpanCheck.Count
+		name := strings.Join(check.OpName, ".")
+		node.Operation = name
+		result, err := e.evaluateCountCheck(b, check, spans)
+		if errors.Is(err, ErrEvaluationAborted) {
+			return nil, err
+		}
+		if err != nil {
+			node.Error = err.Error()
+			return node, nil
+		}
+		node.Result = result
+		if err := recordSpans(b, node, spans, func(span *models.Span) bool {
+			return span.OperationName == name
+		}); err != nil {
+			return nil, err
+		}
+
+	case term.SpanCheck != nil && term.SpanCheck.Has != nil:
+		node.Kind = ExplainKindHas
+		check := term.SpanCheck.Has
+		name := strings.Join(check.OpName, ".")
+		node.Operation = name
+		if err := recordSpans(b, node, spans, func(span *models.Span) bool {
+			return span.OperationName == name && e.spanSatisfiesHasCheck(check, span, name)
+		}); err != nil {
+			return nil, err
+		}
+		node.Result = node.MatchedSpanCount > 0
+
+	default:
+		node.Error = "term has no grouped or span check"
+	}
+
+	return node, nil
+}
+
+// spanSatisfiesHasCheck applies a has check's .where() chain or comparison to
+// one span, with the same error handling as evaluateHasCheck (errors = no match)
+func (e *Evaluator) spanSatisfiesHasCheck(check *HasCheck, span *models.Span, name string) bool {
+	if check.Where != nil {
+		filters := append([]*WhereFilter{check.Where.First}, check.Where.ChainedWhere...)
+		for _, filter := range filters {
+			matched, err := e.evaluateWhereFilter(filter, span, name)
+			if err != nil || !matched {
+				return false
+			}
+		}
+		return true
+	}
+
+	if check.Comparison != nil {
+		matched, err := e.evaluateComparisonOnSpan(check.Comparison, span, name)
+		return err == nil && matched
+	}
+
+	return true
+}
+
+// recordSpans stores the (capped) IDs and total count of spans matching
+// match, charging the scan to b
+func recordSpans(b *budget, node *models.ExplanationNode, spans []*models.Span, match func(*models.Span) bool) error {
+	if err := b.step(len(spans)); err != nil {
+		return err
+	}
+	for _, span := range spans {
+		// Filters may run expensive regexes: check for cancellation per span
+		if err := b.step(0); err != nil {
+			return err
+		}
+		if !match(span) {
+			continue
+		}
+		node.MatchedSpanCount++
+		if len(node.MatchedSpanIDs) < maxExplainedSpans {
+			node.MatchedSpanIDs = append(node.MatchedSpanIDs, span.SpanID)
+		}
+	}
+	return nil
+}
+
+// polarized renders a span check as itself or, if positive is false, its negation
+func polarized(leaf *models.ExplanationNode, positive bool) string {
+	if positive {
+		return leaf.Expression
+	}
+	return "not " + leaf.Expression
+}
+
+// deciding returns the span checks responsible for node's result differing
+// from want. Nothing is returned when node.Result == want.
+func deciding(node *models.ExplanationNode, want bool) []*models.ExplanationNode {
+	if node.Result == want {
+		return nil
+	}
+
+	switch node.Kind {
+	case ExplainKindNot:
+		return deciding(node.Children[0], !want)
+	case ExplainKindOr, ExplainKindAnd:
+		var leaves []*models.ExplanationNode
+		for _, child := range node.Children {
+			leaves = append(leaves, deciding(child, want)...)
+		}
+		return leaves
+	default:
+		return []*models.ExplanationNode{node}
+	}
+}
+
+// satisfyingSpanIDs returns the spans of positive span checks that made node
+// evaluate to want (negated checks contribute no spans)
+func satisfyingSpanIDs(node *models.ExplanationNode, want bool) []string {
+	if node.Result != want {
+		return nil
+	}
+
+	switch node.Kind {
+	case ExplainKindNot:
+		return satisfyingSpanIDs(node.Children[0], !want)
+	case ExplainKindOr, ExplainKindAnd:
+		var ids []string
+		seen := make(map[string]struct{})
+		for _, child := range node.Children {
+			for _, id := range satisfyingSpanIDs(child, want) {
+				if _, ok := seen[id]; !ok {
+					seen[id] = struct{}{}
+					ids = append(ids, id)
+				}
+			}
+		}
+		return ids
+	default:
+		if !want {
+			return nil
+		}
+		return node.MatchedSpanIDs
+	}
+}
+
+// OffendingSpans reduces a violating explanation to the spans that caused the
+// violation, each labelled with its role:
+//   - trigger: spans that satisfied the when clause
+//   - forbidden: spans matching a check that must not hold (a never check, or
+//     a negated always check)
+//   - missing-expected: spans of an expected operation that didn't satisfy the
+//     check (e.g. failed its .where() filter); absent operations have none
+//
+// It returns nil if the explanation isn't a violation.
+func OffendingSpans(explanation *models.RuleExplanation, spans []*models.Span) []models.SpanRef {
+	if explanation == nil || !explanation.Violated {
+		return nil
+	}
+
+	refs := newSpanRefSet(spans)
+	refs.addIDs(explanation.WhenSpanIDs, models.SpanRoleTrigger)
+	if explanation.Always != nil {
+		for _, leaf := range deciding(explanation.Always, true) {
+			refs.addLeaf(leaf, spans)
+		}
+	}
+	if explanation.Never != nil {
+		for _, leaf := range deciding(explanation.Never, false) {
+			refs.addLeaf(leaf, spans)
+		}
+	}
+	return refs.refs
+}
+
+// spanRefSet collects span references, deduplicated by span and role
+type spanRefSet struct {
+	byID map[string]*models.Span
+	seen map[[2]string]struct{}
+	refs []models.SpanRef
+}
+
+func newSpanRefSet(spans []*models.Span) *spanRefSet {
+	byID := make(map[string]*models.Span, len(spans))
+	for _, span := range spans {
+		if span.SpanID != "" {
+			byID[span.SpanID] = span
+		}
+	}
+	return &spanRefSet{byID: byID, seen: make(map[[2]string]struct{})}
+}
+
+func (s *spanRefSet) addIDs(ids []string, role string) {
+	for _, id := range ids {
+		span, ok := s.byID[id]
+		if !ok {
+			continue
+		}
+		key := [2]string{id, role}
+		if _, dup := s.seen[key]; dup {
+			continue
+		}
+		s.seen[key] = struct{}{}
+		s.refs = append(s.refs, models.SpanRef{
+			TraceID:     span.TraceID,
+			SpanID:      span.SpanID,
+			ServiceName: span.ServiceName,
+			Role:        role,
+		})
+	}
+}
+
+// addLeaf adds the spans of a span check that decided a violation. A check
+// that held (but mustn't) blames its matching spans; a check that failed (but
+// should have held) blames the spans of its operation that fell short.
+func (s *spanRefSet) addLeaf(leaf *models.ExplanationNode, spans []*models.Span) {
+	if leaf.Error != "" {
+		return
+	}
+	if leaf.Result {
+		s.addIDs(leaf.MatchedSpanIDs, models.SpanRoleForbidden)
+		return
+	}
+
+	var ids []string
+	for _, span := range spans {
+		if span.OperationName != leaf.Operation {
+			continue
+		}
+		ids = append(ids, span.SpanID)
+		if len(ids) == maxExplainedSpans {
+			break
+		}
+	}
+	s.addIDs(ids, models.SpanRoleMissingExpected)
+}
@@ -0,0 +1,254 @@
+package dsl
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func explainSpan(id, name string, attrs map[string]string) *models.Span {
+	return &models.Span{SpanID: id, TraceID: "trace-1", OperationName: name, Attributes: attrs}
+}
+
+func TestExplain_AlwaysViolation(t *testing.T) {
+	rule, err := Parse(`when { payment.where(amount > 1000) } always { fraud_check and audit_log }`)
+	require.NoError(t, err)
+
+	spans := []*models.Span{
+		explainSpan("s1", "payment", map[string]string{"amount": "5000"}),
+		explainSpan("s2", "payment", map[string]string{"amount": "10"}),
+		explainSpan("s3", "fraud_check", nil),
+	}
+
+	explanation, err := NewEvaluator().Explain(rule, spans)
+	require.NoError(t, err)
+
+	assert.True(t, explanation.Violated)
+	assert.True(t, explanation.When.Result)
+	assert.Equal(t, ExplainKindHas, explanation.When.Kind)
+	assert.Equal(t, "payment.where(amount > 1000)", explanation.When.Expression)
+	assert.Equal(t, []string{"s1"}, explanation.WhenSpanIDs)
+
+	require.NotNil(t, explanation.Always)
+	assert.False(t, explanation.Always.Result)
+	assert.Equal(t, ExplainKindAnd, explanation.Always.Kind)
+	require.Len(t, explanation.Always.Children, 2)
+	assert.True(t, explanation.Always.Children[0].Result)
+	assert.Equal(t, []string{"s3"}, explanation.Always.Children[0].MatchedSpanIDs)
+	assert.False(t, explanation.Always.Children[1].Result)
+
+	assert.Equal(t, []string{"audit_log"}, explanation.UnsatisfiedAlways)
+	assert.Nil(t, explanation.Never)
+	assert.Empty(t, explanation.MatchedNever)
+}
+
+func TestExplain_NeverViolation(t *testing.T) {
+	rule, err := Parse(`when { admin_login } never { data_export or not mfa_verified }`)
+	require.NoError(t, err)
+
+	spans := []*models.Span{
+		explainSpan("s1", "admin_login", nil),
+		explainSpan("s2", "data_export", nil),
+		explainSpan("s3", "data_export", nil),
+	}
+
+	explanation, err := NewEvaluator().Explain(rule, spans)
+	require.NoError(t, err)
+
+	assert.True(t, explanation.Violated)
+	assert.Equal(t, []string{"s1"}, explanation.WhenSpanIDs)
+
+	require.NotNil(t, explanation.Never)
+	assert.True(t, explanation.Never.Result)
+	assert.Equal(t, ExplainKindOr, explanation.Never.Kind)
+	assert.ElementsMatch(t, []string{"data_export", "not mfa_verified"}, explanation.MatchedNever)
+
+	dataExport := explanation.Never.Children[0]
+	assert.Equal(t, 2, dataExport.MatchedSpanCount)
+	assert.Equal(t, []string{"s2", "s3"}, dataExport.MatchedSpanIDs)
+
+	notMFA := explanation.Never.Children[1]
+	assert.Equal(t, ExplainKindNot, notMFA.Kind)
+	assert.Equal(t, "not mfa_verified", notMFA.Expression)
+	assert.True(t, notMFA.Result)
+}
+
+func TestExplain_NegatedAlways(t *testing.T) {
+	rule, err := Parse(`when { checkout } always { not retry }`)
+	require.NoError(t, err)
+
+	spans := []*models.Span{
+		explainSpan("s1", "checkout", nil),
+		explainSpan("s2", "retry", nil),
+	}
+
+	explanation, err := NewEvaluator().Explain(rule, spans)
+	require.NoError(t, err)
+
+	assert.True(t, explanation.Violated)
+	assert.Equal(t, []string{"not retry"}, explanation.UnsatisfiedAlways)
+}
+
+func TestExplain_NoViolation(t *testing.T) {
+	rule, err := Parse(`when { count(http_retry) > 3 } always { alert }`)
+	require.NoError(t, err)
+
+	spans := []*models.Span{
+		explainSpan("s1", "http_retry", nil),
+		explainSpan("s2", "alert", nil),
+	}
+
+	explanation, err := NewEvaluator().Explain(rule, spans)
+	require.NoError(t, err)
+
+	assert.False(t, explanation.Violated)
+	assert.False(t, explanation.When.Result)
+	assert.Equal(t, ExplainKindCount, explanation.When.Kind)
+	assert.Equal(t, "count(http_retry) > 3", explanation.When.Expression)
+	assert.Equal(t, 1, explanation.When.MatchedSpanCount)
+	assert.Empty(t, explanation.WhenSpanIDs)
+
+	// Clauses are still explained, but nothing is blamed when "when" didn't match
+	require.NotNil(t, explanation.Always)
+	assert.True(t, explanation.Always.Result)
+	assert.Empty(t, explanation.UnsatisfiedAlways)
+}
+
+func TestExplain_CapsSpanIDs(t *testing.T) {
+	rule, err := Parse(`when { http } always { auth }`)
+	require.NoError(t, err)
+
+	spans := make([]*models.Span, 0, 250)
+	for i := 0; i < 250; i++ {
+		spans = append(spans, explainSpan("", "http", nil))
+	}
+
+	explanation, err := NewEvaluator().Explain(rule, spans)
+	require.NoError(t, err)
+
+	assert.Equal(t, 250, explanation.When.MatchedSpanCount)
+	assert.Len(t, explanation.When.MatchedSpanIDs, maxExplainedSpans)
+}
+
+func TestExplain_AgreesWithEvaluateRule(t *testing.T) {
+	seed := getSeedFromEnv(t)
+	fuzzer := NewDSLFuzzer(seed)
+	rng := rand.New(rand.NewSource(seed))
+	evaluator := NewEvaluator()
+
+	for i := 0; i < 200; i++ {
+		input := fuzzer.nextGoodDSL()
+		rule, err := Parse(input)
+		if err != nil {
+			continue // Generator occasionally emits grammar it can't parse
+		}
+
+		spans := randomTrace(fuzzer, rng)
+		want, wantErr := evaluator.EvaluateRule(rule, spans)
+		explanation, err := evaluator.Explain(rule, spans)
+		if wantErr != nil {
+			assert.Error(t, err, "seed=%d rule=%s", seed, input)
+			continue
+		}
+		require.NoError(t, err, "seed=%d rule=%s", seed, input)
+		assert.Equal(t, want, explanation.Violated, "seed=%d rule=%s", seed, input)
+
+		// Node results must agree with the short-circuiting evaluator
+		whenMatched, err := evaluator.evaluateCondition(nil, rule.When, spans)
+		require.NoError(t, err)
+		assert.Equal(t, whenMatched, explanation.When.Result, "seed=%d rule=%s", seed, input)
+		if rule.Always != nil {
+			alwaysMatched, err := evaluator.evaluateCondition(nil, rule.Always, spans)
+			require.NoError(t, err)
+			assert.Equal(t, alwaysMatched, explanation.Always.Result, "seed=%d rule=%s", seed, input)
+		}
+		if rule.Never != nil {
+			neverMatched, err := evaluator.evaluateCondition(nil, rule.Never, spans)
+			require.NoError(t, err)
+			assert.Equal(t, neverMatched, explanation.Never.Result, "seed=%d rule=%s", seed, input)
+		}
+
+		// Formatted expressions must parse back
+		_, err = Parse("when { " + explanation.When.Expression + " } always { x }")
+		assert.NoError(t, err, "seed=%d expression=%s", seed, explanation.When.Expression)
+	}
+}
+
+func TestOffendingSpans_Roles(t *testing.T) {
+	tests := []struct {
+		name  string
+		dsl   string
+		spans []*models.Span
+		want  map[string]string // span ID -> role
+	}{
+		{
+			name: "never match is forbidden",
+			dsl:  `when { admin_login } never { data_export }`,
+			spans: []*models.Span{
+				explainSpan("s1", "admin_login", nil),
+				explainSpan("s2", "data_export", nil),
+				explainSpan("s3", "db_query", nil),
+			},
+			want: map[string]string{"s1": models.SpanRoleTrigger, "s2": models.SpanRoleForbidden},
+		},
+		{
+			name: "always near miss is missing-expected",
+			dsl:  `when { payment } always { fraud_check.where(score < 50) and audit_log }`,
+			spans: []*models.Span{
+				explainSpan("s1", "payment", nil),
+				explainSpan("s2", "fraud_check", map[string]string{"score": "90"}),
+				explainSpan("s3", "db_query", nil),
+			},
+			want: map[string]string{"s1": models.SpanRoleTrigger, "s2": models.SpanRoleMissingExpected},
+		},
+		{
+			name: "negated always match is forbidden",
+			dsl:  `when { checkout } always { not retry }`,
+			spans: []*models.Span{
+				explainSpan("s1", "checkout", nil),
+				explainSpan("s2", "retry", nil),
+			},
+			want: map[string]string{"s1": models.SpanRoleTrigger, "s2": models.SpanRoleForbidden},
+		},
+		{
+			name: "satisfied always isn't blamed",
+			dsl:  `when { payment } always { auth } never { refund }`,
+			spans: []*models.Span{
+				explainSpan("s1", "payment", nil),
+				explainSpan("s2", "auth", nil),
+				explainSpan("s3", "refund", nil),
+			},
+			want: map[string]string{"s1": models.SpanRoleTrigger, "s3": models.SpanRoleForbidden},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.dsl)
+			require.NoError(t, err)
+			explanation, err := NewEvaluator().Explain(rule, tt.spans)
+			require.NoError(t, err)
+			require.True(t, explanation.Violated)
+
+			got := make(map[string]string)
+			for _, ref := range OffendingSpans(explanation, tt.spans) {
+				assert.Equal(t, "trace-1", ref.TraceID)
+				got[ref.SpanID] = ref.Role
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOffendingSpans_NoViolation(t *testing.T) {
+	rule, err := Parse(`when { payment } always { auth }`)
+	require.NoError(t, err)
+	spans := []*models.Span{explainSpan("s1", "payment", nil), explainSpan("s2", "auth", nil)}
+
+	explanation, err := NewEvaluator().Explain(rule, spans)
+	require.NoError(t, err)
+	assert.Nil(t, OffendingSpans(explanation, spans))
+}
@@ -0,0 +1,136 @@
+package dsl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Formatting of AST nodes back to DSL source, used to label explanation nodes.
+// The output is normalized (single spaces, no comments) rather than the
+// original text, but parses back to an equivalent AST.
+
+func formatCondition(cond *Condition) string {
+	if cond == nil {
+		return ""
+	}
+	parts := make([]string, len(cond.Or))
+	for i, orTerm := range cond.Or {
+		parts[i] = formatOrTerm(orTerm)
+	}
+	return strings.Join(parts, " or ")
+}
+
+func formatOrTerm(orTerm *OrTerm) string {
+	parts := make([]string, len(orTerm.And))
+	for i, andTerm := range orTerm.And {
+		parts[i] = formatAndTerm(andTerm)
+	}
+	return strings.Join(parts, " and ")
+}
+
+func formatAndTerm(andTerm *AndTerm) string {
+	if andTerm.Not {
+		return "not " + formatTerm(andTerm.Term)
+	}
+	return formatTerm(andTerm.Term)
+}
+
+func formatTerm(term *Term) string {
+	switch {
+	case term == nil:
+		return ""
+	case term.Grouped != nil:
+		return "(" + formatCondition(term.Grouped) + ")"
+	case term.SpanCheck != nil && term.SpanCheck.Count != nil:
+		return formatCountCheck(term.SpanCheck.Count)
+	case term.SpanCheck != nil && term.SpanCheck.Has != nil:
+		return formatHasCheck(term.SpanCheck.Has)
+	default:
+		return ""
+	}
+}
+
+func formatCountCheck(check *CountCheck) string {
+	return "count(" + strings.Join(check.OpName, ".") + ") " + check.Operator + " " + formatExpression(check.Right)
+}
+
+func formatHasCheck(check *HasCheck) string {
+	var sb strings.Builder
+	sb.WriteString(strings.Join(check.OpName, "."))
+
+	if check.Where != nil {
+		sb.WriteString(".where(" + formatWhereCondition(check.Where.First.Condition) + ")")
+		for _, chained := range check.Where.ChainedWhere {
+			sb.WriteString(".where(" + formatWhereCondition(chained.Condition) + ")")
+		}
+	} else if check.Comparison != nil {
+		sb.WriteString(" " + check.Comparison.Operator + " " + formatExpression(check.Comparison.Right))
+	}
+
+	return sb.String()
+}
+
+func formatWhereCondition(cond *WhereCondition) string {
+	parts := make([]string, len(cond.Or))
+	for i, andTerm := range cond.Or {
+		terms := make([]string, len(andTerm.And))
+		for j, atomic := range andTerm.And {
+			terms[j] = formatWhereAtomicTerm(atomic)
+		}
+		parts[i] = strings.Join(terms, " and ")
+	}
+	return strings.Join(parts, " or ")
+}
+
+func formatWhereAtomicTerm(term *WhereAtomicTerm) string {
+	var s string
+	switch {
+	case term.Grouped != nil:
+		s = "(" + formatWhereCondition(term.Grouped) + ")"
+	case term.Comparison != nil:
+		s = term.Comparison.Attribute + " " + term.Comparison.Operator + " " + formatExpression(term.Comparison.Right)
+	case term.SpanRef != nil:
+		s = strings.Join(term.SpanRef.SpanName, ".")
+	case term.BoolIdent != nil:
+		s = *term.BoolIdent
+	}
+
+	if term.Not {
+		return "not " + s
+	}
+	return s
+}
+
+func formatExpression(expr *Expression) string {
+	switch {
+	case expr == nil:
+		return ""
+	case expr.Value != nil:
+		return formatValue(expr.Value)
+	case expr.Count != nil:
+		return "count(" + strings.Join(expr.Count.OpName, ".") + ")"
+	default:
+		return strings.Join(expr.Path, ".")
+	}
+}
+
+func formatValue(val *Value) string {
+	switch {
+	case val.String != nil:
+		return *val.String // Token includes its quotes
+	case val.Number != nil:
+		s := strconv.FormatFloat(*val.Number, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0" // Keep it a Float token
+		}
+		return s
+	case val.Int != nil:
+		return strconv.Itoa(*val.Int)
+	case val.Bool != nil:
+		return strconv.FormatBool(*val.Bool)
+	case val.Ident != nil:
+		return *val.Ident
+	default:
+		return "[" + strings.Join(val.List, ", ") + "]"
+	}
+}
@@ -0,0 +1,101 @@
+package dsl
+
+import (
+	"hash/fnv"
+	"regexp"
+	"sync"
+)
+
+const (
+	// regexCacheShards spreads lock contention across independent shards
+	regexCacheShards = 16
+
+	// defaultRegexCacheSize bounds the number of compiled patterns kept in memory
+	defaultRegexCacheSize = 1024
+)
+
+// sharedRegexCache is used by every Evaluator (and by compiled programs for
+// patterns only known at evaluation time), so a pattern is compiled once per
+// process rather than once per evaluator.
+var sharedRegexCache = newRegexCache(defaultRegexCacheSize)
+
+// regexCache is a bounded, thread-safe cache of compiled regexes. Patterns are
+// hashed to one of regexCacheShards shards, each guarded by its own RWMutex,
+// so concurrent evaluations never serialize on a single lock. When a shard is
+// full an arbitrary entry is evicted; patterns in rules are few and stable, so
+// the bound only matters for adversarial or generated input.
+type regexCache struct {
+	shards      [regexCacheShards]regexCacheShard
+	maxPerShard int
+}
+
+type regexCacheShard struct {
+	mu      sync.RWMutex
+	entries map[string]*regexp.Regexp
+}
+
+// newRegexCache creates a cache holding at most (roughly) size patterns
+func newRegexCache(size int) *regexCache {
+	maxPerShard := size / regexCacheShards
+	if maxPerShard < 1 {
+		maxPerShard = 1
+	}
+
+	c := &regexCache{maxPerShard: maxPerShard}
+	for i := range c.shards {
+		c.shards[i].entries = make(map[string]*regexp.Regexp)
+	}
+	return c
+}
+
+// get returns the compiled regex for pattern, compiling and caching it on a miss.
+// Invalid patterns are not cached.
+func (c *regexCache) get(pattern string) (*regexp.Regexp, error) {
+	shard := c.shardFor(pattern)
+
+	shard.mu.RLock()
+	regex, ok := shard.entries[pattern]
+	shard.mu.RUnlock()
+	if ok {
+		return regex, nil
+	}
+
+	// Compile outside the lock; *regexp.Regexp is safe for concurrent use
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if existing, ok := shard.entries[pattern]; ok {
+		return existing, nil
+	}
+	if len(shard.entries) >= c.maxPerShard {
+		for evict := range shard.entries {
+			delete(shard.entries, evict)
+			break
+		}
+	}
+	shard.entries[pattern] = regex
+
+	return regex, nil
+}
+
+// len returns the number of cached patterns
+func (c *regexCache) len() int {
+	n := 0
+	for i := range c.shards {
+		c.shards[i].mu.RLock()
+		n += len(c.shards[i].entries)
+		c.shards[i].mu.RUnlock()
+	}
+	return n
+}
+
+func (c *regexCache) shardFor(pattern string) *regexCacheShard {
+	h := fnv.New32a()
+	h.Write([]byte(pattern))
+	return &c.shards[h.Sum32()%regexCacheShards]
+}
@@ -0,0 +1,117 @@
+package dsl
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexCache_ReusesCompiledPattern(t *testing.T) {
+	cache := newRegexCache(64)
+
+	first, err := cache.get(`^/api/v[0-9]+/`)
+	require.NoError(t, err)
+	second, err := cache.get(`^/api/v[0-9]+/`)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, cache.len())
+}
+
+func TestRegexCache_InvalidPatternNotCached(t *testing.T) {
+	cache := newRegexCache(64)
+
+	_, err := cache.get(`[unclosed`)
+	require.Error(t, err)
+	assert.Equal(t, 0, cache.len())
+}
+
+func TestRegexCache_Bounded(t *testing.T) {
+	cache := newRegexCache(32)
+
+	for i := 0; i < 1000; i++ {
+		_, err := cache.get(fmt.Sprintf("^pattern-%d$", i))
+		require.NoError(t, err)
+	}
+
+	assert.LessOrEqual(t, cache.len(), 32)
+}
+
+// TestEvaluator_ConcurrentMatches runs matches rules from many goroutines
+// against one shared evaluator. Run with -race.
+func TestEvaluator_ConcurrentMatches(t *testing.T) {
+	evaluator := NewEvaluator()
+
+	const goroutines = 32
+	const iterations = 200
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < iterations; i++ {
+				// Mix shared and goroutine-specific patterns so that cache hits,
+				// misses and evictions all happen concurrently
+				pattern := fmt.Sprintf("^/api/v%d/", (g*iterations+i)%50)
+				rule, err := Parse(fmt.Sprintf(`when { http.where(path matches %q) } always { auth }`, pattern))
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				version := (g*iterations + i) % 50
+				spans := []*models.Span{
+					{OperationName: "http", Attributes: map[string]string{"path": fmt.Sprintf("/api/v%d/users", version)}},
+				}
+
+				result, err := evaluator.EvaluateRule(rule, spans)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.True(t, result, "missing auth span should violate for pattern %s", pattern)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// TestProgram_ConcurrentMatches evaluates compiled matches programs (which bind
+// their regex at compile time) from many goroutines. Run with -race.
+func TestProgram_ConcurrentMatches(t *testing.T) {
+	rule, err := Parse(`when { http.where(path matches "^/admin/.*") } never { public_access }`)
+	require.NoError(t, err)
+	program, err := Compile(rule)
+	require.NoError(t, err)
+
+	violating := []*models.Span{
+		{OperationName: "http", Attributes: map[string]string{"path": "/admin/users"}},
+		{OperationName: "public_access"},
+	}
+	clean := []*models.Span{
+		{OperationName: "http", Attributes: map[string]string{"path": "/public/users"}},
+		{OperationName: "public_access"},
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				result, err := program.Evaluate(violating)
+				assert.NoError(t, err)
+				assert.True(t, result)
+
+				result, err = program.Evaluate(clean)
+				assert.NoError(t, err)
+				assert.False(t, result)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -2,11 +2,14 @@ package services
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/middleware"
 	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/internal/rules"
+	internalServices "github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/pkg/fsm"
 	"github.com/betracehq/betrace/backend/pkg/models"
 	"google.golang.org/grpc/codes"
@@ -23,21 +26,55 @@ type RuleStore interface {
 	List() ([]models.Rule, error)
 }
 
+// RuleVersionStore keeps the immutable version history of rules
+type RuleVersionStore interface {
+	AppendVersion(version models.RuleVersion) error
+	Versions(id string) ([]models.RuleVersion, error) // Oldest first
+}
+
+// unknownAuthor is recorded as the author of changes made without an
+// authenticated user
+const unknownAuthor = "unknown"
+
 // RuleService implements the gRPC RuleService with FSM-based race condition protection
 type RuleService struct {
 	pb.UnimplementedRuleServiceServer
 	engine   *rules.RuleEngine
 	store    RuleStore // Optional: nil means no persistence
 	registry *fsm.RuleLifecycleRegistry // FSM state tracking to prevent race conditions
+	matches  *internalServices.MatchHistory // nil if match history isn't kept
+	versions RuleVersionStore               // nil if version history isn't kept
 }
 
 // NewRuleService creates a new RuleService with FSM protection
 func NewRuleService(engine *rules.RuleEngine, store RuleStore) *RuleService {
-	return &RuleService{
+	s := &RuleService{
 		engine:   engine,
 		store:    store,
 		registry: fsm.NewRuleLifecycleRegistry(),
 	}
+
+	// Rules already loaded (recovered from disk at startup) are persisted,
+	// so they can be updated, enabled, disabled and deleted
+	for _, compiled := range engine.ListRules() {
+		ruleFSM := s.registry.Get(compiled.Rule.ID)
+		for _, event := range []fsm.RuleLifecycleEvent{fsm.EventCreate, fsm.EventValidate, fsm.EventCompile, fsm.EventPersist} {
+			ruleFSM.Transition(event)
+		}
+	}
+	return s
+}
+
+// SetMatchHistory serves ListRuleMatches from history and forgets the
+// matches of deleted rules
+func (s *RuleService) SetMatchHistory(history *internalServices.MatchHistory) {
+	s.matches = history
+}
+
+// SetVersionStore records every rule change as a version in store and serves
+// ListRuleVersions, DiffRuleVersions and RollbackRule from it
+func (s *RuleService) SetVersionStore(store RuleVersionStore) {
+	s.versions = store
 }
 
 // ListRules returns all rules
@@ -45,8 +82,21 @@ func (s *RuleService) ListRules(ctx context.Context, req *pb.ListRulesRequest) (
 	ctx, span := observability.Tracer.Start(ctx, "RuleService.ListRules")
 	defer span.End()
 
-	observability.Debug(ctx, "ListRules: enabled_only=%v severity=%s tags=%v",
-		req.EnabledOnly, req.Severity, req.Tags)
+	observability.Debug(ctx, "ListRules: enabled_only=%v severity=%s tags=%v framework=%s control=%s",
+		req.EnabledOnly, req.Severity, req.Tags, req.Framework, req.Control)
+
+	var framework observability.ComplianceFramework
+	if req.Framework != "" {
+		var ok bool
+		if framework, ok = observability.ParseComplianceFramework(req.Framework); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown compliance framework: %s", req.Framework)
+		}
+	} else if req.Control != "" {
+		return nil, status.Error(codes.InvalidArgument, "control filter requires a framework")
+	}
+	if req.Mode != "" && req.Mode != models.RuleModeEnforcing && req.Mode != models.RuleModeShadow {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown rule mode: %s", req.Mode)
+	}
 
 	allRules := s.engine.ListRules()
 	observability.Debug(ctx, "ListRules: found %d total rules", len(allRules))
@@ -77,6 +127,12 @@ func (s *RuleService) ListRules(ctx context.Context, req *pb.ListRulesRequest) (
 				continue
 			}
 		}
+		if framework != "" && !mapsControl(r.Rule, framework, req.Control) {
+			continue
+		}
+		if req.Mode != "" && r.Rule.EffectiveMode() != req.Mode {
+			continue
+		}
 		filteredRules = append(filteredRules, r)
 	}
 
@@ -135,6 +191,8 @@ func (s *RuleService) CreateRule(ctx context.Context, req *pb.CreateRuleRequest)
 		Enabled:     req.Enabled,
 		Severity:    req.Severity,
 		Tags:        req.Tags,
+		Controls:    controlsFromProto(req.Controls),
+		Mode:        req.Mode,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -150,6 +208,20 @@ func (s *RuleService) CreateRule(ctx context.Context, req *pb.CreateRuleRequest)
 		observability.Error(ctx, "CreateRule: validation failed: %v", err)
 		return nil, status.Errorf(codes.InvalidArgument, "rule validation failed: %v", err)
 	}
+	controls, err := internalServices.NormalizeControls(rule.Controls)
+	if err != nil {
+		ruleFSM.Transition(fsm.EventValidationFailed)
+		observability.Error(ctx, "CreateRule: validation failed: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "rule validation failed: %v", err)
+	}
+	rule.Controls = controls
+	rule.Mode = rule.EffectiveMode()
+
+	// A recreated rule continues the history of the deleted one
+	if rule.Version, err = s.nextVersion(nil, rule.ID); err != nil {
+		ruleFSM.Transition(fsm.EventValidationFailed)
+		return nil, status.Errorf(codes.Internal, "failed to read rule versions: %v", err)
+	}
 
 	if err := ruleFSM.Transition(fsm.EventValidate); err != nil {
 		return nil, status.Errorf(codes.Internal, "FSM transition failed: %v", err)
@@ -176,6 +248,15 @@ func (s *RuleService) CreateRule(ctx context.Context, req *pb.CreateRuleRequest)
 			return nil, status.Errorf(codes.Internal, "failed to persist rule: %v", err)
 		}
 	}
+	if err := s.appendVersion(ctx, rule, models.RuleChangeCreated, 0); err != nil {
+		if s.store != nil {
+			s.store.Delete(rule.ID)
+		}
+		s.engine.DeleteRule(rule.ID)
+		ruleFSM.Transition(fsm.EventPersistenceFailed)
+		observability.Error(ctx, "CreateRule: failed to record rule version: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to record rule version: %v", err)
+	}
 
 	// Phase 5: Mark as persisted (terminal state)
 	if err := ruleFSM.Transition(fsm.EventPersist); err != nil {
@@ -185,6 +266,7 @@ func (s *RuleService) CreateRule(ctx context.Context, req *pb.CreateRuleRequest)
 	observability.EmitComplianceEvidence(ctx, observability.SOC2_CC8_1, "rule_created", map[string]interface{}{
 		"rule_id":    rule.ID,
 		"expression": rule.Expression,
+		"version":    rule.Version,
 	})
 
 	observability.Info(ctx, "CreateRule: success id=%s", rule.ID)
@@ -200,7 +282,28 @@ func (s *RuleService) UpdateRule(ctx context.Context, req *pb.UpdateRuleRequest)
 
 	observability.Info(ctx, "UpdateRule: id=%s", req.Id)
 
-	ruleFSM := s.registry.Get(req.Id)
+	rule := models.Rule{
+		ID:          req.Id,
+		Name:        req.Name,
+		Description: req.Description,
+		Expression:  req.Expression,
+		Enabled:     req.Enabled,
+		Severity:    req.Severity,
+		Tags:        req.Tags,
+		Controls:    controlsFromProto(req.Controls),
+		Mode:        req.Mode,
+	}
+	return s.updateRule(ctx, req.Id, models.RuleChangeUpdated, 0, func(models.Rule) (models.Rule, error) {
+		return rule, nil
+	})
+}
+
+// updateRule replaces a rule with FSM-based atomicity, recording the change
+// as a new version. apply builds the new rule from the current one, read
+// while the FSM blocks every other change to the rule. restored is the
+// version a rollback restores.
+func (s *RuleService) updateRule(ctx context.Context, id string, change string, restored int, apply func(current models.Rule) (models.Rule, error)) (*pb.Rule, error) {
+	ruleFSM := s.registry.Get(id)
 
 	// Phase 1: Atomically transition to RuleUpdating
 	// FIXES Bug #1: This blocks concurrent Delete operations
@@ -213,21 +316,22 @@ func (s *RuleService) UpdateRule(ctx context.Context, req *pb.UpdateRuleRequest)
 	// (EventDelete is invalid from RuleUpdating state)
 
 	// Save old rule for rollback on failure
-	oldRule, ok := s.engine.GetRule(req.Id)
+	oldRule, ok := s.engine.GetRule(id)
 	if !ok {
 		ruleFSM.Rollback()
-		return nil, status.Errorf(codes.NotFound, "rule not found: %s", req.Id)
+		return nil, status.Errorf(codes.NotFound, "rule not found: %s", id)
 	}
-
-	rule := models.Rule{
-		ID:          req.Id,
-		Name:        req.Name,
-		Description: req.Description,
-		Expression:  req.Expression,
-		Enabled:     req.Enabled,
-		Severity:    req.Severity,
-		Tags:        req.Tags,
-		UpdatedAt:   time.Now(),
+	rule, err := apply(oldRule.Rule)
+	if err != nil {
+		ruleFSM.Rollback()
+		return nil, err
+	}
+	rule.ID = id
+	rule.CreatedAt = oldRule.Rule.CreatedAt
+	rule.UpdatedAt = time.Now()
+	if rule.Mode == "" {
+		// Only PromoteRule switches a shadow rule to enforcing
+		rule.Mode = oldRule.Rule.Mode
 	}
 
 	// Phase 2: Validate
@@ -241,6 +345,17 @@ func (s *RuleService) UpdateRule(ctx context.Context, req *pb.UpdateRuleRequest)
 		observability.Error(ctx, "UpdateRule: validation failed: %v", err)
 		return nil, status.Errorf(codes.InvalidArgument, "rule validation failed: %v", err)
 	}
+	controls, err := internalServices.NormalizeControls(rule.Controls)
+	if err != nil {
+		ruleFSM.Rollback()
+		observability.Error(ctx, "UpdateRule: validation failed: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "rule validation failed: %v", err)
+	}
+	rule.Controls = controls
+	if rule.Version, err = s.nextVersion(&oldRule.Rule, rule.ID); err != nil {
+		ruleFSM.Rollback()
+		return nil, status.Errorf(codes.Internal, "failed to read rule versions: %v", err)
+	}
 
 	if err := ruleFSM.Transition(fsm.EventValidate); err != nil {
 		ruleFSM.Rollback()
@@ -270,15 +385,25 @@ func (s *RuleService) UpdateRule(ctx context.Context, req *pb.UpdateRuleRequest)
 			return nil, status.Errorf(codes.Internal, "failed to persist rule: %v", err)
 		}
 	}
+	if err := s.appendVersion(ctx, rule, change, restored); err != nil {
+		if s.store != nil {
+			s.store.Update(oldRule.Rule)
+		}
+		s.engine.LoadRule(oldRule.Rule)
+		ruleFSM.Transition(fsm.EventPersistenceFailed)
+		observability.Error(ctx, "UpdateRule: failed to record rule version (rolled back): %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to record rule version: %v", err)
+	}
 
 	// Phase 5: Mark as persisted
 	if err := ruleFSM.Transition(fsm.EventPersist); err != nil {
 		return nil, status.Errorf(codes.Internal, "FSM transition failed: %v", err)
 	}
 
-	observability.EmitComplianceEvidence(ctx, observability.SOC2_CC8_1, "rule_updated", map[string]interface{}{
+	observability.EmitComplianceEvidence(ctx, observability.SOC2_CC8_1, "rule_"+change, map[string]interface{}{
 		"rule_id":    rule.ID,
 		"expression": rule.Expression,
+		"version":    rule.Version,
 	})
 
 	return modelToProto(&rule), nil
@@ -315,6 +440,9 @@ func (s *RuleService) DeleteRule(ctx context.Context, req *pb.DeleteRuleRequest)
 
 	// Phase 3: Delete from engine (only AFTER disk succeeds)
 	s.engine.DeleteRule(req.Id)
+	if s.matches != nil {
+		s.matches.Forget(req.Id)
+	}
 
 	// Phase 4: Mark as deleted
 	if err := ruleFSM.Transition(fsm.EventDeleteComplete); err != nil {
@@ -339,66 +467,328 @@ func (s *RuleService) EnableRule(ctx context.Context, req *pb.EnableRuleRequest)
 
 	observability.Info(ctx, "EnableRule: id=%s", req.Id)
 
-	compiled, ok := s.engine.GetRule(req.Id)
-	if !ok {
+	if _, ok := s.engine.GetRule(req.Id); !ok {
 		return nil, status.Errorf(codes.NotFound, "rule not found: %s", req.Id)
 	}
+	return s.updateRule(ctx, req.Id, models.RuleChangeEnabled, 0, func(current models.Rule) (models.Rule, error) {
+		current.Enabled = true
+		return current, nil
+	})
+}
 
-	rule := compiled.Rule
-	rule.Enabled = true
-	rule.UpdatedAt = time.Now()
+// DisableRule disables an enabled rule
+// FIXES: Bug #3 (enable/disable not persisted to disk)
+func (s *RuleService) DisableRule(ctx context.Context, req *pb.DisableRuleRequest) (*pb.Rule, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.DisableRule")
+	defer span.End()
 
-	// FIXES Bug #3: Persist to disk FIRST (crash-safe)
-	if s.store != nil {
-		if err := s.store.Update(rule); err != nil {
-			observability.Error(ctx, "EnableRule: failed to persist: %v", err)
-			return nil, status.Errorf(codes.Internal, "failed to persist enable state: %v", err)
+	observability.Info(ctx, "DisableRule: id=%s", req.Id)
+
+	if _, ok := s.engine.GetRule(req.Id); !ok {
+		return nil, status.Errorf(codes.NotFound, "rule not found: %s", req.Id)
+	}
+	return s.updateRule(ctx, req.Id, models.RuleChangeDisabled, 0, func(current models.Rule) (models.Rule, error) {
+		current.Enabled = false
+		return current, nil
+	})
+}
+
+// PromoteRule switches a shadow rule to enforcing. Its violations go to the
+// real violation store and stream from then on; earlier would-be violations
+// stay in the shadow store.
+func (s *RuleService) PromoteRule(ctx context.Context, req *pb.PromoteRuleRequest) (*pb.Rule, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.PromoteRule")
+	defer span.End()
+
+	observability.Info(ctx, "PromoteRule: id=%s", req.Id)
+
+	if _, ok := s.engine.GetRule(req.Id); !ok {
+		return nil, status.Errorf(codes.NotFound, "rule not found: %s", req.Id)
+	}
+	return s.updateRule(ctx, req.Id, models.RuleChangePromoted, 0, func(current models.Rule) (models.Rule, error) {
+		if !current.IsShadow() {
+			return current, status.Errorf(codes.FailedPrecondition, "rule %s is already enforcing", req.Id)
+		}
+		current.Mode = models.RuleModeEnforcing
+		return current, nil
+	})
+}
+
+// ListRuleMatches returns a page of the traces a rule recently applied to
+func (s *RuleService) ListRuleMatches(ctx context.Context, req *pb.ListRuleMatchesRequest) (*pb.ListRuleMatchesResponse, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.ListRuleMatches")
+	defer span.End()
+
+	if s.matches == nil {
+		return nil, status.Error(codes.Unimplemented, "rule match history is not enabled")
+	}
+	if _, ok := s.engine.GetRule(req.Id); !ok {
+		return nil, status.Errorf(codes.NotFound, "rule not found: %s", req.Id)
+	}
+
+	filter := internalServices.MatchFilter{
+		Outcome: req.Outcome,
+		Cursor:  req.Cursor,
+		Limit:   defaultViolationPageSize,
+	}
+	if req.StartTime != nil {
+		filter.Since = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		filter.Until = req.EndTime.AsTime()
+	}
+	if req.Limit > 0 {
+		filter.Limit = int(req.Limit)
+	}
+	if filter.Limit > maxViolationPageSize {
+		filter.Limit = maxViolationPageSize
+	}
+
+	page, err := s.matches.Query(req.Id, filter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &pb.ListRuleMatchesResponse{
+		Matches:    make([]*pb.RuleMatch, len(page.Matches)),
+		TotalCount: int32(page.TotalCount),
+		NextCursor: page.NextCursor,
+	}
+	for i, m := range page.Matches {
+		resp.Matches[i] = &pb.RuleMatch{
+			TraceId:   m.TraceID,
+			Service:   m.Service,
+			Outcome:   m.Outcome,
+			MatchedAt: timestamppb.New(m.MatchedAt),
 		}
 	}
 
-	// Load into engine AFTER disk succeeds
-	if err := s.engine.LoadRuleWithObservability(ctx, rule); err != nil {
-		// Rollback disk state (need old rule for full rollback)
-		observability.Error(ctx, "EnableRule: failed to load rule: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to enable rule: %v", err)
+	observability.Debug(ctx, "ListRuleMatches: id=%s returning %d of %d matches", req.Id, len(resp.Matches), resp.TotalCount)
+	return resp, nil
+}
+
+// ListRuleVersions returns a rule's version history, oldest first
+func (s *RuleService) ListRuleVersions(ctx context.Context, req *pb.ListRuleVersionsRequest) (*pb.ListRuleVersionsResponse, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.ListRuleVersions")
+	defer span.End()
+
+	history, err := s.ruleVersions(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListRuleVersionsResponse{Versions: make([]*pb.RuleVersion, len(history))}
+	for i := range history {
+		resp.Versions[i] = ruleVersionToProto(&history[i])
 	}
 
-	return modelToProto(&rule), nil
+	observability.Debug(ctx, "ListRuleVersions: id=%s returning %d versions", req.Id, len(resp.Versions))
+	return resp, nil
 }
 
-// DisableRule disables an enabled rule
-// FIXES: Bug #3 (enable/disable not persisted to disk)
-func (s *RuleService) DisableRule(ctx context.Context, req *pb.DisableRuleRequest) (*pb.Rule, error) {
-	ctx, span := observability.Tracer.Start(ctx, "RuleService.DisableRule")
+// DiffRuleVersions compares two versions of a rule, by default the latest
+// version and the one before it
+func (s *RuleService) DiffRuleVersions(ctx context.Context, req *pb.DiffRuleVersionsRequest) (*pb.DiffRuleVersionsResponse, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.DiffRuleVersions")
 	defer span.End()
 
-	observability.Info(ctx, "DisableRule: id=%s", req.Id)
+	history, err := s.ruleVersions(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	to := int(req.To)
+	if to == 0 {
+		to = history[len(history)-1].Version
+	}
+	from := int(req.From)
+	if from == 0 {
+		from = to - 1
+	}
+	fromVersion, ok := findVersion(history, from)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "rule %s has no version %d", req.Id, from)
+	}
+	toVersion, ok := findVersion(history, to)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "rule %s has no version %d", req.Id, to)
+	}
 
-	compiled, ok := s.engine.GetRule(req.Id)
+	resp := &pb.DiffRuleVersionsResponse{
+		From: ruleVersionToProto(fromVersion),
+		To:   ruleVersionToProto(toVersion),
+	}
+	for _, c := range internalServices.DiffRules(fromVersion.Rule, toVersion.Rule) {
+		resp.Changes = append(resp.Changes, &pb.RuleFieldChange{Field: c.Field, From: c.From, To: c.To})
+	}
+	for _, line := range internalServices.DiffLines(fromVersion.Rule.Expression, toVersion.Rule.Expression) {
+		resp.ExpressionDiff = append(resp.ExpressionDiff, &pb.DiffLine{Op: line.Op, Text: line.Text})
+	}
+
+	observability.Debug(ctx, "DiffRuleVersions: id=%s from=%d to=%d changes=%d", req.Id, from, to, len(resp.Changes))
+	return resp, nil
+}
+
+// RollbackRule restores an earlier version of a rule. The restored rule is
+// recorded as a new version, so the history stays append-only. The rule
+// keeps its current mode and enabled state: rolling back a promoted rule's
+// definition doesn't send it back to shadow, and rolling back a disabled
+// rule doesn't start enforcing it.
+func (s *RuleService) RollbackRule(ctx context.Context, req *pb.RollbackRuleRequest) (*pb.Rule, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.RollbackRule")
+	defer span.End()
+
+	observability.Info(ctx, "RollbackRule: id=%s version=%d", req.Id, req.Version)
+
+	history, err := s.ruleVersions(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	restored, ok := findVersion(history, int(req.Version))
 	if !ok {
-		return nil, status.Errorf(codes.NotFound, "rule not found: %s", req.Id)
+		return nil, status.Errorf(codes.NotFound, "rule %s has no version %d", req.Id, req.Version)
 	}
+	return s.updateRule(ctx, req.Id, models.RuleChangeRolledBack, restored.Version, func(current models.Rule) (models.Rule, error) {
+		rule := restored.Rule
+		rule.Mode = "" // updateRule keeps the current mode
+		rule.Enabled = current.Enabled
+		return rule, nil
+	})
+}
 
-	rule := compiled.Rule
-	rule.Enabled = false
-	rule.UpdatedAt = time.Now()
+// GetControlCoverage lists compliance controls with the rules mapped to them
+func (s *RuleService) GetControlCoverage(ctx context.Context, req *pb.GetControlCoverageRequest) (*pb.GetControlCoverageResponse, error) {
+	ctx, span := observability.Tracer.Start(ctx, "RuleService.GetControlCoverage")
+	defer span.End()
 
-	// FIXES Bug #3: Persist to disk FIRST (crash-safe)
-	if s.store != nil {
-		if err := s.store.Update(rule); err != nil {
-			observability.Error(ctx, "DisableRule: failed to persist: %v", err)
-			return nil, status.Errorf(codes.Internal, "failed to persist disable state: %v", err)
+	var allRules []models.Rule
+	for _, r := range s.engine.ListRules() {
+		allRules = append(allRules, r.Rule)
+	}
+	coverage, err := internalServices.Coverage(allRules, req.Framework)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	resp := &pb.GetControlCoverageResponse{Controls: make([]*pb.ControlCoverage, len(coverage))}
+	for i, c := range coverage {
+		resp.Controls[i] = &pb.ControlCoverage{
+			Framework:    c.Framework,
+			ControlId:    c.ControlID,
+			Name:         c.Name,
+			RuleIds:      c.RuleIDs,
+			EnabledRules: int32(c.EnabledRules),
+			Covered:      c.Covered(),
+		}
+		if c.Covered() {
+			resp.CoveredCount++
+		} else {
+			resp.UncoveredCount++
 		}
 	}
 
-	// Load into engine AFTER disk succeeds
-	if err := s.engine.LoadRuleWithObservability(ctx, rule); err != nil {
-		// Rollback disk state (need old rule for full rollback)
-		observability.Error(ctx, "DisableRule: failed to load rule: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to disable rule: %v", err)
+	observability.Debug(ctx, "GetControlCoverage: %d covered, %d uncovered", resp.CoveredCount, resp.UncoveredCount)
+	return resp, nil
+}
+
+// nextVersion returns the version of the next change to rule id. current is
+// the rule being changed (nil on create); a rule predating versioning has
+// its current definition recorded as version 1 first, so it isn't lost.
+func (s *RuleService) nextVersion(current *models.Rule, id string) (int, error) {
+	next := 1
+	if current != nil {
+		next = current.Version + 1
+	}
+	if s.versions == nil {
+		return next, nil
+	}
+
+	history, err := s.versions.Versions(id)
+	if err != nil {
+		return 0, err
+	}
+	if n := len(history); n > 0 {
+		return max(next, history[n-1].Version+1), nil
+	}
+	if current != nil && current.Version == 0 {
+		baseline := *current
+		baseline.Version = 1
+		if err := s.versions.AppendVersion(models.RuleVersion{
+			RuleID:    id,
+			Version:   1,
+			Change:    models.RuleChangeCreated,
+			Author:    unknownAuthor,
+			CreatedAt: current.UpdatedAt,
+			Rule:      baseline,
+		}); err != nil {
+			return 0, err
+		}
+		return 2, nil
 	}
+	return next, nil
+}
 
-	return modelToProto(&rule), nil
+// appendVersion records rule, which carries its new version, in its history
+func (s *RuleService) appendVersion(ctx context.Context, rule models.Rule, change string, restored int) error {
+	if s.versions == nil {
+		return nil
+	}
+	return s.versions.AppendVersion(models.RuleVersion{
+		RuleID:          rule.ID,
+		Version:         rule.Version,
+		Change:          change,
+		Author:          changeAuthor(ctx),
+		CreatedAt:       rule.UpdatedAt,
+		RestoredVersion: restored,
+		Rule:            rule,
+	})
+}
+
+// ruleVersions returns a rule's history, or the gRPC error if there is none
+func (s *RuleService) ruleVersions(id string) ([]models.RuleVersion, error) {
+	if s.versions == nil {
+		return nil, status.Error(codes.Unimplemented, "rule version history is not enabled")
+	}
+	history, err := s.versions.Versions(id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read rule versions: %v", err)
+	}
+	if len(history) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no versions of rule: %s", id)
+	}
+	return history, nil
+}
+
+// findVersion returns the given version from a rule's history
+func findVersion(history []models.RuleVersion, version int) (*models.RuleVersion, bool) {
+	for i := range history {
+		if history[i].Version == version {
+			return &history[i], true
+		}
+	}
+	return nil, false
+}
+
+// changeAuthor names the authenticated user making a change to a rule or a
+// violation's triage: their email, else their user ID
+func changeAuthor(ctx context.Context) string {
+	if email := middleware.EmailFromContext(ctx); email != "" {
+		return email
+	}
+	if userID := middleware.UserIDFromContext(ctx); userID != "" {
+		return userID
+	}
+	return unknownAuthor
+}
+
+// Helper: Convert models.RuleVersion to pb.RuleVersion
+func ruleVersionToProto(v *models.RuleVersion) *pb.RuleVersion {
+	return &pb.RuleVersion{
+		RuleId:          v.RuleID,
+		Version:         int32(v.Version),
+		Change:          v.Change,
+		Author:          v.Author,
+		CreatedAt:       timestamppb.New(v.CreatedAt),
+		RestoredVersion: int32(v.RestoredVersion),
+		Rule:            modelToProto(&v.Rule),
+	}
 }
 
 // Helper: Convert models.Rule to pb.Rule
@@ -411,7 +801,38 @@ func modelToProto(r *models.Rule) *pb.Rule {
 		Enabled:     r.Enabled,
 		Severity:    r.Severity,
 		Tags:        r.Tags,
+		Controls:    controlsToProto(r.Controls),
+		Version:     int32(r.Version),
+		Mode:        r.EffectiveMode(),
 		CreatedAt:   timestamppb.New(r.CreatedAt),
 		UpdatedAt:   timestamppb.New(r.UpdatedAt),
 	}
 }
+
+// Helper: Convert pb.ComplianceControl mappings to models
+func controlsFromProto(controls []*pb.ComplianceControl) []models.ComplianceControl {
+	var result []models.ComplianceControl
+	for _, c := range controls {
+		result = append(result, models.ComplianceControl{Framework: c.Framework, ControlID: c.ControlId})
+	}
+	return result
+}
+
+// Helper: Convert models.ComplianceControl mappings to pb
+func controlsToProto(controls []models.ComplianceControl) []*pb.ComplianceControl {
+	var result []*pb.ComplianceControl
+	for _, c := range controls {
+		result = append(result, &pb.ComplianceControl{Framework: c.Framework, ControlId: c.ControlID})
+	}
+	return result
+}
+
+// mapsControl reports whether rule is mapped to framework and, if set, controlID
+func mapsControl(rule models.Rule, framework observability.ComplianceFramework, controlID string) bool {
+	for _, control := range observability.RuleControls(rule) {
+		if control.Framework == framework && (controlID == "" || strings.EqualFold(control.ControlID, controlID)) {
+			return true
+		}
+	}
+	return false
+}
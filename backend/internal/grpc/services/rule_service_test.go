@@ -2,14 +2,20 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/middleware"
 	"github.com/betracehq/betrace/backend/internal/rules"
+	internalServices "github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/fsm"
 	"github.com/betracehq/betrace/backend/pkg/models"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TestListRules_NoFilters tests listing all rules without filters
@@ -74,6 +80,354 @@ func TestListRules_NoFilters(t *testing.T) {
 	}
 }
 
+// TestRuleControls_CreateFilterAndCoverage tests compliance control mappings
+func TestRuleControls_CreateFilterAndCoverage(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	store, err := storage.NewDiskRuleStoreWithFS("data", storage.NewMockFileSystem())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	service := NewRuleService(engine, store)
+	ctx := context.Background()
+
+	created, err := service.CreateRule(ctx, &pb.CreateRuleRequest{
+		Name:       "admin-mfa",
+		Expression: "when { admin_login } always { mfa }",
+		Enabled:    true,
+		Controls:   []*pb.ComplianceControl{{Framework: "SOC 2", ControlId: "cc6.1"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+	if len(created.Controls) != 1 || created.Controls[0].Framework != "soc2" || created.Controls[0].ControlId != "CC6.1" {
+		t.Errorf("Expected the normalized soc2 CC6.1 mapping, got %v", created.Controls)
+	}
+	if persisted, _ := store.Get("admin-mfa"); len(persisted.Controls) != 1 {
+		t.Errorf("Expected the mapping to be persisted, got %+v", persisted)
+	}
+	if _, err := service.CreateRule(ctx, &pb.CreateRuleRequest{
+		Name:       "latency",
+		Expression: "when { request } always { fast }",
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+
+	_, err = service.CreateRule(ctx, &pb.CreateRuleRequest{
+		Name:       "pci",
+		Expression: "when { card } always { vault }",
+		Controls:   []*pb.ComplianceControl{{Framework: "pci-dss", ControlId: "3.4"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown framework, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		req  *pb.ListRulesRequest
+		want int
+	}{
+		{&pb.ListRulesRequest{Framework: "soc2"}, 1},
+		{&pb.ListRulesRequest{Framework: "SOC2", Control: "CC6.1"}, 1},
+		{&pb.ListRulesRequest{Framework: "soc2", Control: "CC7.1"}, 0},
+		{&pb.ListRulesRequest{Framework: "hipaa"}, 0},
+	} {
+		resp, err := service.ListRules(ctx, tc.req)
+		if err != nil {
+			t.Fatalf("ListRules(%v) failed: %v", tc.req, err)
+		}
+		if len(resp.Rules) != tc.want {
+			t.Errorf("ListRules(%v): expected %d rules, got %d", tc.req, tc.want, len(resp.Rules))
+		}
+	}
+	if _, err := service.ListRules(ctx, &pb.ListRulesRequest{Control: "CC6.1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a control without a framework, got %v", err)
+	}
+
+	coverage, err := service.GetControlCoverage(ctx, &pb.GetControlCoverageRequest{Framework: "soc2"})
+	if err != nil {
+		t.Fatalf("GetControlCoverage failed: %v", err)
+	}
+	if coverage.CoveredCount != 1 || coverage.UncoveredCount != int32(len(coverage.Controls)-1) {
+		t.Errorf("Expected one covered soc2 control, got %d covered and %d uncovered", coverage.CoveredCount, coverage.UncoveredCount)
+	}
+	for _, c := range coverage.Controls {
+		if c.Covered != (c.ControlId == "CC6.1") {
+			t.Errorf("Unexpected coverage for %s: %v", c.ControlId, c)
+		}
+	}
+}
+
+// TestListRuleMatches tests paging through a rule's match history
+func TestRuleVersions_ListDiffRollback(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	store, err := storage.NewDiskRuleStoreWithFS("data", storage.NewMockFileSystem())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	service := NewRuleService(engine, store)
+	ctx := context.Background()
+
+	if _, err := service.ListRuleVersions(ctx, &pb.ListRuleVersionsRequest{Id: "mfa"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a version store, got %v", err)
+	}
+	service.SetVersionStore(store)
+
+	alice := context.WithValue(ctx, middleware.ContextKeyEmail, "alice@example.com")
+	if _, err := service.CreateRule(alice, &pb.CreateRuleRequest{Name: "mfa", Expression: "when { admin_login }\nalways { mfa }", Enabled: true, Severity: "HIGH"}); err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+	updated, err := service.UpdateRule(ctx, &pb.UpdateRuleRequest{Id: "mfa", Name: "mfa", Expression: "when { admin_login }\nalways { mfa_passed }", Enabled: true, Severity: "LOW"})
+	if err != nil {
+		t.Fatalf("UpdateRule failed: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("Expected the update to be version 2, got %d", updated.Version)
+	}
+	if _, err := service.DisableRule(ctx, &pb.DisableRuleRequest{Id: "mfa"}); err != nil {
+		t.Fatalf("DisableRule failed: %v", err)
+	}
+
+	rolledBack, err := service.RollbackRule(alice, &pb.RollbackRuleRequest{Id: "mfa", Version: 1})
+	if err != nil {
+		t.Fatalf("RollbackRule failed: %v", err)
+	}
+	if rolledBack.Version != 4 || rolledBack.Expression != "when { admin_login }\nalways { mfa }" || rolledBack.Enabled || rolledBack.Severity != "HIGH" {
+		t.Errorf("Expected version 1 restored as version 4, still disabled, got %v", rolledBack)
+	}
+	if compiled, _ := engine.GetRule("mfa"); compiled.Rule.Version != 4 {
+		t.Errorf("Expected the engine to run version 4, got %d", compiled.Rule.Version)
+	}
+
+	versions, err := service.ListRuleVersions(ctx, &pb.ListRuleVersionsRequest{Id: "mfa"})
+	if err != nil {
+		t.Fatalf("ListRuleVersions failed: %v", err)
+	}
+	var changes []string
+	for _, v := range versions.Versions {
+		changes = append(changes, fmt.Sprintf("%d:%s:%s", v.Version, v.Change, v.Author))
+	}
+	expected := "[1:created:alice@example.com 2:updated:unknown 3:disabled:unknown 4:rolled_back:alice@example.com]"
+	if fmt.Sprint(changes) != expected {
+		t.Errorf("Expected versions %s, got %v", expected, changes)
+	}
+	if versions.Versions[3].RestoredVersion != 1 {
+		t.Errorf("Expected the rollback to record the restored version, got %d", versions.Versions[3].RestoredVersion)
+	}
+
+	diff, err := service.DiffRuleVersions(ctx, &pb.DiffRuleVersionsRequest{Id: "mfa", From: 1, To: 2})
+	if err != nil {
+		t.Fatalf("DiffRuleVersions failed: %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Field != "severity" || diff.Changes[0].From != "HIGH" || diff.Changes[0].To != "LOW" {
+		t.Errorf("Expected only the severity to change, got %v", diff.Changes)
+	}
+	var lines []string
+	for _, line := range diff.ExpressionDiff {
+		lines = append(lines, line.Op+line.Text)
+	}
+	if fmt.Sprint(lines) != "[ when { admin_login } -always { mfa } +always { mfa_passed }]" {
+		t.Errorf("Unexpected expression diff: %v", lines)
+	}
+
+	// Defaults compare the latest version with the one before it
+	diff, err = service.DiffRuleVersions(ctx, &pb.DiffRuleVersionsRequest{Id: "mfa"})
+	if err != nil || diff.From.Version != 3 || diff.To.Version != 4 {
+		t.Errorf("Expected a diff of versions 3 and 4, got %v (%v)", diff, err)
+	}
+	if _, err := service.RollbackRule(ctx, &pb.RollbackRuleRequest{Id: "mfa", Version: 9}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a missing version, got %v", err)
+	}
+
+	// History survives deletion, and a recreated rule continues it
+	if _, err := service.DeleteRule(ctx, &pb.DeleteRuleRequest{Id: "mfa"}); err != nil {
+		t.Fatalf("DeleteRule failed: %v", err)
+	}
+	recreated, err := service.CreateRule(ctx, &pb.CreateRuleRequest{Name: "mfa", Expression: "when { admin_login } always { mfa }"})
+	if err != nil || recreated.Version != 5 {
+		t.Errorf("Expected the recreated rule to be version 5, got %v (%v)", recreated, err)
+	}
+}
+
+func TestRuleVersions_RecordsRulesPredatingVersioning(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	store, err := storage.NewDiskRuleStoreWithFS("data", storage.NewMockFileSystem())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	legacy := models.Rule{ID: "legacy", Name: "legacy", Expression: "when { a } always { b }", Enabled: true}
+	if err := store.Create(legacy); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := engine.LoadRule(legacy); err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+	service := NewRuleService(engine, store)
+	service.SetVersionStore(store)
+
+	if _, err := service.DisableRule(context.Background(), &pb.DisableRuleRequest{Id: "legacy"}); err != nil {
+		t.Fatalf("DisableRule failed: %v", err)
+	}
+	versions, _ := store.Versions("legacy")
+	if len(versions) != 2 || !versions[0].Rule.Enabled || versions[0].Author != "unknown" || versions[1].Version != 2 || versions[1].Rule.Enabled {
+		t.Errorf("Expected the original definition kept as version 1, got %+v", versions)
+	}
+}
+
+func TestRuleVersions_EnableDisableWaitForOtherChanges(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	store, err := storage.NewDiskRuleStoreWithFS("data", storage.NewMockFileSystem())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	service := NewRuleService(engine, store)
+	service.SetVersionStore(store)
+	ctx := context.Background()
+	if _, err := service.CreateRule(ctx, &pb.CreateRuleRequest{Name: "mfa", Expression: "when { admin_login } always { mfa }", Enabled: true}); err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+
+	// An update in progress holds the rule until it persists or rolls back
+	ruleFSM := service.registry.Get("mfa")
+	if err := ruleFSM.Transition(fsm.EventUpdate); err != nil {
+		t.Fatalf("Transition failed: %v", err)
+	}
+	if _, err := service.DisableRule(ctx, &pb.DisableRuleRequest{Id: "mfa"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition while the rule is changing, got %v", err)
+	}
+	if _, err := service.EnableRule(ctx, &pb.EnableRuleRequest{Id: "mfa"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition while the rule is changing, got %v", err)
+	}
+	if versions, _ := store.Versions("mfa"); len(versions) != 1 {
+		t.Errorf("Expected no versions recorded while the rule is changing, got %+v", versions)
+	}
+	ruleFSM.Rollback()
+
+	disabled, err := service.DisableRule(ctx, &pb.DisableRuleRequest{Id: "mfa"})
+	if err != nil || disabled.Enabled || disabled.Version != 2 {
+		t.Fatalf("Expected version 2 disabled, got %v (%v)", disabled, err)
+	}
+	if persisted, err := store.Get("mfa"); err != nil || persisted.Enabled || persisted.Version != 2 {
+		t.Errorf("Expected the store to hold version 2 disabled, got %+v (%v)", persisted, err)
+	}
+}
+
+func TestShadowRules_CreateFilterPromote(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	store, err := storage.NewDiskRuleStoreWithFS("data", storage.NewMockFileSystem())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	service := NewRuleService(engine, store)
+	service.SetVersionStore(store)
+	ctx := context.Background()
+
+	created, err := service.CreateRule(ctx, &pb.CreateRuleRequest{Name: "new-auth", Expression: "when { payment } always { auth }", Enabled: true, Mode: models.RuleModeShadow})
+	if err != nil || created.Mode != models.RuleModeShadow {
+		t.Fatalf("Expected a shadow rule, got %v (%v)", created, err)
+	}
+	if created, err := service.CreateRule(ctx, &pb.CreateRuleRequest{Name: "old-auth", Expression: "when { login } always { auth }", Enabled: true}); err != nil || created.Mode != models.RuleModeEnforcing {
+		t.Fatalf("Expected rules to enforce by default, got %v (%v)", created, err)
+	}
+	if _, err := service.CreateRule(ctx, &pb.CreateRuleRequest{Name: "bad", Expression: "when { a } always { b }", Mode: "dry-run"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown mode, got %v", err)
+	}
+
+	shadow, err := service.ListRules(ctx, &pb.ListRulesRequest{Mode: models.RuleModeShadow})
+	if err != nil || len(shadow.Rules) != 1 || shadow.Rules[0].Id != "new-auth" {
+		t.Errorf("Expected only the shadow rule, got %v (%v)", shadow, err)
+	}
+
+	// Updates that don't set a mode keep the rule in shadow
+	updated, err := service.UpdateRule(ctx, &pb.UpdateRuleRequest{Id: "new-auth", Name: "new-auth", Expression: "when { payment } always { auth and fraud_check }", Enabled: true})
+	if err != nil || updated.Mode != models.RuleModeShadow {
+		t.Errorf("Expected the update to keep shadow mode, got %v (%v)", updated, err)
+	}
+
+	promoted, err := service.PromoteRule(ctx, &pb.PromoteRuleRequest{Id: "new-auth"})
+	if err != nil || promoted.Mode != models.RuleModeEnforcing || promoted.Expression != updated.Expression {
+		t.Fatalf("Expected the rule promoted unchanged, got %v (%v)", promoted, err)
+	}
+	if compiled, _ := engine.GetRule("new-auth"); compiled.Rule.IsShadow() {
+		t.Error("Expected the engine to run the promoted rule as enforcing")
+	}
+	versions, _ := store.Versions("new-auth")
+	if last := versions[len(versions)-1]; last.Change != models.RuleChangePromoted || last.Version != 3 {
+		t.Errorf("Expected the promotion recorded as version 3, got %+v", last)
+	}
+
+	// Rolling back the definition keeps the rule enforcing
+	rolledBack, err := service.RollbackRule(ctx, &pb.RollbackRuleRequest{Id: "new-auth", Version: 1})
+	if err != nil || rolledBack.Mode != models.RuleModeEnforcing || rolledBack.Expression != created.Expression {
+		t.Fatalf("Expected version 1's expression restored as enforcing, got %v (%v)", rolledBack, err)
+	}
+	if compiled, _ := engine.GetRule("new-auth"); compiled.Rule.IsShadow() {
+		t.Error("Expected the engine to keep running the rolled back rule as enforcing")
+	}
+
+	if _, err := service.PromoteRule(ctx, &pb.PromoteRuleRequest{Id: "new-auth"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition promoting an enforcing rule, got %v", err)
+	}
+	if _, err := service.PromoteRule(ctx, &pb.PromoteRuleRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestListRuleMatches(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	service := NewRuleService(engine, nil)
+	ctx := context.Background()
+
+	if _, err := service.ListRuleMatches(ctx, &pb.ListRuleMatchesRequest{Id: "payment-auth"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without match history, got %v", err)
+	}
+
+	history := internalServices.NewMatchHistory(0)
+	service.SetMatchHistory(history)
+	if _, err := service.CreateRule(ctx, &pb.CreateRuleRequest{Name: "payment-auth", Expression: "when { payment } always { auth }", Enabled: true}); err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+	base := time.Now().Add(-time.Hour)
+	for i, outcome := range []string{internalServices.MatchSatisfied, internalServices.MatchViolated, internalServices.MatchSatisfied} {
+		history.Record(internalServices.RuleMatch{RuleID: "payment-auth", TraceID: fmt.Sprintf("trace-%d", i), Outcome: outcome, MatchedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	resp, err := service.ListRuleMatches(ctx, &pb.ListRuleMatchesRequest{Id: "payment-auth", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListRuleMatches failed: %v", err)
+	}
+	if resp.TotalCount != 3 || len(resp.Matches) != 2 || resp.Matches[0].TraceId != "trace-2" || resp.NextCursor == "" {
+		t.Errorf("Expected the first page of 3 matches, got %v", resp)
+	}
+	resp, err = service.ListRuleMatches(ctx, &pb.ListRuleMatchesRequest{Id: "payment-auth", Cursor: resp.NextCursor})
+	if err != nil || len(resp.Matches) != 1 || resp.Matches[0].TraceId != "trace-0" || resp.NextCursor != "" {
+		t.Errorf("Expected the last match on the second page, got %v (%v)", resp, err)
+	}
+	resp, err = service.ListRuleMatches(ctx, &pb.ListRuleMatchesRequest{
+		Id:        "payment-auth",
+		Outcome:   internalServices.MatchViolated,
+		StartTime: timestamppb.New(base),
+		EndTime:   timestamppb.New(base.Add(2 * time.Minute)),
+	})
+	if err != nil || len(resp.Matches) != 1 || resp.Matches[0].TraceId != "trace-1" {
+		t.Errorf("Expected the violating match in range, got %v (%v)", resp, err)
+	}
+
+	if _, err := service.ListRuleMatches(ctx, &pb.ListRuleMatchesRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown rule, got %v", err)
+	}
+	if _, err := service.ListRuleMatches(ctx, &pb.ListRuleMatchesRequest{Id: "payment-auth", Cursor: "bogus"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad cursor, got %v", err)
+	}
+
+	// Deleting the rule forgets its matches
+	if _, err := service.DeleteRule(ctx, &pb.DeleteRuleRequest{Id: "payment-auth"}); err != nil {
+		t.Fatalf("DeleteRule failed: %v", err)
+	}
+	if page, _ := history.Query("payment-auth", internalServices.MatchFilter{}); page.TotalCount != 0 {
+		t.Errorf("Expected the deleted rule's matches to be dropped, got %d", page.TotalCount)
+	}
+}
+
 // TestListRules_EnabledOnly tests filtering by enabled status
 func TestListRules_EnabledOnly(t *testing.T) {
 	engine := rules.NewRuleEngine()
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/dsl"
+	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/internal/rules"
 	internalServices "github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/pkg/models"
@@ -14,20 +16,35 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// serviceNameAttribute is the OpenTelemetry attribute naming a span's service
+const serviceNameAttribute = "service.name"
+
 // SpanService implements the gRPC SpanService
 type SpanService struct {
 	pb.UnimplementedSpanServiceServer
 	engine         *rules.RuleEngine
-	violationStore *internalServices.ViolationStoreMemory
+	violationStore internalServices.ViolationStore
 	traceBuffer    *internalServices.TraceBufferFSM
+	fingerprinter  *internalServices.Fingerprinter     // nil if violations aren't grouped
+	hub            *internalServices.ViolationHub      // nil if nobody streams violations
+	evidence       *internalServices.EvidenceRecorder  // nil if evidence isn't captured
+	evaluations    *internalServices.EvaluationCounter // nil if evaluations aren't counted
+	matches        *internalServices.MatchHistory      // nil if match history isn't kept
+	shadowStore    internalServices.ViolationStore     // nil drops shadow rules' violations
+	shadowHub      *internalServices.ViolationHub      // nil if nobody streams shadow violations
+	archive        *internalServices.TraceArchive      // nil if traces aren't archived for backtests
 }
 
-// NewSpanService creates a new span service
-func NewSpanService(engine *rules.RuleEngine, violationStore *internalServices.ViolationStoreMemory) *SpanService {
+// NewSpanService creates a new span service. Violations are fingerprinted
+// with the span data in hand when violationStore is an *IncidentStore.
+func NewSpanService(engine *rules.RuleEngine, violationStore internalServices.ViolationStore) *SpanService {
 	s := &SpanService{
 		engine:         engine,
 		violationStore: violationStore,
 	}
+	if incidents, ok := violationStore.(*internalServices.IncidentStore); ok {
+		s.fingerprinter = incidents.Fingerprinter()
+	}
 
 	// Create FSM-enhanced trace buffer with 3 second timeout
 	// FSM prevents race conditions between adding spans and evaluation
@@ -36,6 +53,39 @@ func NewSpanService(engine *rules.RuleEngine, violationStore *internalServices.V
 	return s
 }
 
+// SetViolationHub publishes every recorded violation to hub for live watchers
+func (s *SpanService) SetViolationHub(hub *internalServices.ViolationHub) {
+	s.hub = hub
+}
+
+// SetEvidenceRecorder snapshots the rule and spans behind every violation
+func (s *SpanService) SetEvidenceRecorder(evidence *internalServices.EvidenceRecorder) {
+	s.evidence = evidence
+}
+
+// SetEvaluationCounter counts every trace-level rule evaluation by outcome
+func (s *SpanService) SetEvaluationCounter(evaluations *internalServices.EvaluationCounter) {
+	s.evaluations = evaluations
+}
+
+// SetMatchHistory records every trace a rule's when clause matches
+func (s *SpanService) SetMatchHistory(matches *internalServices.MatchHistory) {
+	s.matches = matches
+}
+
+// SetShadowStore records the would-be violations of shadow rules in store,
+// publishing them to hub, instead of the violation store. Without one they
+// are only counted.
+func (s *SpanService) SetShadowStore(store internalServices.ViolationStore, hub *internalServices.ViolationHub) {
+	s.shadowStore = store
+	s.shadowHub = hub
+}
+
+// SetTraceArchive archives every completed trace for backtests
+func (s *SpanService) SetTraceArchive(archive *internalServices.TraceArchive) {
+	s.archive = archive
+}
+
 // IngestSpans handles span ingestion and rule evaluation
 func (s *SpanService) IngestSpans(ctx context.Context, req *pb.IngestSpansRequest) (*pb.IngestSpansResponse, error) {
 	if req == nil || len(req.Spans) == 0 {
@@ -93,9 +143,11 @@ func (s *SpanService) IngestSpans(ctx context.Context, req *pb.IngestSpansReques
 
 				// Create violation
 				violation := models.Violation{
-					RuleID:   ruleID,
-					RuleName: compiledRule.Rule.Name,
-					Severity: compiledRule.Rule.Severity,
+					RuleID:      ruleID,
+					RuleName:    compiledRule.Rule.Name,
+					RuleVersion: compiledRule.Rule.Version,
+					Severity:    compiledRule.Rule.Severity,
+					Tags:        compiledRule.Rule.Tags,
 					Message:  fmt.Sprintf("Rule '%s' matched span '%s' in trace '%s'", compiledRule.Rule.Name, protoSpan.SpanId, protoSpan.TraceId),
 				}
 
@@ -104,12 +156,14 @@ func (s *SpanService) IngestSpans(ctx context.Context, req *pb.IngestSpansReques
 						TraceID:     protoSpan.TraceId,
 						SpanID:      protoSpan.SpanId,
 						ServiceName: modelSpan.ServiceName,
+						Role:        models.SpanRoleTrigger,
 					},
 				}
+				s.fingerprint(&violation, spanRefs, []*models.Span{&modelSpan})
+				s.snapshot(&violation, compiledRule.Rule, spanRefs, []*models.Span{&modelSpan})
 
 				// Record violation
-				_, err := s.violationStore.Record(ctx, violation, spanRefs)
-				if err != nil {
+				if err := s.record(ctx, compiledRule.Rule, violation, spanRefs); err != nil {
 					log.Printf("Error recording violation for rule %s: %v", ruleID, err)
 				} else {
 					log.Printf("Violation recorded: rule=%s trace=%s span=%s", ruleID, protoSpan.TraceId, protoSpan.SpanId)
@@ -159,12 +213,19 @@ func (s *SpanService) protoToModelSpan(protoSpan *pb.Span) models.Span {
 		duration = endTime.Sub(startTime).Nanoseconds()
 	}
 
+	// Exporters that flatten resource attributes into the span send the
+	// service as an attribute instead
+	serviceName := protoSpan.ServiceName
+	if serviceName == "" {
+		serviceName = protoSpan.Attributes[serviceNameAttribute]
+	}
+
 	return models.Span{
 		SpanID:        protoSpan.SpanId,
 		TraceID:       protoSpan.TraceId,
 		ParentSpanID:  protoSpan.ParentSpanId,
 		OperationName: protoSpan.Name,
-		ServiceName:   "", // Not in proto, could be added later
+		ServiceName:   serviceName,
 		StartTime:     startTime,
 		EndTime:       endTime,
 		Duration:      duration,
@@ -173,6 +234,22 @@ func (s *SpanService) protoToModelSpan(protoSpan *pb.Span) models.Span {
 	}
 }
 
+// traceService returns the service of a trace's root span, or of its first
+// span naming one if the root hasn't arrived or names none
+func traceService(spans []*models.Span) string {
+	for _, span := range spans {
+		if span.ParentSpanID == "" && span.ServiceName != "" {
+			return span.ServiceName
+		}
+	}
+	for _, span := range spans {
+		if span.ServiceName != "" {
+			return span.ServiceName
+		}
+	}
+	return ""
+}
+
 // onTraceComplete is called when a trace is considered complete
 func (s *SpanService) onTraceComplete(ctx context.Context, traceID string, spans []*models.Span) {
 	log.Printf("Trace complete: trace_id=%s spans=%d", traceID, len(spans))
@@ -184,11 +261,51 @@ func (s *SpanService) onTraceComplete(ctx context.Context, traceID string, spans
 	}
 	log.Printf("  Span names: %v", spanNames)
 
+	if s.archive != nil {
+		if err := s.archive.Record(traceID, spans, time.Now()); err != nil {
+			log.Printf("Error archiving trace %s: %v", traceID, err)
+		}
+	}
+
 	// Evaluate trace-level rules
-	matchedRuleIDs, err := s.engine.EvaluateTrace(ctx, traceID, spans)
-	if err != nil {
-		log.Printf("Error evaluating trace-level rules for trace %s: %v", traceID, err)
-		return
+	results := s.engine.EvaluateTraceDetailed(ctx, traceID, spans)
+
+	matchedRuleIDs := make([]string, 0, len(results))
+	evaluatedAt := time.Now()
+	service := traceService(spans)
+	for _, result := range results {
+		outcome := ""
+		switch {
+		case result.Aborted:
+			// Not a match and not a pass: the rule ran out of budget on this trace
+			log.Printf("Trace-level rule evaluation aborted: rule=%s trace=%s reason=%s: %v", result.RuleID, traceID, result.AbortReason, result.Error)
+			outcome = internalServices.EvaluationErrored
+		case result.Error != nil:
+			log.Printf("Error evaluating trace-level rule %s for trace %s: %v", result.RuleID, traceID, result.Error)
+			outcome = internalServices.EvaluationErrored
+		case result.Matched:
+			matchedRuleIDs = append(matchedRuleIDs, result.RuleID)
+			outcome = internalServices.EvaluationViolated
+		case result.Passed:
+			outcome = internalServices.EvaluationPassed
+		}
+		// Traces the rule doesn't apply to aren't evidence either way, and
+		// shadow rules only count in their own metric, never as compliance
+		// evidence
+		switch {
+		case outcome == "":
+		case s.isShadow(result.RuleID):
+			observability.ShadowRuleEvaluations.WithLabelValues(result.RuleID, outcome).Inc()
+		case s.evaluations != nil:
+			s.evaluations.Record(result.RuleID, service, traceID, evaluatedAt, outcome)
+		}
+		if s.matches != nil && (result.Matched || result.Passed) {
+			match := internalServices.RuleMatch{RuleID: result.RuleID, TraceID: traceID, Service: service, Outcome: internalServices.MatchSatisfied, MatchedAt: evaluatedAt}
+			if result.Matched {
+				match.Outcome = internalServices.MatchViolated
+			}
+			s.matches.Record(match)
+		}
 	}
 
 	log.Printf("Trace evaluation complete: trace_id=%s matched_rules=%d rule_ids=%v", traceID, len(matchedRuleIDs), matchedRuleIDs)
@@ -202,28 +319,80 @@ func (s *SpanService) onTraceComplete(ctx context.Context, traceID string, spans
 
 		// Create violation
 		violation := models.Violation{
-			RuleID:   ruleID,
-			RuleName: compiledRule.Rule.Name,
-			Severity: compiledRule.Rule.Severity,
+			RuleID:      ruleID,
+			RuleName:    compiledRule.Rule.Name,
+			RuleVersion: compiledRule.Rule.Version,
+			Severity:    compiledRule.Rule.Severity,
+			Tags:        compiledRule.Rule.Tags,
 			Message:  fmt.Sprintf("Rule '%s' matched trace '%s' with %d spans", compiledRule.Rule.Name, traceID, len(spans)),
 		}
 
-		// Create span references for all spans in the trace
-		spanRefs := make([]models.SpanRef, len(spans))
-		for i, span := range spans {
-			spanRefs[i] = models.SpanRef{
-				TraceID:     span.TraceID,
-				SpanID:      span.SpanID,
-				ServiceName: span.ServiceName,
-			}
+		// Explain why the rule fired (only runs for violating traces) and
+		// reference just the spans that caused it
+		var spanRefs []models.SpanRef
+		explanation, err := s.engine.ExplainRule(ctx, ruleID, spans)
+		if err != nil {
+			log.Printf("Error explaining trace-level violation for rule %s: %v", ruleID, err)
+		} else {
+			violation.Explanation = explanation
+			spanRefs = dsl.OffendingSpans(explanation, spans)
+		}
+		if len(spanRefs) == 0 {
+			// Keep the violation attributable to its trace
+			spanRefs = []models.SpanRef{{TraceID: traceID}}
 		}
+		s.fingerprint(&violation, spanRefs, spans)
+		s.snapshot(&violation, compiledRule.Rule, spanRefs, spans)
 
 		// Record violation
-		_, err := s.violationStore.Record(ctx, violation, spanRefs)
-		if err != nil {
+		if err := s.record(ctx, compiledRule.Rule, violation, spanRefs); err != nil {
 			log.Printf("Error recording trace-level violation for rule %s: %v", ruleID, err)
 		} else {
-			log.Printf("Trace-level violation recorded: rule=%s trace=%s spans=%d", ruleID, traceID, len(spans))
+			log.Printf("Trace-level violation recorded: rule=%s trace=%s spans=%d offending=%d", ruleID, traceID, len(spans), len(spanRefs))
 		}
 	}
 }
+
+// fingerprint groups a violation by the spans it references
+func (s *SpanService) fingerprint(violation *models.Violation, spanRefs []models.SpanRef, spans []*models.Span) {
+	if s.fingerprinter == nil {
+		return
+	}
+	violation.Fingerprint, violation.GroupKeys = s.fingerprinter.Fingerprint(violation.RuleID, spanRefs, spans)
+}
+
+// snapshot attaches the evidence a violation was detected on
+func (s *SpanService) snapshot(violation *models.Violation, rule models.Rule, spanRefs []models.SpanRef, spans []*models.Span) {
+	if s.evidence == nil {
+		return
+	}
+	violation.Evidence = s.evidence.Snapshot(rule, spanRefs, spans)
+}
+
+// record stores a violation of rule and hands it to live watchers (never
+// blocks). Violations of shadow rules go to the shadow store and hub.
+func (s *SpanService) record(ctx context.Context, rule models.Rule, violation models.Violation, spanRefs []models.SpanRef) error {
+	store, hub := s.violationStore, s.hub
+	if rule.IsShadow() {
+		observability.ShadowViolationsRecorded.WithLabelValues(rule.ID).Inc()
+		if s.shadowStore == nil {
+			return nil
+		}
+		store, hub = s.shadowStore, s.shadowHub
+	}
+
+	recorded, err := store.Record(ctx, violation, spanRefs)
+	if err != nil {
+		return err
+	}
+	if hub != nil {
+		hub.Publish(recorded)
+	}
+	return nil
+}
+
+// isShadow reports whether ruleID is loaded in shadow mode
+func (s *SpanService) isShadow(ruleID string) bool {
+	compiled, ok := s.engine.GetRule(ruleID)
+	return ok && compiled.Rule.IsShadow()
+}
@@ -2,13 +2,18 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"reflect"
 	"testing"
 	"time"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/internal/rules"
 	internalServices "github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/pkg/models"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // TestValidateSpan_ValidInputs tests that valid spans pass validation
@@ -428,3 +433,555 @@ func TestIngestSpans_ErrorHandling(t *testing.T) {
 		t.Errorf("Expected Accepted=1, got %d", resp.Accepted)
 	}
 }
+
+// TestOnTraceComplete_AttachesExplanation verifies violations carry an explanation
+func TestOnTraceComplete_AttachesExplanation(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	violationStore := internalServices.NewViolationStoreMemory("test-key")
+	service := NewSpanService(engine, violationStore)
+	defer service.traceBuffer.Stop()
+
+	ctx := context.Background()
+
+	rule := models.Rule{
+		ID:         "payment-auth",
+		Name:       "Payments require auth",
+		Expression: "when { payment } always { auth and fraud_check }",
+		Enabled:    true,
+		Severity:   "HIGH",
+		Version:    3,
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	spans := []*models.Span{
+		{TraceID: "trace-1", SpanID: "span-1", OperationName: "payment", ServiceName: "checkout"},
+		{TraceID: "trace-1", SpanID: "span-2", ParentSpanID: "span-1", OperationName: "auth", ServiceName: "auth"},
+	}
+	service.onTraceComplete(ctx, "trace-1", spans)
+
+	violations, err := violationStore.Query(ctx, internalServices.QueryFilters{RuleID: "payment-auth"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d", len(violations))
+	}
+	if violations[0].RuleVersion != 3 {
+		t.Errorf("Expected the violation to record rule version 3, got %d", violations[0].RuleVersion)
+	}
+
+	explanation := violations[0].Explanation
+	if explanation == nil {
+		t.Fatal("Expected violation to carry an explanation")
+	}
+	if !explanation.Violated {
+		t.Error("Expected explanation.Violated=true")
+	}
+	if len(explanation.WhenSpanIDs) != 1 || explanation.WhenSpanIDs[0] != "span-1" {
+		t.Errorf("Expected WhenSpanIDs=[span-1], got %v", explanation.WhenSpanIDs)
+	}
+	if len(explanation.UnsatisfiedAlways) != 1 || explanation.UnsatisfiedAlways[0] != "fraud_check" {
+		t.Errorf("Expected UnsatisfiedAlways=[fraud_check], got %v", explanation.UnsatisfiedAlways)
+	}
+}
+
+// TestOnTraceComplete_ReferencesOnlyOffendingSpans verifies large traces don't
+// copy every span into the violation
+func TestOnTraceComplete_ReferencesOnlyOffendingSpans(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	violationStore := internalServices.NewViolationStoreMemory("test-key")
+	service := NewSpanService(engine, violationStore)
+	defer service.traceBuffer.Stop()
+
+	ctx := context.Background()
+
+	rule := models.Rule{
+		ID:         "admin-export",
+		Name:       "Admins must not export data",
+		Expression: "when { admin_login } never { data_export }",
+		Enabled:    true,
+		Severity:   "HIGH",
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	spans := []*models.Span{
+		{TraceID: "trace-1", SpanID: "login", OperationName: "admin_login", ServiceName: "auth"},
+		{TraceID: "trace-1", SpanID: "export", OperationName: "data_export", ServiceName: "reports"},
+	}
+	for i := 0; i < 2000; i++ {
+		spans = append(spans, &models.Span{TraceID: "trace-1", SpanID: fmt.Sprintf("noise-%d", i), OperationName: "db_query"})
+	}
+	service.onTraceComplete(ctx, "trace-1", spans)
+
+	violations, err := violationStore.Query(ctx, internalServices.QueryFilters{RuleID: "admin-export"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d", len(violations))
+	}
+
+	want := []models.SpanRef{
+		{TraceID: "trace-1", SpanID: "login", ServiceName: "auth", Role: models.SpanRoleTrigger},
+		{TraceID: "trace-1", SpanID: "export", ServiceName: "reports", Role: models.SpanRoleForbidden},
+	}
+	if !reflect.DeepEqual(violations[0].SpanRefs, want) {
+		t.Errorf("Expected SpanRefs=%v, got %v", want, violations[0].SpanRefs)
+	}
+	if !reflect.DeepEqual(violations[0].TraceIDs, []string{"trace-1"}) {
+		t.Errorf("Expected TraceIDs=[trace-1], got %v", violations[0].TraceIDs)
+	}
+}
+
+// TestOnTraceComplete_GroupsRepeatedViolations verifies the same failure in
+// many traces collapses into one incident, grouped by span attributes
+func TestOnTraceComplete_GroupsRepeatedViolations(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	fingerprinter, err := internalServices.NewFingerprinter([]string{internalServices.GroupByAttribute + "tenant"})
+	if err != nil {
+		t.Fatalf("NewFingerprinter failed: %v", err)
+	}
+	incidents, err := internalServices.NewIncidentStore(context.Background(), internalServices.NewViolationStoreMemory("test-key"), fingerprinter)
+	if err != nil {
+		t.Fatalf("NewIncidentStore failed: %v", err)
+	}
+	service := NewSpanService(engine, incidents)
+	defer service.traceBuffer.Stop()
+
+	ctx := context.Background()
+
+	rule := models.Rule{
+		ID:         "admin-export",
+		Name:       "Admins must not export data",
+		Expression: "when { admin_login } never { data_export }",
+		Enabled:    true,
+		Severity:   "HIGH",
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	for i, tenant := range []string{"acme", "acme", "acme", "globex"} {
+		traceID := fmt.Sprintf("trace-%d", i)
+		service.onTraceComplete(ctx, traceID, []*models.Span{
+			{TraceID: traceID, SpanID: "login", OperationName: "admin_login", Attributes: map[string]string{"tenant": tenant}},
+			{TraceID: traceID, SpanID: "export", OperationName: "data_export"},
+		})
+	}
+
+	list, total := incidents.ListIncidents(internalServices.IncidentFilters{RuleID: "admin-export"})
+	if total != 2 {
+		t.Fatalf("Expected 2 incidents (one per tenant), got %d", total)
+	}
+	counts := map[string]int64{}
+	for _, incident := range list {
+		counts[incident.GroupKeys["attribute:tenant"]] = incident.Count
+	}
+	if counts["acme"] != 3 || counts["globex"] != 1 {
+		t.Errorf("Expected acme=3 globex=1, got %v", counts)
+	}
+}
+
+// TestOnTraceComplete_RoutesShadowViolations verifies shadow rules' violations
+// stay out of the real store and stream, and are counted
+func TestOnTraceComplete_RoutesShadowViolations(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	violationStore := internalServices.NewViolationStoreMemory("test-key")
+	service := NewSpanService(engine, violationStore)
+	defer service.traceBuffer.Stop()
+
+	hub := internalServices.NewViolationHub(10)
+	service.SetViolationHub(hub)
+	sub := hub.Subscribe(internalServices.QueryFilters{})
+	defer sub.Close()
+	shadowStore := internalServices.NewViolationStoreMemory("test-key")
+	shadowHub := internalServices.NewViolationHub(10)
+	service.SetShadowStore(shadowStore, shadowHub)
+	shadowSub := shadowHub.Subscribe(internalServices.QueryFilters{})
+	defer shadowSub.Close()
+	evaluations, err := internalServices.NewEvaluationCounter("", internalServices.EvaluationCounterOptions{})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	service.SetEvaluationCounter(evaluations)
+
+	for _, rule := range []models.Rule{
+		{ID: "shadow-auth", Name: "shadow-auth", Expression: "when { payment } always { auth }", Enabled: true, Mode: models.RuleModeShadow},
+		{ID: "enforced-fraud", Name: "enforced-fraud", Expression: "when { payment } always { fraud_check }", Enabled: true},
+	} {
+		if err := engine.LoadRule(rule); err != nil {
+			t.Fatalf("Failed to load rule: %v", err)
+		}
+	}
+	counter := func(outcome string) float64 {
+		var m dto.Metric
+		if err := observability.ShadowRuleEvaluations.WithLabelValues("shadow-auth", outcome).Write(&m); err != nil {
+			t.Fatalf("Failed to read metric: %v", err)
+		}
+		return m.GetCounter().GetValue()
+	}
+	violatedBefore, passedBefore := counter(internalServices.EvaluationViolated), counter(internalServices.EvaluationPassed)
+
+	ctx := context.Background()
+	service.onTraceComplete(ctx, "trace-bad", []*models.Span{{TraceID: "trace-bad", SpanID: "s1", OperationName: "payment"}})
+	service.onTraceComplete(ctx, "trace-ok", []*models.Span{
+		{TraceID: "trace-ok", SpanID: "s1", OperationName: "payment"},
+		{TraceID: "trace-ok", SpanID: "s2", ParentSpanID: "s1", OperationName: "auth"},
+	})
+
+	shadow, _ := shadowStore.Query(ctx, internalServices.QueryFilters{})
+	if len(shadow) != 1 || shadow[0].RuleID != "shadow-auth" || shadow[0].TraceIDs[0] != "trace-bad" {
+		t.Errorf("Expected the shadow rule's violation in the shadow store, got %+v", shadow)
+	}
+	if real, _ := violationStore.Query(ctx, internalServices.QueryFilters{RuleID: "shadow-auth"}); len(real) != 0 {
+		t.Errorf("Expected no shadow violations in the violation store, got %+v", real)
+	}
+	if real, _ := violationStore.Query(ctx, internalServices.QueryFilters{RuleID: "enforced-fraud"}); len(real) != 2 {
+		t.Errorf("Expected enforcing violations to be recorded as usual, got %d", len(real))
+	}
+	if v := <-shadowSub.Events(); v.RuleID != "shadow-auth" {
+		t.Errorf("Expected the shadow violation on the shadow stream, got %+v", v)
+	}
+	for i := 0; i < 2; i++ {
+		if v := <-sub.Events(); v.RuleID != "enforced-fraud" {
+			t.Errorf("Expected only enforcing violations on the violation stream, got %+v", v)
+		}
+	}
+
+	if got := counter(internalServices.EvaluationViolated) - violatedBefore; got != 1 {
+		t.Errorf("Expected 1 violated shadow evaluation, got %v", got)
+	}
+	if got := counter(internalServices.EvaluationPassed) - passedBefore; got != 1 {
+		t.Errorf("Expected 1 passed shadow evaluation, got %v", got)
+	}
+
+	// Only enforcing rules' evaluations are compliance evidence
+	if shadow := evaluations.Buckets(internalServices.EvaluationFilter{RuleID: "shadow-auth"}); len(shadow) != 0 {
+		t.Errorf("Expected no compliance evaluations of the shadow rule, got %+v", shadow)
+	}
+	if enforced := evaluations.Buckets(internalServices.EvaluationFilter{RuleID: "enforced-fraud"}); len(enforced) != 1 || enforced[0].Violated != 2 {
+		t.Errorf("Expected 2 violated evaluations of the enforcing rule, got %+v", enforced)
+	}
+}
+
+// TestOnTraceComplete_PublishesViolations verifies recorded violations reach live watchers
+func TestOnTraceComplete_PublishesViolations(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	service := NewSpanService(engine, internalServices.NewViolationStoreMemory("test-key"))
+	defer service.traceBuffer.Stop()
+
+	hub := internalServices.NewViolationHub(10)
+	service.SetViolationHub(hub)
+	sub := hub.Subscribe(internalServices.QueryFilters{RuleID: "admin-export"})
+	defer sub.Close()
+
+	rule := models.Rule{
+		ID:         "admin-export",
+		Name:       "Admins must not export data",
+		Expression: "when { admin_login } never { data_export }",
+		Enabled:    true,
+		Severity:   "HIGH",
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	service.onTraceComplete(context.Background(), "trace-1", []*models.Span{
+		{TraceID: "trace-1", SpanID: "login", OperationName: "admin_login"},
+		{TraceID: "trace-1", SpanID: "export", OperationName: "data_export"},
+	})
+
+	select {
+	case v := <-sub.Events():
+		if v.ID == "" || v.Signature == "" || v.TraceIDs[0] != "trace-1" {
+			t.Errorf("Expected the stored (signed) violation to be published, got %+v", v)
+		}
+	default:
+		t.Fatal("Expected violation to be published")
+	}
+}
+
+// TestOnTraceComplete_SnapshotsEvidence verifies violations keep a bounded,
+// redacted copy of the trace and the rule it was evaluated with
+func TestOnTraceComplete_SnapshotsEvidence(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	violationStore := internalServices.NewViolationStoreMemory("test-key")
+	service := NewSpanService(engine, violationStore)
+	defer service.traceBuffer.Stop()
+	service.SetEvidenceRecorder(internalServices.NewEvidenceRecorder(internalServices.EvidenceLimits{
+		MaxSpans:         3,
+		RedactAttributes: []string{"user.email"},
+	}))
+
+	ctx := context.Background()
+	rule := models.Rule{
+		ID:         "admin-export",
+		Name:       "Admins must not export data",
+		Expression: "when { admin_login } never { data_export }",
+		Enabled:    true,
+		Severity:   "HIGH",
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	spans := []*models.Span{
+		{TraceID: "trace-1", SpanID: "login", OperationName: "admin_login", Attributes: map[string]string{"user.email": "alice@example.com"}},
+		{TraceID: "trace-1", SpanID: "export", OperationName: "data_export"},
+	}
+	for i := 0; i < 10; i++ {
+		spans = append(spans, &models.Span{TraceID: "trace-1", SpanID: fmt.Sprintf("noise-%d", i), OperationName: "db_query"})
+	}
+	service.onTraceComplete(ctx, "trace-1", spans)
+
+	violations, err := violationStore.Query(ctx, internalServices.QueryFilters{RuleID: "admin-export"})
+	if err != nil || len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d (%v)", len(violations), err)
+	}
+	evidence := violations[0].Evidence
+	if evidence == nil {
+		t.Fatal("Expected violation to carry evidence")
+	}
+	if evidence.RuleExpression != rule.Expression || evidence.ExpressionDigest != internalServices.ExpressionDigest(rule.Expression) {
+		t.Errorf("Expected the evaluated rule in the snapshot, got %q", evidence.RuleExpression)
+	}
+	if len(evidence.Spans) != 3 || evidence.TotalSpans != len(spans) || evidence.Spans[0].SpanID != "login" || evidence.Spans[1].SpanID != "export" {
+		t.Errorf("Expected the offending spans first within the limit, got %+v", evidence.Spans)
+	}
+	if evidence.Spans[0].Attributes["user.email"] != models.RedactedValue {
+		t.Errorf("Expected user.email to be redacted, got %q", evidence.Spans[0].Attributes["user.email"])
+	}
+
+	// The snapshot is signed with the rest of the violation
+	if _, err := violationStore.GetByID(ctx, violations[0].ID); err != nil {
+		t.Errorf("Expected the violation with evidence to verify: %v", err)
+	}
+}
+
+// TestOnTraceComplete_CountsEvaluations verifies trace-level evaluations are
+// counted by outcome and root service, with passing traces as exemplars
+func TestOnTraceComplete_CountsEvaluations(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	service := NewSpanService(engine, internalServices.NewViolationStoreMemory("test-key"))
+	defer service.traceBuffer.Stop()
+	counter, err := internalServices.NewEvaluationCounter("", internalServices.EvaluationCounterOptions{Exemplars: 5})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	service.SetEvaluationCounter(counter)
+
+	rule := models.Rule{
+		ID:         "payment-auth",
+		Name:       "Payments require auth",
+		Expression: "when { payment } always { auth }",
+		Enabled:    true,
+		Severity:   "HIGH",
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+	service.onTraceComplete(ctx, "trace-1", []*models.Span{
+		{TraceID: "trace-1", SpanID: "span-1", OperationName: "payment", ServiceName: "checkout"},
+		{TraceID: "trace-1", SpanID: "span-2", ParentSpanID: "span-1", OperationName: "auth", ServiceName: "auth"},
+	})
+	service.onTraceComplete(ctx, "trace-2", []*models.Span{
+		{TraceID: "trace-2", SpanID: "span-3", OperationName: "payment"},
+	})
+
+	counts := counter.Counts(rule.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if want := (internalServices.EvaluationCounts{Evaluated: 2, Passed: 1, Violated: 1}); counts != want {
+		t.Errorf("Expected %+v, got %+v", want, counts)
+	}
+	buckets := counter.Buckets(internalServices.EvaluationFilter{RuleID: rule.ID, Service: "checkout"})
+	if len(buckets) != 1 || buckets[0].Passed != 1 || len(buckets[0].PassExemplars) != 1 || buckets[0].PassExemplars[0] != "trace-1" {
+		t.Errorf("Expected the pass under checkout with trace-1 as exemplar, got %+v", buckets)
+	}
+}
+
+// TestOnTraceComplete_RecordsMatches verifies traces a rule applies to are
+// kept in its match history with their outcome
+func TestOnTraceComplete_RecordsMatches(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	service := NewSpanService(engine, internalServices.NewViolationStoreMemory("test-key"))
+	defer service.traceBuffer.Stop()
+	history := internalServices.NewMatchHistory(0)
+	service.SetMatchHistory(history)
+
+	if err := engine.LoadRule(models.Rule{ID: "payment-auth", Expression: "when { payment } always { auth }", Enabled: true}); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+	service.onTraceComplete(ctx, "trace-ok", []*models.Span{
+		{TraceID: "trace-ok", SpanID: "span-1", OperationName: "payment", ServiceName: "checkout"},
+		{TraceID: "trace-ok", SpanID: "span-2", ParentSpanID: "span-1", OperationName: "auth"},
+	})
+	service.onTraceComplete(ctx, "trace-bad", []*models.Span{
+		{TraceID: "trace-bad", SpanID: "span-3", OperationName: "payment", ServiceName: "checkout"},
+	})
+
+	page, err := history.Query("payment-auth", internalServices.MatchFilter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(page.Matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", page.Matches)
+	}
+	if m := page.Matches[0]; m.TraceID != "trace-bad" || m.Outcome != internalServices.MatchViolated || m.Service != "checkout" {
+		t.Errorf("Expected the violating trace first, got %+v", m)
+	}
+	if m := page.Matches[1]; m.TraceID != "trace-ok" || m.Outcome != internalServices.MatchSatisfied {
+		t.Errorf("Expected the satisfying trace, got %+v", m)
+	}
+}
+
+func TestOnTraceComplete_ArchivesTraces(t *testing.T) {
+	service := NewSpanService(rules.NewRuleEngine(), internalServices.NewViolationStoreMemory("test-key"))
+	defer service.traceBuffer.Stop()
+	archive, err := internalServices.NewTraceArchive(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewTraceArchive failed: %v", err)
+	}
+	defer archive.Close()
+	service.SetTraceArchive(archive)
+
+	service.onTraceComplete(context.Background(), "trace-1", []*models.Span{
+		{TraceID: "trace-1", SpanID: "span-1", OperationName: "payment", ServiceName: "checkout"},
+	})
+
+	files, err := archive.Files(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one archive file, got %v (%v)", files, err)
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer file.Close()
+	var traces []internalServices.ArchivedTrace
+	internalServices.ReadArchivedTraces(file, func(trace internalServices.ArchivedTrace) error {
+		traces = append(traces, trace)
+		return nil
+	})
+	if len(traces) != 1 || traces[0].TraceID != "trace-1" || len(traces[0].Spans) != 1 || traces[0].Spans[0].ServiceName != "checkout" {
+		t.Errorf("Expected the trace archived, got %+v", traces)
+	}
+}
+
+// ingestTrace ingests spans through IngestSpans and completes their trace
+// without waiting for the trace buffer's timeout
+func ingestTrace(t *testing.T, service *SpanService, traceID string, spans ...*pb.Span) {
+	t.Helper()
+	for _, span := range spans {
+		span.TraceId = traceID
+	}
+	if _, err := service.IngestSpans(context.Background(), &pb.IngestSpansRequest{Spans: spans}); err != nil {
+		t.Fatalf("IngestSpans failed: %v", err)
+	}
+	service.onTraceComplete(context.Background(), traceID, service.traceBuffer.GetTrace(traceID))
+}
+
+// TestIngestSpans_KeepsServiceNames verifies ingested spans carry their
+// service, so violations group into incidents and filter by service
+func TestIngestSpans_KeepsServiceNames(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	fingerprinter, err := internalServices.NewFingerprinter(internalServices.DefaultGroupingKeys)
+	if err != nil {
+		t.Fatalf("NewFingerprinter failed: %v", err)
+	}
+	incidents, err := internalServices.NewIncidentStore(context.Background(), internalServices.NewViolationStoreMemory("test-key"), fingerprinter)
+	if err != nil {
+		t.Fatalf("NewIncidentStore failed: %v", err)
+	}
+	service := NewSpanService(engine, incidents)
+	defer service.traceBuffer.Stop()
+	if err := engine.LoadRule(models.Rule{ID: "payment-auth", Name: "Payments need auth", Expression: "when { payment } always { auth }", Enabled: true}); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	// The service comes from the field, or the service.name attribute
+	ingestTrace(t, service, "trace-1", &pb.Span{SpanId: "s1", Name: "payment", ServiceName: "checkout"})
+	ingestTrace(t, service, "trace-2", &pb.Span{SpanId: "s2", Name: "payment", Attributes: map[string]string{"service.name": "billing"}})
+	ingestTrace(t, service, "trace-3", &pb.Span{SpanId: "s3", Name: "payment", ServiceName: "checkout"})
+
+	list, total := incidents.ListIncidents(internalServices.IncidentFilters{RuleID: "payment-auth"})
+	if total != 2 {
+		t.Fatalf("Expected one incident per service, got %+v", list)
+	}
+	counts := map[string]int64{}
+	for _, incident := range list {
+		counts[incident.GroupKeys[internalServices.GroupByService]] = incident.Count
+	}
+	// Each trace is checked span by span on ingestion and again once complete
+	if counts["checkout"] != 4 || counts["billing"] != 2 {
+		t.Errorf("Expected checkout=4 billing=2, got %v", counts)
+	}
+
+	violations, err := incidents.Query(context.Background(), internalServices.QueryFilters{ServiceName: "billing"})
+	if err != nil || len(violations) != 2 {
+		t.Fatalf("Expected billing's violations, got %+v (%v)", violations, err)
+	}
+	for _, v := range violations {
+		if v.TraceIDs[0] != "trace-2" {
+			t.Errorf("Expected only trace-2, got %+v", v)
+		}
+	}
+}
+
+// TestIngestSpans_CountsEvaluationsPerService verifies evaluations and
+// matches of ingested traces are kept under the service that sent them
+func TestIngestSpans_CountsEvaluationsPerService(t *testing.T) {
+	engine := rules.NewRuleEngine()
+	service := NewSpanService(engine, internalServices.NewViolationStoreMemory("test-key"))
+	defer service.traceBuffer.Stop()
+	counter, err := internalServices.NewEvaluationCounter("", internalServices.EvaluationCounterOptions{Exemplars: 5})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	service.SetEvaluationCounter(counter)
+	history := internalServices.NewMatchHistory(0)
+	service.SetMatchHistory(history)
+	if err := engine.LoadRule(models.Rule{ID: "payment-auth", Expression: "when { payment } always { auth }", Enabled: true}); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+
+	ingestTrace(t, service, "trace-1",
+		&pb.Span{SpanId: "s1", Name: "payment", ServiceName: "checkout"},
+		&pb.Span{SpanId: "s2", ParentSpanId: "s1", Name: "auth", ServiceName: "auth"})
+	ingestTrace(t, service, "trace-2",
+		&pb.Span{SpanId: "s3", Name: "payment", Attributes: map[string]string{"service.name": "billing"}},
+		&pb.Span{SpanId: "s4", ParentSpanId: "s3", Name: "auth", Attributes: map[string]string{"service.name": "auth"}})
+	ingestTrace(t, service, "trace-3", &pb.Span{SpanId: "s5", Name: "payment", ServiceName: "checkout"})
+
+	for svc, want := range map[string]internalServices.EvaluationCounts{
+		"checkout":                      {Evaluated: 2, Passed: 1, Violated: 1},
+		"billing":                       {Evaluated: 1, Passed: 1},
+		internalServices.UnknownService: {},
+	} {
+		var got internalServices.EvaluationCounts
+		for _, b := range counter.Buckets(internalServices.EvaluationFilter{RuleID: "payment-auth", Service: svc}) {
+			got.Add(b.EvaluationCounts)
+		}
+		if got != want {
+			t.Errorf("%s: expected %+v, got %+v", svc, want, got)
+		}
+	}
+
+	page, err := history.Query("payment-auth", internalServices.MatchFilter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	services := map[string]string{}
+	for _, m := range page.Matches {
+		services[m.TraceID] = m.Service
+	}
+	if want := map[string]string{"trace-1": "checkout", "trace-2": "billing", "trace-3": "checkout"}; !reflect.DeepEqual(services, want) {
+		t.Errorf("Expected matches under %v, got %v", want, services)
+	}
+}
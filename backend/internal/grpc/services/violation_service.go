@@ -2,67 +2,574 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/rules"
 	internalServices "github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/fsm"
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // ViolationService implements the gRPC ViolationService
 type ViolationService struct {
 	pb.UnimplementedViolationServiceServer
-	violationStore *internalServices.ViolationStoreMemory
+	violationStore internalServices.ViolationStore
+	incidents      *internalServices.IncidentStore // nil if violations aren't grouped
+	keys           *internalServices.Keyring       // nil if violations aren't signed
+	hub            *internalServices.ViolationHub  // nil disables WatchViolations
+	engine         *rules.RuleEngine               // nil disables ReproduceViolation
+	shadowStore    internalServices.ViolationStore // nil if shadow violations aren't kept
+	shadowHub      *internalServices.ViolationHub
 }
 
-// NewViolationService creates a new violation service
-func NewViolationService(violationStore *internalServices.ViolationStoreMemory) *ViolationService {
+// NewViolationService creates a new violation service. Incident RPCs are
+// served when violationStore is an *IncidentStore; signatures are verified
+// with the keys it signs violations with.
+func NewViolationService(violationStore internalServices.ViolationStore) *ViolationService {
+	incidents, _ := violationStore.(*internalServices.IncidentStore)
 	return &ViolationService{
 		violationStore: violationStore,
+		incidents:      incidents,
+		keys:           internalServices.StoreKeyring(violationStore),
 	}
 }
 
-// ListViolations returns rule violations
+// SetViolationHub enables WatchViolations, streaming violations published to hub
+func (s *ViolationService) SetViolationHub(hub *internalServices.ViolationHub) {
+	s.hub = hub
+}
+
+// SetRuleEngine enables ReproduceViolation, evaluating evidence snapshots
+// within engine's evaluation limits
+func (s *ViolationService) SetRuleEngine(engine *rules.RuleEngine) {
+	s.engine = engine
+}
+
+// SetShadowStore serves the would-be violations of shadow rules from store
+// and hub when ListViolations or WatchViolations ask for them
+func (s *ViolationService) SetShadowStore(store internalServices.ViolationStore, hub *internalServices.ViolationHub) {
+	s.shadowStore = store
+	s.shadowHub = hub
+}
+
+// stores returns the violation store and hub a request reads from
+func (s *ViolationService) stores(shadow bool) (internalServices.ViolationStore, *internalServices.ViolationHub, error) {
+	if !shadow {
+		return s.violationStore, s.hub, nil
+	}
+	if s.shadowStore == nil {
+		return nil, nil, status.Error(codes.Unimplemented, "shadow violations are not kept")
+	}
+	return s.shadowStore, s.shadowHub, nil
+}
+
+// Page size bounds for ListViolations
+const (
+	defaultViolationPageSize = 100
+	maxViolationPageSize     = 1000
+)
+
+// ListViolations returns one page of rule violations matching the request's filters
 func (s *ViolationService) ListViolations(ctx context.Context, req *pb.ListViolationsRequest) (*pb.ListViolationsResponse, error) {
 	// Build query filters
 	filters := internalServices.QueryFilters{
-		RuleID: req.RuleId,
-		Limit:  100,
+		RuleID:      req.RuleId,
+		TraceID:     req.TraceId,
+		Severity:    req.Severity,
+		ServiceName: req.ServiceName,
+		Tags:        req.Tags,
+		Fingerprint: req.Fingerprint,
+		Status:      req.Status,
+		Assignee:    req.Assignee,
+		Cursor:      req.Cursor,
+		Order:       req.Order,
+		Limit:       defaultViolationPageSize,
+	}
+
+	if req.StartTime != nil {
+		filters.Since = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		filters.Until = req.EndTime.AsTime()
 	}
 
 	if req.Limit > 0 {
 		filters.Limit = int(req.Limit)
 	}
+	if filters.Limit > maxViolationPageSize {
+		filters.Limit = maxViolationPageSize
+	}
 
 	// Query violations
-	violations, err := s.violationStore.Query(ctx, filters)
+	store, _, err := s.stores(req.Shadow)
+	if err != nil {
+		return nil, err
+	}
+	page, err := store.QueryPage(ctx, filters)
+	if errors.Is(err, internalServices.ErrInvalidQuery) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
+	violations := page.Violations
 
 	// Convert to proto violations
 	pbViolations := make([]*pb.Violation, len(violations))
 	for i, v := range violations {
-		pbViolations[i] = &pb.Violation{
-			Id:        v.ID,
-			RuleId:    v.RuleID,
-			RuleName:  v.RuleName,
-			TraceId:   "", // Use first trace ID if available
-			SpanId:    "",  // Use first span ID if available
-			Timestamp: timestamppb.New(v.CreatedAt),
-			Severity:  v.Severity,
-			Message:   v.Message,
-			Context:   make(map[string]string), // Empty for now
-		}
-
-		// Set trace/span IDs from first reference
-		if len(v.SpanRefs) > 0 {
-			pbViolations[i].TraceId = v.SpanRefs[0].TraceID
-			pbViolations[i].SpanId = v.SpanRefs[0].SpanID
-		}
+		pbViolations[i] = violationToProto(v)
 	}
 
 	return &pb.ListViolationsResponse{
 		Violations: pbViolations,
-		TotalCount: int32(len(violations)),
+		TotalCount: int32(page.TotalCount),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// WatchViolations streams matching violations, replaying those after req.Cursor first
+func (s *ViolationService) WatchViolations(req *pb.WatchViolationsRequest, stream pb.ViolationService_WatchViolationsServer) error {
+	store, hub, err := s.stores(req.Shadow)
+	if err != nil {
+		return err
+	}
+	if hub == nil {
+		return status.Error(codes.Unimplemented, "violation streaming is not enabled")
+	}
+
+	filters := internalServices.QueryFilters{
+		RuleID:   req.RuleId,
+		Severity: req.Severity,
+		Tags:     req.Tags,
+		Cursor:   req.Cursor,
+	}
+	err = internalServices.WatchViolations(stream.Context(), store, hub, filters, func(event internalServices.ViolationEvent) error {
+		return stream.Send(&pb.ViolationEvent{
+			Violation: violationToProto(event.Violation),
+			Cursor:    event.Cursor,
+		})
+	})
+
+	switch {
+	case errors.Is(err, internalServices.ErrInvalidQuery):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, internalServices.ErrSubscriberLagged):
+		// Retryable: the client resumes from its last cursor
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return err
+	}
+}
+
+// ListIncidents returns violation groups, most recently seen first
+func (s *ViolationService) ListIncidents(ctx context.Context, req *pb.ListIncidentsRequest) (*pb.ListIncidentsResponse, error) {
+	if s.incidents == nil {
+		return nil, status.Error(codes.Unimplemented, "incident grouping is not enabled")
+	}
+
+	filters := internalServices.IncidentFilters{
+		RuleID:   req.RuleId,
+		Severity: req.Severity,
+		Limit:    defaultViolationPageSize,
+	}
+	if req.Since != nil {
+		filters.Since = req.Since.AsTime()
+	}
+	if req.Limit > 0 {
+		filters.Limit = min(int(req.Limit), maxViolationPageSize)
+	}
+
+	incidents, total := s.incidents.ListIncidents(filters)
+	pbIncidents := make([]*pb.Incident, len(incidents))
+	for i, incident := range incidents {
+		pbIncidents[i] = incidentToProto(incident)
+	}
+
+	return &pb.ListIncidentsResponse{
+		Incidents:  pbIncidents,
+		TotalCount: int32(total),
+	}, nil
+}
+
+// GetIncident returns one violation group and its most recent violations
+func (s *ViolationService) GetIncident(ctx context.Context, req *pb.GetIncidentRequest) (*pb.GetIncidentResponse, error) {
+	if s.incidents == nil {
+		return nil, status.Error(codes.Unimplemented, "incident grouping is not enabled")
+	}
+	if req.Fingerprint == "" {
+		return nil, status.Error(codes.InvalidArgument, "fingerprint is required")
+	}
+
+	incident, err := s.incidents.GetIncident(req.Fingerprint)
+	if errors.Is(err, internalServices.ErrIncidentNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recent, err := s.violationStore.Query(ctx, internalServices.QueryFilters{
+		Fingerprint: req.Fingerprint,
+		Limit:       maxIncidentSamples,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pbViolations := make([]*pb.Violation, len(recent))
+	for i, v := range recent {
+		pbViolations[i] = violationToProto(v)
+	}
+
+	return &pb.GetIncidentResponse{
+		Incident:         incidentToProto(*incident),
+		RecentViolations: pbViolations,
 	}, nil
 }
+
+// UpdateViolationStatus moves each listed violation to the requested status
+func (s *ViolationService) UpdateViolationStatus(ctx context.Context, req *pb.UpdateViolationStatusRequest) (*pb.UpdateViolationStatusResponse, error) {
+	return s.triageBulk(ctx, req.ViolationIds, internalServices.TriageUpdate{
+		Actor:   changeAuthor(ctx),
+		Status:  req.Status,
+		Reason:  req.Reason,
+		Comment: req.Comment,
+	})
+}
+
+// AssignViolations sets the assignee of each listed violation
+func (s *ViolationService) AssignViolations(ctx context.Context, req *pb.AssignViolationsRequest) (*pb.UpdateViolationStatusResponse, error) {
+	return s.triageBulk(ctx, req.ViolationIds, internalServices.TriageUpdate{
+		Actor:    changeAuthor(ctx),
+		Assignee: &req.Assignee,
+	})
+}
+
+// AddViolationComment appends a triage comment to a violation
+func (s *ViolationService) AddViolationComment(ctx context.Context, req *pb.AddViolationCommentRequest) (*pb.Violation, error) {
+	if req.ViolationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "violation_id is required")
+	}
+	if req.Text == "" {
+		return nil, status.Error(codes.InvalidArgument, "text is required")
+	}
+
+	v, err := s.violationStore.Triage(ctx, req.ViolationId, internalServices.TriageUpdate{
+		Actor:   changeAuthor(ctx),
+		Comment: req.Text,
+	})
+	if err != nil {
+		return nil, triageStatusError(err)
+	}
+	return violationToProto(v), nil
+}
+
+// VerifyViolation checks a stored violation's signature and returns what was signed
+func (s *ViolationService) VerifyViolation(ctx context.Context, req *pb.VerifyViolationRequest) (*pb.VerifyViolationResponse, error) {
+	if req.ViolationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "violation_id is required")
+	}
+
+	v, err := s.violationStore.GetByID(ctx, req.ViolationId)
+	switch {
+	case errors.Is(err, internalServices.ErrInvalidSignature):
+		return &pb.VerifyViolationResponse{Error: err.Error()}, nil
+	case errors.Is(err, storage.ErrViolationNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return nil, err
+	}
+
+	resp := &pb.VerifyViolationResponse{
+		Algorithm: internalServices.SignatureAlgorithm(*v),
+		KeyId:     v.SignatureKeyID,
+		Signature: v.Signature,
+		Violation: violationToProto(*v),
+	}
+	if err := s.verifySignature(*v); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Valid = true
+	}
+	if resp.Algorithm == internalServices.SignatureAlgEdDSA {
+		resp.SignedPayload = internalServices.CanonicalViolation(*v)
+	}
+	return resp, nil
+}
+
+// verifySignature checks v's signature with the store's keys. Without keys
+// signing is disabled and no signature counts as verified.
+func (s *ViolationService) verifySignature(v models.Violation) error {
+	switch {
+	case v.Signature == "":
+		return errors.New("violation is not signed")
+	case s.keys == nil:
+		return errors.New("signing is disabled; the signature is unverified")
+	}
+	return s.keys.VerifyViolation(v)
+}
+
+// ReproduceViolation re-runs a violation's captured rule expression against
+// its evidence snapshot
+func (s *ViolationService) ReproduceViolation(ctx context.Context, req *pb.ReproduceViolationRequest) (*pb.ReproduceViolationResponse, error) {
+	if req.ViolationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "violation_id is required")
+	}
+	if s.engine == nil {
+		return nil, status.Error(codes.Unimplemented, "violation reproduction is not enabled")
+	}
+
+	v, err := s.violationStore.GetByID(ctx, req.ViolationId)
+	switch {
+	case errors.Is(err, internalServices.ErrInvalidSignature):
+		return &pb.ReproduceViolationResponse{Error: err.Error()}, nil
+	case errors.Is(err, storage.ErrViolationNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	if v.Evidence == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "violation %s has no evidence snapshot", v.ID)
+	}
+
+	signatureErr := s.verifySignature(*v)
+	resp := &pb.ReproduceViolationResponse{
+		SignatureValid:   signatureErr == nil,
+		ExpressionDigest: v.Evidence.ExpressionDigest,
+		Violation:        violationToProto(*v),
+	}
+	if current, ok := s.engine.GetRule(v.RuleID); ok {
+		resp.CurrentExpressionDigest = internalServices.ExpressionDigest(current.Rule.Expression)
+	}
+
+	spans := make([]*models.Span, len(v.Evidence.Spans))
+	for i := range v.Evidence.Spans {
+		spans[i] = &v.Evidence.Spans[i]
+	}
+	matched, err := s.engine.EvaluateExpression(ctx, v.Evidence.RuleExpression, spans)
+	if err != nil {
+		resp.Error = fmt.Sprintf("evaluating the captured rule failed: %v", err)
+		return resp, nil
+	}
+	if explanation, err := s.engine.ExplainExpression(ctx, v.Evidence.RuleExpression, spans); err == nil {
+		resp.Explanation = explanationToProto(explanation)
+	}
+
+	resp.Reproduced = matched
+	switch {
+	case !matched && len(v.Evidence.RedactedAttributes) > 0:
+		resp.Error = fmt.Sprintf("the captured rule doesn't match the snapshot; it may depend on redacted attributes %v", v.Evidence.RedactedAttributes)
+	case !matched:
+		resp.Error = "the captured rule doesn't match the snapshot"
+	case signatureErr != nil:
+		resp.Error = signatureErr.Error()
+	}
+	return resp, nil
+}
+
+// triageBulk applies update to each violation independently, collecting per-ID failures
+func (s *ViolationService) triageBulk(ctx context.Context, ids []string, update internalServices.TriageUpdate) (*pb.UpdateViolationStatusResponse, error) {
+	if len(ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "violation_ids is required")
+	}
+	if len(ids) > maxViolationPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many violation_ids: %d exceeds limit of %d", len(ids), maxViolationPageSize)
+	}
+	if err := update.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &pb.UpdateViolationStatusResponse{}
+	for _, id := range ids {
+		v, err := s.violationStore.Triage(ctx, id, update)
+		if err != nil {
+			resp.Failures = append(resp.Failures, &pb.ViolationUpdateFailure{
+				ViolationId: id,
+				Error:       err.Error(),
+			})
+			continue
+		}
+		resp.Updated = append(resp.Updated, violationToProto(v))
+	}
+	return resp, nil
+}
+
+// triageStatusError maps triage errors to gRPC status codes
+func triageStatusError(err error) error {
+	var transitionErr *fsm.InvalidViolationTransitionError
+	switch {
+	case errors.Is(err, internalServices.ErrInvalidTriage):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &transitionErr):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, storage.ErrViolationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return err
+	}
+}
+
+// maxIncidentSamples is how many recent violations GetIncident returns
+const maxIncidentSamples = 10
+
+// violationToProto converts a violation to its proto form
+func violationToProto(v models.Violation) *pb.Violation {
+	pbViolation := &pb.Violation{
+		Id:          v.ID,
+		RuleId:      v.RuleID,
+		RuleName:    v.RuleName,
+		RuleVersion: int32(v.RuleVersion),
+		TraceId:     "", // Use first trace ID if available
+		SpanId:      "", // Use first span ID if available
+		Timestamp:   timestamppb.New(v.CreatedAt),
+		Severity:    v.Severity,
+		Message:     v.Message,
+		Context:     make(map[string]string), // Empty for now
+		Explanation: explanationToProto(v.Explanation),
+		SpanRefs:    spanRefsToProto(v.SpanRefs),
+		Tags:        v.Tags,
+		Fingerprint: v.Fingerprint,
+		GroupKeys:   v.GroupKeys,
+		Status:      v.Status,
+		Assignee:    v.Assignee,
+
+		Signature:      v.Signature,
+		SignatureKeyId: v.SignatureKeyID,
+		Evidence:       evidenceToProto(v.Evidence),
+	}
+
+	for _, c := range v.Comments {
+		pbViolation.Comments = append(pbViolation.Comments, &pb.ViolationComment{
+			Author:    c.Author,
+			Text:      c.Text,
+			CreatedAt: timestamppb.New(c.CreatedAt),
+		})
+	}
+	for _, change := range v.StatusHistory {
+		pbViolation.StatusHistory = append(pbViolation.StatusHistory, &pb.StatusChange{
+			From:      change.From,
+			To:        change.To,
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+			ChangedAt: timestamppb.New(change.ChangedAt),
+		})
+	}
+	if v.UpdatedAt != nil {
+		pbViolation.UpdatedAt = timestamppb.New(*v.UpdatedAt)
+	}
+
+	// Set trace/span IDs from first reference
+	if len(v.SpanRefs) > 0 {
+		pbViolation.TraceId = v.SpanRefs[0].TraceID
+		pbViolation.SpanId = v.SpanRefs[0].SpanID
+	}
+	return pbViolation
+}
+
+// incidentToProto converts an incident to its proto form
+func incidentToProto(incident models.Incident) *pb.Incident {
+	return &pb.Incident{
+		Fingerprint:       incident.Fingerprint,
+		RuleId:            incident.RuleID,
+		RuleName:          incident.RuleName,
+		Severity:          incident.Severity,
+		GroupKeys:         incident.GroupKeys,
+		FirstSeen:         timestamppb.New(incident.FirstSeen),
+		LastSeen:          timestamppb.New(incident.LastSeen),
+		Count:             incident.Count,
+		LatestViolationId: incident.LatestViolationID,
+		SampleTraceIds:    incident.SampleTraceIDs,
+	}
+}
+
+// spanRefsToProto converts violation span references to their proto form
+func spanRefsToProto(refs []models.SpanRef) []*pb.SpanReference {
+	pbRefs := make([]*pb.SpanReference, len(refs))
+	for i, ref := range refs {
+		pbRefs[i] = &pb.SpanReference{
+			TraceId:     ref.TraceID,
+			SpanId:      ref.SpanID,
+			ServiceName: ref.ServiceName,
+			Role:        ref.Role,
+		}
+	}
+	return pbRefs
+}
+
+// evidenceToProto converts an evidence snapshot to its proto form
+func evidenceToProto(e *models.EvidenceSnapshot) *pb.EvidenceSnapshot {
+	if e == nil {
+		return nil
+	}
+
+	spans := make([]*pb.EvidenceSpan, len(e.Spans))
+	for i, span := range e.Spans {
+		spans[i] = &pb.EvidenceSpan{
+			TraceId:       span.TraceID,
+			SpanId:        span.SpanID,
+			ParentSpanId:  span.ParentSpanID,
+			OperationName: span.OperationName,
+			ServiceName:   span.ServiceName,
+			StartTime:     timestamppb.New(span.StartTime),
+			EndTime:       timestamppb.New(span.EndTime),
+			Duration:      span.Duration,
+			Attributes:    span.Attributes,
+			Status:        span.Status,
+		}
+	}
+	return &pb.EvidenceSnapshot{
+		RuleExpression:      e.RuleExpression,
+		ExpressionDigest:    e.ExpressionDigest,
+		Spans:               spans,
+		TotalSpans:          int32(e.TotalSpans),
+		TruncatedAttributes: int32(e.TruncatedAttributes),
+		RedactedAttributes:  e.RedactedAttributes,
+	}
+}
+
+// explanationToProto converts a rule explanation to its proto form
+func explanationToProto(e *models.RuleExplanation) *pb.RuleExplanation {
+	if e == nil {
+		return nil
+	}
+
+	return &pb.RuleExplanation{
+		Violated:          e.Violated,
+		When:              explanationNodeToProto(e.When),
+		Always:            explanationNodeToProto(e.Always),
+		Never:             explanationNodeToProto(e.Never),
+		WhenSpanIds:       e.WhenSpanIDs,
+		UnsatisfiedAlways: e.UnsatisfiedAlways,
+		MatchedNever:      e.MatchedNever,
+	}
+}
+
+func explanationNodeToProto(n *models.ExplanationNode) *pb.ExplanationNode {
+	if n == nil {
+		return nil
+	}
+
+	children := make([]*pb.ExplanationNode, len(n.Children))
+	for i, child := range n.Children {
+		children[i] = explanationNodeToProto(child)
+	}
+
+	return &pb.ExplanationNode{
+		Kind:             n.Kind,
+		Expression:       n.Expression,
+		Result:           n.Result,
+		Error:            n.Error,
+		Operation:        n.Operation,
+		MatchedSpanIds:   n.MatchedSpanIDs,
+		MatchedSpanCount: int32(n.MatchedSpanCount),
+		Children:         children,
+	}
+}
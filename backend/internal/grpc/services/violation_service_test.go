@@ -2,11 +2,23 @@ package services
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	"github.com/betracehq/betrace/backend/internal/middleware"
+	"github.com/betracehq/betrace/backend/internal/rules"
 	internalServices "github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/internal/storage"
 	"github.com/betracehq/betrace/backend/pkg/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TestViolationService_ListViolations_Empty tests listing with no violations
@@ -29,6 +41,30 @@ func TestViolationService_ListViolations_Empty(t *testing.T) {
 	}
 }
 
+func TestViolationService_ListViolations_Shadow(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+	ctx := context.Background()
+
+	if _, err := service.ListViolations(ctx, &pb.ListViolationsRequest{Shadow: true}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a shadow store, got %v", err)
+	}
+
+	shadowStore := internalServices.NewViolationStoreMemory("test-key")
+	service.SetShadowStore(shadowStore, internalServices.NewViolationHub(10))
+	if _, err := shadowStore.Record(ctx, models.Violation{RuleID: "shadow-rule", Message: "would fire"}, nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	resp, err := service.ListViolations(ctx, &pb.ListViolationsRequest{Shadow: true})
+	if err != nil || len(resp.Violations) != 1 || resp.Violations[0].RuleId != "shadow-rule" {
+		t.Errorf("Expected the shadow violation, got %v (%v)", resp, err)
+	}
+	if resp, _ := service.ListViolations(ctx, &pb.ListViolationsRequest{}); len(resp.Violations) != 0 {
+		t.Errorf("Expected shadow violations to stay out of the violation store, got %v", resp.Violations)
+	}
+}
+
 // TestViolationService_ListViolations_WithViolations tests listing violations
 func TestViolationService_ListViolations_WithViolations(t *testing.T) {
 	store := internalServices.NewViolationStoreMemory("test-key")
@@ -333,3 +369,584 @@ func TestViolationService_ListViolations_Metadata(t *testing.T) {
 		t.Error("Expected Context map to be initialized, got nil")
 	}
 }
+
+// TestViolationService_ListViolations_Explanation tests explanation conversion
+func TestViolationService_ListViolations_Explanation(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+
+	ctx := context.Background()
+
+	store.Record(ctx, models.Violation{
+		RuleID:   "rule-1",
+		RuleName: "Test Rule",
+		Severity: "HIGH",
+		Message:  "Test violation with explanation",
+		Explanation: &models.RuleExplanation{
+			Violated:          true,
+			When:              &models.ExplanationNode{Kind: "has", Expression: "payment", Result: true, MatchedSpanIDs: []string{"span-1"}, MatchedSpanCount: 1},
+			Always:            &models.ExplanationNode{Kind: "has", Expression: "auth", Result: false},
+			WhenSpanIDs:       []string{"span-1"},
+			UnsatisfiedAlways: []string{"auth"},
+		},
+	}, nil)
+
+	resp, err := service.ListViolations(ctx, &pb.ListViolationsRequest{})
+	if err != nil {
+		t.Fatalf("ListViolations failed: %v", err)
+	}
+	if len(resp.Violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d", len(resp.Violations))
+	}
+
+	explanation := resp.Violations[0].Explanation
+	if explanation == nil {
+		t.Fatal("Expected explanation to be converted")
+	}
+	if !explanation.Violated || explanation.When.Expression != "payment" || explanation.When.MatchedSpanCount != 1 {
+		t.Errorf("Unexpected when clause: %+v", explanation.When)
+	}
+	if explanation.Never != nil {
+		t.Errorf("Expected no never clause, got %+v", explanation.Never)
+	}
+	if len(explanation.UnsatisfiedAlways) != 1 || explanation.UnsatisfiedAlways[0] != "auth" {
+		t.Errorf("Expected UnsatisfiedAlways=[auth], got %v", explanation.UnsatisfiedAlways)
+	}
+}
+
+// TestViolationService_ListViolations_Filters tests trace, time, severity, service and tag filters
+func TestViolationService_ListViolations_Filters(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	records := []struct {
+		violation models.Violation
+		ref       models.SpanRef
+	}{
+		{models.Violation{RuleID: "rule-1", Severity: "HIGH", Tags: []string{"pci"}, CreatedAt: base},
+			models.SpanRef{TraceID: "trace-1", SpanID: "span-1", ServiceName: "checkout"}},
+		{models.Violation{RuleID: "rule-1", Severity: "LOW", CreatedAt: base.Add(time.Hour)},
+			models.SpanRef{TraceID: "trace-2", SpanID: "span-2", ServiceName: "auth"}},
+		{models.Violation{RuleID: "rule-2", Severity: "HIGH", Tags: []string{"pci", "soc2"}, CreatedAt: base.Add(2 * time.Hour)},
+			models.SpanRef{TraceID: "trace-2", SpanID: "span-3", ServiceName: "checkout"}},
+	}
+	for _, r := range records {
+		if _, err := store.Record(ctx, r.violation, []models.SpanRef{r.ref}); err != nil {
+			t.Fatalf("Failed to record violation: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		req       *pb.ListViolationsRequest
+		wantSpans []string
+	}{
+		{"trace", &pb.ListViolationsRequest{TraceId: "trace-2"}, []string{"span-3", "span-2"}},
+		{"time range", &pb.ListViolationsRequest{
+			StartTime: timestamppb.New(base.Add(time.Hour)),
+			EndTime:   timestamppb.New(base.Add(2 * time.Hour)),
+		}, []string{"span-2"}},
+		{"severity", &pb.ListViolationsRequest{Severity: "HIGH"}, []string{"span-3", "span-1"}},
+		{"service", &pb.ListViolationsRequest{ServiceName: "auth"}, []string{"span-2"}},
+		{"tags", &pb.ListViolationsRequest{Tags: []string{"pci", "soc2"}}, []string{"span-3"}},
+		{"oldest first", &pb.ListViolationsRequest{RuleId: "rule-1", Order: "oldest"}, []string{"span-1", "span-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.ListViolations(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ListViolations failed: %v", err)
+			}
+
+			var gotSpans []string
+			for _, v := range resp.Violations {
+				gotSpans = append(gotSpans, v.SpanId)
+			}
+			if !reflect.DeepEqual(gotSpans, tt.wantSpans) {
+				t.Errorf("Expected spans %v, got %v", tt.wantSpans, gotSpans)
+			}
+			if int(resp.TotalCount) != len(tt.wantSpans) {
+				t.Errorf("Expected TotalCount=%d, got %d", len(tt.wantSpans), resp.TotalCount)
+			}
+		})
+	}
+}
+
+// TestViolationService_ListViolations_CursorPagination tests paging through all results
+func TestViolationService_ListViolations_CursorPagination(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+
+	ctx := context.Background()
+
+	// Same timestamp for every violation: order falls back to the ID tie-breaker
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", CreatedAt: createdAt},
+			[]models.SpanRef{{TraceID: "trace-1", SpanID: "span-1"}})
+	}
+
+	seen := make(map[string]bool)
+	req := &pb.ListViolationsRequest{Limit: 3}
+	for pages := 1; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Expected pagination to finish in 3 pages")
+		}
+
+		resp, err := service.ListViolations(ctx, req)
+		if err != nil {
+			t.Fatalf("ListViolations failed: %v", err)
+		}
+		if resp.TotalCount != 7 {
+			t.Errorf("Expected TotalCount=7 for the whole result, got %d", resp.TotalCount)
+		}
+		for _, v := range resp.Violations {
+			if seen[v.Id] {
+				t.Errorf("Violation %s returned on two pages", v.Id)
+			}
+			seen[v.Id] = true
+		}
+
+		if resp.NextCursor == "" {
+			break
+		}
+		req.Cursor = resp.NextCursor
+	}
+
+	if len(seen) != 7 {
+		t.Errorf("Expected 7 distinct violations across pages, got %d", len(seen))
+	}
+}
+
+// TestViolationService_ListViolations_InvalidCursor tests malformed cursors are rejected
+func TestViolationService_ListViolations_InvalidCursor(t *testing.T) {
+	service := NewViolationService(internalServices.NewViolationStoreMemory("test-key"))
+
+	_, err := service.ListViolations(context.Background(), &pb.ListViolationsRequest{Cursor: "%%%"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func newIncidentViolationService(t *testing.T) (*ViolationService, *internalServices.IncidentStore) {
+	t.Helper()
+	fingerprinter, err := internalServices.NewFingerprinter(internalServices.DefaultGroupingKeys)
+	if err != nil {
+		t.Fatalf("NewFingerprinter failed: %v", err)
+	}
+	store, err := internalServices.NewIncidentStore(context.Background(), internalServices.NewViolationStoreMemory("test-key"), fingerprinter)
+	if err != nil {
+		t.Fatalf("NewIncidentStore failed: %v", err)
+	}
+	return NewViolationService(store), store
+}
+
+// TestViolationService_Incidents tests listing and fetching grouped violations
+func TestViolationService_Incidents(t *testing.T) {
+	service, store := newIncidentViolationService(t)
+	ctx := context.Background()
+
+	record := func(ruleID, serviceName, traceID string) models.Violation {
+		v, err := store.Record(ctx, models.Violation{RuleID: ruleID, RuleName: "Rule " + ruleID, Severity: "HIGH", Message: "violation"},
+			[]models.SpanRef{{TraceID: traceID, SpanID: "span-1", ServiceName: serviceName}})
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		return v
+	}
+	v1 := record("rule-1", "checkout", "trace-1")
+	record("rule-1", "checkout", "trace-2")
+	record("rule-2", "auth", "trace-3")
+
+	listResp, err := service.ListIncidents(ctx, &pb.ListIncidentsRequest{RuleId: "rule-1"})
+	if err != nil {
+		t.Fatalf("ListIncidents failed: %v", err)
+	}
+	if listResp.TotalCount != 1 || len(listResp.Incidents) != 1 {
+		t.Fatalf("Expected 1 incident for rule-1, got %d", listResp.TotalCount)
+	}
+	incident := listResp.Incidents[0]
+	if incident.Fingerprint != v1.Fingerprint || incident.Count != 2 {
+		t.Errorf("Unexpected incident: %v", incident)
+	}
+	if incident.GroupKeys["service"] != "checkout" {
+		t.Errorf("Expected group key service=checkout, got %v", incident.GroupKeys)
+	}
+
+	getResp, err := service.GetIncident(ctx, &pb.GetIncidentRequest{Fingerprint: v1.Fingerprint})
+	if err != nil {
+		t.Fatalf("GetIncident failed: %v", err)
+	}
+	if len(getResp.RecentViolations) != 2 {
+		t.Errorf("Expected 2 recent violations, got %d", len(getResp.RecentViolations))
+	}
+	for _, v := range getResp.RecentViolations {
+		if v.Fingerprint != v1.Fingerprint {
+			t.Errorf("Expected recent violation with fingerprint %s, got %s", v1.Fingerprint, v.Fingerprint)
+		}
+	}
+
+	violationsResp, err := service.ListViolations(ctx, &pb.ListViolationsRequest{Fingerprint: v1.Fingerprint})
+	if err != nil {
+		t.Fatalf("ListViolations failed: %v", err)
+	}
+	if violationsResp.TotalCount != 2 {
+		t.Errorf("Expected 2 violations for fingerprint, got %d", violationsResp.TotalCount)
+	}
+
+	_, err = service.GetIncident(ctx, &pb.GetIncidentRequest{Fingerprint: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+// TestViolationService_Incidents_NotEnabled tests incident RPCs without grouping
+func TestViolationService_Incidents_NotEnabled(t *testing.T) {
+	service := NewViolationService(internalServices.NewViolationStoreMemory("test-key"))
+
+	_, err := service.ListIncidents(context.Background(), &pb.ListIncidentsRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented, got %v", err)
+	}
+}
+
+// TestViolationService_UpdateViolationStatus tests bulk status changes with partial failures
+func TestViolationService_UpdateViolationStatus(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyEmail, "alice")
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		v, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "violation"}, nil)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		ids = append(ids, v.ID)
+	}
+	if _, err := store.Triage(ctx, ids[2], internalServices.TriageUpdate{Actor: "bob", Status: models.ViolationStatusResolved}); err != nil {
+		t.Fatalf("Triage failed: %v", err)
+	}
+
+	resp, err := service.UpdateViolationStatus(ctx, &pb.UpdateViolationStatusRequest{
+		ViolationIds: append(ids, "missing"),
+		Status:       models.ViolationStatusAcknowledged,
+		Reason:       "on call",
+		Comment:      "investigating",
+	})
+	if err != nil {
+		t.Fatalf("UpdateViolationStatus failed: %v", err)
+	}
+	if len(resp.Updated) != 2 {
+		t.Errorf("Expected 2 updated violations, got %d", len(resp.Updated))
+	}
+	for _, v := range resp.Updated {
+		if v.Status != models.ViolationStatusAcknowledged || len(v.StatusHistory) != 1 || v.StatusHistory[0].Actor != "alice" {
+			t.Errorf("Unexpected updated violation: %v", v)
+		}
+		if len(v.Comments) != 1 || v.UpdatedAt == nil {
+			t.Errorf("Expected comment and updated_at on %s", v.Id)
+		}
+	}
+	failed := map[string]bool{}
+	for _, f := range resp.Failures {
+		failed[f.ViolationId] = true
+	}
+	if len(resp.Failures) != 2 || !failed[ids[2]] || !failed["missing"] {
+		t.Errorf("Expected resolved and missing violations to fail, got %v", resp.Failures)
+	}
+
+	listResp, err := service.ListViolations(ctx, &pb.ListViolationsRequest{Status: models.ViolationStatusAcknowledged})
+	if err != nil {
+		t.Fatalf("ListViolations failed: %v", err)
+	}
+	if listResp.TotalCount != 2 {
+		t.Errorf("Expected 2 acknowledged violations, got %d", listResp.TotalCount)
+	}
+}
+
+// TestViolationService_UpdateViolationStatus_InvalidRequest tests request-level validation
+func TestViolationService_UpdateViolationStatus_InvalidRequest(t *testing.T) {
+	service := NewViolationService(internalServices.NewViolationStoreMemory("test-key"))
+	ctx := context.Background()
+
+	requests := []*pb.UpdateViolationStatusRequest{
+		{Status: models.ViolationStatusResolved},                                   // No IDs
+		{ViolationIds: []string{"v-1"}, Status: "closed"},                          // Unknown status
+		{ViolationIds: make([]string, maxViolationPageSize+1), Status: "resolved"}, // Too many
+	}
+	for _, req := range requests {
+		if _, err := service.UpdateViolationStatus(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
+
+// TestViolationService_AssignAndComment tests assignment and comments
+func TestViolationService_AssignAndComment(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+	ctx := context.Background()
+
+	v, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "violation"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	bob := context.WithValue(ctx, middleware.ContextKeyEmail, "bob")
+	assignResp, err := service.AssignViolations(bob, &pb.AssignViolationsRequest{ViolationIds: []string{v.ID}, Assignee: "alice"})
+	if err != nil {
+		t.Fatalf("AssignViolations failed: %v", err)
+	}
+	if len(assignResp.Updated) != 1 || assignResp.Updated[0].Assignee != "alice" || assignResp.Updated[0].Status != models.ViolationStatusOpen {
+		t.Errorf("Expected open violation assigned to alice, got %v", assignResp)
+	}
+
+	alice := context.WithValue(ctx, middleware.ContextKeyEmail, "alice")
+	comment, err := service.AddViolationComment(alice, &pb.AddViolationCommentRequest{ViolationId: v.ID, Text: "benign"})
+	if err != nil {
+		t.Fatalf("AddViolationComment failed: %v", err)
+	}
+	if len(comment.Comments) != 1 || comment.Comments[0].Text != "benign" || comment.Comments[0].Author != "alice" {
+		t.Errorf("Unexpected comments: %v", comment.Comments)
+	}
+
+	_, err = service.AddViolationComment(alice, &pb.AddViolationCommentRequest{ViolationId: "missing", Text: "x"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	// Unauthenticated changes are attributed to an unknown author
+	comment, err = service.AddViolationComment(ctx, &pb.AddViolationCommentRequest{ViolationId: v.ID, Text: "x"})
+	if err != nil || comment.Comments[1].Author != unknownAuthor {
+		t.Errorf("Expected a comment by %s, got %v (%v)", unknownAuthor, comment, err)
+	}
+}
+
+// TestViolationService_VerifyViolation tests signature verification and the signed payload
+func TestViolationService_VerifyViolation(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := internalServices.NewViolationStoreDisk(dir, "test-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	v, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "violation"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	resp, err := NewViolationService(store).VerifyViolation(ctx, &pb.VerifyViolationRequest{ViolationId: v.ID})
+	if err != nil {
+		t.Fatalf("VerifyViolation failed: %v", err)
+	}
+	if !resp.Valid || resp.Algorithm != internalServices.SignatureAlgEdDSA || resp.KeyId != store.Keyring().Active().ID {
+		t.Errorf("Expected a valid EdDSA signature, got %v", resp)
+	}
+	if string(resp.SignedPayload) != string(internalServices.CanonicalViolation(v)) || resp.Violation.Signature != v.Signature {
+		t.Errorf("Expected the canonical payload and signature, got %s", resp.SignedPayload)
+	}
+	if _, err := NewViolationService(store).VerifyViolation(ctx, &pb.VerifyViolationRequest{ViolationId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+	store.Close()
+
+	// Without the signing key the stored signature no longer verifies
+	if err := os.RemoveAll(filepath.Join(dir, "keys")); err != nil {
+		t.Fatalf("Failed to remove keyring: %v", err)
+	}
+	reopened, err := internalServices.NewViolationStoreDisk(dir, "test-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	resp, err = NewViolationService(reopened).VerifyViolation(ctx, &pb.VerifyViolationRequest{ViolationId: v.ID})
+	if err != nil {
+		t.Fatalf("VerifyViolation failed: %v", err)
+	}
+	if resp.Valid || resp.Error == "" || resp.Violation != nil {
+		t.Errorf("Expected verification to fail, got %v", resp)
+	}
+
+	// With signing disabled nothing is verified, so no signature is valid
+	unsigned := internalServices.NewViolationStoreMemory("")
+	forged, err := unsigned.Record(ctx, models.Violation{RuleID: "rule-1", Message: "violation", Signature: "forged"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	resp, err = NewViolationService(unsigned).VerifyViolation(ctx, &pb.VerifyViolationRequest{ViolationId: forged.ID})
+	if err != nil {
+		t.Fatalf("VerifyViolation failed: %v", err)
+	}
+	if resp.Valid || !strings.Contains(resp.Error, "signing is disabled") {
+		t.Errorf("Expected an unverified signature with signing disabled, got %v", resp)
+	}
+}
+
+// fakeWatchStream collects events sent by WatchViolations
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.ViolationEvent
+}
+
+func (s *fakeWatchStream) Context() context.Context { return s.ctx }
+
+func (s *fakeWatchStream) Send(event *pb.ViolationEvent) error {
+	s.events <- event
+	return nil
+}
+
+// TestViolationService_WatchViolations tests replay from a cursor followed by live events
+func TestViolationService_WatchViolations(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	hub := internalServices.NewViolationHub(10)
+	service := NewViolationService(store)
+	service.SetViolationHub(hub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	base := time.Now()
+	store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "m", CreatedAt: base}, nil)
+	second, _ := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "m", CreatedAt: base.Add(time.Second)}, nil)
+	store.Record(ctx, models.Violation{RuleID: "rule-2", Severity: "HIGH", Message: "m", CreatedAt: base.Add(time.Second)}, nil)
+
+	// Page once to obtain the cursor after the first violation
+	page, err := service.ListViolations(ctx, &pb.ListViolationsRequest{RuleId: "rule-1", Order: "oldest", Limit: 1})
+	if err != nil {
+		t.Fatalf("ListViolations failed: %v", err)
+	}
+
+	stream := &fakeWatchStream{ctx: ctx, events: make(chan *pb.ViolationEvent, 10)}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- service.WatchViolations(&pb.WatchViolationsRequest{RuleId: "rule-1", Cursor: page.NextCursor}, stream)
+	}()
+
+	replayed := <-stream.events
+	if replayed.Violation.Id != second.ID || replayed.Cursor == "" {
+		t.Errorf("Expected replay of %s with cursor, got %v", second.ID, replayed)
+	}
+
+	for hub.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	live := models.Violation{ID: "live-1", RuleID: "rule-1", Severity: "HIGH", CreatedAt: base.Add(2 * time.Second)}
+	hub.Publish(models.Violation{ID: "other", RuleID: "rule-2", CreatedAt: base.Add(2 * time.Second)})
+	hub.Publish(live)
+	if got := <-stream.events; got.Violation.Id != "live-1" {
+		t.Errorf("Expected live-1, got %s", got.Violation.Id)
+	}
+
+	cancel()
+	if err := <-errc; status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled, got %v", err)
+	}
+}
+
+// TestViolationService_WatchViolations_Errors tests disabled streaming and bad cursors
+func TestViolationService_WatchViolations_Errors(t *testing.T) {
+	store := internalServices.NewViolationStoreMemory("test-key")
+	service := NewViolationService(store)
+	stream := &fakeWatchStream{ctx: context.Background(), events: make(chan *pb.ViolationEvent, 1)}
+
+	if err := service.WatchViolations(&pb.WatchViolationsRequest{}, stream); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a hub, got %v", err)
+	}
+
+	service.SetViolationHub(internalServices.NewViolationHub(1))
+	if err := service.WatchViolations(&pb.WatchViolationsRequest{Cursor: "!!"}, stream); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for bad cursor, got %v", err)
+	}
+}
+
+// TestViolationService_ReproduceViolation tests re-running a captured rule
+// against the evidence snapshot after the rule has changed
+func TestViolationService_ReproduceViolation(t *testing.T) {
+	ctx := context.Background()
+	engine := rules.NewRuleEngine()
+	store := internalServices.NewViolationStoreMemory("test-key")
+	spanService := NewSpanService(engine, store)
+	defer spanService.traceBuffer.Stop()
+	spanService.SetEvidenceRecorder(internalServices.NewEvidenceRecorder(internalServices.EvidenceLimits{}))
+
+	rule := models.Rule{
+		ID:         "payment-auth",
+		Name:       "Payments require auth",
+		Expression: "when { payment } always { auth }",
+		Enabled:    true,
+		Severity:   "HIGH",
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+	spanService.onTraceComplete(ctx, "trace-1", []*models.Span{
+		{TraceID: "trace-1", SpanID: "span-1", OperationName: "payment"},
+	})
+	violations, err := store.Query(ctx, internalServices.QueryFilters{RuleID: rule.ID})
+	if err != nil || len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d (%v)", len(violations), err)
+	}
+
+	// The rule changes after detection; the captured version still reproduces
+	rule.Expression = "when { payment } always { auth or sso }"
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to reload rule: %v", err)
+	}
+
+	service := NewViolationService(store)
+	if _, err := service.ReproduceViolation(ctx, &pb.ReproduceViolationRequest{ViolationId: violations[0].ID}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a rule engine, got %v", err)
+	}
+	service.SetRuleEngine(engine)
+
+	resp, err := service.ReproduceViolation(ctx, &pb.ReproduceViolationRequest{ViolationId: violations[0].ID})
+	if err != nil {
+		t.Fatalf("ReproduceViolation failed: %v", err)
+	}
+	if !resp.Reproduced || !resp.SignatureValid || resp.Error != "" {
+		t.Errorf("Expected a reproduced, signed violation, got %v", resp)
+	}
+	if resp.ExpressionDigest == resp.CurrentExpressionDigest || resp.CurrentExpressionDigest != internalServices.ExpressionDigest(rule.Expression) {
+		t.Errorf("Expected captured and current expression digests to differ, got %s and %s", resp.ExpressionDigest, resp.CurrentExpressionDigest)
+	}
+	if resp.Explanation == nil || !resp.Explanation.Violated || len(resp.Violation.Evidence.Spans) != 1 {
+		t.Errorf("Expected an explanation and the snapshot, got %v", resp)
+	}
+
+	// Violations recorded without a snapshot can't be reproduced
+	bare, err := store.Record(ctx, models.Violation{RuleID: rule.ID, Message: "no evidence"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := service.ReproduceViolation(ctx, &pb.ReproduceViolationRequest{ViolationId: bare.ID}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got %v", err)
+	}
+	if _, err := service.ReproduceViolation(ctx, &pb.ReproduceViolationRequest{ViolationId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+	// With signing disabled the signature is unverified, however it reads
+	unsigned := internalServices.NewViolationStoreMemory("")
+	forged := violations[0]
+	forged.ID = ""
+	forged, err = unsigned.Record(ctx, forged, forged.SpanRefs)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	unverified := NewViolationService(unsigned)
+	unverified.SetRuleEngine(engine)
+	resp, err = unverified.ReproduceViolation(ctx, &pb.ReproduceViolationRequest{ViolationId: forged.ID})
+	if err != nil {
+		t.Fatalf("ReproduceViolation failed: %v", err)
+	}
+	if !resp.Reproduced || resp.SignatureValid || !strings.Contains(resp.Error, "signing is disabled") {
+		t.Errorf("Expected a reproduced but unverified violation, got %v", resp)
+	}
+}
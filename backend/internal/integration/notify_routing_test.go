@@ -0,0 +1,166 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/betracehq/betrace/backend/generated/betrace/v1"
+	grpcServices "github.com/betracehq/betrace/backend/internal/grpc/services"
+	"github.com/betracehq/betrace/backend/internal/notify"
+	"github.com/betracehq/betrace/backend/internal/rules"
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// routedTemplate renders a webhook notification as its group and violations
+const routedTemplate = `{"groupLabels":{{json .GroupLabels}},"violations":{{json .Violations}}}`
+
+// routedNotification is a webhook notification rendered with routedTemplate
+type routedNotification struct {
+	GroupLabels map[string]string  `json:"groupLabels"`
+	Violations  []models.Violation `json:"violations"`
+}
+
+// notifyPipeline ingests spans and notifies about the violations they cause
+type notifyPipeline struct {
+	spans  *grpcServices.SpanService
+	cancel context.CancelFunc
+	done   chan error
+	n      *notify.Notifier
+}
+
+// newNotifyPipeline wires span ingestion to a notifier with cfg, the way the
+// backend does, and loads rule
+func newNotifyPipeline(t *testing.T, cfg notify.Config, rule models.Rule) *notifyPipeline {
+	t.Helper()
+	engine := rules.NewRuleEngine()
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+	store := services.NewViolationStoreMemory("test-signature-key")
+	hub := services.NewViolationHub(100)
+	spans := grpcServices.NewSpanService(engine, store)
+	spans.SetViolationHub(hub)
+
+	n, err := notify.NewNotifier(t.TempDir(), "test-signature-key", cfg)
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	n.Start()
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &notifyPipeline{spans: spans, cancel: cancel, done: make(chan error, 1), n: n}
+	go func() { p.done <- n.Run(ctx, store, hub) }()
+	for hub.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	t.Cleanup(p.close)
+	return p
+}
+
+func (p *notifyPipeline) close() {
+	p.cancel()
+	<-p.done
+	p.n.Close()
+}
+
+// ingest sends spans as one trace
+func (p *notifyPipeline) ingest(t *testing.T, traceID string, spans ...*pb.Span) {
+	t.Helper()
+	for _, span := range spans {
+		span.TraceId = traceID
+	}
+	if _, err := p.spans.IngestSpans(context.Background(), &pb.IngestSpansRequest{Spans: spans}); err != nil {
+		t.Fatalf("IngestSpans failed: %v", err)
+	}
+}
+
+// collect starts an endpoint decoding each request body as a T into a channel
+func collect[T any](t *testing.T) (*httptest.Server, chan T) {
+	t.Helper()
+	received := make(chan T, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload T
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Invalid payload %s: %v", body, err)
+		}
+		received <- payload
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// next waits for the next payload
+func next[T any](t *testing.T, received chan T) T {
+	t.Helper()
+	select {
+	case payload := <-received:
+		return payload
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a notification")
+		var zero T
+		return zero
+	}
+}
+
+// TestNotify_RoutesIngestedViolationsByService verifies violations of
+// ingested spans are routed by their service and grouped per service
+func TestNotify_RoutesIngestedViolationsByService(t *testing.T) {
+	checkoutServer, checkout := collect[routedNotification](t)
+	defaultServer, fallback := collect[routedNotification](t)
+	wait := 50 * time.Millisecond
+	p := newNotifyPipeline(t, notify.Config{
+		Webhooks: []notify.WebhookConfig{
+			{Name: "checkout-team", URL: checkoutServer.URL, Template: routedTemplate},
+			{Name: "everyone", URL: defaultServer.URL, Template: routedTemplate},
+		},
+		Route: &notify.RouteConfig{
+			Receiver:  "everyone",
+			GroupBy:   []string{notify.LabelService},
+			GroupWait: &wait,
+			Routes:    []notify.RouteConfig{{Receiver: "checkout-team", Services: []string{"checkout"}}},
+		},
+	}, models.Rule{ID: "payment-auth", Name: "Payments need auth", Expression: "when { payment } always { auth }", Enabled: true, Severity: "HIGH"})
+
+	// The service comes from the field, or the service.name attribute
+	p.ingest(t, "trace-1", &pb.Span{SpanId: "s1", Name: "payment", ServiceName: "checkout"})
+	p.ingest(t, "trace-2", &pb.Span{SpanId: "s2", Name: "payment", Attributes: map[string]string{"service.name": "billing"}})
+	p.ingest(t, "trace-3", &pb.Span{SpanId: "s3", Name: "payment", ServiceName: "inventory"})
+
+	got := next(t, checkout)
+	if got.GroupLabels[notify.LabelService] != "checkout" || len(got.Violations) != 1 || got.Violations[0].TraceIDs[0] != "trace-1" {
+		t.Errorf("Expected checkout's violation routed to its team, got %+v", got)
+	}
+
+	groups := map[string][]string{}
+	for i := 0; i < 2; i++ {
+		got := next(t, fallback)
+		for _, v := range got.Violations {
+			groups[got.GroupLabels[notify.LabelService]] = append(groups[got.GroupLabels[notify.LabelService]], v.TraceIDs[0])
+		}
+	}
+	if len(groups) != 2 || len(groups["billing"]) != 1 || groups["billing"][0] != "trace-2" || len(groups["inventory"]) != 1 || groups["inventory"][0] != "trace-3" {
+		t.Errorf("Expected billing and inventory notified in their own groups, got %v", groups)
+	}
+}
+
+// TestNotify_LabelsIngestedAlertsWithService verifies alerts for violations
+// of ingested spans carry the service label
+func TestNotify_LabelsIngestedAlertsWithService(t *testing.T) {
+	server, received := collect[[]notify.Alert](t)
+	p := newNotifyPipeline(t, notify.Config{
+		Alertmanagers: []notify.AlertmanagerConfig{{Name: "alertmanager", URL: server.URL}},
+	}, models.Rule{ID: "payment-auth", Name: "Payments need auth", Expression: "when { payment } always { auth }", Enabled: true, Severity: "HIGH"})
+
+	p.ingest(t, "trace-1", &pb.Span{SpanId: "s1", Name: "payment", Attributes: map[string]string{"service.name": "checkout"}})
+
+	alerts := next(t, received)
+	if len(alerts) != 1 || alerts[0].Labels[notify.AlertLabelService] != "checkout" {
+		t.Errorf("Expected one alert labeled service=checkout, got %+v", alerts)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/betracehq/betrace/backend/internal/services"
 	"github.com/betracehq/betrace/backend/internal/storage"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TestServer is a helper that starts services in-process for testing
@@ -85,6 +87,30 @@ func (c *directRuleClient) DisableRule(ctx context.Context, req *pb.DisableRuleR
 	return c.service.DisableRule(ctx, req)
 }
 
+func (c *directRuleClient) ListRuleMatches(ctx context.Context, req *pb.ListRuleMatchesRequest, opts ...grpc.CallOption) (*pb.ListRuleMatchesResponse, error) {
+	return c.service.ListRuleMatches(ctx, req)
+}
+
+func (c *directRuleClient) GetControlCoverage(ctx context.Context, req *pb.GetControlCoverageRequest, opts ...grpc.CallOption) (*pb.GetControlCoverageResponse, error) {
+	return c.service.GetControlCoverage(ctx, req)
+}
+
+func (c *directRuleClient) PromoteRule(ctx context.Context, req *pb.PromoteRuleRequest, opts ...grpc.CallOption) (*pb.Rule, error) {
+	return c.service.PromoteRule(ctx, req)
+}
+
+func (c *directRuleClient) ListRuleVersions(ctx context.Context, req *pb.ListRuleVersionsRequest, opts ...grpc.CallOption) (*pb.ListRuleVersionsResponse, error) {
+	return c.service.ListRuleVersions(ctx, req)
+}
+
+func (c *directRuleClient) DiffRuleVersions(ctx context.Context, req *pb.DiffRuleVersionsRequest, opts ...grpc.CallOption) (*pb.DiffRuleVersionsResponse, error) {
+	return c.service.DiffRuleVersions(ctx, req)
+}
+
+func (c *directRuleClient) RollbackRule(ctx context.Context, req *pb.RollbackRuleRequest, opts ...grpc.CallOption) (*pb.Rule, error) {
+	return c.service.RollbackRule(ctx, req)
+}
+
 type directSpanClient struct {
 	service *grpcServices.SpanService
 }
@@ -101,6 +127,38 @@ func (c *directViolationClient) ListViolations(ctx context.Context, req *pb.List
 	return c.service.ListViolations(ctx, req)
 }
 
+func (c *directViolationClient) WatchViolations(ctx context.Context, req *pb.WatchViolationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.ViolationEvent], error) {
+	return nil, status.Error(codes.Unimplemented, "streaming requires a real gRPC connection")
+}
+
+func (c *directViolationClient) ListIncidents(ctx context.Context, req *pb.ListIncidentsRequest, opts ...grpc.CallOption) (*pb.ListIncidentsResponse, error) {
+	return c.service.ListIncidents(ctx, req)
+}
+
+func (c *directViolationClient) GetIncident(ctx context.Context, req *pb.GetIncidentRequest, opts ...grpc.CallOption) (*pb.GetIncidentResponse, error) {
+	return c.service.GetIncident(ctx, req)
+}
+
+func (c *directViolationClient) UpdateViolationStatus(ctx context.Context, req *pb.UpdateViolationStatusRequest, opts ...grpc.CallOption) (*pb.UpdateViolationStatusResponse, error) {
+	return c.service.UpdateViolationStatus(ctx, req)
+}
+
+func (c *directViolationClient) AssignViolations(ctx context.Context, req *pb.AssignViolationsRequest, opts ...grpc.CallOption) (*pb.UpdateViolationStatusResponse, error) {
+	return c.service.AssignViolations(ctx, req)
+}
+
+func (c *directViolationClient) VerifyViolation(ctx context.Context, req *pb.VerifyViolationRequest, opts ...grpc.CallOption) (*pb.VerifyViolationResponse, error) {
+	return c.service.VerifyViolation(ctx, req)
+}
+
+func (c *directViolationClient) ReproduceViolation(ctx context.Context, req *pb.ReproduceViolationRequest, opts ...grpc.CallOption) (*pb.ReproduceViolationResponse, error) {
+	return c.service.ReproduceViolation(ctx, req)
+}
+
+func (c *directViolationClient) AddViolationComment(ctx context.Context, req *pb.AddViolationCommentRequest, opts ...grpc.CallOption) (*pb.Violation, error) {
+	return c.service.AddViolationComment(ctx, req)
+}
+
 type directHealthClient struct {
 	service *grpcServices.HealthService
 }
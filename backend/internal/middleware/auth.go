@@ -14,6 +14,11 @@ import (
 	"time"
 
 	"crypto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // Context keys for auth claims
@@ -89,10 +94,7 @@ func (m *AuthMiddleware) Handler(next http.Handler) http.Handler {
 		// Skip auth in demo mode
 		if m.demoMode {
 			// Set demo context values
-			ctx := context.WithValue(r.Context(), ContextKeyUserID, "demo-user")
-			ctx = context.WithValue(ctx, ContextKeyOrgID, "demo-tenant")
-			ctx = context.WithValue(ctx, ContextKeyEmail, "demo@betrace.dev")
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(withDemoClaims(r.Context())))
 			return
 		}
 
@@ -118,13 +120,47 @@ func (m *AuthMiddleware) Handler(next http.Handler) http.Handler {
 		}
 
 		// Set claims in context
-		ctx := context.WithValue(r.Context(), ContextKeyUserID, claims.Sub)
-		ctx = context.WithValue(ctx, ContextKeyOrgID, claims.OrgID)
-		ctx = context.WithValue(ctx, ContextKeyEmail, claims.Email)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
 	})
 }
 
+// UnaryServerInterceptor returns a gRPC interceptor that puts the caller's
+// identity in the context, like Handler. It identifies rather than enforces:
+// calls without a bearer token proceed anonymously, while invalid tokens
+// are rejected. The grpc-gateway forwards the Authorization header as
+// "authorization" metadata.
+func (m *AuthMiddleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if m.demoMode {
+			return handler(withDemoClaims(ctx), req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+			return handler(ctx, req)
+		}
+
+		claims, err := m.validateJWT(strings.TrimPrefix(values[0], "Bearer "))
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+		return handler(withClaims(ctx, claims), req)
+	}
+}
+
+// withClaims sets a validated token's claims in ctx
+func withClaims(ctx context.Context, claims *JWTClaims) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyUserID, claims.Sub)
+	ctx = context.WithValue(ctx, ContextKeyOrgID, claims.OrgID)
+	return context.WithValue(ctx, ContextKeyEmail, claims.Email)
+}
+
+// withDemoClaims sets the demo mode identity in ctx
+func withDemoClaims(ctx context.Context) context.Context {
+	return withClaims(ctx, &JWTClaims{Sub: "demo-user", OrgID: "demo-tenant", Email: "demo@betrace.dev"})
+}
+
 func (m *AuthMiddleware) validateJWT(tokenStr string) (*JWTClaims, error) {
 	parts := strings.Split(tokenStr, ".")
 	if len(parts) != 3 {
@@ -0,0 +1,241 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// alertsPath is the Alertmanager v2 API endpoint for posting alerts
+const alertsPath = "/api/v2/alerts"
+
+// DefaultResolveAfter is how long an incident stays firing after its latest
+// violation. Routes to an Alertmanager must notify more often than this, so
+// it is well above DefaultGroupInterval.
+const DefaultResolveAfter = 15 * time.Minute
+
+// Labels set on every alert, besides Labels from the config and "key:value" rule tags
+const (
+	AlertLabelName        = "alertname" // Rule name
+	AlertLabelRuleID      = "rule_id"
+	AlertLabelSeverity    = "severity"
+	AlertLabelService     = "service"     // Comma-separated services of the referenced spans
+	AlertLabelFingerprint = "fingerprint" // Incident fingerprint: one alert per incident
+	AlertLabelTags        = "tags"        // All rule tags as ",a,b,", for regex matchers like tags=~".*,pci,.*"
+)
+
+// labelName matches valid Prometheus label names
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// AlertmanagerConfig configures one Alertmanager receiver
+type AlertmanagerConfig struct {
+	Name string
+	URL  string // Base URL, e.g. http://alertmanager:9093
+	// TraceURL is a text/template rendering a trace link from {{.TraceID}},
+	// e.g. https://grafana.example.com/explore?traceId={{.TraceID}}
+	TraceURL string
+	Labels   map[string]string // Added to every alert
+	Headers  map[string]string // E.g. Authorization
+	// ResolveAfter is how long an incident stays firing without new violations
+	ResolveAfter   time.Duration
+	MaxAttempts    int // Including the first
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // Per attempt
+	QueueSize      int           // Pending deliveries before new ones are dead-lettered
+}
+
+// Alert is an Alertmanager v2 API alert
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// Alertmanager posts violations to Alertmanager as alerts, one per
+// incident. Each post sets the alert to end ResolveAfter past the incident's
+// latest violation, so Alertmanager resolves it once the incident goes quiet.
+type Alertmanager struct {
+	*deliveryQueue
+	cfg      AlertmanagerConfig
+	traceURL *template.Template
+	client   *http.Client
+}
+
+// NewAlertmanager validates cfg and creates an Alertmanager receiver; call Start to begin delivering
+func NewAlertmanager(cfg AlertmanagerConfig, dlq *DeadLetterQueue) (*Alertmanager, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("alertmanager name is required")
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("alertmanager %s: url is required", cfg.Name)
+	}
+	for name := range cfg.Labels {
+		if !labelName.MatchString(name) {
+			return nil, fmt.Errorf("alertmanager %s: invalid label name %q", cfg.Name, name)
+		}
+	}
+	if cfg.ResolveAfter <= 0 {
+		cfg.ResolveAfter = DefaultResolveAfter
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	a := &Alertmanager{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+	if cfg.TraceURL != "" {
+		tmpl, err := template.New(cfg.Name).Parse(cfg.TraceURL)
+		if err != nil {
+			return nil, fmt.Errorf("alertmanager %s: invalid trace_url template: %w", cfg.Name, err)
+		}
+		a.traceURL = tmpl
+	}
+	a.deliveryQueue = newDeliveryQueue(cfg.Name, queueConfig{
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		queueSize:      cfg.QueueSize,
+	}, a.render, a.send, dlq)
+	return a, nil
+}
+
+// Alerts converts violations to alerts, merging violations of the same incident
+func (a *Alertmanager) Alerts(violations []models.Violation) ([]Alert, error) {
+	var alerts []Alert
+	byIncident := make(map[string]int)
+	for _, v := range violations {
+		key := v.Fingerprint
+		if key == "" {
+			key = v.ID
+		}
+
+		i, seen := byIncident[key]
+		if !seen {
+			byIncident[key] = len(alerts)
+			alerts = append(alerts, Alert{StartsAt: v.CreatedAt})
+			i = len(alerts) - 1
+		}
+		alert := &alerts[i]
+		if v.CreatedAt.Before(alert.StartsAt) {
+			alert.StartsAt = v.CreatedAt
+		}
+		if seen && v.CreatedAt.Add(a.cfg.ResolveAfter).Before(alert.EndsAt) {
+			continue
+		}
+
+		// Latest violation of the incident: it sets labels and annotations
+		link, err := a.traceLink(v)
+		if err != nil {
+			return nil, err
+		}
+		alert.Labels = a.labels(v, key)
+		alert.Annotations = annotations(v, link)
+		alert.EndsAt = v.CreatedAt.Add(a.cfg.ResolveAfter)
+		alert.GeneratorURL = link
+	}
+	return alerts, nil
+}
+
+func (a *Alertmanager) labels(v models.Violation, fingerprint string) map[string]string {
+	labels := make(map[string]string, len(a.cfg.Labels)+len(v.Tags)+6)
+	for k, value := range a.cfg.Labels {
+		labels[k] = value
+	}
+
+	// "key:value" tags become labels, without overriding the standard ones
+	tags := append([]string(nil), v.Tags...)
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if k, value, ok := strings.Cut(tag, ":"); ok && labelName.MatchString(k) && value != "" {
+			labels[k] = value
+		}
+	}
+
+	name := v.RuleName
+	if name == "" {
+		name = v.RuleID
+	}
+	labels[AlertLabelName] = name
+	labels[AlertLabelRuleID] = v.RuleID
+	labels[AlertLabelSeverity] = v.Severity
+	labels[AlertLabelFingerprint] = fingerprint
+	if services := serviceNames(v.SpanRefs); len(services) > 0 {
+		labels[AlertLabelService] = strings.Join(services, ",")
+	}
+	if len(tags) > 0 {
+		labels[AlertLabelTags] = "," + strings.Join(tags, ",") + ","
+	}
+	return labels
+}
+
+func annotations(v models.Violation, traceLink string) map[string]string {
+	result := map[string]string{
+		"summary":      fmt.Sprintf("%s violation of %s", v.Severity, v.RuleName),
+		"message":      v.Message,
+		"violation_id": v.ID,
+	}
+	if len(v.TraceIDs) > 0 {
+		result["trace_id"] = v.TraceIDs[0]
+	}
+	if traceLink != "" {
+		result["trace_url"] = traceLink
+	}
+	return result
+}
+
+// traceLink renders TraceURL for the violation's first trace
+func (a *Alertmanager) traceLink(v models.Violation) (string, error) {
+	if a.traceURL == nil || len(v.TraceIDs) == 0 {
+		return "", nil
+	}
+	var link bytes.Buffer
+	if err := a.traceURL.Execute(&link, struct{ TraceID string }{v.TraceIDs[0]}); err != nil {
+		return "", err
+	}
+	return link.String(), nil
+}
+
+func (a *Alertmanager) render(n Notification) ([]byte, error) {
+	alerts, err := a.Alerts(n.Violations)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(alerts)
+}
+
+// send posts one batch of alerts
+func (a *Alertmanager) send(ctx context.Context, _ string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(a.cfg.URL, "/")+alertsPath, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	for k, v := range a.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BeTrace-Alertmanager/1.0")
+	return do(a.client, req)
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func startAlertmanager(t *testing.T, cfg AlertmanagerConfig) *Alertmanager {
+	t.Helper()
+	dlq, err := NewDeadLetterQueue(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue failed: %v", err)
+	}
+	if cfg.Name == "" {
+		cfg.Name = "alertmanager"
+	}
+	a, err := NewAlertmanager(cfg, dlq)
+	if err != nil {
+		t.Fatalf("NewAlertmanager failed: %v", err)
+	}
+	a.Start()
+	t.Cleanup(a.Close)
+	return a
+}
+
+func incidentViolation(id, fingerprint string, createdAt time.Time) models.Violation {
+	v := testViolation(id)
+	v.Fingerprint = fingerprint
+	v.CreatedAt = createdAt
+	v.TraceIDs = []string{"trace-" + id}
+	v.Tags = []string{"pci", "owner:payments"}
+	v.SpanRefs = []models.SpanRef{{TraceID: "trace-" + id, SpanID: "s", ServiceName: "checkout"}}
+	return v
+}
+
+// firing reports whether Alertmanager considers alert active at time at
+func firing(alert Alert, at time.Time) bool {
+	return !alert.StartsAt.After(at) && at.Before(alert.EndsAt)
+}
+
+func TestAlertmanager_PostsAlerts(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	a := startAlertmanager(t, AlertmanagerConfig{
+		URL:      server.URL + "/",
+		TraceURL: "https://grafana.example.com/explore?traceId={{.TraceID}}",
+		Labels:   map[string]string{"env": "prod"},
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+	})
+
+	now := time.Now()
+	a.Send(single(incidentViolation("v-1", "fp-1", now)))
+	recv.wait(t, 1)
+
+	req := recv.requests[0]
+	if req.URL.Path != "/api/v2/alerts" || req.Method != "POST" || req.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Unexpected request %s %s %v", req.Method, req.URL.Path, req.Header)
+	}
+
+	var alerts []Alert
+	if err := json.Unmarshal(recv.bodies[0], &alerts); err != nil || len(alerts) != 1 {
+		t.Fatalf("Expected one alert, got %s (%v)", recv.bodies[0], err)
+	}
+	alert := alerts[0]
+
+	wantLabels := map[string]string{
+		AlertLabelName:        "PII access",
+		AlertLabelRuleID:      "rule-1",
+		AlertLabelSeverity:    "HIGH",
+		AlertLabelService:     "checkout",
+		AlertLabelFingerprint: "fp-1",
+		AlertLabelTags:        ",owner:payments,pci,",
+		"owner":               "payments",
+		"env":                 "prod",
+	}
+	for k, want := range wantLabels {
+		if got := alert.Labels[k]; got != want {
+			t.Errorf("Label %s: expected %q, got %q", k, want, got)
+		}
+	}
+
+	link := "https://grafana.example.com/explore?traceId=trace-v-1"
+	if alert.Annotations["message"] != "unaudited access" || alert.Annotations["trace_url"] != link || alert.GeneratorURL != link {
+		t.Errorf("Unexpected annotations %v / generator %s", alert.Annotations, alert.GeneratorURL)
+	}
+	if !firing(alert, now) {
+		t.Errorf("Expected the alert to be firing: %+v", alert)
+	}
+}
+
+func TestAlertmanager_ResolvesWhenIncidentGoesQuiet(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	a := startAlertmanager(t, AlertmanagerConfig{URL: server.URL, ResolveAfter: time.Minute})
+
+	start := time.Now()
+	a.Send(Notification{Violations: []models.Violation{
+		incidentViolation("v-1", "fp-1", start),
+		incidentViolation("v-3", "fp-1", start.Add(2*time.Minute)),
+		incidentViolation("v-2", "fp-1", start.Add(time.Minute)),
+		incidentViolation("v-4", "fp-2", start),
+	}})
+	recv.wait(t, 1)
+
+	var alerts []Alert
+	if err := json.Unmarshal(recv.bodies[0], &alerts); err != nil || len(alerts) != 2 {
+		t.Fatalf("Expected one alert per incident, got %s (%v)", recv.bodies[0], err)
+	}
+
+	busy, quiet := alerts[0], alerts[1]
+	if !busy.StartsAt.Equal(start) || busy.Annotations["violation_id"] != "v-3" {
+		t.Errorf("Expected the incident's first start and latest violation, got %+v", busy)
+	}
+
+	// Firing while violations keep arriving, resolved a minute after the last
+	if !firing(busy, start.Add(2*time.Minute+30*time.Second)) || firing(busy, start.Add(3*time.Minute+time.Second)) {
+		t.Errorf("Expected fp-1 to resolve a minute after its latest violation, ends %v", busy.EndsAt.Sub(start))
+	}
+	if firing(quiet, start.Add(time.Minute+time.Second)) {
+		t.Errorf("Expected fp-2 to resolve a minute after its only violation, ends %v", quiet.EndsAt.Sub(start))
+	}
+}
+
+func TestNewAlertmanager_Invalid(t *testing.T) {
+	tests := map[string]AlertmanagerConfig{
+		"missing url":   {Name: "am"},
+		"bad label":     {Name: "am", URL: "http://am", Labels: map[string]string{"bad-name": "x"}},
+		"bad trace url": {Name: "am", URL: "http://am", TraceURL: "{{.TraceID"},
+		"missing name":  {URL: "http://am"},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAlertmanager(cfg, nil); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// deadLetterFile is the dead-letter queue file name inside its directory
+const deadLetterFile = "dead-letters.jsonl"
+
+// DeadLetter is a notification that could not be delivered
+type DeadLetter struct {
+	DeliveryID   string    `json:"deliveryId"`
+	Endpoint     string    `json:"endpoint"`
+	ViolationIDs []string  `json:"violationIds"`
+	Payload      string    `json:"payload,omitempty"` // Rendered body, if rendering succeeded
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"lastError"`
+	FailedAt     time.Time `json:"failedAt"`
+
+	// Notification is kept so it can be re-rendered and redelivered
+	Notification Notification `json:"notification"`
+}
+
+// DeadLetterQueue stores undeliverable notifications on disk, one JSON
+// object per line, so they survive restarts and can be inspected or redelivered
+type DeadLetterQueue struct {
+	mu   sync.Mutex
+	path string
+
+	// redeliverMu serializes redeliveries, which remove letters by position
+	redeliverMu sync.Mutex
+}
+
+// NewDeadLetterQueue opens (or creates) a dead-letter queue in dir
+func NewDeadLetterQueue(dir string) (*DeadLetterQueue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	return &DeadLetterQueue{path: filepath.Join(dir, deadLetterFile)}, nil
+}
+
+// Append durably adds a dead letter
+func (q *DeadLetterQueue) Append(letter DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, err := os.OpenFile(q.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter queue: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return f.Sync()
+}
+
+// List returns all dead letters, oldest first
+func (q *DeadLetterQueue) List() ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.read()
+}
+
+// Redeliver offers every dead letter, oldest first, to requeue, and removes
+// the letters it accepted only after they have all been handed off. A crash
+// in between redelivers them again rather than losing them. Letters appended
+// meanwhile are kept.
+func (q *DeadLetterQueue) Redeliver(requeue func(DeadLetter) bool) (int, error) {
+	q.redeliverMu.Lock()
+	defer q.redeliverMu.Unlock()
+
+	letters, err := q.List()
+	if err != nil {
+		return 0, err
+	}
+	handedOff := make([]bool, len(letters))
+	requeued := 0
+	for i, letter := range letters {
+		if requeue(letter) {
+			handedOff[i] = true
+			requeued++
+		}
+	}
+	if requeued == 0 {
+		return 0, nil
+	}
+	return requeued, q.remove(handedOff)
+}
+
+// remove atomically rewrites the queue without the letters marked in
+// handedOff, which are the first letters in the file
+func (q *DeadLetterQueue) remove(handedOff []bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letters, err := q.read()
+	if err != nil {
+		return err
+	}
+
+	tmp := q.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite dead-letter queue: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for i, letter := range letters {
+		if i < len(handedOff) && handedOff[i] {
+			continue
+		}
+		data, err := json.Marshal(letter)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to rewrite dead-letter queue: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to rewrite dead-letter queue: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to rewrite dead-letter queue: %w", err)
+	}
+	return os.Rename(tmp, q.path)
+}
+
+func (q *DeadLetterQueue) read() ([]DeadLetter, error) {
+	f, err := os.Open(q.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter queue: %w", err)
+	}
+	defer f.Close()
+
+	var letters []DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var letter DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			// A torn final line from a crash mid-append; keep the rest
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	return letters, scanner.Err()
+}
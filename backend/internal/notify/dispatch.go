@@ -0,0 +1,239 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// dispatcher batches routed violations into groups and notifies each
+// group's receiver on its route's group_wait/group_interval/repeat_interval.
+//
+// A group starts with the first violation for its route and labels. It
+// ends once a group_interval passes without new violations, unless the
+// route sends reminders: then it lives until none of its violations is
+// still open in the store.
+type dispatcher struct {
+	root      *route
+	receivers map[string]Receiver
+	store     services.ViolationStore
+	save      func(cursor string) // Persists the committed stream position
+
+	mu       sync.Mutex
+	groups   map[string]*group
+	progress progress
+	stopped  bool
+}
+
+type group struct {
+	key      string
+	route    *route
+	labels   map[string]string
+	pending  []pendingViolation // Not yet notified
+	sent     []models.Violation // Last notification, for reminders
+	lastSent time.Time
+
+	timer *time.Timer
+	next  time.Time // When timer fires
+	gen   int       // Invalidates superseded timers
+}
+
+type pendingViolation struct {
+	violation models.Violation
+	position  *position
+}
+
+func newDispatcher(root *route, receivers map[string]Receiver, store services.ViolationStore, cursor string, save func(string)) *dispatcher {
+	return &dispatcher{
+		root:      root,
+		receivers: receivers,
+		store:     store,
+		save:      save,
+		groups:    make(map[string]*group),
+		progress:  progress{last: cursor},
+	}
+}
+
+// dispatch routes v (read at stream position cursor) into its groups
+func (d *dispatcher) dispatch(v models.Violation, cursor string) {
+	routes := d.root.match(&v)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+
+	pos := d.progress.add(cursor, len(routes))
+	now := time.Now()
+	for _, r := range routes {
+		labels := r.groupLabels(&v)
+		key := r.groupKey(labels)
+
+		g, ok := d.groups[key]
+		if !ok {
+			g = &group{key: key, route: r, labels: labels}
+			d.groups[key] = g
+			d.schedule(g, now.Add(r.groupWait))
+		} else if due := g.lastSent.Add(r.groupInterval); !g.lastSent.IsZero() && g.next.After(due) {
+			// Waiting for a reminder; new violations go out on the group interval
+			d.schedule(g, maxTime(now, due))
+		}
+		g.pending = append(g.pending, pendingViolation{violation: v, position: pos})
+	}
+	d.commit()
+}
+
+// stop cancels all group timers. Violations still waiting in groups are
+// not lost: the committed cursor precedes them, so they are re-read from
+// the store on the next run.
+func (d *dispatcher) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	for _, g := range d.groups {
+		g.timer.Stop()
+	}
+}
+
+// schedule (re)arms g's timer to fire at t. The caller holds d.mu.
+func (d *dispatcher) schedule(g *group, t time.Time) {
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.gen++
+	gen := g.gen
+	g.next = t
+	g.timer = time.AfterFunc(time.Until(t), func() { d.flush(g, gen) })
+}
+
+// flush notifies about g's new violations, or sends a reminder, or ends the group
+func (d *dispatcher) flush(g *group, gen int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped || g.gen != gen || d.groups[g.key] != g {
+		return
+	}
+
+	now := time.Now()
+	r := g.route
+	switch {
+	case len(g.pending) > 0:
+		batch := make([]models.Violation, len(g.pending))
+		for i, p := range g.pending {
+			batch[i] = p.violation
+			d.progress.done(p.position)
+		}
+		g.pending = nil
+		d.notify(g, batch, false)
+		g.sent, g.lastSent = batch, now
+		d.schedule(g, now.Add(r.groupInterval))
+		d.commit()
+
+	case r.repeatInterval <= 0:
+		delete(d.groups, g.key)
+
+	case now.Before(g.lastSent.Add(r.repeatInterval)):
+		d.schedule(g, g.lastSent.Add(r.repeatInterval))
+
+	default:
+		open := d.stillOpen(g.sent)
+		if len(open) == 0 {
+			delete(d.groups, g.key)
+			return
+		}
+		d.notify(g, open, true)
+		g.sent, g.lastSent = open, now
+		d.schedule(g, now.Add(r.repeatInterval))
+	}
+}
+
+func (d *dispatcher) notify(g *group, violations []models.Violation, repeat bool) {
+	d.receivers[g.route.receiver].Send(Notification{
+		GroupKey:    g.key,
+		GroupLabels: g.labels,
+		Repeat:      repeat,
+		Violations:  violations,
+	})
+}
+
+// stillOpen re-reads violations from the store and keeps those not yet
+// resolved, marked false positive or suppressed
+func (d *dispatcher) stillOpen(violations []models.Violation) []models.Violation {
+	var open []models.Violation
+	for _, v := range violations {
+		current, err := d.store.GetByID(context.Background(), v.ID)
+		if err != nil {
+			// Evicted by retention; nothing left to remind about
+			continue
+		}
+		switch current.Status {
+		case "", models.ViolationStatusOpen, models.ViolationStatusAcknowledged:
+			open = append(open, *current)
+		}
+	}
+	return open
+}
+
+// commit saves the committed cursor. The caller holds d.mu.
+func (d *dispatcher) commit() {
+	d.save(d.progress.committed())
+}
+
+// progress tracks which stream positions have been handed to receivers, so
+// the saved cursor never moves past a violation still waiting in a group
+type progress struct {
+	last     string      // Cursor of the latest dispatched violation
+	inflight []*position // Oldest first
+}
+
+// position is a dispatched violation waiting in refs groups
+type position struct {
+	prev string // Cursor just before the violation
+	refs int
+}
+
+func (p *progress) add(cursor string, refs int) *position {
+	pos := &position{prev: p.last, refs: refs}
+	p.last = cursor
+	if refs > 0 {
+		p.inflight = append(p.inflight, pos)
+	}
+	return pos
+}
+
+func (p *progress) done(pos *position) {
+	pos.refs--
+	for len(p.inflight) > 0 && p.inflight[0].refs <= 0 {
+		p.inflight = p.inflight[1:]
+	}
+}
+
+// committed returns the cursor to resume from after a restart
+func (p *progress) committed() string {
+	if len(p.inflight) > 0 {
+		return p.inflight[0].prev
+	}
+	return p.last
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// logSave adapts a cursor writer for use from timer goroutines
+func logSave(save func(string) error) func(string) {
+	return func(cursor string) {
+		if err := save(cursor); err != nil {
+			log.Printf("Notifier: %v", err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// recordingReceiver captures notifications instead of delivering them
+type recordingReceiver struct {
+	name string
+	sent chan Notification
+}
+
+func newRecordingReceiver(name string) *recordingReceiver {
+	return &recordingReceiver{name: name, sent: make(chan Notification, 100)}
+}
+
+func (r *recordingReceiver) Name() string        { return r.name }
+func (r *recordingReceiver) Send(n Notification) { r.sent <- n }
+func (r *recordingReceiver) Start()              {}
+func (r *recordingReceiver) Close()              {}
+
+func (r *recordingReceiver) next(t *testing.T) Notification {
+	t.Helper()
+	select {
+	case n := <-r.sent:
+		return n
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for a notification to %s", r.name)
+		return Notification{}
+	}
+}
+
+func (r *recordingReceiver) none(t *testing.T, within time.Duration) {
+	t.Helper()
+	select {
+	case n := <-r.sent:
+		t.Fatalf("Unexpected notification to %s: %+v", r.name, n)
+	case <-time.After(within):
+	}
+}
+
+// cursorLog records the cursors a dispatcher commits
+type cursorLog struct {
+	mu      sync.Mutex
+	cursors []string
+}
+
+func (c *cursorLog) save(cursor string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cursors = append(c.cursors, cursor)
+}
+
+func (c *cursorLog) last() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cursors[len(c.cursors)-1]
+}
+
+func newTestDispatcher(t *testing.T, cfg RouteConfig, store services.ViolationStore, receivers ...*recordingReceiver) (*dispatcher, *cursorLog) {
+	t.Helper()
+	byName := make(map[string]Receiver, len(receivers))
+	for _, r := range receivers {
+		byName[r.name] = r
+	}
+	root, err := newRouteTree(cfg, byName)
+	if err != nil {
+		t.Fatalf("newRouteTree failed: %v", err)
+	}
+	cursors := &cursorLog{}
+	d := newDispatcher(root, byName, store, "", cursors.save)
+	t.Cleanup(d.stop)
+	return d, cursors
+}
+
+func ruleViolation(id, ruleID string) models.Violation {
+	v := testViolation(id)
+	v.RuleID = ruleID
+	return v
+}
+
+func TestDispatcher_GroupWaitBatches(t *testing.T) {
+	recv := newRecordingReceiver("digest")
+	d, _ := newTestDispatcher(t, RouteConfig{
+		Receiver:      "digest",
+		GroupBy:       []string{LabelRuleID},
+		GroupWait:     duration(50 * time.Millisecond),
+		GroupInterval: duration(time.Hour),
+	}, nil, recv)
+
+	d.dispatch(ruleViolation("v-1", "rule-A"), "c1")
+	d.dispatch(ruleViolation("v-2", "rule-A"), "c2")
+	d.dispatch(ruleViolation("v-3", "rule-B"), "c3")
+
+	got := map[string][]string{}
+	for i := 0; i < 2; i++ {
+		n := recv.next(t)
+		got[n.GroupLabels[LabelRuleID]] = violationIDs(n.Violations)
+	}
+	if len(got["rule-A"]) != 2 || len(got["rule-B"]) != 1 {
+		t.Errorf("Expected one batch per rule, got %v", got)
+	}
+
+	// Later violations wait for the group interval
+	d.dispatch(ruleViolation("v-4", "rule-A"), "c4")
+	recv.none(t, 100*time.Millisecond)
+}
+
+func TestDispatcher_GroupInterval(t *testing.T) {
+	recv := newRecordingReceiver("hook")
+	d, _ := newTestDispatcher(t, RouteConfig{
+		Receiver:      "hook",
+		GroupWait:     duration(0),
+		GroupInterval: duration(100 * time.Millisecond),
+	}, nil, recv)
+
+	d.dispatch(ruleViolation("v-1", "rule-A"), "c1")
+	first := recv.next(t)
+	sentAt := time.Now()
+
+	d.dispatch(ruleViolation("v-2", "rule-A"), "c2")
+	d.dispatch(ruleViolation("v-3", "rule-A"), "c3")
+	second := recv.next(t)
+
+	if elapsed := time.Since(sentAt); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected second notification after the group interval, got %v", elapsed)
+	}
+	if len(first.Violations) != 1 || len(second.Violations) != 2 {
+		t.Errorf("Expected batches of 1 then 2, got %d then %d", len(first.Violations), len(second.Violations))
+	}
+	if first.GroupKey != second.GroupKey {
+		t.Errorf("Expected the same group, got %s and %s", first.GroupKey, second.GroupKey)
+	}
+}
+
+func TestDispatcher_RepeatRemindsAboutOpenViolations(t *testing.T) {
+	ctx := context.Background()
+	store := services.NewViolationStoreMemory(testKey)
+	open, _ := store.Record(ctx, testViolation(""), nil)
+	resolved, _ := store.Record(ctx, testViolation(""), nil)
+
+	recv := newRecordingReceiver("hook")
+	d, _ := newTestDispatcher(t, RouteConfig{
+		Receiver:       "hook",
+		GroupWait:      duration(0),
+		GroupInterval:  duration(20 * time.Millisecond),
+		RepeatInterval: duration(100 * time.Millisecond),
+	}, store, recv)
+
+	d.dispatch(open, "c1")
+	d.dispatch(resolved, "c2")
+	first := recv.next(t)
+	if first.Repeat || len(first.Violations) != 2 {
+		t.Fatalf("Expected initial batch of 2, got %+v", first)
+	}
+
+	if _, err := store.Triage(ctx, resolved.ID, services.TriageUpdate{Actor: "alice", Status: models.ViolationStatusResolved}); err != nil {
+		t.Fatalf("Triage failed: %v", err)
+	}
+
+	reminder := recv.next(t)
+	if !reminder.Repeat || len(reminder.Violations) != 1 || reminder.Violations[0].ID != open.ID {
+		t.Errorf("Expected a reminder about %s only, got %+v", open.ID, reminder)
+	}
+
+	// Once everything is closed the group ends
+	if _, err := store.Triage(ctx, open.ID, services.TriageUpdate{Actor: "alice", Status: models.ViolationStatusFalsePositive}); err != nil {
+		t.Fatalf("Triage failed: %v", err)
+	}
+	recv.none(t, 250*time.Millisecond)
+}
+
+func TestDispatcher_CursorWaitsForPendingGroups(t *testing.T) {
+	fast := newRecordingReceiver("pager")
+	slow := newRecordingReceiver("digest")
+	d, cursors := newTestDispatcher(t, RouteConfig{
+		Receiver:  "digest",
+		GroupWait: duration(150 * time.Millisecond),
+		Routes: []RouteConfig{
+			{Receiver: "pager", Severities: []string{"CRITICAL"}, GroupWait: duration(0)},
+		},
+	}, nil, fast, slow)
+
+	low := testViolation("v-1")
+	low.Severity = "LOW"
+	critical := testViolation("v-2")
+	critical.Severity = "CRITICAL"
+
+	d.dispatch(low, "c1")
+	d.dispatch(critical, "c2")
+	fast.next(t)
+
+	// The digest still holds v-1, so a restart must replay from before it
+	if got := cursors.last(); got != "" {
+		t.Errorf("Expected committed cursor to stay before the pending digest, got %q", got)
+	}
+
+	slow.next(t)
+	deadline := time.Now().Add(time.Second)
+	for cursors.last() != "c2" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := cursors.last(); got != "c2" {
+		t.Errorf("Expected committed cursor c2 once the digest was sent, got %q", got)
+	}
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Default email templates; see TemplateData
+const (
+	DefaultEmailSubject = `{{if .Repeat}}Reminder: {{end}}[BeTrace] {{len .Violations}} violation(s){{with .Violation}}: {{.Severity}} {{.RuleName}}{{end}}`
+	DefaultEmailBody    = `{{range .Violations}}[{{.Severity}}] {{.RuleName}} ({{.RuleID}})
+  {{.Message}}
+  Traces:   {{join .TraceIDs ", "}}
+  Recorded: {{.CreatedAt.Format "2006-01-02 15:04:05 MST"}}
+  ID:       {{.ID}}
+
+{{end}}`
+)
+
+const defaultSMTPPort = 587
+
+// EmailConfig configures one email receiver sending through an SMTP server.
+// STARTTLS is used whenever the server offers it.
+type EmailConfig struct {
+	Name           string
+	Host           string
+	Port           int // default 587
+	Username       string
+	Password       string
+	From           string
+	To             []string
+	Subject        string // text/template for the subject line
+	Template       string // text/template for the plain-text body
+	MaxAttempts    int    // Including the first
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // Per attempt
+	QueueSize      int           // Pending deliveries before new ones are dead-lettered
+}
+
+// Email delivers notifications as plain-text email
+type Email struct {
+	*deliveryQueue
+	cfg     EmailConfig
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewEmail validates cfg and creates an email receiver; call Start to begin delivering
+func NewEmail(cfg EmailConfig, dlq *DeadLetterQueue) (*Email, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("email receiver name is required")
+	}
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("email %s: host, from and to are required", cfg.Name)
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = defaultSMTPPort
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = DefaultEmailSubject
+	}
+	if cfg.Template == "" {
+		cfg.Template = DefaultEmailBody
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	subject, err := template.New(cfg.Name + "-subject").Funcs(templateFuncs).Parse(cfg.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("email %s: invalid subject template: %w", cfg.Name, err)
+	}
+	body, err := template.New(cfg.Name).Funcs(templateFuncs).Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("email %s: invalid template: %w", cfg.Name, err)
+	}
+
+	e := &Email{cfg: cfg, subject: subject, body: body}
+	e.deliveryQueue = newDeliveryQueue(cfg.Name, queueConfig{
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		queueSize:      cfg.QueueSize,
+	}, e.render, e.send, dlq)
+	return e, nil
+}
+
+// render builds the RFC 5322 message
+func (e *Email) render(n Notification) ([]byte, error) {
+	data := templateData(e.cfg.Name, n)
+
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := e.body.Execute(&body, data); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&msg, "%s: %s\r\n", k, v) }
+	header("From", e.cfg.From)
+	header("To", strings.Join(e.cfg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject.String()), " ")))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body.String(), "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+// send makes one SMTP transaction
+func (e *Email) send(ctx context.Context, _ string, msg []byte) (time.Duration, error) {
+	dialer := net.Dialer{Timeout: e.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port)))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(e.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		return 0, smtpError(err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return 0, smtpError(err)
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return 0, smtpError(err)
+		}
+	}
+	if err := client.Mail(e.cfg.From); err != nil {
+		return 0, smtpError(err)
+	}
+	for _, to := range e.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return 0, smtpError(err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return 0, smtpError(err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return 0, smtpError(err)
+	}
+	if err := w.Close(); err != nil {
+		return 0, smtpError(err)
+	}
+	// The message is accepted; a failed QUIT doesn't warrant resending it
+	client.Quit()
+	return 0, nil
+}
+
+// smtpError marks permanent (5xx) SMTP replies as not worth retrying
+func smtpError(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// smtpServer is a minimal local SMTP server recording received messages
+type smtpServer struct {
+	addr     string
+	rcptCode int // Reply to RCPT TO, 250 unless set
+	messages chan smtpMessage
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T, rcptCode int) *smtpServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpServer{addr: ln.Addr().String(), rcptCode: rcptCode, messages: make(chan smtpMessage, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP test")
+
+	var msg smtpMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			msg = smtpMessage{from: line}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			if s.rcptCode != 0 {
+				tp.PrintfLine("%d recipient rejected", s.rcptCode)
+				continue
+			}
+			msg.to = append(msg.to, line)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.messages <- msg
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func startEmail(t *testing.T, server *smtpServer, cfg EmailConfig) (*Email, *DeadLetterQueue) {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(server.addr)
+	cfg.Name = "oncall-email"
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+	cfg.From = "betrace@example.com"
+	if cfg.To == nil {
+		cfg.To = []string{"payments-oncall@example.com", "sre@example.com"}
+	}
+
+	dlq, err := NewDeadLetterQueue(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue failed: %v", err)
+	}
+	e, err := NewEmail(cfg, dlq)
+	if err != nil {
+		t.Fatalf("NewEmail failed: %v", err)
+	}
+	e.Start()
+	t.Cleanup(e.Close)
+	return e, dlq
+}
+
+func TestEmail_SendsDigest(t *testing.T) {
+	server := newSMTPServer(t, 0)
+	e, _ := startEmail(t, server, EmailConfig{})
+
+	v1, v2 := testViolation("v-1"), testViolation("v-2")
+	v2.TraceIDs = []string{"trace-a", "trace-b"}
+	e.Send(Notification{Violations: []models.Violation{v1, v2}})
+
+	var msg smtpMessage
+	select {
+	case msg = <-server.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for email")
+	}
+
+	if !strings.Contains(msg.from, "betrace@example.com") || len(msg.to) != 2 {
+		t.Errorf("Unexpected envelope: from %q to %v", msg.from, msg.to)
+	}
+	for _, want := range []string{
+		"Subject: [BeTrace] 2 violation(s): HIGH PII access",
+		"To: payments-oncall@example.com, sre@example.com",
+		"ID:       v-1",
+		"ID:       v-2",
+		"Traces:   trace-a, trace-b",
+	} {
+		if !strings.Contains(msg.data, want) {
+			t.Errorf("Expected message to contain %q:\n%s", want, msg.data)
+		}
+	}
+}
+
+func TestEmail_RejectedRecipientIsNotRetried(t *testing.T) {
+	server := newSMTPServer(t, 550)
+	e, dlq := startEmail(t, server, EmailConfig{InitialBackoff: time.Millisecond})
+
+	e.Send(single(testViolation("v-1")))
+
+	var letters []DeadLetter
+	for deadline := time.Now().Add(5 * time.Second); len(letters) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		letters, _ = dlq.List()
+	}
+	e.Close()
+	if len(letters) != 1 || letters[0].Attempts != 1 || !strings.Contains(letters[0].LastError, "550") {
+		t.Errorf("Expected 1 dead letter after 1 attempt, got %+v", letters)
+	}
+}
+
+func TestNewEmail_Invalid(t *testing.T) {
+	if _, err := NewEmail(EmailConfig{Name: "x", Host: "smtp.example.com", From: "a@example.com"}, nil); err == nil {
+		t.Error("Expected error without recipients")
+	}
+	if _, err := NewEmail(EmailConfig{Name: "x", Host: "h", From: "a@b", To: []string{"c@d"}, Subject: "{{"}, nil); err == nil {
+		t.Error("Expected error for invalid subject template")
+	}
+}
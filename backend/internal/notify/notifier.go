@@ -0,0 +1,192 @@
+// Package notify delivers violation notifications to external systems.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// cursorFile records the stream position notifications have been handed off up to
+const cursorFile = "cursor"
+
+// Config lists the receivers and how violations are routed to them
+type Config struct {
+	Webhooks      []WebhookConfig
+	Emails        []EmailConfig
+	Alertmanagers []AlertmanagerConfig
+	// Route is the root of the routing tree; nil sends every violation to
+	// every receiver as soon as it is recorded
+	Route *RouteConfig
+}
+
+// Notifier routes recorded violations to its receivers
+type Notifier struct {
+	dir       string
+	receivers []Receiver
+	byName    map[string]Receiver
+	route     *route
+	dlq       *DeadLetterQueue
+}
+
+// NewNotifier creates a notifier keeping its state (cursor and dead-letter
+// queue) in dir. Webhook requests are signed with signatureKey.
+func NewNotifier(dir, signatureKey string, cfg Config) (*Notifier, error) {
+	dlq, err := NewDeadLetterQueue(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notifier{
+		dir:    dir,
+		byName: make(map[string]Receiver),
+		dlq:    dlq,
+	}
+	for _, wc := range cfg.Webhooks {
+		w, err := NewWebhook(wc, signatureKey, dlq)
+		if err != nil {
+			return nil, err
+		}
+		if err := n.add(w); err != nil {
+			return nil, err
+		}
+	}
+	for _, ec := range cfg.Emails {
+		e, err := NewEmail(ec, dlq)
+		if err != nil {
+			return nil, err
+		}
+		if err := n.add(e); err != nil {
+			return nil, err
+		}
+	}
+	for _, ac := range cfg.Alertmanagers {
+		a, err := NewAlertmanager(ac, dlq)
+		if err != nil {
+			return nil, err
+		}
+		if err := n.add(a); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Route != nil {
+		if n.route, err = newRouteTree(*cfg.Route, n.byName); err != nil {
+			return nil, fmt.Errorf("invalid notification route: %w", err)
+		}
+	}
+	return n, nil
+}
+
+func (n *Notifier) add(r Receiver) error {
+	if _, dup := n.byName[r.Name()]; dup {
+		return fmt.Errorf("duplicate receiver name %q", r.Name())
+	}
+	n.receivers = append(n.receivers, r)
+	n.byName[r.Name()] = r
+	return nil
+}
+
+// Start begins delivering
+func (n *Notifier) Start() {
+	for _, r := range n.receivers {
+		r.Start()
+	}
+}
+
+// Close stops delivering; anything still queued is dead-lettered
+func (n *Notifier) Close() {
+	for _, r := range n.receivers {
+		r.Close()
+	}
+}
+
+// Notify queues v for every receiver without blocking, bypassing routing
+func (n *Notifier) Notify(v models.Violation) {
+	for _, r := range n.receivers {
+		r.Send(Notification{Violations: []models.Violation{v}})
+	}
+}
+
+// DeadLetters returns the notifier's dead-letter queue
+func (n *Notifier) DeadLetters() *DeadLetterQueue {
+	return n.dlq
+}
+
+// RedeliverDeadLetters re-queues every dead letter for its receiver and
+// returns how many were re-queued. Letters for removed receivers are kept,
+// and re-queued letters leave the queue only once they are all re-queued.
+func (n *Notifier) RedeliverDeadLetters() (int, error) {
+	return n.dlq.Redeliver(func(letter DeadLetter) bool {
+		r, ok := n.byName[letter.Endpoint]
+		if ok {
+			r.Send(letter.Notification)
+		}
+		return ok
+	})
+}
+
+// Run notifies about violations as they are recorded until ctx is done.
+// Progress is saved as a cursor, so violations recorded while the notifier
+// was behind or stopped are sent on the next run. With a routing tree the
+// cursor only advances past violations once their group has been notified,
+// so batches waiting out group_wait survive a restart (delivery is
+// at-least-once: other groups may repeat a notification).
+func (n *Notifier) Run(ctx context.Context, store services.ViolationStore, hub *services.ViolationHub) error {
+	cursor := n.loadCursor()
+
+	handle := func(event services.ViolationEvent) error {
+		n.Notify(event.Violation)
+		return n.saveCursor(event.Cursor)
+	}
+	if n.route != nil {
+		d := newDispatcher(n.route, n.byName, store, cursor, logSave(n.saveCursor))
+		defer d.stop()
+		handle = func(event services.ViolationEvent) error {
+			d.dispatch(event.Violation, event.Cursor)
+			return nil
+		}
+	}
+
+	for {
+		err := services.WatchViolations(ctx, store, hub, services.QueryFilters{Cursor: cursor}, func(event services.ViolationEvent) error {
+			cursor = event.Cursor
+			return handle(event)
+		})
+
+		switch {
+		case errors.Is(err, services.ErrSubscriberLagged):
+			log.Printf("Notifier fell behind the violation stream, resuming from store")
+		case errors.Is(err, services.ErrInvalidQuery) && cursor != "":
+			log.Printf("Notifier cursor is invalid, continuing with live violations: %v", err)
+			cursor = ""
+		default:
+			return err
+		}
+	}
+}
+
+func (n *Notifier) loadCursor() string {
+	data, err := os.ReadFile(filepath.Join(n.dir, cursorFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// saveCursor atomically replaces the cursor file
+func (n *Notifier) saveCursor(cursor string) error {
+	path := filepath.Join(n.dir, cursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(cursor), 0o644); err != nil {
+		return fmt.Errorf("failed to save notifier cursor: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,217 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/services"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestNotifier_RunResumesAfterRestart(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	dir := t.TempDir()
+	store := services.NewViolationStoreMemory(testKey)
+	hub := services.NewViolationHub(10)
+
+	start := func() (*Notifier, context.CancelFunc, chan error) {
+		n, err := NewNotifier(dir, testKey, Config{Webhooks: []WebhookConfig{{Name: "hook", URL: server.URL}}})
+		if err != nil {
+			t.Fatalf("NewNotifier failed: %v", err)
+		}
+		n.Start()
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() { errc <- n.Run(ctx, store, hub) }()
+		for hub.Subscribers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		return n, cancel, errc
+	}
+	record := func() models.Violation {
+		v, err := store.Record(context.Background(), testViolation(""), nil)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		hub.Publish(v)
+		return v
+	}
+
+	n, cancel, errc := start()
+	record()
+	recv.wait(t, 1)
+	cancel()
+	<-errc
+	n.Close()
+
+	// Recorded while the notifier was down
+	missed, _ := store.Record(context.Background(), testViolation(""), nil)
+
+	n, cancel, errc = start()
+	defer func() { cancel(); <-errc; n.Close() }()
+	recv.wait(t, 1)
+
+	if got := string(recv.bodies[1]); !strings.Contains(got, missed.ID) {
+		t.Errorf("Expected missed violation %s to be sent after restart, got %s", missed.ID, got)
+	}
+}
+
+func TestNotifier_RedeliverDeadLetters(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	n, err := NewNotifier(t.TempDir(), testKey, Config{Webhooks: []WebhookConfig{{Name: "hook", URL: server.URL}}})
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	n.DeadLetters().Append(DeadLetter{Endpoint: "hook", ViolationIDs: []string{"v-1"}, Notification: single(testViolation("v-1"))})
+	n.DeadLetters().Append(DeadLetter{Endpoint: "removed", ViolationIDs: []string{"v-2"}, Notification: single(testViolation("v-2"))})
+
+	n.Start()
+	defer n.Close()
+
+	requeued, err := n.RedeliverDeadLetters()
+	if err != nil || requeued != 1 {
+		t.Fatalf("Expected 1 requeued, got %d, %v", requeued, err)
+	}
+	recv.wait(t, 1)
+
+	letters, _ := n.DeadLetters().List()
+	if len(letters) != 1 || letters[0].Endpoint != "removed" {
+		t.Errorf("Expected only the unknown endpoint's letter to remain, got %v", letters)
+	}
+}
+
+func TestDeadLetterQueue_RedeliverRemovesOnlyHandedOffLetters(t *testing.T) {
+	dlq, err := NewDeadLetterQueue(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue failed: %v", err)
+	}
+	dlq.Append(DeadLetter{Endpoint: "hook", ViolationIDs: []string{"v-1"}})
+	dlq.Append(DeadLetter{Endpoint: "removed", ViolationIDs: []string{"v-2"}})
+
+	requeued, err := dlq.Redeliver(func(letter DeadLetter) bool {
+		// Nothing leaves the queue while letters are being handed off
+		if listed, _ := dlq.List(); len(listed) < 2 {
+			t.Errorf("Expected the letters kept during redelivery, got %v", listed)
+		}
+		if letter.Endpoint == "hook" {
+			dlq.Append(DeadLetter{Endpoint: "hook", ViolationIDs: []string{"v-3"}})
+		}
+		return letter.Endpoint == "hook"
+	})
+	if err != nil || requeued != 1 {
+		t.Fatalf("Expected 1 requeued, got %d, %v", requeued, err)
+	}
+
+	letters, _ := dlq.List()
+	var kept []string
+	for _, letter := range letters {
+		kept = append(kept, letter.ViolationIDs...)
+	}
+	if fmt.Sprint(kept) != "[v-2 v-3]" {
+		t.Errorf("Expected the unhandled and newly dead-lettered letters kept, got %v", kept)
+	}
+}
+
+func TestNewNotifier_DuplicateNames(t *testing.T) {
+	_, err := NewNotifier(t.TempDir(), testKey, Config{Webhooks: []WebhookConfig{{Name: "a", URL: "http://x"}, {Name: "a", URL: "http://y"}}})
+	if err == nil {
+		t.Error("Expected error for duplicate webhook names")
+	}
+}
+
+func TestNewNotifier_ResolveAfterExceedsGroupTiming(t *testing.T) {
+	alertmanager := func(resolveAfter time.Duration) []AlertmanagerConfig {
+		return []AlertmanagerConfig{{Name: "am", URL: "http://am", ResolveAfter: resolveAfter}}
+	}
+	if DefaultResolveAfter <= DefaultGroupInterval || DefaultResolveAfter <= DefaultGroupWait {
+		t.Errorf("Expected the default resolve_after %v above the default group timing", DefaultResolveAfter)
+	}
+
+	for name, tc := range map[string]struct {
+		cfg   Config
+		valid bool
+	}{
+		"defaults":           {Config{Alertmanagers: alertmanager(0), Route: &RouteConfig{Receiver: "am"}}, true},
+		"no route":           {Config{Alertmanagers: alertmanager(time.Second)}, true},
+		"equal to interval":  {Config{Alertmanagers: alertmanager(DefaultGroupInterval), Route: &RouteConfig{Receiver: "am"}}, false},
+		"below a child wait": {Config{Alertmanagers: alertmanager(time.Hour), Route: &RouteConfig{Receiver: "am", Routes: []RouteConfig{{Severities: []string{"HIGH"}, GroupWait: duration(2 * time.Hour)}}}}, false},
+	} {
+		_, err := NewNotifier(t.TempDir(), testKey, tc.cfg)
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected a valid config, got %v", name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected resolve_after to be refused", name)
+		}
+	}
+}
+
+func TestNotifier_RunRoutesViolations(t *testing.T) {
+	pager, pagerServer := newReceiver(t, nil)
+	digest, digestServer := newReceiver(t, nil)
+	store := services.NewViolationStoreMemory(testKey)
+	hub := services.NewViolationHub(10)
+
+	n, err := NewNotifier(t.TempDir(), testKey, Config{
+		Webhooks: []WebhookConfig{{Name: "payments-oncall", URL: pagerServer.URL}, {Name: "digest", URL: digestServer.URL}},
+		Route: &RouteConfig{
+			Receiver:  "digest",
+			GroupWait: duration(50 * time.Millisecond),
+			Routes: []RouteConfig{
+				{Receiver: "payments-oncall", Severities: []string{"CRITICAL"}, Frameworks: []string{"pci"}, GroupWait: duration(0)},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	n.Start()
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- n.Run(ctx, store, hub) }()
+	defer func() { cancel(); <-errc; n.Close() }()
+	for hub.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	record := func(severity string, tags ...string) models.Violation {
+		v := testViolation("")
+		v.Severity, v.Tags = severity, tags
+		stored, err := store.Record(context.Background(), v, nil)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		hub.Publish(stored)
+		return stored
+	}
+	critical := record("CRITICAL", "pci")
+	record("LOW", "owner:sre")
+	record("LOW", "owner:sre")
+
+	pager.wait(t, 1)
+	if got := string(pager.bodies[0]); !strings.Contains(got, critical.ID) {
+		t.Errorf("Expected the critical PCI violation to page, got %s", got)
+	}
+
+	digest.wait(t, 1)
+	var envelope struct {
+		Violations []models.Violation `json:"violations"`
+	}
+	if err := json.Unmarshal(digest.bodies[0], &envelope); err != nil || len(envelope.Violations) != 2 {
+		t.Errorf("Expected both low-severity violations in one digest, got %s (%v)", digest.bodies[0], err)
+	}
+}
+
+func TestNewNotifier_InvalidRoute(t *testing.T) {
+	_, err := NewNotifier(t.TempDir(), testKey, Config{
+		Webhooks: []WebhookConfig{{Name: "a", URL: "http://x"}},
+		Route:    &RouteConfig{Receiver: "missing"},
+	})
+	if err == nil {
+		t.Error("Expected error for a route to an unknown receiver")
+	}
+}
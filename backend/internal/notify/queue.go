@@ -0,0 +1,272 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/google/uuid"
+)
+
+// Delivery defaults (applied to zero-valued receiver config fields)
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultTimeout        = 10 * time.Second
+	defaultQueueSize      = 1000
+)
+
+// Notification is a batch of violations for one receiver
+type Notification struct {
+	Receiver    string             `json:"receiver"`
+	GroupKey    string             `json:"groupKey,omitempty"`    // Route group the batch belongs to
+	GroupLabels map[string]string  `json:"groupLabels,omitempty"` // Values of the route's group_by labels
+	Repeat      bool               `json:"repeat,omitempty"`      // Reminder of violations still open
+	Violations  []models.Violation `json:"violations"`
+}
+
+// Receiver delivers notifications to one destination
+type Receiver interface {
+	Name() string
+	// Send queues n for delivery without blocking
+	Send(n Notification)
+	Start()
+	// Close stops delivery; undelivered notifications are dead-lettered
+	Close()
+}
+
+// TemplateData is the data available to payload templates. The json
+// function renders any value as JSON; join joins strings.
+type TemplateData struct {
+	Endpoint    string
+	Violation   models.Violation // Latest violation in the notification
+	Violations  []models.Violation
+	GroupKey    string
+	GroupLabels map[string]string
+	Repeat      bool
+}
+
+func templateData(endpoint string, n Notification) TemplateData {
+	data := TemplateData{
+		Endpoint:    endpoint,
+		Violations:  n.Violations,
+		GroupKey:    n.GroupKey,
+		GroupLabels: n.GroupLabels,
+		Repeat:      n.Repeat,
+	}
+	if len(n.Violations) > 0 {
+		data.Violation = n.Violations[len(n.Violations)-1]
+	}
+	return data
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+}
+
+// errPermanent marks delivery failures that retrying can't fix
+var errPermanent = errors.New("permanent delivery failure")
+
+// queueConfig is the delivery behaviour shared by all receivers
+type queueConfig struct {
+	rateLimit      float64 // Deliveries per second, 0 = unlimited
+	burst          int
+	maxAttempts    int // Including the first
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	queueSize      int
+}
+
+// renderFunc renders a notification into the payload a receiver sends
+type renderFunc func(n Notification) ([]byte, error)
+
+// sendFunc makes one delivery attempt. It returns how long the destination
+// asked us to wait before retrying, and wraps errPermanent for failures not
+// worth retrying.
+type sendFunc func(ctx context.Context, deliveryID string, payload []byte) (time.Duration, error)
+
+// deliveryQueue delivers a receiver's notifications from a bounded queue,
+// with rate limiting, exponential-backoff retries and dead-lettering
+type deliveryQueue struct {
+	name     string
+	cfg      queueConfig
+	render   renderFunc
+	send     sendFunc
+	limiter  *tokenBucket
+	dlq      *DeadLetterQueue
+	queue    chan delivery
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+type delivery struct {
+	id           string
+	notification Notification
+}
+
+func newDeliveryQueue(name string, cfg queueConfig, render renderFunc, send sendFunc, dlq *DeadLetterQueue) *deliveryQueue {
+	return &deliveryQueue{
+		name:    name,
+		cfg:     cfg,
+		render:  render,
+		send:    send,
+		limiter: newTokenBucket(cfg.rateLimit, cfg.burst),
+		dlq:     dlq,
+		queue:   make(chan delivery, cfg.queueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Name returns the receiver name
+func (q *deliveryQueue) Name() string {
+	return q.name
+}
+
+// Start begins delivering queued notifications
+func (q *deliveryQueue) Start() {
+	go q.run()
+}
+
+// Close stops delivery; undelivered notifications are dead-lettered
+func (q *deliveryQueue) Close() {
+	q.stopOnce.Do(func() { close(q.stop) })
+	<-q.done
+}
+
+// Send schedules delivery of n without blocking. If the queue is full the
+// notification is dead-lettered instead.
+func (q *deliveryQueue) Send(n Notification) {
+	n.Receiver = q.name
+	d := delivery{id: uuid.New().String(), notification: n}
+	select {
+	case q.queue <- d:
+	default:
+		q.deadLetter(d, "", 0, fmt.Errorf("delivery queue full (%d pending)", q.cfg.queueSize))
+	}
+}
+
+func (q *deliveryQueue) run() {
+	defer close(q.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-q.stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-q.stop:
+			q.drain()
+			return
+		case d := <-q.queue:
+			q.deliver(ctx, d)
+		}
+	}
+}
+
+// drain dead-letters everything still queued at shutdown
+func (q *deliveryQueue) drain() {
+	for {
+		select {
+		case d := <-q.queue:
+			q.deadLetter(d, "", 0, errors.New("notifier stopped before delivery"))
+		default:
+			return
+		}
+	}
+}
+
+// deliver sends d, retrying transient failures with exponential backoff
+func (q *deliveryQueue) deliver(ctx context.Context, d delivery) {
+	payload, err := q.render(d.notification)
+	if err != nil {
+		q.deadLetter(d, "", 0, fmt.Errorf("%w: template: %v", errPermanent, err))
+		return
+	}
+
+	attempt := 0
+	for attempt < q.cfg.maxAttempts {
+		attempt++
+		if err = q.limiter.Wait(ctx); err != nil {
+			break
+		}
+
+		// An attempt in flight finishes (bounded by the receiver's timeout)
+		// even when stopping; only waits between attempts are cut short
+		var retryAfter time.Duration
+		retryAfter, err = q.send(context.WithoutCancel(ctx), d.id, payload)
+		if err == nil {
+			return
+		}
+		if errors.Is(err, errPermanent) || attempt == q.cfg.maxAttempts {
+			break
+		}
+
+		wait := max(retryAfter, q.backoff(attempt))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			q.deadLetter(d, string(payload), attempt, err)
+			return
+		case <-timer.C:
+		}
+	}
+	q.deadLetter(d, string(payload), attempt, err)
+}
+
+// backoff returns the wait before retry attempt+1: initialBackoff doubled
+// per attempt, capped at maxBackoff, with up to 20% jitter
+func (q *deliveryQueue) backoff(attempt int) time.Duration {
+	wait := q.cfg.initialBackoff << (attempt - 1)
+	if wait <= 0 || wait > q.cfg.maxBackoff {
+		wait = q.cfg.maxBackoff
+	}
+	return wait + time.Duration(rand.Int64N(int64(wait)/5+1))
+}
+
+func (q *deliveryQueue) deadLetter(d delivery, payload string, attempts int, err error) {
+	ids := violationIDs(d.notification.Violations)
+	log.Printf("Receiver %s: giving up on violations %v after %d attempts: %v", q.name, ids, attempts, err)
+	if q.dlq == nil {
+		return
+	}
+	letter := DeadLetter{
+		DeliveryID:   d.id,
+		Endpoint:     q.name,
+		ViolationIDs: ids,
+		Payload:      payload,
+		Attempts:     attempts,
+		LastError:    err.Error(),
+		FailedAt:     time.Now(),
+		Notification: d.notification,
+	}
+	if err := q.dlq.Append(letter); err != nil {
+		log.Printf("Receiver %s: failed to dead-letter violations %v: %v", q.name, ids, err)
+	}
+}
+
+func violationIDs(violations []models.Violation) []string {
+	ids := make([]string, len(violations))
+	for i, v := range violations {
+		ids[i] = v.ID
+	}
+	return ids
+}
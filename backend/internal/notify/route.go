@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// Labels available to RouteConfig.GroupBy
+const (
+	LabelRuleID      = "rule_id"
+	LabelSeverity    = "severity"
+	LabelService     = "service"     // Services of the referenced spans
+	LabelFingerprint = "fingerprint" // One group per incident
+)
+
+// OwnerTagPrefix marks the rule tag naming the owning team, e.g. "owner:payments"
+const OwnerTagPrefix = "owner:"
+
+// Timing defaults for a root route that leaves them unset
+const (
+	DefaultGroupWait      = 30 * time.Second
+	DefaultGroupInterval  = 5 * time.Minute
+	DefaultRepeatInterval = 0 // No reminders
+)
+
+// RouteConfig configures one node of the routing tree. A violation enters at
+// the root and descends into the first child route that matches it (every
+// matching child when Continue is set); the deepest matching routes notify
+// their receivers. Unset fields are inherited from the parent route.
+type RouteConfig struct {
+	Receiver string
+
+	// Matchers: a route matches when every non-empty matcher does
+	Severities []string // Any of these rule severities (case-insensitive)
+	Tags       []string // Every one of these rule tags
+	Services   []string // Any referenced span from one of these services
+	Frameworks []string // Any of these compliance frameworks, carried as rule tags ("pci", "soc2", ...)
+	Owners     []string // Any of these owners, carried as OwnerTagPrefix rule tags
+
+	// Violations are batched per distinct value of these labels
+	GroupBy []string
+	// Wait before the first notification for a new group
+	GroupWait *time.Duration
+	// Wait before notifying a group again about new violations
+	GroupInterval *time.Duration
+	// Wait before reminding about a group's violations that are still open,
+	// 0 = no reminders
+	RepeatInterval *time.Duration
+
+	Continue bool // Keep matching sibling routes after this one
+	Routes   []RouteConfig
+}
+
+// route is a validated RouteConfig with inherited settings resolved
+type route struct {
+	id       string // Position in the tree, e.g. "0.2.1"
+	receiver string
+
+	severities []string
+	tags       []string
+	services   []string
+	frameworks []string
+	owners     []string
+
+	groupBy        []string
+	groupWait      time.Duration
+	groupInterval  time.Duration
+	repeatInterval time.Duration
+
+	cont   bool
+	routes []*route
+}
+
+// newRouteTree validates cfg against the known receivers and resolves inheritance
+func newRouteTree(cfg RouteConfig, receivers map[string]Receiver) (*route, error) {
+	if len(cfg.Severities)+len(cfg.Tags)+len(cfg.Services)+len(cfg.Frameworks)+len(cfg.Owners) > 0 {
+		return nil, fmt.Errorf("root route must not have matchers")
+	}
+	if cfg.Receiver == "" {
+		return nil, fmt.Errorf("root route must have a receiver")
+	}
+	root := &route{
+		id:             "0",
+		groupWait:      DefaultGroupWait,
+		groupInterval:  DefaultGroupInterval,
+		repeatInterval: DefaultRepeatInterval,
+	}
+	return root, root.configure(cfg, receivers)
+}
+
+func (r *route) configure(cfg RouteConfig, receivers map[string]Receiver) error {
+	if cfg.Receiver != "" {
+		if _, ok := receivers[cfg.Receiver]; !ok {
+			return fmt.Errorf("route %s: unknown receiver %q", r.id, cfg.Receiver)
+		}
+		r.receiver = cfg.Receiver
+	}
+	for _, label := range cfg.GroupBy {
+		switch label {
+		case LabelRuleID, LabelSeverity, LabelService, LabelFingerprint:
+		default:
+			return fmt.Errorf("route %s: invalid group_by label %q (want %s, %s, %s or %s)",
+				r.id, label, LabelRuleID, LabelSeverity, LabelService, LabelFingerprint)
+		}
+	}
+	if cfg.GroupBy != nil {
+		r.groupBy = cfg.GroupBy
+	}
+	for _, d := range []*time.Duration{cfg.GroupWait, cfg.GroupInterval, cfg.RepeatInterval} {
+		if d != nil && *d < 0 {
+			return fmt.Errorf("route %s: durations must not be negative", r.id)
+		}
+	}
+	if cfg.GroupWait != nil {
+		r.groupWait = *cfg.GroupWait
+	}
+	if cfg.GroupInterval != nil {
+		r.groupInterval = *cfg.GroupInterval
+	}
+	if cfg.RepeatInterval != nil {
+		r.repeatInterval = *cfg.RepeatInterval
+	}
+	// An alert re-posted group_interval after the last one must still be
+	// firing, or an ongoing incident resolves and fires again every interval
+	if a, ok := receivers[r.receiver].(*Alertmanager); ok && a.cfg.ResolveAfter <= max(r.groupWait, r.groupInterval) {
+		return fmt.Errorf("route %s: alertmanager %s resolve_after (%v) must exceed group_wait (%v) and group_interval (%v)",
+			r.id, a.Name(), a.cfg.ResolveAfter, r.groupWait, r.groupInterval)
+	}
+
+	r.severities = cfg.Severities
+	r.tags = cfg.Tags
+	r.services = cfg.Services
+	r.frameworks = cfg.Frameworks
+	r.owners = cfg.Owners
+	r.cont = cfg.Continue
+
+	for i, childCfg := range cfg.Routes {
+		child := &route{
+			id:             r.id + "." + strconv.Itoa(i),
+			receiver:       r.receiver,
+			groupBy:        r.groupBy,
+			groupWait:      r.groupWait,
+			groupInterval:  r.groupInterval,
+			repeatInterval: r.repeatInterval,
+		}
+		if err := child.configure(childCfg, receivers); err != nil {
+			return err
+		}
+		r.routes = append(r.routes, child)
+	}
+	return nil
+}
+
+// match returns the routes that should notify about v, or nil if r doesn't match
+func (r *route) match(v *models.Violation) []*route {
+	if !r.matches(v) {
+		return nil
+	}
+
+	var matched []*route
+	for _, child := range r.routes {
+		m := child.match(v)
+		matched = append(matched, m...)
+		if len(m) > 0 && !child.cont {
+			break
+		}
+	}
+	if len(matched) == 0 {
+		return []*route{r}
+	}
+	return matched
+}
+
+func (r *route) matches(v *models.Violation) bool {
+	if len(r.severities) > 0 && !containsFold(r.severities, v.Severity) {
+		return false
+	}
+	for _, tag := range r.tags {
+		if !containsFold(v.Tags, tag) {
+			return false
+		}
+	}
+	if len(r.services) > 0 && !anyOf(r.services, serviceNames(v.SpanRefs)) {
+		return false
+	}
+	if len(r.frameworks) > 0 && !anyOf(r.frameworks, v.Tags) {
+		return false
+	}
+	if len(r.owners) > 0 && !anyOf(r.owners, owners(v.Tags)) {
+		return false
+	}
+	return true
+}
+
+// groupLabels returns v's values for the route's group_by labels
+func (r *route) groupLabels(v *models.Violation) map[string]string {
+	labels := make(map[string]string, len(r.groupBy))
+	for _, label := range r.groupBy {
+		switch label {
+		case LabelRuleID:
+			labels[label] = v.RuleID
+		case LabelSeverity:
+			labels[label] = v.Severity
+		case LabelService:
+			labels[label] = strings.Join(serviceNames(v.SpanRefs), ",")
+		case LabelFingerprint:
+			labels[label] = v.Fingerprint
+		}
+	}
+	return labels
+}
+
+// groupKey identifies the route's group for a set of label values
+func (r *route) groupKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+strconv.Quote(v))
+	}
+	sort.Strings(pairs)
+	return r.id + ":{" + strings.Join(pairs, ",") + "}"
+}
+
+// serviceNames returns the sorted distinct services of refs
+func serviceNames(refs []models.SpanRef) []string {
+	var names []string
+	for _, ref := range refs {
+		if ref.ServiceName != "" && !containsFold(names, ref.ServiceName) {
+			names = append(names, ref.ServiceName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// owners returns the owners named by OwnerTagPrefix tags
+func owners(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		if len(tag) > len(OwnerTagPrefix) && strings.EqualFold(tag[:len(OwnerTagPrefix)], OwnerTagPrefix) {
+			result = append(result, tag[len(OwnerTagPrefix):])
+		}
+	}
+	return result
+}
+
+// anyOf reports whether values shares an element with want (case-insensitive)
+func anyOf(want, values []string) bool {
+	for _, v := range values {
+		if containsFold(want, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
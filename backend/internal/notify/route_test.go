@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func duration(d time.Duration) *time.Duration {
+	return &d
+}
+
+// nopReceiver satisfies Receiver for route validation
+type nopReceiver string
+
+func (r nopReceiver) Name() string      { return string(r) }
+func (r nopReceiver) Send(Notification) {}
+func (r nopReceiver) Start()            {}
+func (r nopReceiver) Close()            {}
+
+func testReceivers(names ...string) map[string]Receiver {
+	receivers := make(map[string]Receiver, len(names))
+	for _, name := range names {
+		receivers[name] = nopReceiver(name)
+	}
+	return receivers
+}
+
+func routedViolation(severity, service string, tags ...string) *models.Violation {
+	return &models.Violation{
+		RuleID:   "rule-1",
+		Severity: severity,
+		Tags:     tags,
+		SpanRefs: []models.SpanRef{{TraceID: "t", SpanID: "s", ServiceName: service}},
+	}
+}
+
+func TestRouteTree_Match(t *testing.T) {
+	root, err := newRouteTree(RouteConfig{
+		Receiver: "default",
+		Routes: []RouteConfig{
+			{Receiver: "payments-oncall", Severities: []string{"CRITICAL"}, Frameworks: []string{"pci"}, Continue: true},
+			{Receiver: "audit", Frameworks: []string{"pci", "soc2"}},
+			{Receiver: "sre-digest", Owners: []string{"sre"}, Severities: []string{"low"}},
+			{Services: []string{"checkout"}, Routes: []RouteConfig{
+				{Receiver: "checkout-team", Tags: []string{"latency", "slo"}},
+			}},
+		},
+	}, testReceivers("default", "payments-oncall", "audit", "sre-digest", "checkout-team"))
+	if err != nil {
+		t.Fatalf("newRouteTree failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		violation *models.Violation
+		want      []string
+	}{
+		{"critical pci continues to audit", routedViolation("CRITICAL", "payments", "pci"), []string{"payments-oncall", "audit"}},
+		{"non-critical pci", routedViolation("HIGH", "payments", "PCI"), []string{"audit"}},
+		{"low sre rule", routedViolation("LOW", "api", "owner:sre"), []string{"sre-digest"}},
+		{"high sre rule falls back to root", routedViolation("HIGH", "api", "owner:sre"), []string{"default"}},
+		{"nested route needs every tag", routedViolation("HIGH", "checkout", "latency", "slo"), []string{"checkout-team"}},
+		{"parent route inherits receiver", routedViolation("HIGH", "checkout", "latency"), []string{"default"}},
+		{"no match", routedViolation("HIGH", "auth"), []string{"default"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range root.match(tt.violation) {
+				got = append(got, r.receiver)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected receivers %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Expected receivers %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteTree_InheritsTiming(t *testing.T) {
+	root, err := newRouteTree(RouteConfig{
+		Receiver:  "default",
+		GroupBy:   []string{LabelRuleID},
+		GroupWait: duration(time.Minute),
+		Routes: []RouteConfig{
+			{Receiver: "pager", GroupWait: duration(0), Routes: []RouteConfig{{Severities: []string{"CRITICAL"}}}},
+		},
+	}, testReceivers("default", "pager"))
+	if err != nil {
+		t.Fatalf("newRouteTree failed: %v", err)
+	}
+
+	if root.groupInterval != DefaultGroupInterval || root.repeatInterval != DefaultRepeatInterval {
+		t.Errorf("Expected root defaults, got interval %v repeat %v", root.groupInterval, root.repeatInterval)
+	}
+	leaf := root.routes[0].routes[0]
+	if leaf.receiver != "pager" || leaf.groupWait != 0 || leaf.groupInterval != DefaultGroupInterval {
+		t.Errorf("Expected leaf to inherit pager settings, got %+v", leaf)
+	}
+	if len(leaf.groupBy) != 1 || leaf.groupBy[0] != LabelRuleID {
+		t.Errorf("Expected inherited group_by, got %v", leaf.groupBy)
+	}
+}
+
+func TestRouteTree_Invalid(t *testing.T) {
+	receivers := testReceivers("default")
+	tests := map[string]RouteConfig{
+		"root without receiver": {},
+		"root with matchers":    {Receiver: "default", Severities: []string{"HIGH"}},
+		"unknown receiver":      {Receiver: "default", Routes: []RouteConfig{{Receiver: "missing"}}},
+		"bad group_by":          {Receiver: "default", GroupBy: []string{"trace"}},
+		"negative duration":     {Receiver: "default", GroupWait: duration(-time.Second)},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newRouteTree(cfg, receivers); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestRoute_GroupKey(t *testing.T) {
+	root, _ := newRouteTree(RouteConfig{Receiver: "default", GroupBy: []string{LabelService, LabelSeverity}}, testReceivers("default"))
+
+	a := root.groupKey(root.groupLabels(routedViolation("HIGH", "checkout")))
+	b := root.groupKey(root.groupLabels(routedViolation("HIGH", "checkout", "pci")))
+	c := root.groupKey(root.groupLabels(routedViolation("LOW", "checkout")))
+	if a != b {
+		t.Errorf("Expected equal labels to share a group: %s vs %s", a, b)
+	}
+	if a == c {
+		t.Errorf("Expected different severities to split groups: %s", a)
+	}
+}
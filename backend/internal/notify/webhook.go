@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// Signature headers sent with every webhook request. The signature is
+// "sha256=" + hex HMAC-SHA256 of "betrace.webhook.v1.<timestamp>.<body>"
+// under the webhook key, itself HMAC-SHA256("betrace.webhook.v1") under
+// BETRACE_SIGNATURE_KEY. Receivers verify with VerifySignature.
+const (
+	HeaderSignature  = "X-BeTrace-Signature"
+	HeaderTimestamp  = "X-BeTrace-Timestamp"
+	HeaderDeliveryID = "X-BeTrace-Delivery"
+)
+
+// DefaultTemplate renders the notification as a JSON envelope. "violation"
+// is the latest violation; "violations" holds the whole batch.
+const DefaultTemplate = `{"event":"violation","endpoint":{{json .Endpoint}},"groupKey":{{json .GroupKey}},"repeat":{{json .Repeat}},"violation":{{json .Violation}},"violations":{{json .Violations}}}`
+
+const defaultContentType = "application/json"
+
+// WebhookConfig configures one webhook endpoint
+type WebhookConfig struct {
+	Name           string
+	URL            string
+	Template       string // text/template rendering the body; see TemplateData
+	ContentType    string
+	Headers        map[string]string
+	RateLimit      float64 // Requests per second, 0 = unlimited
+	Burst          int
+	MaxAttempts    int // Including the first
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // Per attempt
+	QueueSize      int           // Pending deliveries before new ones are dead-lettered
+}
+
+// Webhook delivers notifications to one HTTP endpoint as signed POST requests
+type Webhook struct {
+	*deliveryQueue
+	cfg    WebhookConfig
+	tmpl   *template.Template
+	key    []byte
+	client *http.Client
+}
+
+// NewWebhook validates cfg and creates a webhook; call Start to begin delivering
+func NewWebhook(cfg WebhookConfig, signatureKey string, dlq *DeadLetterQueue) (*Webhook, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("webhook name is required")
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook %s: url is required", cfg.Name)
+	}
+	if cfg.Template == "" {
+		cfg.Template = DefaultTemplate
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = defaultContentType
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	tmpl, err := template.New(cfg.Name).Funcs(templateFuncs).Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s: invalid template: %w", cfg.Name, err)
+	}
+
+	w := &Webhook{
+		cfg:    cfg,
+		tmpl:   tmpl,
+		key:    []byte(signatureKey),
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+	w.deliveryQueue = newDeliveryQueue(cfg.Name, queueConfig{
+		rateLimit:      cfg.RateLimit,
+		burst:          cfg.Burst,
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		queueSize:      cfg.QueueSize,
+	}, w.render, w.send, dlq)
+	return w, nil
+}
+
+func (w *Webhook) render(n Notification) ([]byte, error) {
+	var body bytes.Buffer
+	if err := w.tmpl.Execute(&body, templateData(w.cfg.Name, n)); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// send makes one delivery attempt. It returns the server's Retry-After for
+// throttled requests, and wraps errPermanent for failures not worth retrying.
+func (w *Webhook) send(ctx context.Context, deliveryID string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errPermanent, err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", w.cfg.ContentType)
+	req.Header.Set("User-Agent", "BeTrace-Webhook/1.0")
+	req.Header.Set(HeaderDeliveryID, deliveryID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(w.key, timestamp, body))
+
+	return do(w.client, req)
+}
+
+// do sends an HTTP request and classifies the response: 2xx succeeds, 429
+// and 5xx are retried (honouring Retry-After), other statuses are permanent
+func do(client *http.Client, req *http.Request) (time.Duration, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retryAfter(resp), fmt.Errorf("endpoint returned %s", resp.Status)
+	default:
+		// Other 4xx: the request itself is wrong
+		return 0, fmt.Errorf("%w: endpoint returned %s", errPermanent, resp.Status)
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// webhookSignatureContext separates webhook signatures from anything else
+// signed under BETRACE_SIGNATURE_KEY: it derives the webhook key and prefixes
+// every signed string
+const webhookSignatureContext = "betrace.webhook.v1"
+
+// Sign returns the X-BeTrace-Signature value for a request body, signed
+// with the webhook key derived from key (BETRACE_SIGNATURE_KEY)
+func Sign(key []byte, timestamp string, body []byte) string {
+	derive := hmac.New(sha256.New, key)
+	derive.Write([]byte(webhookSignatureContext))
+
+	mac := hmac.New(sha256.New, derive.Sum(nil))
+	mac.Write([]byte(webhookSignatureContext + "." + timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks an X-BeTrace-Signature value (for receivers)
+func VerifySignature(key []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(key, timestamp, body)), []byte(signature))
+}
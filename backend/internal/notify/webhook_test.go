@@ -0,0 +1,265 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+const testKey = "test-signature-key"
+
+// receiver is a local webhook endpoint recording what it was sent
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	times    []time.Time
+	status   func(n int) int // Response status for the n-th request (1-based)
+	received chan struct{}
+}
+
+func newReceiver(t *testing.T, status func(n int) int) (*receiver, *httptest.Server) {
+	r := &receiver{status: status, received: make(chan struct{}, 100)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		r.times = append(r.times, time.Now())
+		n := len(r.requests)
+		r.mu.Unlock()
+
+		code := http.StatusOK
+		if r.status != nil {
+			code = r.status(n)
+		}
+		w.WriteHeader(code)
+		r.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for request %d", i+1)
+		}
+	}
+}
+
+func testViolation(id string) models.Violation {
+	return models.Violation{ID: id, RuleID: "rule-1", RuleName: "PII access", Severity: "HIGH", Message: "unaudited access"}
+}
+
+func single(v models.Violation) Notification {
+	return Notification{Violations: []models.Violation{v}}
+}
+
+func startWebhook(t *testing.T, cfg WebhookConfig) (*Webhook, *DeadLetterQueue) {
+	t.Helper()
+	dlq, err := NewDeadLetterQueue(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue failed: %v", err)
+	}
+	if cfg.Name == "" {
+		cfg.Name = "test"
+	}
+	w, err := NewWebhook(cfg, testKey, dlq)
+	if err != nil {
+		t.Fatalf("NewWebhook failed: %v", err)
+	}
+	w.Start()
+	t.Cleanup(w.Close)
+	return w, dlq
+}
+
+func TestWebhook_DeliversSignedPayload(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	w, _ := startWebhook(t, WebhookConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+
+	w.Send(single(testViolation("v-1")))
+	recv.wait(t, 1)
+
+	req, body := recv.requests[0], recv.bodies[0]
+	if !VerifySignature([]byte(testKey), req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)) {
+		t.Errorf("Signature %q does not verify", req.Header.Get(HeaderSignature))
+	}
+	if VerifySignature([]byte("wrong-key"), req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)) {
+		t.Error("Signature verified with the wrong key")
+	}
+
+	// Signed under a derived key with a domain prefix, not the raw key
+	raw := hmac.New(sha256.New, []byte(testKey))
+	raw.Write([]byte(req.Header.Get(HeaderTimestamp) + "."))
+	raw.Write(body)
+	if req.Header.Get(HeaderSignature) == "sha256="+hex.EncodeToString(raw.Sum(nil)) {
+		t.Error("Expected the signature not to be a plain HMAC under the signature key")
+	}
+	if req.Header.Get(HeaderDeliveryID) == "" || req.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Missing delivery ID or custom header: %v", req.Header)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected application/json, got %s", req.Header.Get("Content-Type"))
+	}
+
+	var envelope struct {
+		Event     string           `json:"event"`
+		Endpoint  string           `json:"endpoint"`
+		Violation models.Violation `json:"violation"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Default payload is not JSON: %v: %s", err, body)
+	}
+	if envelope.Event != "violation" || envelope.Endpoint != "test" || envelope.Violation.ID != "v-1" {
+		t.Errorf("Unexpected envelope: %+v", envelope)
+	}
+}
+
+func TestWebhook_CustomTemplate(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	w, _ := startWebhook(t, WebhookConfig{
+		URL:         server.URL,
+		Template:    `{"text": {{json (printf "%s: %s" .Violation.Severity .Violation.RuleName)}}}`,
+		ContentType: "application/vnd.slack+json",
+	})
+
+	w.Send(single(testViolation("v-1")))
+	recv.wait(t, 1)
+
+	if got := string(recv.bodies[0]); got != `{"text": "HIGH: PII access"}` {
+		t.Errorf("Unexpected body: %s", got)
+	}
+	if ct := recv.requests[0].Header.Get("Content-Type"); ct != "application/vnd.slack+json" {
+		t.Errorf("Expected custom content type, got %s", ct)
+	}
+}
+
+func TestWebhook_InvalidTemplate(t *testing.T) {
+	if _, err := NewWebhook(WebhookConfig{Name: "bad", URL: "http://localhost", Template: "{{.Nope"}, testKey, nil); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+	if _, err := NewWebhook(WebhookConfig{Name: "no-url"}, testKey, nil); err == nil {
+		t.Error("Expected error for missing URL")
+	}
+}
+
+func TestWebhook_RetriesWithBackoff(t *testing.T) {
+	// Fail twice, then succeed
+	recv, server := newReceiver(t, func(n int) int {
+		if n <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	w, dlq := startWebhook(t, WebhookConfig{URL: server.URL, InitialBackoff: 20 * time.Millisecond})
+
+	w.Send(single(testViolation("v-1")))
+	recv.wait(t, 3)
+
+	// Same delivery retried: same ID, waits roughly doubling
+	if recv.requests[0].Header.Get(HeaderDeliveryID) != recv.requests[2].Header.Get(HeaderDeliveryID) {
+		t.Error("Expected retries to reuse the delivery ID")
+	}
+	first, second := recv.times[1].Sub(recv.times[0]), recv.times[2].Sub(recv.times[1])
+	if first < 20*time.Millisecond || second < 40*time.Millisecond {
+		t.Errorf("Expected exponential backoff, waited %v then %v", first, second)
+	}
+
+	w.Close()
+	if letters, _ := dlq.List(); len(letters) != 0 {
+		t.Errorf("Expected no dead letters, got %v", letters)
+	}
+}
+
+func TestWebhook_DeadLettersAfterMaxAttempts(t *testing.T) {
+	recv, server := newReceiver(t, func(int) int { return http.StatusInternalServerError })
+	w, dlq := startWebhook(t, WebhookConfig{URL: server.URL, MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	w.Send(single(testViolation("v-1")))
+	recv.wait(t, 3)
+	w.Close()
+
+	letters, err := dlq.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(letters))
+	}
+	letter := letters[0]
+	if len(letter.ViolationIDs) != 1 || letter.ViolationIDs[0] != "v-1" || letter.Attempts != 3 || letter.Endpoint != "test" || letter.Notification.Violations[0].ID != "v-1" {
+		t.Errorf("Unexpected dead letter: %+v", letter)
+	}
+	if !strings.Contains(letter.LastError, "500") || letter.Payload == "" {
+		t.Errorf("Expected last error and payload, got %+v", letter)
+	}
+}
+
+func TestWebhook_ClientErrorsAreNotRetried(t *testing.T) {
+	recv, server := newReceiver(t, func(int) int { return http.StatusBadRequest })
+	w, dlq := startWebhook(t, WebhookConfig{URL: server.URL, InitialBackoff: time.Millisecond})
+
+	w.Send(single(testViolation("v-1")))
+	recv.wait(t, 1)
+	w.Close()
+
+	if n := len(recv.requests); n != 1 {
+		t.Errorf("Expected 1 attempt for a 400, got %d", n)
+	}
+	if letters, _ := dlq.List(); len(letters) != 1 || letters[0].Attempts != 1 {
+		t.Errorf("Expected 1 dead letter after 1 attempt, got %v", letters)
+	}
+}
+
+func TestWebhook_RateLimit(t *testing.T) {
+	recv, server := newReceiver(t, nil)
+	w, _ := startWebhook(t, WebhookConfig{URL: server.URL, RateLimit: 20, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		w.Send(single(testViolation("v")))
+	}
+	recv.wait(t, 6)
+
+	// 2 immediately (burst), then 4 more at 20/s
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected rate limiting to spread 6 requests over >=200ms, took %v", elapsed)
+	}
+}
+
+func TestWebhook_FullQueueDeadLetters(t *testing.T) {
+	block := make(chan struct{})
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	w, dlq := startWebhook(t, WebhookConfig{URL: server.URL, QueueSize: 1, Timeout: 5 * time.Second})
+	for i := 0; i < 5; i++ {
+		w.Send(single(testViolation("v")))
+	}
+
+	// One in flight, one queued, the rest dead-lettered without blocking
+	letters, _ := dlq.List()
+	if len(letters) < 3 {
+		t.Errorf("Expected at least 3 dead letters from a full queue, got %d", len(letters))
+	}
+}
@@ -15,7 +15,7 @@ var (
 			Help:    "Time taken to evaluate a single rule against a span",
 			Buckets: prometheus.ExponentialBuckets(0.000001, 2, 20), // 1μs to 1s
 		},
-		[]string{"rule_id", "result"}, // result: match|no_match|error
+		[]string{"rule_id", "result"}, // result: match|no_match|error|aborted
 	)
 
 	RuleEvaluationTotal = promauto.NewCounterVec(
@@ -26,6 +26,31 @@ var (
 		[]string{"rule_id", "result"},
 	)
 
+	RuleEvaluationAborted = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "betrace_rule_evaluation_aborted_total",
+			Help: "Rule evaluations aborted before producing a result",
+		},
+		[]string{"rule_id", "reason"}, // reason: timeout|step_budget|canceled
+	)
+
+	// Shadow (dry-run) rules: match rate is violated / (passed + violated)
+	ShadowRuleEvaluations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "betrace_shadow_rule_evaluations_total",
+			Help: "Trace evaluations of shadow-mode rules that applied to the trace",
+		},
+		[]string{"rule_id", "outcome"}, // outcome: passed|violated|errored
+	)
+
+	ShadowViolationsRecorded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "betrace_shadow_violations_total",
+			Help: "Would-be violations of shadow-mode rules, kept out of the violation stream",
+		},
+		[]string{"rule_id"},
+	)
+
 	RuleEngineSpansProcessed = promauto.NewCounter(
 		prometheus.CounterOpts{
 			Name: "betrace_rule_engine_spans_processed_total",
@@ -184,7 +184,7 @@ func InitMetrics() error {
 func RecordRuleEvaluation(ctx context.Context, ruleID string, result string, durationSeconds float64) {
 	attrs := metric.WithAttributes(
 		attribute.String("rule_id", ruleID),
-		attribute.String("result", result), // match|no_match|error
+		attribute.String("result", result), // match|no_match|error|aborted
 	)
 
 	ruleEvaluationDuration.Record(ctx, durationSeconds, attrs)
@@ -19,18 +19,10 @@ import (
 // InitOpenTelemetry initializes OpenTelemetry with both tracing and metrics
 // Exports to OTLP endpoint (works with Tempo, SigNoz, Kibana, etc.)
 func InitOpenTelemetry(ctx context.Context, serviceName, serviceVersion string) (func(context.Context) error, error) {
-	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if endpoint == "" {
-		endpoint = "localhost:4317"
-	}
+	endpoint := defaultEndpoint()
 
 	// Create resource with service information
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(serviceName),
-			semconv.ServiceVersionKey.String(serviceVersion),
-		),
-	)
+	res, err := newResource(ctx, serviceName, serviceVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -94,3 +86,21 @@ func InitOpenTelemetry(ctx context.Context, serviceName, serviceVersion string)
 
 	return shutdownFunc, nil
 }
+
+// defaultEndpoint returns the OTLP gRPC endpoint from OTEL_EXPORTER_OTLP_ENDPOINT
+func defaultEndpoint() string {
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	return "localhost:4317"
+}
+
+// newResource describes this service to telemetry backends
+func newResource(ctx context.Context, serviceName, serviceVersion string) (*resource.Resource, error) {
+	return resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(serviceVersion),
+		),
+	)
+}
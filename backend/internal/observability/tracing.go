@@ -3,8 +3,10 @@ package observability
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/betracehq/betrace/backend/pkg/models"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -67,6 +69,54 @@ var (
 	FedRAMP_CM_2 = ComplianceControl{FrameworkFedRAMP, "CM-2", "Baseline Configuration"}
 )
 
+// ComplianceFrameworks lists the supported frameworks
+var ComplianceFrameworks = []ComplianceFramework{FrameworkSOC2, FrameworkHIPAA, FrameworkGDPR, FrameworkFedRAMP}
+
+// ComplianceControls is the catalog of known controls, in framework order
+var ComplianceControls = []ComplianceControl{
+	SOC2_CC6_1, SOC2_CC6_2, SOC2_CC6_3, SOC2_CC6_6, SOC2_CC6_7, SOC2_CC7_1, SOC2_CC7_2, SOC2_CC8_1,
+	HIPAA_164_312_a, HIPAA_164_312_b, HIPAA_164_312_a_2_i, HIPAA_164_312_a_2_iv, HIPAA_164_312_e_2_ii,
+	GDPR_Art_15, GDPR_Art_17, GDPR_Art_7, GDPR_Art_32,
+	FedRAMP_AC_2, FedRAMP_AC_3, FedRAMP_AU_2, FedRAMP_AU_3, FedRAMP_CM_2,
+}
+
+// ParseComplianceFramework resolves a framework name, ignoring case, spaces
+// and hyphens ("SOC 2", "soc2" and "FedRAMP" all resolve)
+func ParseComplianceFramework(name string) (ComplianceFramework, bool) {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+	for _, framework := range ComplianceFrameworks {
+		if string(framework) == key {
+			return framework, true
+		}
+	}
+	return "", false
+}
+
+// LookupControl returns the control with its notes from the catalog. Controls
+// outside the catalog are returned without notes.
+func LookupControl(framework ComplianceFramework, controlID string) ComplianceControl {
+	for _, control := range ComplianceControls {
+		if control.Framework == framework && strings.EqualFold(control.ControlID, controlID) {
+			return control
+		}
+	}
+	return ComplianceControl{Framework: framework, ControlID: controlID}
+}
+
+// RuleControls returns the compliance controls a rule is evidence for.
+// Mappings naming an unknown framework are skipped.
+func RuleControls(rule models.Rule) []ComplianceControl {
+	var controls []ComplianceControl
+	for _, mapping := range rule.Controls {
+		framework, ok := ParseComplianceFramework(mapping.Framework)
+		if !ok {
+			continue
+		}
+		controls = append(controls, LookupControl(framework, mapping.ControlID))
+	}
+	return controls
+}
+
 // ComplianceSpanAttributes creates standard compliance span attributes
 func ComplianceSpanAttributes(control ComplianceControl, outcome string) []attribute.KeyValue {
 	return []attribute.KeyValue{
@@ -0,0 +1,209 @@
+package observability
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand/v2"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ViolationSpanName names the spans (and span events) violations are exported as
+const ViolationSpanName = "betrace.violation"
+
+// ViolationExportConfig configures the OTLP violation exporter. Zero batch
+// settings use the OTel SDK defaults (512 spans, 5s, queue of 2048).
+type ViolationExportConfig struct {
+	Endpoint     string // OTLP gRPC host:port, default OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure     bool
+	Headers      map[string]string
+	BatchSize    int           // Spans per export
+	BatchTimeout time.Duration // Max delay before a partial batch is exported
+	QueueSize    int           // Spans buffered before new ones are dropped
+}
+
+// ViolationExporter emits violations into the traces they were found in:
+// each violation becomes a "betrace.violation" span, parented to the
+// offending span, carrying a span event with the violation's details. Tempo
+// and Jaeger then show violations inline next to the offending spans.
+//
+// It uses its own tracer provider, so violation export has its own endpoint
+// and batching independent of BeTrace's self-instrumentation.
+type ViolationExporter struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewViolationExporter creates an exporter sending to an OTLP gRPC endpoint
+func NewViolationExporter(ctx context.Context, cfg ViolationExportConfig, serviceName, serviceVersion string) (*ViolationExporter, error) {
+	res, err := newResource(ctx, serviceName, serviceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint()
+	}
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+	if err != nil {
+		return nil, err
+	}
+	return newViolationExporter(res, exporter, cfg), nil
+}
+
+func newViolationExporter(res *resource.Resource, exporter sdktrace.SpanExporter, cfg ViolationExportConfig) *ViolationExporter {
+	var batch []sdktrace.BatchSpanProcessorOption
+	if cfg.BatchSize > 0 {
+		batch = append(batch, sdktrace.WithMaxExportBatchSize(cfg.BatchSize))
+	}
+	if cfg.BatchTimeout > 0 {
+		batch = append(batch, sdktrace.WithBatchTimeout(cfg.BatchTimeout))
+	}
+	if cfg.QueueSize > 0 {
+		batch = append(batch, sdktrace.WithMaxQueueSize(cfg.QueueSize))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithResource(res),
+		sdktrace.WithIDGenerator(violationIDGenerator{}),
+		sdktrace.WithBatcher(exporter, batch...),
+	)
+	return &ViolationExporter{provider: provider, tracer: provider.Tracer("betrace.violations")}
+}
+
+// Export queues v for export, one span per trace it references
+func (e *ViolationExporter) Export(v models.Violation) {
+	for _, ref := range exportRefs(v) {
+		traceID, err := trace.TraceIDFromHex(ref.TraceID)
+		if err != nil {
+			continue
+		}
+
+		ctx := context.Background()
+		if spanID, err := trace.SpanIDFromHex(ref.SpanID); err == nil {
+			// Child of the offending span
+			ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: trace.FlagsSampled,
+				Remote:     true,
+			}))
+		} else {
+			// Trace-level violation without offending spans: a root span in the trace
+			ctx = context.WithValue(ctx, traceIDKey{}, traceID)
+		}
+
+		attrs := violationAttributes(v, ref)
+		_, span := e.tracer.Start(ctx, ViolationSpanName,
+			trace.WithTimestamp(v.CreatedAt),
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attrs...),
+		)
+		span.AddEvent(ViolationSpanName, trace.WithTimestamp(v.CreatedAt), trace.WithAttributes(
+			attribute.String("betrace.violation.id", v.ID),
+			attribute.String("betrace.violation.rule_id", v.RuleID),
+			attribute.String("betrace.violation.severity", v.Severity),
+			attribute.String("betrace.violation.message", v.Message),
+		))
+		span.SetStatus(codes.Error, v.Message)
+		span.End(trace.WithTimestamp(v.CreatedAt))
+	}
+}
+
+// Shutdown exports queued violations and stops the exporter
+func (e *ViolationExporter) Shutdown(ctx context.Context) error {
+	return e.provider.Shutdown(ctx)
+}
+
+func violationAttributes(v models.Violation, ref models.SpanRef) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Bool("betrace.violation", true),
+		attribute.String("betrace.violation.id", v.ID),
+		attribute.String("betrace.violation.severity", v.Severity),
+		attribute.String("betrace.violation.rule_id", v.RuleID),
+		attribute.String("betrace.violation.rule_name", v.RuleName),
+		attribute.String("betrace.violation.message", v.Message),
+		attribute.String("betrace.violation.trace_id", ref.TraceID),
+	}
+	if ref.SpanID != "" {
+		attrs = append(attrs, attribute.String("betrace.violation.span_id", ref.SpanID))
+	}
+	if ref.ServiceName != "" {
+		attrs = append(attrs, attribute.String("betrace.violation.service", ref.ServiceName))
+	}
+	if v.Fingerprint != "" {
+		attrs = append(attrs, attribute.String("betrace.violation.fingerprint", v.Fingerprint))
+	}
+	if len(v.Tags) > 0 {
+		attrs = append(attrs, attribute.StringSlice("betrace.violation.tags", v.Tags))
+	}
+	return attrs
+}
+
+// exportRefs picks one span reference per trace: the first offending span,
+// or a trace-only reference for traces without one
+func exportRefs(v models.Violation) []models.SpanRef {
+	var refs []models.SpanRef
+	index := make(map[string]int)
+	add := func(ref models.SpanRef) {
+		i, ok := index[ref.TraceID]
+		switch {
+		case !ok:
+			index[ref.TraceID] = len(refs)
+			refs = append(refs, ref)
+		case refs[i].SpanID == "" && ref.SpanID != "":
+			refs[i] = ref
+		}
+	}
+	for _, ref := range v.SpanRefs {
+		add(ref)
+	}
+	for _, traceID := range v.TraceIDs {
+		add(models.SpanRef{TraceID: traceID})
+	}
+	return refs
+}
+
+// traceIDKey carries the trace ID for a root violation span
+type traceIDKey struct{}
+
+// violationIDGenerator places root violation spans in the violating trace
+type violationIDGenerator struct{}
+
+func (violationIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	traceID, ok := ctx.Value(traceIDKey{}).(trace.TraceID)
+	if !ok {
+		crand.Read(traceID[:])
+	}
+	return traceID, newSpanID()
+}
+
+func (violationIDGenerator) NewSpanID(context.Context, trace.TraceID) trace.SpanID {
+	return newSpanID()
+}
+
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,108 @@
+package observability
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func exportViolation(t *testing.T, v models.Violation) tracetest.SpanStubs {
+	t.Helper()
+	memory := tracetest.NewInMemoryExporter()
+	e := newViolationExporter(resource.Empty(), memory, ViolationExportConfig{BatchTimeout: time.Millisecond})
+	defer e.Shutdown(context.Background())
+
+	e.Export(v)
+	if err := e.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush failed: %v", err)
+	}
+	return memory.GetSpans()
+}
+
+func TestViolationExporter_ParentsToOffendingSpan(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	spans := exportViolation(t, models.Violation{
+		ID:          "v-1",
+		RuleID:      "rule-1",
+		RuleName:    "missing_audit_log",
+		Severity:    "CRITICAL",
+		Message:     "PII access without audit log",
+		TraceIDs:    []string{testTraceID},
+		SpanRefs:    []models.SpanRef{{TraceID: testTraceID, SpanID: testSpanID, ServiceName: "checkout"}},
+		Fingerprint: "fp-1",
+		CreatedAt:   createdAt,
+	})
+
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name != ViolationSpanName {
+		t.Errorf("Expected span name %s, got %s", ViolationSpanName, span.Name)
+	}
+	if span.SpanContext.TraceID().String() != testTraceID || span.Parent.SpanID().String() != testSpanID {
+		t.Errorf("Expected child of %s/%s, got trace %s parent %s", testTraceID, testSpanID, span.SpanContext.TraceID(), span.Parent.SpanID())
+	}
+	if !span.StartTime.Equal(createdAt) || span.Status.Code != codes.Error {
+		t.Errorf("Expected error span at %v, got %v %v", createdAt, span.StartTime, span.Status)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	for k, want := range map[string]string{
+		"betrace.violation":           "true",
+		"betrace.violation.rule_id":   "rule-1",
+		"betrace.violation.severity":  "CRITICAL",
+		"betrace.violation.span_id":   testSpanID,
+		"betrace.violation.service":   "checkout",
+		"betrace.violation.trace_id":  testTraceID,
+		"betrace.violation.rule_name": "missing_audit_log",
+	} {
+		if attrs[k] != want {
+			t.Errorf("Attribute %s: expected %q, got %q", k, want, attrs[k])
+		}
+	}
+
+	if len(span.Events) != 1 || span.Events[0].Name != ViolationSpanName {
+		t.Fatalf("Expected a %s span event, got %v", ViolationSpanName, span.Events)
+	}
+}
+
+func TestViolationExporter_TraceLevelViolation(t *testing.T) {
+	otherTrace := "0af7651916cd43dd8448eb211c80319c"
+	spans := exportViolation(t, models.Violation{
+		ID:       "v-1",
+		RuleID:   "rule-1",
+		TraceIDs: []string{testTraceID, otherTrace, "not-hex"},
+		SpanRefs: []models.SpanRef{{TraceID: testTraceID}, {TraceID: testTraceID, SpanID: testSpanID}},
+	})
+
+	if len(spans) != 2 {
+		t.Fatalf("Expected one span per valid trace, got %d", len(spans))
+	}
+	byTrace := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byTrace[span.SpanContext.TraceID().String()] = span
+	}
+
+	if byTrace[testTraceID].Parent.SpanID().String() != testSpanID {
+		t.Errorf("Expected the offending span to be preferred as parent, got %s", byTrace[testTraceID].Parent.SpanID())
+	}
+	root, ok := byTrace[otherTrace]
+	if !ok || root.Parent.IsValid() {
+		t.Errorf("Expected a root span in trace %s, got %+v", otherTrace, root)
+	}
+}
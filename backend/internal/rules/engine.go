@@ -2,10 +2,13 @@ package rules
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/betracehq/betrace/backend/internal/dsl"
+	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/pkg/models"
 )
 
@@ -13,29 +16,159 @@ const (
 	// MaxRules is the maximum number of compiled rules allowed in memory
 	// Prevents unbounded memory growth (150K rules ~= 128MB with typical mix)
 	MaxRules = 100000
+
+	// DefaultEvaluationTimeout bounds the time spent evaluating one rule against one trace
+	DefaultEvaluationTimeout = 5 * time.Second
+
+	// DefaultMaxEvaluationSteps bounds the span visits of one rule against one trace
+	DefaultMaxEvaluationSteps = 10000000
 )
 
+// Abort reasons reported in EvaluationResult and the
+// betrace_rule_evaluation_aborted_total metric
+const (
+	AbortReasonTimeout    = "timeout"
+	AbortReasonStepBudget = "step_budget"
+	AbortReasonCanceled   = "canceled"
+)
+
+// EvaluationLimits bounds the work spent evaluating a single rule against a
+// single trace. A zero field means no limit.
+type EvaluationLimits struct {
+	Timeout  time.Duration // Wall-clock budget per rule
+	MaxSteps int64         // Span visits per rule (see dsl.Limits)
+}
+
+// dslLimits converts the limits to the evaluator's
+func (l EvaluationLimits) dslLimits() dsl.Limits {
+	return dsl.Limits{MaxSteps: l.MaxSteps, Timeout: l.Timeout}
+}
+
 // CompiledRule represents a rule with its pre-parsed AST and field filter
 type CompiledRule struct {
 	Rule        models.Rule
 	AST         *dsl.Rule    // Pre-parsed DSL v2.0 AST (cached)
+	Program     *dsl.Program // AST compiled to closures (used for evaluation)
 	FieldFilter *FieldFilter // Fields accessed by this rule (lazy evaluation)
+
+	// RequiredOperations lists operation names of which at least one must be
+	// present in a trace for the when clause to match (nil = evaluate always)
+	RequiredOperations []string
 }
 
 // RuleEngine manages compiled rules and evaluates them against spans
 type RuleEngine struct {
 	mu            sync.RWMutex
 	rules         map[string]*CompiledRule
-	evaluator     *dsl.Evaluator // DSL v2.0 evaluator
+	index         *operationIndex  // Operation name -> rules that require it
+	explainer     *dsl.Evaluator   // AST evaluator, used for explain mode only
 	parseErrors   map[string]error // Track rules that failed to parse
+	limits        EvaluationLimits // Per-rule evaluation budget
 }
 
 // NewRuleEngine creates a new rule engine
 func NewRuleEngine() *RuleEngine {
 	return &RuleEngine{
 		rules:       make(map[string]*CompiledRule),
-		evaluator:   dsl.NewEvaluator(),
+		index:       newOperationIndex(),
+		explainer:   dsl.NewEvaluator(),
 		parseErrors: make(map[string]error),
+		limits: EvaluationLimits{
+			Timeout:  DefaultEvaluationTimeout,
+			MaxSteps: DefaultMaxEvaluationSteps,
+		},
+	}
+}
+
+// SetEvaluationLimits replaces the per-rule evaluation budget
+func (e *RuleEngine) SetEvaluationLimits(limits EvaluationLimits) {
+	e.mu.Lock()
+	e.limits = limits
+	e.mu.Unlock()
+}
+
+// EvaluationLimits returns the per-rule evaluation budget
+func (e *RuleEngine) EvaluationLimits() EvaluationLimits {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.limits
+}
+
+// newCompiledRule builds the cached representation of a parsed rule
+func newCompiledRule(rule models.Rule, ast *dsl.Rule) (*CompiledRule, error) {
+	program, err := dsl.Compile(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rules whose when clause can't be reduced to required operations stay unindexed
+	requiredOps, _ := dsl.RequiredOperations(ast)
+
+	return &CompiledRule{
+		Rule:               rule,
+		AST:                ast,
+		Program:            program,
+		RequiredOperations: requiredOps,
+	}, nil
+}
+
+// storeLocked caches a compiled rule and updates the operation index.
+// Caller must hold e.mu for writing.
+func (e *RuleEngine) storeLocked(compiled *CompiledRule) {
+	if old, exists := e.rules[compiled.Rule.ID]; exists {
+		e.index.remove(old.Rule.ID, old.RequiredOperations)
+	}
+
+	e.rules[compiled.Rule.ID] = compiled
+	e.index.add(compiled.Rule.ID, compiled.RequiredOperations)
+	delete(e.parseErrors, compiled.Rule.ID) // Clear any previous error
+}
+
+// candidateRules returns enabled rules whose when clause could match the spans,
+// using the operation index to skip rules that require absent operations
+func (e *RuleEngine) candidateRules(spans []*models.Span) []*CompiledRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	candidates := e.index.candidates(spans)
+	rules := make([]*CompiledRule, 0, len(candidates))
+	for ruleID := range candidates {
+		if r, ok := e.rules[ruleID]; ok && r.Rule.Enabled {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// evaluateCompiled runs a rule's program within the evaluation limits and
+// reports whether it was violated
+func evaluateCompiled(ctx context.Context, compiled *CompiledRule, spans []*models.Span, limits EvaluationLimits) (bool, error) {
+	outcome, err := evaluateCompiledOutcome(ctx, compiled, spans, limits)
+	return outcome == dsl.OutcomeViolated, err
+}
+
+// evaluateCompiledOutcome runs a rule's program within the evaluation limits.
+// Aborted evaluations are counted per rule and reason.
+func evaluateCompiledOutcome(ctx context.Context, compiled *CompiledRule, spans []*models.Span, limits EvaluationLimits) (dsl.Outcome, error) {
+	outcome, err := compiled.Program.EvaluateOutcome(ctx, spans, limits.dslLimits())
+	if errors.Is(err, dsl.ErrEvaluationAborted) {
+		observability.RuleEvaluationAborted.WithLabelValues(compiled.Rule.ID, AbortReason(err)).Inc()
+	}
+	return outcome, err
+}
+
+// AbortReason classifies an error wrapping dsl.ErrEvaluationAborted.
+// It returns "" for errors that are not aborts.
+func AbortReason(err error) string {
+	switch {
+	case !errors.Is(err, dsl.ErrEvaluationAborted):
+		return ""
+	case errors.Is(err, dsl.ErrStepBudgetExceeded):
+		return AbortReasonStepBudget
+	case errors.Is(err, context.DeadlineExceeded):
+		return AbortReasonTimeout
+	default:
+		return AbortReasonCanceled
 	}
 }
 
@@ -70,18 +203,19 @@ func (e *RuleEngine) LoadRule(rule models.Rule) error {
 		return fmt.Errorf("failed to parse rule %s: %w", rule.ID, err)
 	}
 
-	// Build field filter for lazy evaluation (temporarily disabled for DSL v2.0)
+	// Compile the AST into an evaluation program
 	// TODO: Implement field filter extraction for DSL v2.0 AST
-	var fieldFilter *FieldFilter
+	compiled, err := newCompiledRule(rule, ast)
+	if err != nil {
+		e.mu.Lock()
+		e.parseErrors[rule.ID] = err
+		e.mu.Unlock()
+		return fmt.Errorf("failed to compile rule %s: %w", rule.ID, err)
+	}
 
-	// Cache the compiled rule
+	// Cache the compiled rule and index it by required operations
 	e.mu.Lock()
-	e.rules[rule.ID] = &CompiledRule{
-		Rule:        rule,
-		AST:         ast,
-		FieldFilter: fieldFilter,
-	}
-	delete(e.parseErrors, rule.ID) // Clear any previous error
+	e.storeLocked(compiled)
 	e.mu.Unlock()
 
 	return nil
@@ -90,6 +224,9 @@ func (e *RuleEngine) LoadRule(rule models.Rule) error {
 // UnloadRule removes a rule from the engine
 func (e *RuleEngine) UnloadRule(ruleID string) {
 	e.mu.Lock()
+	if old, exists := e.rules[ruleID]; exists {
+		e.index.remove(ruleID, old.RequiredOperations)
+	}
 	delete(e.rules, ruleID)
 	delete(e.parseErrors, ruleID)
 	e.mu.Unlock()
@@ -125,6 +262,7 @@ func (e *RuleEngine) EvaluateRule(ctx context.Context, ruleID string, span *mode
 	// Get compiled rule (read lock only)
 	e.mu.RLock()
 	compiled, ok := e.rules[ruleID]
+	limits := e.limits
 	e.mu.RUnlock()
 
 	if !ok {
@@ -139,30 +277,24 @@ func (e *RuleEngine) EvaluateRule(ctx context.Context, ruleID string, span *mode
 	// DSL v2.0 is trace-level by design - convert single span to trace
 	spans := []*models.Span{span}
 
-	// Evaluate using DSL v2.0 evaluator
-	return e.evaluator.EvaluateRule(compiled.AST, spans)
+	// Evaluate using the compiled DSL v2.0 program
+	return evaluateCompiled(ctx, compiled, spans, limits)
 }
 
 // EvaluateAll evaluates all enabled rules against a span (converts to single-span trace)
 // Returns list of rule IDs that matched
 func (e *RuleEngine) EvaluateAll(ctx context.Context, span *models.Span) ([]string, error) {
-	// Get snapshot of rules (read lock only)
-	e.mu.RLock()
-	rules := make([]*CompiledRule, 0, len(e.rules))
-	for _, r := range e.rules {
-		if r.Rule.Enabled {
-			rules = append(rules, r)
-		}
-	}
-	e.mu.RUnlock()
-
 	// DSL v2.0 is trace-level by design - convert single span to trace
 	spans := []*models.Span{span}
 
+	// Get snapshot of candidate rules (read lock only)
+	rules := e.candidateRules(spans)
+	limits := e.EvaluationLimits()
+
 	// Evaluate each rule (no locks needed - AST is immutable)
 	matches := make([]string, 0, 10)
 	for _, compiled := range rules {
-		result, err := e.evaluator.EvaluateRule(compiled.AST, spans)
+		result, err := evaluateCompiled(ctx, compiled, spans, limits)
 		if err != nil {
 			// Log error but continue evaluating other rules
 			continue
@@ -179,31 +311,82 @@ func (e *RuleEngine) EvaluateAll(ctx context.Context, span *models.Span) ([]stri
 // EvaluateTrace evaluates all enabled rules against a complete trace
 // Returns list of rule IDs that matched
 func (e *RuleEngine) EvaluateTrace(ctx context.Context, traceID string, spans []*models.Span) ([]string, error) {
-	// Get snapshot of rules (read lock only)
-	e.mu.RLock()
-	rules := make([]*CompiledRule, 0, len(e.rules))
-	for _, r := range e.rules {
-		if r.Rule.Enabled {
-			rules = append(rules, r)
+	matches := make([]string, 0, 10)
+	for _, result := range e.EvaluateTraceDetailed(ctx, traceID, spans) {
+		// Errors and aborts are reported by EvaluateTraceDetailed; continue with other rules
+		if result.Matched {
+			matches = append(matches, result.RuleID)
 		}
 	}
-	e.mu.RUnlock()
+
+	return matches, nil
+}
+
+// EvaluateTraceDetailed evaluates all enabled rules that can match a complete
+// trace and returns one result per evaluated rule, including rules whose
+// evaluation errored or was aborted by the evaluation limits
+func (e *RuleEngine) EvaluateTraceDetailed(ctx context.Context, traceID string, spans []*models.Span) []EvaluationResult {
+	// Get snapshot of rules that can match this trace's operations (read lock only)
+	rules := e.candidateRules(spans)
+	limits := e.EvaluationLimits()
 
 	// DSL v2.0 is trace-level by design - all rules evaluate over complete traces
-	matches := make([]string, 0, 10)
+	results := make([]EvaluationResult, 0, len(rules))
 	for _, compiled := range rules {
-		result, err := e.evaluator.EvaluateRule(compiled.AST, spans)
-		if err != nil {
-			// Log error but continue evaluating other rules
-			continue
-		}
+		outcome, err := evaluateCompiledOutcome(ctx, compiled, spans, limits)
+		results = append(results, newEvaluationResult(compiled, outcome, err))
+	}
 
-		if result {
-			matches = append(matches, compiled.Rule.ID)
-		}
+	return results
+}
+
+// ExplainRule evaluates a loaded rule against a trace in explain mode,
+// returning which spans and sub-conditions decided the result. It is much
+// slower than EvaluateTrace; call it for violating traces or on demand.
+// Like evaluation, it stops when ctx is done or the engine's evaluation
+// limits are exceeded.
+func (e *RuleEngine) ExplainRule(ctx context.Context, ruleID string, spans []*models.Span) (*models.RuleExplanation, error) {
+	e.mu.RLock()
+	compiled, ok := e.rules[ruleID]
+	limits := e.limits
+	e.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("rule not found: %s", ruleID)
 	}
 
-	return matches, nil
+	explanation, err := e.explainer.ExplainContext(ctx, compiled.AST, spans, limits.dslLimits())
+	if errors.Is(err, dsl.ErrEvaluationAborted) {
+		observability.RuleEvaluationAborted.WithLabelValues(compiled.Rule.ID, AbortReason(err)).Inc()
+	}
+	return explanation, err
+}
+
+// ExplainExpression parses a DSL v2.0 expression and explains it against a
+// trace within the engine's evaluation limits, without loading it as a rule
+func (e *RuleEngine) ExplainExpression(ctx context.Context, expression string, spans []*models.Span) (*models.RuleExplanation, error) {
+	ast, err := e.parseRuleDSL(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.explainer.ExplainContext(ctx, ast, spans, e.EvaluationLimits().dslLimits())
+}
+
+// EvaluateExpression parses, compiles and evaluates a DSL v2.0 expression
+// against a trace within the engine's evaluation limits, without loading it
+// as a rule
+func (e *RuleEngine) EvaluateExpression(ctx context.Context, expression string, spans []*models.Span) (bool, error) {
+	ast, err := e.parseRuleDSL(expression)
+	if err != nil {
+		return false, err
+	}
+	program, err := dsl.Compile(ast)
+	if err != nil {
+		return false, err
+	}
+
+	return program.EvaluateContext(ctx, spans, e.EvaluationLimits().dslLimits())
 }
 
 // EvaluateAllDetailed evaluates all enabled rules and returns detailed results
@@ -212,6 +395,28 @@ type EvaluationResult struct {
 	RuleName string
 	Matched  bool
 	Error    error
+
+	// Passed is set when the rule applied to the trace (its when clause
+	// matched) and held; neither Matched nor Passed means it didn't apply
+	Passed bool
+
+	// Aborted is set when evaluation stopped before producing a result
+	// (Matched is then meaningless); AbortReason says why
+	Aborted     bool
+	AbortReason string
+}
+
+func newEvaluationResult(compiled *CompiledRule, outcome dsl.Outcome, err error) EvaluationResult {
+	reason := AbortReason(err)
+	return EvaluationResult{
+		RuleID:      compiled.Rule.ID,
+		RuleName:    compiled.Rule.Name,
+		Matched:     outcome == dsl.OutcomeViolated && err == nil,
+		Passed:      outcome == dsl.OutcomePassed && err == nil,
+		Error:       err,
+		Aborted:     reason != "",
+		AbortReason: reason,
+	}
 }
 
 func (e *RuleEngine) EvaluateAllDetailed(ctx context.Context, span *models.Span) []EvaluationResult {
@@ -223,6 +428,7 @@ func (e *RuleEngine) EvaluateAllDetailed(ctx context.Context, span *models.Span)
 			rules = append(rules, r)
 		}
 	}
+	limits := e.limits
 	e.mu.RUnlock()
 
 	// DSL v2.0 is trace-level by design - convert single span to trace
@@ -231,13 +437,8 @@ func (e *RuleEngine) EvaluateAllDetailed(ctx context.Context, span *models.Span)
 	// Evaluate each rule
 	results := make([]EvaluationResult, 0, len(rules))
 	for _, compiled := range rules {
-		matched, err := e.evaluator.EvaluateRule(compiled.AST, spans)
-		results = append(results, EvaluationResult{
-			RuleID:   compiled.Rule.ID,
-			RuleName: compiled.Rule.Name,
-			Matched:  matched,
-			Error:    err,
-		})
+		outcome, err := evaluateCompiledOutcome(ctx, compiled, spans, limits)
+		results = append(results, newEvaluationResult(compiled, outcome, err))
 	}
 
 	return results
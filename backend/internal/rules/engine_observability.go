@@ -39,6 +39,7 @@ func (e *RuleEngine) EvaluateAllWithObservability(ctx context.Context, span *mod
 			activeRuleCount++
 		}
 	}
+	limits := e.limits
 	e.mu.RUnlock()
 
 	// Update active rules gauge (OTel) - just track current count
@@ -60,15 +61,20 @@ func (e *RuleEngine) EvaluateAllWithObservability(ctx context.Context, span *mod
 		// Track that we're using DSL v2.0
 		observability.RecordLazyFieldsLoaded(ctx, compiled.Rule.ID, int64(len(span.Attributes)))
 
-		// Evaluate using DSL v2.0
-		result, err := e.evaluator.EvaluateRule(compiled.AST, spans)
+		// Evaluate using the compiled DSL v2.0 program
+		result, err := evaluateCompiled(ruleCtx, compiled, spans, limits)
 
 		duration := time.Since(startTime)
 
 		if err != nil {
-			// Record error (OTel)
+			// Record error or abort (OTel)
+			resultStr := "error"
+			if reason := AbortReason(err); reason != "" {
+				resultStr = "aborted"
+				ruleSpan.SetAttributes(attribute.String("abort_reason", reason))
+			}
 			ruleSpan.SetAttributes(attribute.String("error", err.Error()))
-			observability.RecordRuleEvaluation(ruleCtx, compiled.Rule.ID, "error", duration.Seconds())
+			observability.RecordRuleEvaluation(ruleCtx, compiled.Rule.ID, resultStr, duration.Seconds())
 			ruleSpan.End()
 			continue
 		}
@@ -84,8 +90,9 @@ func (e *RuleEngine) EvaluateAllWithObservability(ctx context.Context, span *mod
 		if result {
 			matches = append(matches, compiled.Rule.ID)
 
-			// Emit compliance evidence for matched rules
-			if isComplianceRule(compiled.Rule) {
+			// Emit compliance evidence for matched rules; shadow rules
+			// aren't evidence of anything yet
+			if isComplianceRule(compiled.Rule) && !compiled.Rule.IsShadow() {
 				emitComplianceEvidenceForRule(ctx, compiled.Rule, span)
 			}
 		}
@@ -125,16 +132,18 @@ func (e *RuleEngine) LoadRuleWithObservability(ctx context.Context, rule models.
 	}
 
 	// DSL v2.0 doesn't use field filters - full trace evaluation
-	var fieldFilter *FieldFilter
+	compiled, err := newCompiledRule(rule, ast)
+	if err != nil {
+		observability.RecordRuleLoadResult(ctx, span, rule.ID, err, duration)
+		e.mu.Lock()
+		e.parseErrors[rule.ID] = err
+		e.mu.Unlock()
+		return err
+	}
 
-	// Cache the compiled rule
+	// Cache the compiled rule and index it by required operations
 	e.mu.Lock()
-	e.rules[rule.ID] = &CompiledRule{
-		Rule:        rule,
-		AST:         ast,
-		FieldFilter: fieldFilter,
-	}
-	delete(e.parseErrors, rule.ID)
+	e.storeLocked(compiled)
 	activeCount := len(e.rules)
 	e.mu.Unlock()
 
@@ -145,21 +154,20 @@ func (e *RuleEngine) LoadRuleWithObservability(ctx context.Context, rule models.
 	span.SetAttributes(
 		attribute.String("dsl.version", "2.0"),
 		attribute.Bool("dsl.trace_level", true),
+		attribute.StringSlice("dsl.required_operations", compiled.RequiredOperations),
 	)
 
 	return nil
 }
 
-// isComplianceRule checks if a rule is tagged for compliance monitoring
+// isComplianceRule checks if a rule is mapped to compliance controls
 func isComplianceRule(rule models.Rule) bool {
-	// Check if rule has compliance tags/metadata
-	// This would be populated from rule configuration
-	return false // TODO: Implement based on rule metadata
+	return len(rule.Controls) > 0
 }
 
-// emitComplianceEvidenceForRule emits compliance evidence for a matched rule
+// emitComplianceEvidenceForRule emits compliance evidence for each control a
+// matched rule monitors
 func emitComplianceEvidenceForRule(ctx context.Context, rule models.Rule, span *models.Span) {
-	// Example: Emit SOC2 CC7.1 evidence for monitoring
 	details := map[string]interface{}{
 		"rule_id":      rule.ID,
 		"span_id":      span.SpanID,
@@ -168,7 +176,9 @@ func emitComplianceEvidenceForRule(ctx context.Context, rule models.Rule, span *
 		"matched":      true,
 	}
 
-	observability.EmitComplianceEvidence(ctx, observability.SOC2_CC7_1, "monitored", details)
+	for _, control := range observability.RuleControls(rule) {
+		observability.EmitComplianceEvidence(ctx, control, "monitored", details)
+	}
 }
 
 // countFieldsLoaded estimates how many fields were actually loaded during lazy evaluation
@@ -2,9 +2,15 @@ package rules
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/betracehq/betrace/backend/internal/dsl"
+	"github.com/betracehq/betrace/backend/internal/observability"
 	"github.com/betracehq/betrace/backend/pkg/models"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -430,3 +436,130 @@ func BenchmarkRuleEngine_WithoutCache(b *testing.B) {
 		_, _ = evaluator.Evaluate(ast, span)
 	}
 }
+
+// TestRuleEngine_ConcurrentMatchesRules evaluates regex rules from many
+// goroutines, as concurrent trace completion callbacks do. Run with -race.
+func TestRuleEngine_ConcurrentMatchesRules(t *testing.T) {
+	engine := NewRuleEngine()
+
+	for i := 0; i < 10; i++ {
+		err := engine.LoadRule(models.Rule{
+			ID:         fmt.Sprintf("admin-%d", i),
+			Expression: fmt.Sprintf(`when { http.where(path matches "^/admin/v%d/") } always { auth_check }`, i),
+			Enabled:    true,
+		})
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			spans := []*models.Span{
+				{
+					TraceID:       fmt.Sprintf("trace-%d", g),
+					OperationName: "http",
+					Attributes:    map[string]string{"path": fmt.Sprintf("/admin/v%d/users", g%10)},
+				},
+			}
+			for i := 0; i < 50; i++ {
+				violations, err := engine.EvaluateTrace(context.Background(), spans[0].TraceID, spans)
+				assert.NoError(t, err)
+				assert.Len(t, violations, 1)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestRuleEngine_EvaluationLimits(t *testing.T) {
+	engine := NewRuleEngine()
+	assert.Equal(t, EvaluationLimits{Timeout: DefaultEvaluationTimeout, MaxSteps: DefaultMaxEvaluationSteps}, engine.EvaluationLimits())
+
+	require.NoError(t, engine.LoadRule(models.Rule{
+		ID:         "needs-auth",
+		Name:       "Payments need auth",
+		Expression: `when { payment } always { auth }`,
+		Enabled:    true,
+	}))
+
+	spans := make([]*models.Span, 0, 100)
+	for i := 0; i < 100; i++ {
+		spans = append(spans, &models.Span{TraceID: "trace-1", OperationName: "payment"})
+	}
+
+	t.Run("within budget", func(t *testing.T) {
+		engine.SetEvaluationLimits(EvaluationLimits{MaxSteps: 200})
+
+		results := engine.EvaluateTraceDetailed(context.Background(), "trace-1", spans)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Matched)
+		assert.False(t, results[0].Aborted)
+		assert.NoError(t, results[0].Error)
+	})
+
+	t.Run("step budget exceeded", func(t *testing.T) {
+		engine.SetEvaluationLimits(EvaluationLimits{MaxSteps: 150})
+		before := abortedCount(t, "needs-auth", AbortReasonStepBudget)
+
+		results := engine.EvaluateTraceDetailed(context.Background(), "trace-1", spans)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Matched)
+		assert.True(t, results[0].Aborted)
+		assert.Equal(t, AbortReasonStepBudget, results[0].AbortReason)
+		assert.ErrorIs(t, results[0].Error, dsl.ErrEvaluationAborted)
+		assert.Equal(t, before+1, abortedCount(t, "needs-auth", AbortReasonStepBudget))
+
+		// Aborted rules are not reported as matches
+		matches, err := engine.EvaluateTrace(context.Background(), "trace-1", spans)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		engine.SetEvaluationLimits(EvaluationLimits{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := engine.EvaluateTraceDetailed(ctx, "trace-1", spans)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Aborted)
+		assert.Equal(t, AbortReasonCanceled, results[0].AbortReason)
+	})
+
+	t.Run("explain shares the budget", func(t *testing.T) {
+		// Evaluating costs 200 steps, and explaining another 200
+		engine.SetEvaluationLimits(EvaluationLimits{MaxSteps: 400})
+		explanation, err := engine.ExplainRule(context.Background(), "needs-auth", spans)
+		require.NoError(t, err)
+		assert.True(t, explanation.Violated)
+
+		engine.SetEvaluationLimits(EvaluationLimits{MaxSteps: 399})
+		before := abortedCount(t, "needs-auth", AbortReasonStepBudget)
+		_, err = engine.ExplainRule(context.Background(), "needs-auth", spans)
+		assert.ErrorIs(t, err, dsl.ErrStepBudgetExceeded)
+		assert.Equal(t, before+1, abortedCount(t, "needs-auth", AbortReasonStepBudget))
+
+		_, err = engine.ExplainExpression(context.Background(), `when { payment } always { auth }`, spans)
+		assert.ErrorIs(t, err, dsl.ErrStepBudgetExceeded)
+	})
+}
+
+func TestAbortReason(t *testing.T) {
+	assert.Equal(t, "", AbortReason(nil))
+	assert.Equal(t, "", AbortReason(errors.New("boom")))
+	assert.Equal(t, AbortReasonStepBudget, AbortReason(fmt.Errorf("%w: %w", dsl.ErrEvaluationAborted, dsl.ErrStepBudgetExceeded)))
+	assert.Equal(t, AbortReasonTimeout, AbortReason(fmt.Errorf("%w: %w", dsl.ErrEvaluationAborted, context.DeadlineExceeded)))
+	assert.Equal(t, AbortReasonCanceled, AbortReason(fmt.Errorf("%w: %w", dsl.ErrEvaluationAborted, context.Canceled)))
+}
+
+// abortedCount reads the betrace_rule_evaluation_aborted_total counter
+func abortedCount(t *testing.T, ruleID, reason string) float64 {
+	t.Helper()
+
+	var m dto.Metric
+	require.NoError(t, observability.RuleEvaluationAborted.WithLabelValues(ruleID, reason).Write(&m))
+	return m.GetCounter().GetValue()
+}
@@ -0,0 +1,76 @@
+package rules
+
+import "github.com/betracehq/betrace/backend/pkg/models"
+
+// operationIndex is an inverted index from operation name to the rules whose
+// when clause requires that operation. Rules whose when clause cannot be
+// reduced to a set of required operations are kept in unindexed and are
+// evaluated against every trace.
+//
+// operationIndex is not safe for concurrent use; RuleEngine guards it with mu.
+type operationIndex struct {
+	byOperation map[string]map[string]struct{}
+	unindexed   map[string]struct{}
+}
+
+func newOperationIndex() *operationIndex {
+	return &operationIndex{
+		byOperation: make(map[string]map[string]struct{}),
+		unindexed:   make(map[string]struct{}),
+	}
+}
+
+// add registers a rule under its required operations (nil means unindexed)
+func (idx *operationIndex) add(ruleID string, requiredOps []string) {
+	if len(requiredOps) == 0 {
+		idx.unindexed[ruleID] = struct{}{}
+		return
+	}
+
+	for _, op := range requiredOps {
+		ruleIDs, ok := idx.byOperation[op]
+		if !ok {
+			ruleIDs = make(map[string]struct{})
+			idx.byOperation[op] = ruleIDs
+		}
+		ruleIDs[ruleID] = struct{}{}
+	}
+}
+
+// remove drops a rule previously registered with the given required operations
+func (idx *operationIndex) remove(ruleID string, requiredOps []string) {
+	delete(idx.unindexed, ruleID)
+
+	for _, op := range requiredOps {
+		ruleIDs, ok := idx.byOperation[op]
+		if !ok {
+			continue
+		}
+		delete(ruleIDs, ruleID)
+		if len(ruleIDs) == 0 {
+			delete(idx.byOperation, op)
+		}
+	}
+}
+
+// candidates returns the IDs of rules that could match a trace containing the given spans
+func (idx *operationIndex) candidates(spans []*models.Span) map[string]struct{} {
+	result := make(map[string]struct{}, len(idx.unindexed))
+	for ruleID := range idx.unindexed {
+		result[ruleID] = struct{}{}
+	}
+
+	seen := make(map[string]struct{})
+	for _, span := range spans {
+		if _, ok := seen[span.OperationName]; ok {
+			continue
+		}
+		seen[span.OperationName] = struct{}{}
+
+		for ruleID := range idx.byOperation[span.OperationName] {
+			result[ruleID] = struct{}{}
+		}
+	}
+
+	return result
+}
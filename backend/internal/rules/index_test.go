@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleEngine_OperationIndex(t *testing.T) {
+	engine := NewRuleEngine()
+
+	rules := []models.Rule{
+		{ID: "payment-rule", Expression: `when { payment } always { fraud_check }`, Enabled: true},
+		{ID: "refund-rule", Expression: `when { refund or chargeback } always { audit }`, Enabled: true},
+		{ID: "absence-rule", Expression: `when { not heartbeat } always { alert }`, Enabled: true},
+	}
+	for _, r := range rules {
+		require.NoError(t, engine.LoadRule(r))
+	}
+
+	compiled, ok := engine.GetRule("refund-rule")
+	require.True(t, ok)
+	assert.Equal(t, []string{"chargeback", "refund"}, compiled.RequiredOperations)
+
+	compiled, ok = engine.GetRule("absence-rule")
+	require.True(t, ok)
+	assert.Nil(t, compiled.RequiredOperations)
+
+	candidateIDs := func(names ...string) []string {
+		spans := make([]*models.Span, len(names))
+		for i, name := range names {
+			spans[i] = &models.Span{OperationName: name}
+		}
+		ids := []string{}
+		for _, r := range engine.candidateRules(spans) {
+			ids = append(ids, r.Rule.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"absence-rule"}, candidateIDs("http_request"))
+	assert.ElementsMatch(t, []string{"payment-rule", "absence-rule"}, candidateIDs("payment", "db_query"))
+	assert.ElementsMatch(t, []string{"refund-rule", "absence-rule"}, candidateIDs("chargeback"))
+}
+
+func TestRuleEngine_OperationIndex_ReloadAndUnload(t *testing.T) {
+	engine := NewRuleEngine()
+
+	require.NoError(t, engine.LoadRule(models.Rule{
+		ID: "rule1", Expression: `when { payment } always { fraud_check }`, Enabled: true,
+	}))
+
+	// Replacing the expression must drop the old index entry
+	require.NoError(t, engine.LoadRule(models.Rule{
+		ID: "rule1", Expression: `when { refund } always { audit }`, Enabled: true,
+	}))
+
+	spans := []*models.Span{{OperationName: "payment"}}
+	assert.Empty(t, engine.candidateRules(spans))
+
+	spans = []*models.Span{{OperationName: "refund"}}
+	assert.Len(t, engine.candidateRules(spans), 1)
+
+	engine.UnloadRule("rule1")
+	assert.Empty(t, engine.candidateRules(spans))
+	assert.Empty(t, engine.index.byOperation)
+	assert.Empty(t, engine.index.unindexed)
+}
+
+func TestRuleEngine_EvaluateTrace_SkipsIrrelevantRules(t *testing.T) {
+	engine := NewRuleEngine()
+
+	require.NoError(t, engine.LoadRule(models.Rule{
+		ID: "payment-rule", Expression: `when { payment } always { fraud_check }`, Enabled: true,
+	}))
+	require.NoError(t, engine.LoadRule(models.Rule{
+		ID: "absence-rule", Expression: `when { not fraud_check } never { payment }`, Enabled: true,
+	}))
+
+	ctx := context.Background()
+
+	// Trace without the indexed operation still reaches unindexed rules
+	matches, err := engine.EvaluateTrace(ctx, "trace-1", []*models.Span{{OperationName: "db_query"}})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// Indexed and unindexed rules both match when their operations are present
+	matches, err = engine.EvaluateTrace(ctx, "trace-2", []*models.Span{{OperationName: "payment"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"payment-rule", "absence-rule"}, matches)
+}
+
+// BenchmarkRuleEngine_EvaluateTrace_Indexed measures trace evaluation with many
+// rules where only a handful are relevant to the trace
+func BenchmarkRuleEngine_EvaluateTrace_Indexed(b *testing.B) {
+	engine := NewRuleEngine()
+	for i := 0; i < 5000; i++ {
+		rule := models.Rule{
+			ID:         fmt.Sprintf("rule-%d", i),
+			Expression: fmt.Sprintf(`when { operation_%d.where(amount > 1000) } always { audit_%d }`, i, i),
+			Enabled:    true,
+		}
+		if err := engine.LoadRule(rule); err != nil {
+			b.Fatalf("LoadRule failed: %v", err)
+		}
+	}
+
+	spans := make([]*models.Span, 50)
+	for i := range spans {
+		spans[i] = &models.Span{
+			SpanID:        fmt.Sprintf("span-%d", i),
+			OperationName: fmt.Sprintf("operation_%d", i),
+			Attributes:    map[string]string{"amount": "500"},
+		}
+	}
+
+	ctx := context.Background()
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.EvaluateTrace(ctx, "trace-bench", spans); err != nil {
+			b.Fatalf("EvaluateTrace failed: %v", err)
+		}
+	}
+}
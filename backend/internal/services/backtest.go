@@ -0,0 +1,541 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/dsl"
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/google/uuid"
+)
+
+// Backtest trace sources
+const (
+	BacktestSourceUpload    = "upload"    // OTLP/JSON uploaded with the job
+	BacktestSourceDirectory = "directory" // OTLP/JSON files under the configured trace directory
+	BacktestSourceArchive   = "archive"   // Traces BeTrace archived as it evaluated them
+)
+
+// Backtest job states
+const (
+	BacktestQueued    = "queued"
+	BacktestRunning   = "running"
+	BacktestSucceeded = "succeeded"
+	BacktestFailed    = "failed"
+	BacktestCanceled  = "canceled"
+)
+
+// Backtest defaults
+const (
+	DefaultBacktestConcurrency = 2                  // Jobs running at once
+	DefaultBacktestMaxJobs     = 100                // Finished jobs kept
+	DefaultBacktestMaxPending  = 10                 // Queued and running jobs
+	DefaultBacktestSamples     = 10                 // Trace IDs sampled per outcome
+	MaxBacktestSamples         = 100                // Upper bound on BacktestRequest.Samples
+	DefaultBacktestWindow      = 7 * 24 * time.Hour // Archive period when since is unset
+)
+
+var (
+	// ErrInvalidBacktest is returned for backtests that can't be started
+	ErrInvalidBacktest = errors.New("invalid backtest")
+
+	// ErrBacktestNotFound is returned for unknown (or expired) job IDs
+	ErrBacktestNotFound = errors.New("backtest not found")
+
+	// ErrBacktestQueueFull is returned when MaxPending jobs are already
+	// queued or running
+	ErrBacktestQueueFull = errors.New("too many backtests queued")
+)
+
+// RuleGetter looks up saved rules
+type RuleGetter interface {
+	Get(id string) (models.Rule, error)
+}
+
+// BacktestRequest describes a backtest: a saved rule or a draft expression,
+// and the traces to run it against
+type BacktestRequest struct {
+	RuleID     string `json:"ruleId,omitempty"`
+	Expression string `json:"expression,omitempty"` // Draft expression, instead of RuleID
+	Source     string `json:"source"`               // BacktestSourceUpload, BacktestSourceDirectory or BacktestSourceArchive
+	Samples    int    `json:"samples,omitempty"`    // Trace IDs kept per outcome, default DefaultBacktestSamples
+
+	// Path is the file or directory of a directory source, relative to the
+	// trace directory. Files ending in .json or .jsonl are read.
+	Path string `json:"path,omitempty"`
+
+	// Since and Until select the traces of an archive source archived in
+	// [Since, Until); default the DefaultBacktestWindow up to now
+	Since time.Time `json:"since,omitempty"`
+	Until time.Time `json:"until,omitempty"`
+
+	// Spans are the decoded spans of an upload source
+	Spans []*models.Span `json:"-"`
+}
+
+// BacktestProgress is how far a backtest has got
+type BacktestProgress struct {
+	FilesTotal int   `json:"filesTotal"` // An upload is one file
+	FilesDone  int   `json:"filesDone"`
+	Traces     int64 `json:"traces"` // Traces evaluated so far
+}
+
+// BacktestResult counts how a rule would have judged the traces. Counts
+// are kept up to date while the job runs.
+type BacktestResult struct {
+	Traces           int64    `json:"traces"`   // Traces evaluated
+	Matched          int64    `json:"matched"`  // Traces the when clause matched
+	Violated         int64    `json:"violated"` // Matched traces that violated the rule
+	Passed           int64    `json:"passed"`   // Matched traces where the rule held
+	Errored          int64    `json:"errored"`  // Evaluation failed or hit the evaluation limits
+	ViolationSamples []string `json:"violationSamples"`
+	PassSamples      []string `json:"passSamples"`
+}
+
+// BacktestJob is the state of one backtest
+type BacktestJob struct {
+	ID         string           `json:"id"`
+	State      string           `json:"state"`
+	RuleID     string           `json:"ruleId,omitempty"`
+	Expression string           `json:"expression"`
+	Source     string           `json:"source"`
+	Path       string           `json:"path,omitempty"`
+	Since      *time.Time       `json:"since,omitempty"`
+	Until      *time.Time       `json:"until,omitempty"`
+	Progress   BacktestProgress `json:"progress"`
+	Result     BacktestResult   `json:"result"`
+	Error      string           `json:"error,omitempty"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	StartedAt  *time.Time       `json:"startedAt,omitempty"`
+	FinishedAt *time.Time       `json:"finishedAt,omitempty"`
+}
+
+// Done reports whether the job has finished, successfully or not
+func (j BacktestJob) Done() bool {
+	return j.State == BacktestSucceeded || j.State == BacktestFailed || j.State == BacktestCanceled
+}
+
+// BacktestOptions configures a BacktestRunner; zero values use the defaults
+type BacktestOptions struct {
+	TraceDir    string        // Root of directory sources; "" disables them
+	Archive     *TraceArchive // nil disables archive sources
+	Limits      dsl.Limits    // Per rule per trace, like live evaluation
+	Concurrency int
+	MaxJobs     int
+	// MaxPending caps queued plus running jobs, so queued uploads can't
+	// hold unbounded decoded spans in memory
+	MaxPending int
+}
+
+// backtest is a job with what it needs to run
+type backtest struct {
+	job     BacktestJob // Guarded by BacktestRunner.mu
+	rule    *dsl.Rule
+	req     BacktestRequest
+	samples int
+	cancel  context.CancelFunc
+}
+
+// BacktestRunner runs backtests in the background: a rule is evaluated
+// offline with dsl.Evaluator against recorded traces, counting how often
+// it would have matched and fired. Jobs are kept in memory; the oldest
+// finished jobs are dropped beyond MaxJobs, and new jobs are refused while
+// MaxPending are unfinished.
+type BacktestRunner struct {
+	rules     RuleGetter
+	evaluator *dsl.Evaluator
+	opts      BacktestOptions
+	slots     chan struct{} // One per running job
+	wg        sync.WaitGroup
+
+	mu    sync.Mutex
+	jobs  map[string]*backtest
+	order []string // Job IDs, oldest first
+}
+
+// NewBacktestRunner creates a runner resolving saved rules with rules
+func NewBacktestRunner(rules RuleGetter, opts BacktestOptions) *BacktestRunner {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBacktestConcurrency
+	}
+	if opts.MaxJobs <= 0 {
+		opts.MaxJobs = DefaultBacktestMaxJobs
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = DefaultBacktestMaxPending
+	}
+	return &BacktestRunner{
+		rules:     rules,
+		evaluator: dsl.NewEvaluator(),
+		opts:      opts,
+		slots:     make(chan struct{}, opts.Concurrency),
+		jobs:      make(map[string]*backtest),
+	}
+}
+
+// Start validates a backtest and queues it, returning the queued job. It
+// fails with ErrBacktestQueueFull while MaxPending jobs are unfinished.
+func (r *BacktestRunner) Start(req BacktestRequest) (BacktestJob, error) {
+	bt, err := r.prepare(req)
+	if err != nil {
+		return BacktestJob{}, err
+	}
+
+	r.mu.Lock()
+	if r.pendingLocked() >= r.opts.MaxPending {
+		r.mu.Unlock()
+		return BacktestJob{}, fmt.Errorf("%w: %d are queued or running", ErrBacktestQueueFull, r.opts.MaxPending)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	bt.cancel = cancel
+	r.jobs[bt.job.ID] = bt
+	r.order = append(r.order, bt.job.ID)
+	r.pruneLocked()
+	job := bt.job
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go r.run(ctx, bt)
+	return job, nil
+}
+
+// prepare validates req and resolves its rule
+func (r *BacktestRunner) prepare(req BacktestRequest) (*backtest, error) {
+	expression := req.Expression
+	switch {
+	case req.RuleID != "" && expression != "":
+		return nil, fmt.Errorf("%w: set ruleId or expression, not both", ErrInvalidBacktest)
+	case req.RuleID != "":
+		if r.rules == nil {
+			return nil, fmt.Errorf("%w: saved rules are unavailable", ErrInvalidBacktest)
+		}
+		rule, err := r.rules.Get(req.RuleID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBacktest, err)
+		}
+		expression = rule.Expression
+	case expression == "":
+		return nil, fmt.Errorf("%w: ruleId or expression is required", ErrInvalidBacktest)
+	}
+	rule, err := dsl.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expression: %v", ErrInvalidBacktest, err)
+	}
+
+	samples := req.Samples
+	switch {
+	case samples < 0 || samples > MaxBacktestSamples:
+		return nil, fmt.Errorf("%w: samples must be between 0 and %d", ErrInvalidBacktest, MaxBacktestSamples)
+	case samples == 0:
+		samples = DefaultBacktestSamples
+	}
+
+	job := BacktestJob{
+		ID:         uuid.New().String(),
+		State:      BacktestQueued,
+		RuleID:     req.RuleID,
+		Expression: expression,
+		Source:     req.Source,
+		CreatedAt:  time.Now().UTC(),
+		Result:     BacktestResult{ViolationSamples: []string{}, PassSamples: []string{}},
+	}
+	switch req.Source {
+	case BacktestSourceUpload:
+		if len(req.Spans) == 0 {
+			return nil, fmt.Errorf("%w: the upload has no spans", ErrInvalidBacktest)
+		}
+	case BacktestSourceDirectory:
+		if r.opts.TraceDir == "" {
+			return nil, fmt.Errorf("%w: directory sources are disabled (backtest.trace_dir)", ErrInvalidBacktest)
+		}
+		req.Path = path.Clean(strings.TrimPrefix(req.Path, "/"))
+		if !fs.ValidPath(req.Path) {
+			return nil, fmt.Errorf("%w: path %q is outside the trace directory", ErrInvalidBacktest, req.Path)
+		}
+		root, err := os.OpenRoot(r.opts.TraceDir)
+		if err != nil {
+			return nil, err
+		}
+		_, err = root.Stat(req.Path)
+		root.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: path %q not found in the trace directory", ErrInvalidBacktest, req.Path)
+		}
+		job.Path = req.Path
+	case BacktestSourceArchive:
+		if r.opts.Archive == nil {
+			return nil, fmt.Errorf("%w: the trace archive is disabled (backtest.archive_traces)", ErrInvalidBacktest)
+		}
+		if req.Until.IsZero() {
+			req.Until = job.CreatedAt
+		}
+		if req.Since.IsZero() {
+			req.Since = req.Until.Add(-DefaultBacktestWindow)
+		}
+		if !req.Since.Before(req.Until) {
+			return nil, fmt.Errorf("%w: since must be before until", ErrInvalidBacktest)
+		}
+		job.Since, job.Until = &req.Since, &req.Until
+	default:
+		return nil, fmt.Errorf("%w: unknown source %q", ErrInvalidBacktest, req.Source)
+	}
+
+	return &backtest{job: job, rule: rule, req: req, samples: samples}, nil
+}
+
+// pendingLocked counts the queued and running jobs
+func (r *BacktestRunner) pendingLocked() int {
+	pending := 0
+	for _, bt := range r.jobs {
+		if !bt.job.Done() {
+			pending++
+		}
+	}
+	return pending
+}
+
+// pruneLocked drops the oldest finished jobs beyond MaxJobs
+func (r *BacktestRunner) pruneLocked() {
+	excess := len(r.order) - r.opts.MaxJobs
+	kept := r.order[:0]
+	for _, id := range r.order {
+		if excess > 0 && r.jobs[id].job.Done() {
+			delete(r.jobs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	r.order = kept
+}
+
+// Get returns a job by ID
+func (r *BacktestRunner) Get(id string) (BacktestJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bt, ok := r.jobs[id]
+	if !ok {
+		return BacktestJob{}, fmt.Errorf("%w: %s", ErrBacktestNotFound, id)
+	}
+	return bt.job.clone(), nil
+}
+
+// List returns every kept job, newest first
+func (r *BacktestRunner) List() []BacktestJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs := make([]BacktestJob, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, r.jobs[r.order[i]].job.clone())
+	}
+	return jobs
+}
+
+// Cancel stops a queued or running job, keeping the counts it reached.
+// Canceling a finished job does nothing.
+func (r *BacktestRunner) Cancel(id string) (BacktestJob, error) {
+	r.mu.Lock()
+	bt, ok := r.jobs[id]
+	r.mu.Unlock()
+	if !ok {
+		return BacktestJob{}, fmt.Errorf("%w: %s", ErrBacktestNotFound, id)
+	}
+	bt.cancel()
+	return r.Get(id)
+}
+
+// Close cancels every job and waits for them to stop
+func (r *BacktestRunner) Close() {
+	r.mu.Lock()
+	for _, bt := range r.jobs {
+		bt.cancel()
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// clone copies a job so callers can't race with the runner's updates
+func (j BacktestJob) clone() BacktestJob {
+	j.Result.ViolationSamples = append([]string{}, j.Result.ViolationSamples...)
+	j.Result.PassSamples = append([]string{}, j.Result.PassSamples...)
+	return j
+}
+
+// run waits for a slot, then evaluates every trace of the job's source
+func (r *BacktestRunner) run(ctx context.Context, bt *backtest) {
+	defer r.wg.Done()
+	defer bt.cancel()
+	defer func() { bt.req.Spans = nil }() // Let an upload be collected
+
+	select {
+	case r.slots <- struct{}{}:
+		defer func() { <-r.slots }()
+	case <-ctx.Done():
+	}
+	if ctx.Err() != nil {
+		r.finish(bt, ctx.Err()) // Canceled while queued
+		return
+	}
+	r.update(bt, func(job *BacktestJob) {
+		now := time.Now().UTC()
+		job.State = BacktestRunning
+		job.StartedAt = &now
+	})
+
+	var err error
+	switch bt.req.Source {
+	case BacktestSourceUpload:
+		r.update(bt, func(job *BacktestJob) { job.Progress.FilesTotal = 1 })
+		err = r.evaluateTraces(ctx, bt, GroupTraces(bt.req.Spans))
+		r.update(bt, func(job *BacktestJob) { job.Progress.FilesDone = 1 })
+	case BacktestSourceDirectory:
+		err = r.runDirectory(ctx, bt)
+	case BacktestSourceArchive:
+		err = r.runArchive(ctx, bt)
+	}
+	r.finish(bt, err)
+}
+
+// runDirectory evaluates the traces of each OTLP/JSON file under the job's
+// path. Traces are grouped per file: a trace split across files is
+// evaluated once per file.
+func (r *BacktestRunner) runDirectory(ctx context.Context, bt *backtest) error {
+	root, err := os.OpenRoot(r.opts.TraceDir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	fsys := root.FS()
+
+	var files []string
+	err = fs.WalkDir(fsys, bt.req.Path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".jsonl")) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.update(bt, func(job *BacktestJob) { job.Progress.FilesTotal = len(files) })
+
+	for _, name := range files {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		spans, err := DecodeOTLPJSON(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := r.evaluateTraces(ctx, bt, GroupTraces(spans)); err != nil {
+			return err
+		}
+		r.update(bt, func(job *BacktestJob) { job.Progress.FilesDone++ })
+	}
+	return nil
+}
+
+// runArchive evaluates the archived traces of the job's period
+func (r *BacktestRunner) runArchive(ctx context.Context, bt *backtest) error {
+	files, err := r.opts.Archive.Files(bt.req.Since, bt.req.Until)
+	if err != nil {
+		return err
+	}
+	r.update(bt, func(job *BacktestJob) { job.Progress.FilesTotal = len(files) })
+
+	for _, name := range files {
+		file, err := os.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Pruned since it was listed
+		}
+		if err != nil {
+			return err
+		}
+		err = ReadArchivedTraces(file, func(trace ArchivedTrace) error {
+			if trace.ArchivedAt.Before(bt.req.Since) || !trace.ArchivedAt.Before(bt.req.Until) {
+				return nil
+			}
+			return r.evaluateTraces(ctx, bt, [][]*models.Span{trace.Spans})
+		})
+		file.Close()
+		if err != nil {
+			return err
+		}
+		r.update(bt, func(job *BacktestJob) { job.Progress.FilesDone++ })
+	}
+	return nil
+}
+
+// evaluateTraces runs the job's rule against each trace, stopping when ctx
+// is done
+func (r *BacktestRunner) evaluateTraces(ctx context.Context, bt *backtest, traces [][]*models.Span) error {
+	for _, spans := range traces {
+		outcome, err := r.evaluator.EvaluateRuleOutcome(ctx, bt.rule, spans, r.opts.Limits)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		traceID := ""
+		if len(spans) > 0 {
+			traceID = spans[0].TraceID
+		}
+
+		r.update(bt, func(job *BacktestJob) {
+			result := &job.Result
+			job.Progress.Traces++
+			result.Traces++
+			switch {
+			case err != nil:
+				result.Errored++
+			case outcome == dsl.OutcomeViolated:
+				result.Matched++
+				result.Violated++
+				if len(result.ViolationSamples) < bt.samples {
+					result.ViolationSamples = append(result.ViolationSamples, traceID)
+				}
+			case outcome == dsl.OutcomePassed:
+				result.Matched++
+				result.Passed++
+				if len(result.PassSamples) < bt.samples {
+					result.PassSamples = append(result.PassSamples, traceID)
+				}
+			}
+		})
+	}
+	return nil
+}
+
+// update changes a job under the runner's lock
+func (r *BacktestRunner) update(bt *backtest, fn func(job *BacktestJob)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&bt.job)
+}
+
+// finish records how a job ended
+func (r *BacktestRunner) finish(bt *backtest, err error) {
+	r.update(bt, func(job *BacktestJob) {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		switch {
+		case errors.Is(err, context.Canceled):
+			job.State = BacktestCanceled
+		case err != nil:
+			job.State = BacktestFailed
+			job.Error = err.Error()
+		default:
+			job.State = BacktestSucceeded
+		}
+	})
+}
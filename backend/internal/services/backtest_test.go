@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// otlpTrace encodes a trace of the given operations as OTLP/JSON
+func otlpTrace(traceID string, ops ...string) string {
+	spans := make([]string, len(ops))
+	for i, op := range ops {
+		spans[i] = fmt.Sprintf(`{"traceId":%q,"spanId":"%s-%d","name":%q,"startTimeUnixNano":"1000","endTimeUnixNano":"3000","attributes":[{"key":"amount","value":{"intValue":"42"}}]}`, traceID, traceID, i, op)
+	}
+	return `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},"scopeSpans":[{"spans":[` + strings.Join(spans, ",") + `]}]}]}`
+}
+
+// savedRules serves saved rules from a map
+type savedRules map[string]models.Rule
+
+func (s savedRules) Get(id string) (models.Rule, error) {
+	rule, ok := s[id]
+	if !ok {
+		return models.Rule{}, fmt.Errorf("rule %s not found", id)
+	}
+	return rule, nil
+}
+
+// waitForBacktest polls a job until it finishes
+func waitForBacktest(t *testing.T, runner *BacktestRunner, id string) BacktestJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := runner.Get(id)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if job.Done() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Backtest %s didn't finish", id)
+	return BacktestJob{}
+}
+
+func TestDecodeOTLPJSON(t *testing.T) {
+	// Two documents, one per line, as the collector's file exporter writes them
+	input := otlpTrace("t1", "payment") + "\n" + otlpTrace("t2", "payment", "auth") + "\n" + otlpTrace("t1", "auth")
+	spans, err := DecodeOTLPJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("DecodeOTLPJSON failed: %v", err)
+	}
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.ServiceName != "checkout" || span.OperationName != "payment" || span.Attributes["amount"] != "42" || span.Duration != 2000 || span.Status != "OK" {
+		t.Errorf("Unexpected span %+v", span)
+	}
+
+	traces := GroupTraces(spans)
+	if len(traces) != 2 || len(traces[0]) != 2 || traces[0][1].OperationName != "auth" || traces[1][0].TraceID != "t2" {
+		t.Errorf("Expected spans grouped by trace in order, got %v", traces)
+	}
+
+	if _, err := DecodeOTLPJSON(strings.NewReader(`{"resourceSpans": [`)); err == nil {
+		t.Error("Expected an error for truncated OTLP/JSON")
+	}
+}
+
+func TestBacktestRunner_UploadCountsMatchesAndViolations(t *testing.T) {
+	spans, err := DecodeOTLPJSON(strings.NewReader(otlpTrace("violates", "payment") + otlpTrace("holds", "payment", "auth") + otlpTrace("unrelated", "login")))
+	if err != nil {
+		t.Fatalf("DecodeOTLPJSON failed: %v", err)
+	}
+	runner := NewBacktestRunner(savedRules{"needs-auth": {ID: "needs-auth", Expression: "when { payment } always { auth }"}}, BacktestOptions{})
+	defer runner.Close()
+
+	job, err := runner.Start(BacktestRequest{RuleID: "needs-auth", Source: BacktestSourceUpload, Spans: spans})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if job.State != BacktestQueued || job.Expression != "when { payment } always { auth }" {
+		t.Errorf("Expected a queued job for the saved rule, got %+v", job)
+	}
+
+	job = waitForBacktest(t, runner, job.ID)
+	want := BacktestResult{Traces: 3, Matched: 2, Violated: 1, Passed: 1, ViolationSamples: []string{"violates"}, PassSamples: []string{"holds"}}
+	if job.State != BacktestSucceeded || fmt.Sprint(job.Result) != fmt.Sprint(want) {
+		t.Errorf("Expected %+v, got %s %+v (%s)", want, job.State, job.Result, job.Error)
+	}
+	if job.Progress != (BacktestProgress{FilesTotal: 1, FilesDone: 1, Traces: 3}) || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Errorf("Expected complete progress and timestamps, got %+v", job)
+	}
+	if jobs := runner.List(); len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("Expected the job listed, got %+v", jobs)
+	}
+}
+
+func TestBacktestRunner_DirectorySource(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "week", "nested"), 0755)
+	os.WriteFile(filepath.Join(dir, "week", "monday.json"), []byte(otlpTrace("a", "payment")), 0644)
+	os.WriteFile(filepath.Join(dir, "week", "nested", "tuesday.jsonl"), []byte(otlpTrace("b", "payment")+"\n"+otlpTrace("c", "payment", "auth")+"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "week", "notes.txt"), []byte("not traces"), 0644)
+	os.WriteFile(filepath.Join(dir, "secret.json"), []byte(otlpTrace("z", "payment")), 0644)
+
+	runner := NewBacktestRunner(nil, BacktestOptions{TraceDir: dir})
+	defer runner.Close()
+
+	job, err := runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceDirectory, Path: "/week", Samples: 1})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	job = waitForBacktest(t, runner, job.ID)
+	if job.State != BacktestSucceeded || job.Path != "week" || job.Result.Traces != 3 || job.Result.Violated != 2 || len(job.Result.ViolationSamples) != 1 {
+		t.Errorf("Expected 2 violations in 3 traces with one sample, got %s %+v (%s)", job.State, job.Result, job.Error)
+	}
+	if job.Progress.FilesTotal != 2 || job.Progress.FilesDone != 2 {
+		t.Errorf("Expected both trace files read, got %+v", job.Progress)
+	}
+
+	for _, path := range []string{"../outside", "week/../../outside", "missing"} {
+		if _, err := runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceDirectory, Path: path}); !errors.Is(err, ErrInvalidBacktest) {
+			t.Errorf("Expected ErrInvalidBacktest for path %q, got %v", path, err)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "week", "broken.json"), []byte("{"), 0644)
+	job, _ = runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceDirectory, Path: "week"})
+	if job = waitForBacktest(t, runner, job.ID); job.State != BacktestFailed || !strings.Contains(job.Error, "broken.json") {
+		t.Errorf("Expected the job to fail on the broken file, got %s (%s)", job.State, job.Error)
+	}
+}
+
+func TestBacktestRunner_ArchiveSource(t *testing.T) {
+	archive, err := NewTraceArchive(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewTraceArchive failed: %v", err)
+	}
+	defer archive.Close()
+	now := time.Now().UTC()
+	trace := func(id string, ops ...string) []*models.Span {
+		spans := make([]*models.Span, len(ops))
+		for i, op := range ops {
+			spans[i] = &models.Span{TraceID: id, SpanID: fmt.Sprintf("%s-%d", id, i), OperationName: op}
+		}
+		return spans
+	}
+	archive.Record("old", trace("old", "payment"), now.Add(-10*24*time.Hour))
+	archive.Record("yesterday", trace("yesterday", "payment"), now.Add(-24*time.Hour))
+	archive.Record("today", trace("today", "payment", "auth"), now)
+
+	runner := NewBacktestRunner(nil, BacktestOptions{Archive: archive})
+	defer runner.Close()
+
+	// The default period is the last week
+	job, err := runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceArchive})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	job = waitForBacktest(t, runner, job.ID)
+	if job.State != BacktestSucceeded || job.Result.Traces != 2 || job.Result.Violated != 1 || job.Result.ViolationSamples[0] != "yesterday" {
+		t.Errorf("Expected last week's 2 traces with 1 violation, got %s %+v (%s)", job.State, job.Result, job.Error)
+	}
+
+	job, _ = runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceArchive, Since: now.Add(-time.Hour), Until: now.Add(time.Hour)})
+	if job = waitForBacktest(t, runner, job.ID); job.Result.Traces != 1 || job.Result.Passed != 1 {
+		t.Errorf("Expected only today's trace, got %+v", job.Result)
+	}
+
+	if _, err := runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceArchive, Since: now, Until: now}); !errors.Is(err, ErrInvalidBacktest) {
+		t.Errorf("Expected ErrInvalidBacktest for an empty period, got %v", err)
+	}
+}
+
+func TestBacktestRunner_CancelQueuedAndRunning(t *testing.T) {
+	spans := []*models.Span{{TraceID: "t1", OperationName: "payment"}, {TraceID: "t2", OperationName: "payment"}}
+	runner := NewBacktestRunner(nil, BacktestOptions{Concurrency: 1})
+	defer runner.Close()
+
+	// Hold the only slot, so the job stays queued
+	runner.slots <- struct{}{}
+	queued, err := runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceUpload, Spans: spans})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := runner.Cancel(queued.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	job := waitForBacktest(t, runner, queued.ID)
+	<-runner.slots
+	if job.State != BacktestCanceled || job.StartedAt != nil || job.Result.Traces != 0 {
+		t.Errorf("Expected the queued job canceled before it started, got %+v", job)
+	}
+
+	// A running job stops at the next trace
+	bt, err := runner.prepare(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceUpload, Spans: spans})
+	if err != nil {
+		t.Fatalf("prepare failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := runner.evaluateTraces(ctx, bt, GroupTraces(spans)); !errors.Is(err, context.Canceled) || bt.job.Result.Traces != 0 {
+		t.Errorf("Expected evaluation to stop, got %v after %d traces", err, bt.job.Result.Traces)
+	}
+
+	if _, err := runner.Cancel("missing"); !errors.Is(err, ErrBacktestNotFound) {
+		t.Errorf("Expected ErrBacktestNotFound, got %v", err)
+	}
+}
+
+func TestBacktestRunner_RejectsInvalidRequests(t *testing.T) {
+	runner := NewBacktestRunner(savedRules{}, BacktestOptions{})
+	defer runner.Close()
+	spans := []*models.Span{{TraceID: "t", OperationName: "payment"}}
+
+	for name, req := range map[string]BacktestRequest{
+		"no rule":             {Source: BacktestSourceUpload, Spans: spans},
+		"rule and expression": {RuleID: "r", Expression: "when { a } always { b }", Source: BacktestSourceUpload, Spans: spans},
+		"unknown rule":        {RuleID: "missing", Source: BacktestSourceUpload, Spans: spans},
+		"invalid expression":  {Expression: "when {", Source: BacktestSourceUpload, Spans: spans},
+		"empty upload":        {Expression: "when { a } always { b }", Source: BacktestSourceUpload},
+		"unknown source":      {Expression: "when { a } always { b }", Source: "tempo"},
+		"directory disabled":  {Expression: "when { a } always { b }", Source: BacktestSourceDirectory, Path: "traces"},
+		"archive disabled":    {Expression: "when { a } always { b }", Source: BacktestSourceArchive},
+		"too many samples":    {Expression: "when { a } always { b }", Source: BacktestSourceUpload, Spans: spans, Samples: MaxBacktestSamples + 1},
+	} {
+		if _, err := runner.Start(req); !errors.Is(err, ErrInvalidBacktest) {
+			t.Errorf("%s: expected ErrInvalidBacktest, got %v", name, err)
+		}
+	}
+	if jobs := runner.List(); len(jobs) != 0 {
+		t.Errorf("Expected no jobs, got %d", len(jobs))
+	}
+}
+
+func TestBacktestRunner_DropsOldestFinishedJobs(t *testing.T) {
+	runner := NewBacktestRunner(nil, BacktestOptions{MaxJobs: 2})
+	defer runner.Close()
+	spans := []*models.Span{{TraceID: "t", OperationName: "payment"}}
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job, err := runner.Start(BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceUpload, Spans: spans})
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		waitForBacktest(t, runner, job.ID)
+		ids = append(ids, job.ID)
+	}
+	if _, err := runner.Get(ids[0]); !errors.Is(err, ErrBacktestNotFound) {
+		t.Errorf("Expected the oldest job dropped, got %v", err)
+	}
+	if jobs := runner.List(); len(jobs) != 2 || jobs[0].ID != ids[2] {
+		t.Errorf("Expected the 2 newest jobs, newest first, got %+v", jobs)
+	}
+}
+
+func TestBacktestRunner_CapsPendingJobs(t *testing.T) {
+	runner := NewBacktestRunner(nil, BacktestOptions{Concurrency: 1, MaxPending: 2})
+	defer runner.Close()
+	req := BacktestRequest{Expression: "when { payment } always { auth }", Source: BacktestSourceUpload, Spans: []*models.Span{{TraceID: "t", OperationName: "payment"}}}
+
+	// Hold the only slot, so jobs stay queued
+	runner.slots <- struct{}{}
+	var ids []string
+	for i := 0; i < 2; i++ {
+		job, err := runner.Start(req)
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+	if _, err := runner.Start(req); !errors.Is(err, ErrBacktestQueueFull) {
+		t.Errorf("Expected ErrBacktestQueueFull, got %v", err)
+	}
+
+	// A finished job frees its place
+	runner.Cancel(ids[0])
+	waitForBacktest(t, runner, ids[0])
+	if _, err := runner.Start(req); err != nil {
+		t.Errorf("Expected a job to start once one finished, got %v", err)
+	}
+	<-runner.slots
+}
+
+func TestTraceArchive_PrunesExpiredDays(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := NewTraceArchive(dir, 48*time.Hour)
+	if err != nil {
+		t.Fatalf("NewTraceArchive failed: %v", err)
+	}
+	defer archive.Close()
+	now := time.Now().UTC()
+	spans := []*models.Span{{TraceID: "t", OperationName: "payment"}}
+
+	archive.Record("expired", spans, now.Add(-5*24*time.Hour))
+	archive.Record("kept", spans, now.Add(-24*time.Hour))
+	archive.Record("today", spans, now)
+
+	files, err := archive.Files(now.Add(-30*24*time.Hour), now)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected the expired day pruned, got %v (%v)", files, err)
+	}
+
+	file, _ := os.Open(files[1])
+	defer file.Close()
+	var got []string
+	err = ReadArchivedTraces(file, func(trace ArchivedTrace) error {
+		got = append(got, trace.TraceID)
+		return nil
+	})
+	if err != nil || len(got) != 1 || got[0] != "today" {
+		t.Errorf("Expected today's trace, got %v (%v)", got, err)
+	}
+
+	// A line still being written is skipped
+	partial := `{"traceId":"complete","spans":[]}` + "\n" + `{"traceId":"part`
+	got = nil
+	ReadArchivedTraces(strings.NewReader(partial), func(trace ArchivedTrace) error {
+		got = append(got, trace.TraceID)
+		return nil
+	})
+	if len(got) != 1 || got[0] != "complete" {
+		t.Errorf("Expected only the complete line, got %v", got)
+	}
+}
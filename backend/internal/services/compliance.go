@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/observability"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// DefaultCompliancePeriod is the reporting period when a query sets no start
+const DefaultCompliancePeriod = 30 * 24 * time.Hour
+
+// maxControlViolationSamples is how many recent violation IDs a rule's evidence lists
+const maxControlViolationSamples = 10
+
+// maxPassExemplars is how many passing trace IDs a rule's evidence lists
+const maxPassExemplars = 10
+
+// ErrInvalidComplianceQuery is returned for malformed compliance queries
+var ErrInvalidComplianceQuery = errors.New("invalid compliance query")
+
+// NormalizeControls validates a rule's control mappings: frameworks must be
+// supported, and IDs take their catalog spelling. Duplicates are dropped.
+func NormalizeControls(controls []models.ComplianceControl) ([]models.ComplianceControl, error) {
+	var normalized []models.ComplianceControl
+	seen := make(map[models.ComplianceControl]bool)
+	for _, mapping := range controls {
+		framework, ok := observability.ParseComplianceFramework(mapping.Framework)
+		if !ok {
+			return nil, fmt.Errorf("unknown compliance framework %q", mapping.Framework)
+		}
+		control := observability.LookupControl(framework, strings.TrimSpace(mapping.ControlID))
+		mapping = models.ComplianceControl{Framework: string(control.Framework), ControlID: control.ControlID}
+		if !seen[mapping] {
+			seen[mapping] = true
+			normalized = append(normalized, mapping)
+		}
+	}
+	return normalized, nil
+}
+
+// RuleLister lists the rules compliance reports cover (see storage.DiskRuleStore)
+type RuleLister interface {
+	List() ([]models.Rule, error)
+}
+
+// ComplianceQuery selects the controls and period of a compliance report
+type ComplianceQuery struct {
+	Framework string // Empty = every framework
+	Control   string // Control ID within Framework; empty = every control
+	Since     time.Time
+	Until     time.Time
+}
+
+// normalize validates the query and fills in the default period
+func (q ComplianceQuery) normalize(now time.Time) (ComplianceQuery, error) {
+	if q.Framework != "" {
+		framework, ok := observability.ParseComplianceFramework(q.Framework)
+		if !ok {
+			return q, fmt.Errorf("%w: unknown framework %q", ErrInvalidComplianceQuery, q.Framework)
+		}
+		q.Framework = string(framework)
+	} else if q.Control != "" {
+		return q, fmt.Errorf("%w: control requires a framework", ErrInvalidComplianceQuery)
+	}
+	if q.Until.IsZero() {
+		q.Until = now
+	}
+	if q.Since.IsZero() {
+		q.Since = q.Until.Add(-DefaultCompliancePeriod)
+	}
+	if !q.Since.Before(q.Until) {
+		return q, fmt.Errorf("%w: since must be before until", ErrInvalidComplianceQuery)
+	}
+	q.Since, q.Until = q.Since.UTC(), q.Until.UTC()
+	return q, nil
+}
+
+// matches reports whether control is selected by the query
+func (q ComplianceQuery) matches(control observability.ComplianceControl) bool {
+	if q.Framework != "" && string(control.Framework) != q.Framework {
+		return false
+	}
+	return q.Control == "" || strings.EqualFold(control.ControlID, q.Control)
+}
+
+// ComplianceReport is the evidence for a set of controls over a period
+type ComplianceReport struct {
+	Framework   string            `json:"framework,omitempty"`
+	Control     string            `json:"control,omitempty"`
+	Since       time.Time         `json:"since"`
+	Until       time.Time         `json:"until"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+	CountsSince time.Time         `json:"countsSince"` // Evaluations before this time weren't counted
+	Controls    []ControlEvidence `json:"controls"`
+}
+
+// ControlEvidence is the evidence for one control: the rules monitoring it
+// and their totals
+type ControlEvidence struct {
+	Framework string `json:"framework"`
+	ControlID string `json:"controlId"`
+	EvaluationCounts
+	Violations int            `json:"violations"`
+	Rules      []RuleEvidence `json:"rules"`
+}
+
+// RuleEvidence is one rule's evaluations and violations in the period
+type RuleEvidence struct {
+	RuleID           string `json:"ruleId"`
+	RuleName         string `json:"ruleName"`
+	ExpressionDigest string `json:"expressionDigest"` // See ExpressionDigest
+	Severity         string `json:"severity"`
+	Enabled          bool   `json:"enabled"`
+	EvaluationCounts
+	Violations    int                  `json:"violations"`
+	ViolationIDs  []string             `json:"violationIds,omitempty"`  // Most recent first, up to 10
+	Services      []ServiceEvaluations `json:"services,omitempty"`      // Evaluations per service, by name
+	PassExemplars []string             `json:"passExemplars,omitempty"` // Sampled passing trace IDs, most recent hours first, up to 10
+}
+
+// ServiceEvaluations is a rule's evaluations of one service's traces
+type ServiceEvaluations struct {
+	Service string `json:"service"`
+	EvaluationCounts
+}
+
+// ComplianceReporter builds compliance evidence from BeTrace's own data:
+// rules mapped to controls, their evaluation counts and the violations they
+// recorded
+type ComplianceReporter struct {
+	rules      RuleLister
+	violations ViolationStore
+	counter    *EvaluationCounter // nil reports no evaluation counts
+	keys       *Keyring           // nil leaves export bundles unsigned
+}
+
+// NewComplianceReporter creates a reporter over rules and violations
+func NewComplianceReporter(rules RuleLister, violations ViolationStore, counter *EvaluationCounter, keys *Keyring) *ComplianceReporter {
+	return &ComplianceReporter{rules: rules, violations: violations, counter: counter, keys: keys}
+}
+
+// Evidence reports the controls selected by q, ordered by framework and
+// control ID, with the rules mapped to each
+func (r *ComplianceReporter) Evidence(ctx context.Context, q ComplianceQuery) (ComplianceReport, error) {
+	q, err := q.normalize(time.Now())
+	if err != nil {
+		return ComplianceReport{}, err
+	}
+	report := ComplianceReport{
+		Framework:   q.Framework,
+		Control:     q.Control,
+		Since:       q.Since,
+		Until:       q.Until,
+		GeneratedAt: time.Now().UTC(),
+		Controls:    []ControlEvidence{},
+	}
+	if r.counter != nil {
+		report.CountsSince = r.counter.Since()
+	}
+
+	mapped, err := r.mappedControls(q)
+	if err != nil {
+		return ComplianceReport{}, err
+	}
+
+	for _, control := range mapped {
+		evidence := ControlEvidence{Framework: string(control.control.Framework), ControlID: control.control.ControlID}
+		for _, rule := range control.rules {
+			ruleEvidence, err := r.ruleEvidence(ctx, rule, q)
+			if err != nil {
+				return ComplianceReport{}, err
+			}
+			evidence.EvaluationCounts.Add(ruleEvidence.EvaluationCounts)
+			evidence.Violations += ruleEvidence.Violations
+			evidence.Rules = append(evidence.Rules, ruleEvidence)
+		}
+		report.Controls = append(report.Controls, evidence)
+	}
+	return report, nil
+}
+
+// controlRules pairs a control with the rules mapped to it
+type controlRules struct {
+	control observability.ComplianceControl
+	rules   []models.Rule
+}
+
+// mappedControls groups the rules mapped to controls selected by q
+func (r *ComplianceReporter) mappedControls(q ComplianceQuery) ([]controlRules, error) {
+	rules, err := r.rules.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	byControl := make(map[observability.ComplianceControl][]models.Rule)
+	for _, rule := range rules {
+		for _, control := range observability.RuleControls(rule) {
+			if q.matches(control) {
+				byControl[control] = append(byControl[control], rule)
+			}
+		}
+	}
+
+	result := make([]controlRules, 0, len(byControl))
+	for control, rules := range byControl {
+		result = append(result, controlRules{control: control, rules: rules})
+	}
+	sort.Slice(result, func(i, j int) bool { return controlLess(result[i].control, result[j].control) })
+	return result, nil
+}
+
+// controlLess orders controls by framework, then control ID
+func controlLess(a, b observability.ComplianceControl) bool {
+	if a.Framework != b.Framework {
+		return a.Framework < b.Framework
+	}
+	return a.ControlID < b.ControlID
+}
+
+// ruleEvidence counts a rule's evaluations and violations in the period
+func (r *ComplianceReporter) ruleEvidence(ctx context.Context, rule models.Rule, q ComplianceQuery) (RuleEvidence, error) {
+	evidence := RuleEvidence{
+		RuleID:           rule.ID,
+		RuleName:         rule.Name,
+		ExpressionDigest: ExpressionDigest(rule.Expression),
+		Severity:         rule.Severity,
+		Enabled:          rule.Enabled,
+	}
+	if r.counter != nil {
+		r.addEvaluations(&evidence, r.counter.Buckets(EvaluationFilter{RuleID: rule.ID, Since: q.Since, Until: q.Until}))
+	}
+
+	page, err := r.violations.QueryPage(ctx, QueryFilters{
+		RuleID: rule.ID,
+		Since:  q.Since,
+		Until:  q.Until,
+		Limit:  maxControlViolationSamples,
+	})
+	if err != nil {
+		return RuleEvidence{}, fmt.Errorf("failed to query violations of rule %s: %w", rule.ID, err)
+	}
+	evidence.Violations = page.TotalCount
+	for _, v := range page.Violations {
+		evidence.ViolationIDs = append(evidence.ViolationIDs, v.ID)
+	}
+	return evidence, nil
+}
+
+// addEvaluations totals a rule's evaluation buckets (oldest first) into its
+// evidence, per service, and samples its pass exemplars from the newest
+func (r *ComplianceReporter) addEvaluations(evidence *RuleEvidence, buckets []EvaluationBucket) {
+	byService := make(map[string]*ServiceEvaluations)
+	var services []string
+	for _, b := range buckets {
+		evidence.EvaluationCounts.Add(b.EvaluationCounts)
+		service, ok := byService[b.Service]
+		if !ok {
+			service = &ServiceEvaluations{Service: b.Service}
+			byService[b.Service] = service
+			services = append(services, b.Service)
+		}
+		service.Add(b.EvaluationCounts)
+	}
+	sort.Strings(services)
+	for _, name := range services {
+		evidence.Services = append(evidence.Services, *byService[name])
+	}
+
+	for i := len(buckets) - 1; i >= 0 && len(evidence.PassExemplars) < maxPassExemplars; i-- {
+		for _, traceID := range buckets[i].PassExemplars {
+			if len(evidence.PassExemplars) == maxPassExemplars {
+				break
+			}
+			evidence.PassExemplars = append(evidence.PassExemplars, traceID)
+		}
+	}
+}
+
+// EvaluationQuery selects hourly evaluation buckets: those of rules mapped
+// to the controls and within the period of the ComplianceQuery, optionally
+// narrowed to one rule and service
+type EvaluationQuery struct {
+	ComplianceQuery
+	RuleID  string
+	Service string
+}
+
+// EvaluationReport is the hourly evaluation buckets selected by an
+// EvaluationQuery and their totals
+type EvaluationReport struct {
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	CountsSince time.Time `json:"countsSince"` // Evaluations before this time weren't counted
+	EvaluationCounts
+	Buckets []EvaluationBucket `json:"buckets"` // By start, rule ID and service
+}
+
+// Evaluations reports the hourly evaluation buckets selected by q. Without
+// a framework, rules not mapped to any control are included too.
+func (r *ComplianceReporter) Evaluations(ctx context.Context, q EvaluationQuery) (EvaluationReport, error) {
+	normalized, err := q.normalize(time.Now())
+	if err != nil {
+		return EvaluationReport{}, err
+	}
+	report := EvaluationReport{Since: normalized.Since, Until: normalized.Until, Buckets: []EvaluationBucket{}}
+	if r.counter == nil {
+		return report, nil
+	}
+	report.CountsSince = r.counter.Since()
+
+	var ruleIDs map[string]bool // nil selects every rule
+	if normalized.Framework != "" {
+		mapped, err := r.mappedControls(normalized)
+		if err != nil {
+			return EvaluationReport{}, err
+		}
+		ruleIDs = make(map[string]bool)
+		for _, control := range mapped {
+			for _, rule := range control.rules {
+				ruleIDs[rule.ID] = true
+			}
+		}
+	}
+
+	for _, b := range r.counter.Buckets(EvaluationFilter{RuleID: q.RuleID, Service: q.Service, Since: report.Since, Until: report.Until}) {
+		if ruleIDs == nil || ruleIDs[b.RuleID] {
+			report.EvaluationCounts.Add(b.EvaluationCounts)
+			report.Buckets = append(report.Buckets, b)
+		}
+	}
+	return report, nil
+}
+
+// ControlCoverage reports which rules monitor a control
+type ControlCoverage struct {
+	Framework    string   `json:"framework"`
+	ControlID    string   `json:"controlId,omitempty"` // Empty for rules mapped to the whole framework
+	Name         string   `json:"name,omitempty"`      // Catalog name of the control
+	RuleIDs      []string `json:"ruleIds"`
+	EnabledRules int      `json:"enabledRules"` // Enabled, enforcing rules
+}
+
+// Covered reports whether an enabled, enforcing rule monitors the control
+// (shadow rules raise no violations, so they don't)
+func (c ControlCoverage) Covered() bool {
+	return c.EnabledRules > 0
+}
+
+// Coverage lists every catalog control of framework (empty = every
+// framework) and every control rules are mapped to, with the rules
+// monitoring each, ordered by framework and control ID
+func Coverage(rules []models.Rule, framework string) ([]ControlCoverage, error) {
+	q, err := ComplianceQuery{Framework: framework}.normalize(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	byControl := make(map[observability.ComplianceControl]*ControlCoverage)
+	var controls []observability.ComplianceControl
+	entry := func(control observability.ComplianceControl) *ControlCoverage {
+		c, ok := byControl[control]
+		if !ok {
+			c = &ControlCoverage{Framework: string(control.Framework), ControlID: control.ControlID, Name: control.Notes, RuleIDs: []string{}}
+			byControl[control] = c
+			controls = append(controls, control)
+		}
+		return c
+	}
+	for _, control := range observability.ComplianceControls {
+		if q.matches(control) {
+			entry(control)
+		}
+	}
+	for _, rule := range rules {
+		for _, control := range observability.RuleControls(rule) {
+			if !q.matches(control) {
+				continue
+			}
+			c := entry(control)
+			c.RuleIDs = append(c.RuleIDs, rule.ID)
+			if rule.Enabled && !rule.IsShadow() {
+				c.EnabledRules++
+			}
+		}
+	}
+
+	sort.Slice(controls, func(i, j int) bool { return controlLess(controls[i], controls[j]) })
+	coverage := make([]ControlCoverage, len(controls))
+	for i, control := range controls {
+		coverage[i] = *byControl[control]
+		sort.Strings(coverage[i].RuleIDs)
+	}
+	return coverage, nil
+}
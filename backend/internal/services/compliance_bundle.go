@@ -0,0 +1,294 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// Files of a compliance bundle
+const (
+	BundleEvidenceFile   = "evidence.json"   // ComplianceReport
+	BundleViolationsFile = "violations.json" // []BundleViolation, oldest first
+	BundleManifestFile   = "manifest.csv"    // One row per violation with its signature
+	BundleKeysFile       = "jwks.json"       // Public keys at export time
+	BundleSignatureFile  = "signature.json"  // BundleSignature over the other files
+)
+
+// MaxComplianceExport bounds the violations in one compliance bundle
+const MaxComplianceExport = 10000
+
+// ErrInvalidBundle is returned when a compliance bundle fails verification
+var ErrInvalidBundle = errors.New("invalid compliance bundle")
+
+// bundleManifestHeader is the first row of manifest.csv
+var bundleManifestHeader = []string{"violation_id", "rule_id", "controls", "severity", "created_at", "payload_sha256", "algorithm", "key_id", "signature"}
+
+// BundleViolation is a violation with the payload its signature covers
+type BundleViolation struct {
+	Violation     models.Violation `json:"violation"`
+	SignedPayload []byte           `json:"signedPayload,omitempty"` // CanonicalViolation (base64); EdDSA only
+}
+
+// BundleSignature commits to the SHA-256 of every other file in a bundle
+type BundleSignature struct {
+	Files     map[string]string `json:"files"` // File name -> hex SHA-256
+	CreatedAt time.Time         `json:"createdAt"`
+	KeyID     string            `json:"keyId,omitempty"`     // JWKS kid; empty if signing is disabled
+	Signature string            `json:"signature,omitempty"` // base64url Ed25519 over BundleSignaturePayload
+}
+
+// BundleSignaturePayload returns the bytes a bundle signature covers: a
+// version line, the creation time, then "<sha256>  <name>" per file sorted
+// by name (the sha256sum format)
+func BundleSignaturePayload(s BundleSignature) []byte {
+	names := make([]string, 0, len(s.Files))
+	for name := range s.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "betrace.compliance.bundle.v1\n%s\n", s.CreatedAt.UTC().Format(time.RFC3339Nano))
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", s.Files[name], name)
+	}
+	return []byte(b.String())
+}
+
+// ComplianceBundle is a self-contained compliance export: the evidence
+// report, every violation of the reported rules in the period with its
+// signature, and the keys to check them. Auditors verify it offline with
+// VerifyComplianceBundle, without access to the tracing backend.
+type ComplianceBundle struct {
+	Files map[string][]byte
+}
+
+// Export builds the compliance bundle for q. It fails with
+// ErrInvalidComplianceQuery if the period holds more than
+// MaxComplianceExport violations.
+func (r *ComplianceReporter) Export(ctx context.Context, q ComplianceQuery) (*ComplianceBundle, error) {
+	report, err := r.Evidence(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each rule once, with every control it's evidence for
+	ruleControls := make(map[string][]string)
+	var ruleIDs []string
+	for _, control := range report.Controls {
+		for _, rule := range control.Rules {
+			if _, ok := ruleControls[rule.RuleID]; !ok {
+				ruleIDs = append(ruleIDs, rule.RuleID)
+			}
+			ruleControls[rule.RuleID] = append(ruleControls[rule.RuleID], control.Framework+":"+control.ControlID)
+		}
+	}
+	sort.Strings(ruleIDs)
+
+	var violations []BundleViolation
+	for _, ruleID := range ruleIDs {
+		found, err := r.violations.Query(ctx, QueryFilters{
+			RuleID: ruleID,
+			Since:  report.Since,
+			Until:  report.Until,
+			Order:  OrderOldestFirst,
+			Limit:  MaxComplianceExport - len(violations) + 1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query violations of rule %s: %w", ruleID, err)
+		}
+		if len(violations)+len(found) > MaxComplianceExport {
+			return nil, fmt.Errorf("%w: more than %d violations in the period; narrow it", ErrInvalidComplianceQuery, MaxComplianceExport)
+		}
+		for _, v := range found {
+			bv := BundleViolation{Violation: v}
+			if SignatureAlgorithm(v) == SignatureAlgEdDSA {
+				bv.SignedPayload = CanonicalViolation(v)
+			}
+			violations = append(violations, bv)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Violation.CreatedAt.Before(violations[j].Violation.CreatedAt)
+	})
+
+	bundle := &ComplianceBundle{Files: make(map[string][]byte)}
+	keys := JWKS{Keys: []JWK{}}
+	if r.keys != nil {
+		keys = r.keys.JWKS()
+	}
+	for name, v := range map[string]interface{}{
+		BundleEvidenceFile:   report,
+		BundleViolationsFile: violations,
+		BundleKeysFile:       keys,
+	} {
+		if bundle.Files[name], err = json.MarshalIndent(v, "", "  "); err != nil {
+			return nil, err
+		}
+	}
+	if bundle.Files[BundleManifestFile], err = bundleManifest(violations, ruleControls); err != nil {
+		return nil, err
+	}
+
+	signature := BundleSignature{Files: make(map[string]string), CreatedAt: report.GeneratedAt}
+	for name, data := range bundle.Files {
+		signature.Files[name] = sha256Hex(data)
+	}
+	if r.keys != nil {
+		signature.KeyID, signature.Signature = r.keys.sign(BundleSignaturePayload(signature))
+	}
+	if bundle.Files[BundleSignatureFile], err = json.MarshalIndent(signature, "", "  "); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// bundleManifest renders manifest.csv
+func bundleManifest(violations []BundleViolation, ruleControls map[string][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(bundleManifestHeader)
+	for _, bv := range violations {
+		v := bv.Violation
+		w.Write([]string{
+			v.ID,
+			v.RuleID,
+			strings.Join(ruleControls[v.RuleID], ";"),
+			v.Severity,
+			v.CreatedAt.UTC().Format(time.RFC3339Nano),
+			sha256Hex(CanonicalViolation(v)),
+			SignatureAlgorithm(v),
+			v.SignatureKeyID,
+			v.Signature,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// WriteZip writes the bundle as a zip archive, signature last
+func (b *ComplianceBundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, name := range []string{BundleEvidenceFile, BundleViolationsFile, BundleManifestFile, BundleKeysFile, BundleSignatureFile} {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(b.Files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ReadComplianceBundle reads a bundle written by WriteZip
+func ReadComplianceBundle(r io.ReaderAt, size int64) (*ComplianceBundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	bundle := &ComplianceBundle{Files: make(map[string][]byte)}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+		}
+		bundle.Files[f.Name] = data
+	}
+	return bundle, nil
+}
+
+// BundleSummary is the result of verifying a compliance bundle
+type BundleSummary struct {
+	Signature  BundleSignature
+	Violations int // Violations whose Ed25519 signatures verified
+	// Unverifiable lists violations that can't be checked offline: unsigned,
+	// or signed without a key ID
+	Unverifiable []string
+}
+
+// VerifyComplianceBundle checks a bundle offline against keys (the JWKS
+// published by the server): the bundle signature, every file's hash, each
+// violation's signature and the manifest rows
+func VerifyComplianceBundle(b *ComplianceBundle, keys JWKS) (BundleSummary, error) {
+	var summary BundleSummary
+	if err := json.Unmarshal(b.Files[BundleSignatureFile], &summary.Signature); err != nil {
+		return summary, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, BundleSignatureFile, err)
+	}
+	sig := summary.Signature
+	if sig.KeyID == "" {
+		return summary, fmt.Errorf("%w: bundle is not signed", ErrInvalidBundle)
+	}
+	if err := keys.Verify(sig.KeyID, BundleSignaturePayload(sig), sig.Signature); err != nil {
+		return summary, fmt.Errorf("%w: bundle %v", ErrInvalidBundle, err)
+	}
+	for name, data := range b.Files {
+		if name == BundleSignatureFile {
+			continue
+		}
+		if hash, ok := sig.Files[name]; !ok || hash != sha256Hex(data) {
+			return summary, fmt.Errorf("%w: %s doesn't match the signed hash", ErrInvalidBundle, name)
+		}
+	}
+	for name := range sig.Files {
+		if _, ok := b.Files[name]; !ok {
+			return summary, fmt.Errorf("%w: %s is missing", ErrInvalidBundle, name)
+		}
+	}
+
+	var violations []BundleViolation
+	if err := json.Unmarshal(b.Files[BundleViolationsFile], &violations); err != nil {
+		return summary, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, BundleViolationsFile, err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(b.Files[BundleManifestFile])).ReadAll()
+	if err != nil {
+		return summary, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, BundleManifestFile, err)
+	}
+	if len(rows) != len(violations)+1 {
+		return summary, fmt.Errorf("%w: %s has %d rows for %d violations", ErrInvalidBundle, BundleManifestFile, len(rows)-1, len(violations))
+	}
+
+	for i, bv := range violations {
+		v := bv.Violation
+		payload := CanonicalViolation(v)
+		row := rows[i+1]
+		if row[0] != v.ID || row[5] != sha256Hex(payload) || row[8] != v.Signature {
+			return summary, fmt.Errorf("%w: manifest row %d doesn't match violation %s", ErrInvalidBundle, i+1, v.ID)
+		}
+		if SignatureAlgorithm(v) != SignatureAlgEdDSA {
+			summary.Unverifiable = append(summary.Unverifiable, v.ID)
+			continue
+		}
+		if !bytes.Equal(bv.SignedPayload, payload) {
+			return summary, fmt.Errorf("%w: violation %s doesn't match its signed payload", ErrInvalidBundle, v.ID)
+		}
+		if err := keys.Verify(v.SignatureKeyID, payload, v.Signature); err != nil {
+			return summary, fmt.Errorf("%w: violation %s: %v", ErrInvalidBundle, v.ID, err)
+		}
+		summary.Violations++
+	}
+	return summary, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// staticRules lists a fixed set of rules
+type staticRules []models.Rule
+
+func (r staticRules) List() ([]models.Rule, error) { return r, nil }
+
+func TestNormalizeControls(t *testing.T) {
+	got, err := NormalizeControls([]models.ComplianceControl{
+		{Framework: "SOC 2", ControlID: "cc6.1"},
+		{Framework: "soc2", ControlID: "CC6.1"},
+		{Framework: "HIPAA", ControlID: " 164.312(b) "},
+		{Framework: "FedRAMP", ControlID: "SC-99"},
+		{Framework: "gdpr"},
+	})
+	if err != nil {
+		t.Fatalf("NormalizeControls failed: %v", err)
+	}
+	want := []models.ComplianceControl{
+		{Framework: "soc2", ControlID: "CC6.1"},
+		{Framework: "hipaa", ControlID: "164.312(b)"},
+		{Framework: "fedramp", ControlID: "SC-99"},
+		{Framework: "gdpr"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := NormalizeControls([]models.ComplianceControl{{Framework: "pci", ControlID: "3.4"}}); err == nil {
+		t.Error("Expected an unknown framework to be rejected")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	rules := []models.Rule{
+		{ID: "mfa", Enabled: true, Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}}},
+		{ID: "audit", Enabled: false, Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}, {Framework: "soc2", ControlID: "CC7.1"}}},
+		{ID: "custom", Enabled: true, Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC9.9"}, {Framework: "hipaa", ControlID: "164.312(b)"}}},
+		{ID: "latency", Enabled: true, Mode: models.RuleModeShadow, Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC7.2"}}},
+	}
+	coverage, err := Coverage(rules, "SOC2")
+	if err != nil {
+		t.Fatalf("Coverage failed: %v", err)
+	}
+
+	// Every SOC2 catalog control plus the one outside the catalog
+	byID := make(map[string]ControlCoverage)
+	for _, c := range coverage {
+		if c.Framework != "soc2" {
+			t.Errorf("Expected only soc2 controls, got %+v", c)
+		}
+		byID[c.ControlID] = c
+	}
+	if len(coverage) != 9 {
+		t.Errorf("Expected 9 soc2 controls, got %d", len(coverage))
+	}
+	if c := byID["CC6.1"]; !c.Covered() || !reflect.DeepEqual(c.RuleIDs, []string{"audit", "mfa"}) || c.Name != "Logical Access Controls" {
+		t.Errorf("Expected CC6.1 covered by audit and mfa, got %+v", c)
+	}
+	if c := byID["CC7.1"]; c.Covered() || len(c.RuleIDs) != 1 {
+		t.Errorf("Expected CC7.1 mapped only to a disabled rule, got %+v", c)
+	}
+	if c := byID["CC7.2"]; c.Covered() || len(c.RuleIDs) != 1 {
+		t.Errorf("Expected CC7.2 mapped only to a shadow rule, got %+v", c)
+	}
+	if c := byID["CC6.2"]; c.Covered() || len(c.RuleIDs) != 0 {
+		t.Errorf("Expected CC6.2 uncovered, got %+v", c)
+	}
+	if c, ok := byID["CC9.9"]; !ok || !c.Covered() {
+		t.Errorf("Expected the custom control to be listed, got %+v", coverage)
+	}
+
+	if _, err := Coverage(rules, "iso27001"); !errors.Is(err, ErrInvalidComplianceQuery) {
+		t.Errorf("Expected ErrInvalidComplianceQuery, got %v", err)
+	}
+}
+
+// complianceFixture records violations of two SOC2 rules and one unmapped rule
+func complianceFixture(t *testing.T) (*ComplianceReporter, *ViolationStoreMemory) {
+	t.Helper()
+	ctx := context.Background()
+	rules := staticRules{
+		{ID: "mfa", Name: "Admins use MFA", Expression: "when { admin_login } always { mfa }", Enabled: true, Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}}},
+		{ID: "audit", Name: "Exports are audited", Expression: "when { export } always { audit_log }", Enabled: true, Controls: []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}, {Framework: "soc2", ControlID: "CC7.1"}}},
+		{ID: "latency", Name: "Slow requests", Expression: "when { request } always { fast }", Enabled: true},
+	}
+	store := NewViolationStoreMemory("test-key")
+	for _, ruleID := range []string{"mfa", "audit", "audit", "latency"} {
+		if _, err := store.Record(ctx, models.Violation{RuleID: ruleID, Severity: "HIGH", Message: "violated"}, []models.SpanRef{{TraceID: "trace-" + ruleID, SpanID: "span-1"}}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	counter, err := NewEvaluationCounter("", EvaluationCounterOptions{Exemplars: 1})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		counter.Record("mfa", "admin", "trace-ok", time.Now(), EvaluationPassed)
+	}
+	counter.Record("mfa", "billing", "trace-bad", time.Now(), EvaluationViolated)
+	return NewComplianceReporter(rules, store, counter, store.Keyring()), store
+}
+
+func TestComplianceReporter_Evidence(t *testing.T) {
+	reporter, _ := complianceFixture(t)
+	ctx := context.Background()
+
+	report, err := reporter.Evidence(ctx, ComplianceQuery{Framework: "SOC2"})
+	if err != nil {
+		t.Fatalf("Evidence failed: %v", err)
+	}
+	if len(report.Controls) != 2 || report.Controls[0].ControlID != "CC6.1" || report.Controls[1].ControlID != "CC7.1" {
+		t.Fatalf("Expected controls CC6.1 and CC7.1, got %+v", report.Controls)
+	}
+	cc61 := report.Controls[0]
+	if len(cc61.Rules) != 2 || cc61.Violations != 3 || cc61.Evaluated != 6 || cc61.Passed != 5 {
+		t.Errorf("Expected 2 rules, 3 violations and 6 evaluations for CC6.1, got %+v", cc61)
+	}
+	if mfa := cc61.Rules[1]; mfa.RuleID != "mfa" || mfa.ExpressionDigest != ExpressionDigest("when { admin_login } always { mfa }") || len(mfa.ViolationIDs) != 1 {
+		t.Errorf("Expected mfa evidence with its version and violation, got %+v", mfa)
+	}
+	wantServices := []ServiceEvaluations{
+		{Service: "admin", EvaluationCounts: EvaluationCounts{Evaluated: 5, Passed: 5}},
+		{Service: "billing", EvaluationCounts: EvaluationCounts{Evaluated: 1, Violated: 1}},
+	}
+	if mfa := cc61.Rules[1]; !reflect.DeepEqual(mfa.Services, wantServices) || !reflect.DeepEqual(mfa.PassExemplars, []string{"trace-ok"}) {
+		t.Errorf("Expected per-service counts and pass exemplars, got %+v", mfa)
+	}
+
+	// A single control, and a period without violations
+	report, err = reporter.Evidence(ctx, ComplianceQuery{Framework: "soc2", Control: "cc7.1"})
+	if err != nil || len(report.Controls) != 1 || report.Controls[0].Violations != 2 {
+		t.Errorf("Expected CC7.1 with 2 violations, got %+v (%v)", report.Controls, err)
+	}
+	report, err = reporter.Evidence(ctx, ComplianceQuery{Since: time.Now().Add(-48 * time.Hour), Until: time.Now().Add(-24 * time.Hour)})
+	if err != nil || len(report.Controls) != 2 || report.Controls[0].Violations != 0 {
+		t.Errorf("Expected no violations in an earlier period, got %+v (%v)", report.Controls, err)
+	}
+
+	for _, q := range []ComplianceQuery{
+		{Framework: "iso27001"},
+		{Control: "CC6.1"},
+		{Since: time.Now(), Until: time.Now().Add(-time.Hour)},
+	} {
+		if _, err := reporter.Evidence(ctx, q); !errors.Is(err, ErrInvalidComplianceQuery) {
+			t.Errorf("Expected ErrInvalidComplianceQuery for %+v, got %v", q, err)
+		}
+	}
+}
+
+func TestComplianceBundle_VerifiesOffline(t *testing.T) {
+	reporter, store := complianceFixture(t)
+	bundle, err := reporter.Export(context.Background(), ComplianceQuery{Framework: "soc2"})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var zipped bytes.Buffer
+	if err := bundle.WriteZip(&zipped); err != nil {
+		t.Fatalf("WriteZip failed: %v", err)
+	}
+	read, err := ReadComplianceBundle(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	if err != nil {
+		t.Fatalf("ReadComplianceBundle failed: %v", err)
+	}
+	summary, err := VerifyComplianceBundle(read, store.Keyring().JWKS())
+	if err != nil {
+		t.Fatalf("Expected the bundle to verify: %v", err)
+	}
+	if summary.Violations != 3 || len(summary.Unverifiable) != 0 {
+		t.Errorf("Expected 3 verified violations (unmapped rules excluded), got %+v", summary)
+	}
+
+	tampers := map[string]func(b *ComplianceBundle){
+		"edited evidence": func(b *ComplianceBundle) {
+			b.Files[BundleEvidenceFile] = bytes.Replace(b.Files[BundleEvidenceFile], []byte(`"violations": 3`), []byte(`"violations": 0`), 1)
+		},
+		"dropped file": func(b *ComplianceBundle) { delete(b.Files, BundleManifestFile) },
+		"unsigned":     func(b *ComplianceBundle) { b.Files[BundleSignatureFile] = []byte(`{"files":{}}`) },
+		"rewritten violation": func(b *ComplianceBundle) {
+			// Change a violation and re-sign the bundle with another key
+			var violations []BundleViolation
+			json.Unmarshal(b.Files[BundleViolationsFile], &violations)
+			violations[0].Violation.Severity = "LOW"
+			b.Files[BundleViolationsFile], _ = json.Marshal(violations)
+			other := NewEphemeralKeyring()
+			sig := BundleSignature{Files: map[string]string{}, CreatedAt: time.Now()}
+			for name, data := range b.Files {
+				if name != BundleSignatureFile {
+					sig.Files[name] = sha256Hex(data)
+				}
+			}
+			sig.KeyID, sig.Signature = other.sign(BundleSignaturePayload(sig))
+			b.Files[BundleSignatureFile], _ = json.Marshal(sig)
+		},
+	}
+	for name, tamper := range tampers {
+		t.Run(name, func(t *testing.T) {
+			copied := &ComplianceBundle{Files: make(map[string][]byte)}
+			for file, data := range bundle.Files {
+				copied.Files[file] = append([]byte(nil), data...)
+			}
+			tamper(copied)
+			if _, err := VerifyComplianceBundle(copied, store.Keyring().JWKS()); !errors.Is(err, ErrInvalidBundle) {
+				t.Errorf("Expected ErrInvalidBundle, got %v", err)
+			}
+		})
+	}
+}
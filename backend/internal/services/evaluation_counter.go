@@ -0,0 +1,323 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Evaluation outcomes of a rule against one trace it applies to (its when
+// clause matched)
+const (
+	EvaluationPassed   = "passed"   // The rule held
+	EvaluationViolated = "violated" // The rule fired
+	EvaluationErrored  = "errored"  // Evaluation failed or was aborted by the evaluation limits
+)
+
+// DefaultEvaluationRetention is how long EvaluationCounter keeps counts
+const DefaultEvaluationRetention = 90 * 24 * time.Hour
+
+// DefaultEvaluationExemplars is how many passing trace IDs EvaluationCounter
+// samples per rule, service and hour
+const DefaultEvaluationExemplars = 5
+
+// UnknownService is the service evaluations of traces without one count under
+const UnknownService = "unknown"
+
+// evaluationBucket is the width of one EvaluationCounter time bucket
+const evaluationBucket = time.Hour
+
+// evaluationFileLayout names the file of each hourly bucket
+const evaluationFileLayout = "2006-01-02T15"
+
+// EvaluationCounts tallies a rule's trace evaluations by outcome
+type EvaluationCounts struct {
+	Evaluated int64 `json:"evaluated"`
+	Passed    int64 `json:"passed"`
+	Violated  int64 `json:"violated"`
+	Errored   int64 `json:"errored"`
+}
+
+// Add accumulates other into c
+func (c *EvaluationCounts) Add(other EvaluationCounts) {
+	c.Evaluated += other.Evaluated
+	c.Passed += other.Passed
+	c.Violated += other.Violated
+	c.Errored += other.Errored
+}
+
+// EvaluationBucket is one rule's evaluations of one service's traces in one
+// hour, with a sample of the traces that passed
+type EvaluationBucket struct {
+	RuleID  string    `json:"ruleId"`
+	Service string    `json:"service"`
+	Start   time.Time `json:"start"`
+	EvaluationCounts
+	PassExemplars []string `json:"passExemplars,omitempty"` // Trace IDs, sampled uniformly from the passes
+}
+
+// EvaluationFilter selects evaluation buckets; empty fields match everything
+type EvaluationFilter struct {
+	RuleID  string
+	Service string
+	Since   time.Time // Buckets overlapping [Since, Until)
+	Until   time.Time
+}
+
+// EvaluationCounterOptions configures an EvaluationCounter
+type EvaluationCounterOptions struct {
+	Retention time.Duration // 0 uses DefaultEvaluationRetention
+	Exemplars int           // Passing trace IDs kept per rule, service and hour; 0 keeps none
+}
+
+// evaluationKey identifies the counts of one rule and service in a bucket
+type evaluationKey struct {
+	ruleID  string
+	service string
+}
+
+// EvaluationCounter counts trace-level rule evaluations per rule, service
+// and hour. With a directory each hour is a JSON file there, rewritten by
+// Flush when it changed; buckets older than the retention are dropped.
+type EvaluationCounter struct {
+	dir       string // "" keeps counts in memory only
+	retention time.Duration
+	exemplars int
+
+	mu      sync.Mutex
+	since   time.Time
+	buckets map[int64]map[evaluationKey]*EvaluationBucket // Bucket start (unix seconds) -> counts
+	dirty   map[int64]bool                                // Buckets changed since the last Flush
+	pruned  int64                                         // Bucket of the last prune
+	rand    *rand.Rand
+}
+
+// NewEvaluationCounter creates a counter. With a non-empty dir it loads the
+// buckets stored there; counting then started with the oldest of them.
+func NewEvaluationCounter(dir string, opts EvaluationCounterOptions) (*EvaluationCounter, error) {
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultEvaluationRetention
+	}
+	c := &EvaluationCounter{
+		dir:       dir,
+		retention: opts.Retention,
+		exemplars: opts.Exemplars,
+		since:     time.Now().UTC(),
+		buckets:   make(map[int64]map[evaluationKey]*EvaluationBucket),
+		dirty:     make(map[int64]bool),
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if dir == "" {
+		return c, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create evaluation directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		start, err := time.Parse(evaluationFileLayout, strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue // Not a bucket file
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read evaluation bucket: %w", err)
+		}
+		var stored []*EvaluationBucket
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("failed to parse evaluation bucket %s: %w", filepath.Base(file), err)
+		}
+		bucket := make(map[evaluationKey]*EvaluationBucket, len(stored))
+		for _, b := range stored {
+			bucket[evaluationKey{b.RuleID, b.Service}] = b
+		}
+		c.buckets[start.Unix()] = bucket
+		if start.Before(c.since) {
+			c.since = start
+		}
+	}
+	c.pruneLocked(time.Now().Truncate(evaluationBucket).Unix())
+	return c, nil
+}
+
+// Since returns when counting started; evaluations before it are unknown
+func (c *EvaluationCounter) Since() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.since
+}
+
+// Record counts one evaluation of ruleID against a trace of service at time
+// at with the given outcome, sampling traceID as an exemplar if it passed
+func (c *EvaluationCounter) Record(ruleID, service, traceID string, at time.Time, outcome string) {
+	if service == "" {
+		service = UnknownService
+	}
+	start := at.Truncate(evaluationBucket)
+	key := evaluationKey{ruleID, service}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if start.Unix() > c.pruned {
+		c.pruneLocked(start.Unix())
+	}
+
+	bucket := c.buckets[start.Unix()]
+	if bucket == nil {
+		bucket = make(map[evaluationKey]*EvaluationBucket)
+		c.buckets[start.Unix()] = bucket
+	}
+	counts := bucket[key]
+	if counts == nil {
+		counts = &EvaluationBucket{RuleID: ruleID, Service: service, Start: start.UTC()}
+		bucket[key] = counts
+	}
+	c.dirty[start.Unix()] = true
+
+	counts.Evaluated++
+	switch outcome {
+	case EvaluationPassed:
+		counts.Passed++
+		c.sampleLocked(counts, traceID)
+	case EvaluationViolated:
+		counts.Violated++
+	case EvaluationErrored:
+		counts.Errored++
+	}
+}
+
+// sampleLocked keeps traceID among the bucket's exemplars with reservoir
+// sampling, so every pass is equally likely to be kept. Caller must hold c.mu.
+func (c *EvaluationCounter) sampleLocked(b *EvaluationBucket, traceID string) {
+	if c.exemplars <= 0 || traceID == "" {
+		return
+	}
+	if len(b.PassExemplars) < c.exemplars {
+		b.PassExemplars = append(b.PassExemplars, traceID)
+		return
+	}
+	if i := c.rand.Int63n(b.Passed); i < int64(c.exemplars) {
+		b.PassExemplars[i] = traceID
+	}
+}
+
+// pruneLocked drops buckets older than the retention. Caller must hold c.mu.
+func (c *EvaluationCounter) pruneLocked(now int64) {
+	c.pruned = now
+	cutoff := now - int64(c.retention/time.Second)
+	for start := range c.buckets {
+		if start < cutoff {
+			delete(c.buckets, start)
+			delete(c.dirty, start)
+			if c.dir != "" {
+				os.Remove(c.bucketPath(start))
+			}
+		}
+	}
+}
+
+// Counts sums ruleID's evaluations in the hourly buckets overlapping
+// [since, until), across services
+func (c *EvaluationCounter) Counts(ruleID string, since, until time.Time) EvaluationCounts {
+	var total EvaluationCounts
+	for _, b := range c.Buckets(EvaluationFilter{RuleID: ruleID, Since: since, Until: until}) {
+		total.Add(b.EvaluationCounts)
+	}
+	return total
+}
+
+// Buckets returns copies of the buckets matching filter, ordered by start,
+// rule ID and service
+func (c *EvaluationCounter) Buckets(filter EvaluationFilter) []EvaluationBucket {
+	from := filter.Since.Truncate(evaluationBucket).Unix()
+
+	c.mu.Lock()
+	var result []EvaluationBucket
+	for start, bucket := range c.buckets {
+		if !filter.Since.IsZero() && start < from {
+			continue
+		}
+		if !filter.Until.IsZero() && start >= filter.Until.Unix() {
+			continue
+		}
+		for key, b := range bucket {
+			if (filter.RuleID != "" && key.ruleID != filter.RuleID) || (filter.Service != "" && key.service != filter.Service) {
+				continue
+			}
+			copied := *b
+			copied.PassExemplars = append([]string(nil), b.PassExemplars...)
+			result = append(result, copied)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Service < b.Service
+	})
+	return result
+}
+
+// Flush writes the buckets changed since the last Flush to disk; it does
+// nothing for an in-memory counter
+func (c *EvaluationCounter) Flush() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for start := range c.dirty {
+		stored := make([]*EvaluationBucket, 0, len(c.buckets[start]))
+		for _, b := range c.buckets[start] {
+			stored = append(stored, b)
+		}
+		sort.Slice(stored, func(i, j int) bool {
+			if stored[i].RuleID != stored[j].RuleID {
+				return stored[i].RuleID < stored[j].RuleID
+			}
+			return stored[i].Service < stored[j].Service
+		})
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return fmt.Errorf("failed to marshal evaluation bucket: %w", err)
+		}
+
+		// Write to a temporary file and rename (crash-safe)
+		path := c.bucketPath(start)
+		if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+			return fmt.Errorf("failed to write evaluation bucket: %w", err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return fmt.Errorf("failed to rename evaluation bucket: %w", err)
+		}
+		delete(c.dirty, start)
+	}
+	return nil
+}
+
+// Close flushes the counter
+func (c *EvaluationCounter) Close() error {
+	return c.Flush()
+}
+
+// bucketPath returns the file of the bucket starting at start
+func (c *EvaluationCounter) bucketPath(start int64) string {
+	return filepath.Join(c.dir, time.Unix(start, 0).UTC().Format(evaluationFileLayout)+".json")
+}
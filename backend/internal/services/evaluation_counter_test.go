@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluationCounter_CountsPerPeriod(t *testing.T) {
+	counter, err := NewEvaluationCounter("", EvaluationCounterOptions{Retention: 48 * time.Hour})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	now := time.Now().Truncate(time.Hour)
+	counter.Record("rule-1", "api", "t1", now.Add(-3*time.Hour), EvaluationPassed)
+	counter.Record("rule-1", "api", "t2", now.Add(-3*time.Hour), EvaluationViolated)
+	counter.Record("rule-1", "api", "t3", now.Add(time.Minute), EvaluationPassed)
+	counter.Record("rule-1", "", "t4", now.Add(2*time.Minute), EvaluationErrored)
+	counter.Record("rule-2", "api", "t5", now, EvaluationPassed)
+
+	all := counter.Counts("rule-1", now.Add(-24*time.Hour), now.Add(time.Hour))
+	if want := (EvaluationCounts{Evaluated: 4, Passed: 2, Violated: 1, Errored: 1}); all != want {
+		t.Errorf("Expected %+v, got %+v", want, all)
+	}
+	recent := counter.Counts("rule-1", now.Add(30*time.Minute), now.Add(time.Hour))
+	if recent.Evaluated != 2 {
+		t.Errorf("Expected the hour overlapping since to count, got %+v", recent)
+	}
+	if unknown := counter.Buckets(EvaluationFilter{Service: UnknownService}); len(unknown) != 1 || unknown[0].Errored != 1 {
+		t.Errorf("Expected the trace without a service under %q, got %+v", UnknownService, unknown)
+	}
+	if exemplars := counter.Buckets(EvaluationFilter{RuleID: "rule-1", Service: "api"}); len(exemplars) != 2 || len(exemplars[0].PassExemplars) != 0 {
+		t.Errorf("Expected no exemplars when disabled, got %+v", exemplars)
+	}
+
+	// Buckets beyond the retention are dropped as time moves on
+	counter.Record("rule-1", "api", "t6", now.Add(50*time.Hour), EvaluationPassed)
+	if old := counter.Counts("rule-1", now.Add(-24*time.Hour), now.Add(time.Hour)); old.Evaluated != 0 {
+		t.Errorf("Expected expired buckets to be dropped, got %+v", old)
+	}
+}
+
+func TestEvaluationCounter_SamplesExemplars(t *testing.T) {
+	counter, err := NewEvaluationCounter("", EvaluationCounterOptions{Exemplars: 3})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		counter.Record("rule-1", "api", fmt.Sprintf("trace-%d", i), now, EvaluationPassed)
+	}
+	counter.Record("rule-1", "api", "violating", now, EvaluationViolated)
+
+	buckets := counter.Buckets(EvaluationFilter{RuleID: "rule-1"})
+	if len(buckets) != 1 || buckets[0].Passed != 100 || len(buckets[0].PassExemplars) != 3 {
+		t.Fatalf("Expected 3 exemplars of 100 passes, got %+v", buckets)
+	}
+	for _, traceID := range buckets[0].PassExemplars {
+		if !strings.HasPrefix(traceID, "trace-") {
+			t.Errorf("Expected only passing traces as exemplars, got %q", traceID)
+		}
+	}
+}
+
+func TestEvaluationCounter_PersistsBuckets(t *testing.T) {
+	dir := t.TempDir()
+	counter, err := NewEvaluationCounter(dir, EvaluationCounterOptions{Exemplars: 2})
+	if err != nil {
+		t.Fatalf("NewEvaluationCounter failed: %v", err)
+	}
+	earlier := time.Now().Add(-5 * time.Hour)
+	counter.Record("rule-1", "api", "t1", earlier, EvaluationPassed)
+	counter.Record("rule-1", "api", "t2", time.Now(), EvaluationPassed)
+	counter.Record("rule-1", "web", "t3", time.Now(), EvaluationViolated)
+	if err := counter.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := NewEvaluationCounter(dir, EvaluationCounterOptions{Exemplars: 2})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if got, want := reopened.Buckets(EvaluationFilter{}), counter.Buckets(EvaluationFilter{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the buckets to survive a restart:\n got %+v\nwant %+v", got, want)
+	}
+	if since := reopened.Since(); !since.Equal(earlier.Truncate(time.Hour).UTC()) {
+		t.Errorf("Expected counting to start with the oldest bucket, got %v", since)
+	}
+
+	// Expired buckets are removed from disk
+	expired, err := NewEvaluationCounter(dir, EvaluationCounterOptions{Retention: 2 * time.Hour})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if buckets := expired.Buckets(EvaluationFilter{}); len(buckets) != 2 {
+		t.Errorf("Expected the old bucket to expire, got %+v", buckets)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 1 {
+		t.Errorf("Expected one bucket file left, got %v", files)
+	}
+}
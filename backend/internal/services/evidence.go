@@ -0,0 +1,151 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// Default evidence snapshot limits
+const (
+	DefaultEvidenceMaxSpans           = 100
+	DefaultEvidenceMaxAttributeLength = 1024
+)
+
+// EvidenceLimits bounds and redacts the evidence snapshots of violations
+type EvidenceLimits struct {
+	MaxSpans           int      // Spans per snapshot, 0 = DefaultEvidenceMaxSpans
+	MaxAttributeLength int      // Bytes per attribute value, 0 = DefaultEvidenceMaxAttributeLength
+	RedactAttributes   []string // Attribute names whose values are redacted; a trailing * matches a prefix
+}
+
+// EvidenceRecorder snapshots the rule and spans a violation was detected on
+type EvidenceRecorder struct {
+	limits EvidenceLimits
+}
+
+// NewEvidenceRecorder creates an evidence recorder, filling in default limits
+func NewEvidenceRecorder(limits EvidenceLimits) *EvidenceRecorder {
+	if limits.MaxSpans <= 0 {
+		limits.MaxSpans = DefaultEvidenceMaxSpans
+	}
+	if limits.MaxAttributeLength <= 0 {
+		limits.MaxAttributeLength = DefaultEvidenceMaxAttributeLength
+	}
+	return &EvidenceRecorder{limits: limits}
+}
+
+// Snapshot copies the spans of a violation of rule referencing refs, keeping
+// the referenced spans and their ancestors when the trace exceeds the span
+// limit. Attribute values are redacted or truncated; the trace is not modified.
+func (r *EvidenceRecorder) Snapshot(rule models.Rule, refs []models.SpanRef, spans []*models.Span) *models.EvidenceSnapshot {
+	snapshot := &models.EvidenceSnapshot{
+		RuleExpression:   rule.Expression,
+		ExpressionDigest: ExpressionDigest(rule.Expression),
+		TotalSpans:       len(spans),
+	}
+
+	redacted := make(map[string]struct{})
+	for _, span := range selectEvidenceSpans(refs, spans, r.limits.MaxSpans) {
+		snap := *span
+		// Times are signed: fix their encoding independent of location
+		snap.StartTime = span.StartTime.UTC()
+		snap.EndTime = span.EndTime.UTC()
+		snap.Attributes = make(map[string]string, len(span.Attributes))
+		for key, value := range span.Attributes {
+			switch {
+			case r.redacts(key):
+				value = models.RedactedValue
+				redacted[key] = struct{}{}
+			case len(value) > r.limits.MaxAttributeLength:
+				value = truncateUTF8(value, r.limits.MaxAttributeLength)
+				snapshot.TruncatedAttributes++
+			}
+			snap.Attributes[key] = value
+		}
+		snapshot.Spans = append(snapshot.Spans, snap)
+	}
+	sort.SliceStable(snapshot.Spans, func(i, j int) bool {
+		return snapshot.Spans[i].StartTime.Before(snapshot.Spans[j].StartTime)
+	})
+
+	for key := range redacted {
+		snapshot.RedactedAttributes = append(snapshot.RedactedAttributes, key)
+	}
+	sort.Strings(snapshot.RedactedAttributes)
+	return snapshot
+}
+
+// redacts reports whether the value of attribute key is withheld from snapshots
+func (r *EvidenceRecorder) redacts(key string) bool {
+	for _, pattern := range r.limits.RedactAttributes {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// selectEvidenceSpans picks at most max spans: those refs point at, then
+// their ancestors, then the rest in trace order
+func selectEvidenceSpans(refs []models.SpanRef, spans []*models.Span, max int) []*models.Span {
+	if len(spans) <= max {
+		return spans
+	}
+
+	byID := make(map[string]*models.Span, len(spans))
+	for _, span := range spans {
+		byID[span.SpanID] = span
+	}
+	selected := make([]*models.Span, 0, max)
+	seen := make(map[string]struct{}, max)
+	add := func(span *models.Span) {
+		if _, ok := seen[span.SpanID]; ok || len(selected) == max {
+			return
+		}
+		seen[span.SpanID] = struct{}{}
+		selected = append(selected, span)
+	}
+
+	for _, ref := range refs {
+		if span := byID[ref.SpanID]; span != nil {
+			add(span)
+		}
+	}
+	for _, ref := range refs {
+		span := byID[ref.SpanID]
+		// Bounded walk: parent links in received spans may form a cycle
+		for depth := 0; span != nil && depth < len(spans); depth++ {
+			span = byID[span.ParentSpanID]
+			if span != nil {
+				add(span)
+			}
+		}
+	}
+	for _, span := range spans {
+		add(span)
+	}
+	return selected
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// ExpressionDigest identifies a rule expression by content: "sha256:<hex>"
+func ExpressionDigest(expression string) string {
+	sum := sha256.Sum256([]byte(expression))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestEvidenceRecorder_KeepsReferencedSpansAndAncestors(t *testing.T) {
+	spans := []*models.Span{
+		{SpanID: "root", OperationName: "http_request"},
+		{SpanID: "handler", ParentSpanID: "root", OperationName: "checkout"},
+		{SpanID: "payment", ParentSpanID: "handler", OperationName: "payment"},
+	}
+	for i := 0; i < 50; i++ {
+		spans = append(spans, &models.Span{SpanID: fmt.Sprintf("noise-%d", i), ParentSpanID: "root", OperationName: "db_query"})
+	}
+	recorder := NewEvidenceRecorder(EvidenceLimits{MaxSpans: 4})
+	rule := models.Rule{Expression: "when { payment } always { auth }"}
+
+	snapshot := recorder.Snapshot(rule, []models.SpanRef{{SpanID: "payment"}}, spans)
+	var ids []string
+	for _, span := range snapshot.Spans {
+		ids = append(ids, span.SpanID)
+	}
+	if want := []string{"payment", "handler", "root", "noise-0"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected spans %v, got %v", want, ids)
+	}
+	if snapshot.TotalSpans != len(spans) {
+		t.Errorf("Expected TotalSpans=%d, got %d", len(spans), snapshot.TotalSpans)
+	}
+	if snapshot.RuleExpression != rule.Expression || snapshot.ExpressionDigest != ExpressionDigest(rule.Expression) {
+		t.Errorf("Expected the rule expression and its digest, got %q %q", snapshot.RuleExpression, snapshot.ExpressionDigest)
+	}
+	if !strings.HasPrefix(snapshot.ExpressionDigest, "sha256:") || ExpressionDigest("when { other }") == snapshot.ExpressionDigest {
+		t.Errorf("Expected a content-addressed digest, got %q", snapshot.ExpressionDigest)
+	}
+}
+
+func TestEvidenceRecorder_RedactsAndTruncates(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+2", 2*60*60))
+	span := &models.Span{
+		SpanID:    "span-1",
+		StartTime: start,
+		Attributes: map[string]string{
+			"user.email":         "alice@example.com",
+			"http.header.cookie": "session=secret",
+			"db.statement":       strings.Repeat("é", 10), // 20 bytes
+			"payment.amount":     "100",
+		},
+	}
+	recorder := NewEvidenceRecorder(EvidenceLimits{
+		MaxAttributeLength: 5,
+		RedactAttributes:   []string{"user.email", "http.header.*"},
+	})
+
+	snapshot := recorder.Snapshot(models.Rule{}, nil, []*models.Span{span})
+	attrs := snapshot.Spans[0].Attributes
+	if attrs["user.email"] != models.RedactedValue || attrs["http.header.cookie"] != models.RedactedValue {
+		t.Errorf("Expected redacted values, got %v", attrs)
+	}
+	if attrs["db.statement"] != "éé" || attrs["payment.amount"] != "100" {
+		t.Errorf("Expected truncation at a character boundary, got %q", attrs["db.statement"])
+	}
+	if want := []string{"http.header.cookie", "user.email"}; !reflect.DeepEqual(snapshot.RedactedAttributes, want) {
+		t.Errorf("Expected RedactedAttributes=%v, got %v", want, snapshot.RedactedAttributes)
+	}
+	if snapshot.TruncatedAttributes != 1 {
+		t.Errorf("Expected 1 truncated attribute, got %d", snapshot.TruncatedAttributes)
+	}
+	if snapshot.Spans[0].StartTime.Location() != time.UTC || !snapshot.Spans[0].StartTime.Equal(start) {
+		t.Errorf("Expected start time in UTC, got %v", snapshot.Spans[0].StartTime)
+	}
+
+	// The trace itself is left alone
+	if span.Attributes["user.email"] != "alice@example.com" {
+		t.Error("Expected snapshot not to modify the original span")
+	}
+}
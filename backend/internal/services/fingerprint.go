@@ -0,0 +1,95 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// Grouping keys for violation fingerprints
+const (
+	GroupByService   = "service"    // Services of the referenced spans
+	GroupByOperation = "operation"  // Operation names of the referenced spans
+	GroupByAttribute = "attribute:" // Prefix: "attribute:<name>" groups by a span attribute's values
+)
+
+// DefaultGroupingKeys groups a rule's violations per affected service
+var DefaultGroupingKeys = []string{GroupByService}
+
+// Fingerprinter derives a violation's fingerprint from its rule ID and the
+// values of the configured grouping keys, read from the spans the violation
+// references. Violations with equal fingerprints belong to the same incident.
+type Fingerprinter struct {
+	keys []string
+}
+
+// NewFingerprinter validates grouping keys and creates a fingerprinter
+func NewFingerprinter(keys []string) (*Fingerprinter, error) {
+	for _, key := range keys {
+		switch {
+		case key == GroupByService, key == GroupByOperation:
+		case strings.HasPrefix(key, GroupByAttribute) && len(key) > len(GroupByAttribute):
+		default:
+			return nil, fmt.Errorf("invalid grouping key %q (want %s, %s or %s<name>)", key, GroupByService, GroupByOperation, GroupByAttribute)
+		}
+	}
+	return &Fingerprinter{keys: keys}, nil
+}
+
+// Fingerprint returns the fingerprint and grouping key values for a violation
+// of ruleID referencing refs. spans supplies attributes and operation names
+// for the referenced span IDs; without it those keys group by service only.
+func (f *Fingerprinter) Fingerprint(ruleID string, refs []models.SpanRef, spans []*models.Span) (string, map[string]string) {
+	byID := make(map[string]*models.Span, len(refs))
+	for _, span := range spans {
+		byID[span.SpanID] = span
+	}
+
+	groupKeys := make(map[string]string, len(f.keys))
+	for _, key := range f.keys {
+		var values []string
+		for _, ref := range refs {
+			span := byID[ref.SpanID]
+			switch {
+			case key == GroupByService:
+				values = append(values, ref.ServiceName)
+			case key == GroupByOperation && span != nil:
+				values = append(values, span.OperationName)
+			case strings.HasPrefix(key, GroupByAttribute) && span != nil:
+				if value, ok := span.Attributes[strings.TrimPrefix(key, GroupByAttribute)]; ok {
+					values = append(values, value)
+				}
+			}
+		}
+		groupKeys[key] = joinDistinct(values)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(ruleID))
+	for _, key := range f.keys {
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(groupKeys[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), groupKeys
+}
+
+// joinDistinct renders a set of values in a stable order
+func joinDistinct(values []string) string {
+	seen := make(map[string]struct{}, len(values))
+	distinct := values[:0]
+	for _, v := range values {
+		if _, ok := seen[v]; ok || v == "" {
+			continue
+		}
+		seen[v] = struct{}{}
+		distinct = append(distinct, v)
+	}
+	sort.Strings(distinct)
+	return strings.Join(distinct, ",")
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestFingerprinter_GroupsByService(t *testing.T) {
+	f, err := NewFingerprinter(DefaultGroupingKeys)
+	if err != nil {
+		t.Fatalf("Failed to create fingerprinter: %v", err)
+	}
+
+	checkout := []models.SpanRef{{TraceID: "trace-1", SpanID: "span-1", ServiceName: "checkout"}}
+	checkoutAgain := []models.SpanRef{{TraceID: "trace-2", SpanID: "span-9", ServiceName: "checkout"}}
+	auth := []models.SpanRef{{TraceID: "trace-3", SpanID: "span-1", ServiceName: "auth"}}
+
+	fp1, keys := f.Fingerprint("rule-1", checkout, nil)
+	fp2, _ := f.Fingerprint("rule-1", checkoutAgain, nil)
+	fp3, _ := f.Fingerprint("rule-1", auth, nil)
+	fp4, _ := f.Fingerprint("rule-2", checkout, nil)
+
+	if fp1 != fp2 {
+		t.Errorf("Expected same rule and service to share a fingerprint, got %s and %s", fp1, fp2)
+	}
+	if fp1 == fp3 {
+		t.Error("Expected different services to get different fingerprints")
+	}
+	if fp1 == fp4 {
+		t.Error("Expected different rules to get different fingerprints")
+	}
+	if keys[GroupByService] != "checkout" {
+		t.Errorf("Expected service group key 'checkout', got %v", keys)
+	}
+}
+
+func TestFingerprinter_OrderIndependent(t *testing.T) {
+	f, _ := NewFingerprinter([]string{GroupByService})
+
+	a := []models.SpanRef{{SpanID: "s1", ServiceName: "api"}, {SpanID: "s2", ServiceName: "db"}, {SpanID: "s3", ServiceName: "api"}}
+	b := []models.SpanRef{{SpanID: "s4", ServiceName: "db"}, {SpanID: "s5", ServiceName: "api"}}
+
+	fpA, keys := f.Fingerprint("rule-1", a, nil)
+	fpB, _ := f.Fingerprint("rule-1", b, nil)
+	if fpA != fpB {
+		t.Errorf("Expected fingerprint to ignore span order and duplicates, got %s and %s", fpA, fpB)
+	}
+	if keys[GroupByService] != "api,db" {
+		t.Errorf("Expected sorted distinct services 'api,db', got %q", keys[GroupByService])
+	}
+}
+
+func TestFingerprinter_OperationAndAttributeKeys(t *testing.T) {
+	f, err := NewFingerprinter([]string{GroupByOperation, GroupByAttribute + "tenant"})
+	if err != nil {
+		t.Fatalf("Failed to create fingerprinter: %v", err)
+	}
+
+	span := func(id, op, tenant string) *models.Span {
+		return &models.Span{SpanID: id, OperationName: op, Attributes: map[string]string{"tenant": tenant}}
+	}
+	refs := []models.SpanRef{{SpanID: "s1"}}
+
+	fp1, keys := f.Fingerprint("rule-1", refs, []*models.Span{span("s1", "charge", "acme")})
+	fp2, _ := f.Fingerprint("rule-1", refs, []*models.Span{span("s1", "charge", "globex")})
+	fp3, _ := f.Fingerprint("rule-1", refs, []*models.Span{span("s1", "charge", "acme"), span("s2", "refund", "globex")})
+
+	if fp1 == fp2 {
+		t.Error("Expected different tenants to get different fingerprints")
+	}
+	if fp1 != fp3 {
+		t.Error("Expected unreferenced spans not to affect the fingerprint")
+	}
+	if keys[GroupByOperation] != "charge" || keys["attribute:tenant"] != "acme" {
+		t.Errorf("Unexpected group keys: %v", keys)
+	}
+}
+
+func TestNewFingerprinter_InvalidKey(t *testing.T) {
+	for _, key := range []string{"host", "attribute:", ""} {
+		if _, err := NewFingerprinter([]string{key}); err == nil {
+			t.Errorf("Expected error for grouping key %q", key)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// maxIncidentSamples caps the sample trace IDs kept per incident
+const maxIncidentSamples = 10
+
+// ErrIncidentNotFound is returned for an unknown fingerprint
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// IncidentStore groups violations into incidents by fingerprint.
+//
+// It decorates a ViolationStore: Record fingerprints violations that don't
+// carry one yet (from the rule ID and span references alone; callers with
+// the trace's spans should fingerprint first so attribute keys apply) and
+// folds each stored violation into its incident.
+//
+// Incidents are derived state: NewIncidentStore rebuilds them from the
+// retained violations, and Prune reconciles them with the store after
+// retention evicts some, so counts reflect violations still in the store.
+type IncidentStore struct {
+	ViolationStore
+	fingerprinter *Fingerprinter
+
+	// recordMu is held shared from storing a violation until its incident
+	// counts it, so Prune never sees one without the other
+	recordMu sync.RWMutex
+
+	mu        sync.RWMutex
+	incidents map[string]*models.Incident
+	// derived holds fingerprints computed for violations stored without one,
+	// which the store can't be queried by
+	derived map[string]bool
+}
+
+// IncidentFilters defines incident query parameters
+type IncidentFilters struct {
+	RuleID   string
+	Severity string
+	Since    time.Time // Incidents last seen at or after Since
+	Limit    int       // 0 = no limit
+}
+
+// NewIncidentStore wraps store and rebuilds incidents from its violations
+func NewIncidentStore(ctx context.Context, store ViolationStore, fingerprinter *Fingerprinter) (*IncidentStore, error) {
+	s := &IncidentStore{
+		ViolationStore: store,
+		fingerprinter:  fingerprinter,
+		incidents:      make(map[string]*models.Incident),
+		derived:        make(map[string]bool),
+	}
+
+	filters := QueryFilters{Order: OrderOldestFirst, Limit: 1000}
+	for {
+		page, err := store.QueryPage(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild incidents: %w", err)
+		}
+		for _, v := range page.Violations {
+			if v.Fingerprint == "" {
+				v = s.withFingerprint(v)
+				s.derived[v.Fingerprint] = true
+			}
+			s.observe(v)
+		}
+		if page.NextCursor == "" {
+			return s, nil
+		}
+		filters.Cursor = page.NextCursor
+	}
+}
+
+// Keyring returns the keys the wrapped store signs violations with (nil if
+// it doesn't sign them)
+func (s *IncidentStore) Keyring() *Keyring {
+	return StoreKeyring(s.ViolationStore)
+}
+
+// Fingerprinter returns the fingerprinter used for grouping
+func (s *IncidentStore) Fingerprinter() *Fingerprinter {
+	return s.fingerprinter
+}
+
+// Record stores a violation and adds it to its incident
+func (s *IncidentStore) Record(ctx context.Context, violation models.Violation, traceRefs []models.SpanRef) (models.Violation, error) {
+	if violation.Fingerprint == "" {
+		violation.Fingerprint, violation.GroupKeys = s.fingerprinter.Fingerprint(violation.RuleID, traceRefs, nil)
+	}
+
+	s.recordMu.RLock()
+	defer s.recordMu.RUnlock()
+	stored, err := s.ViolationStore.Record(ctx, violation, traceRefs)
+	if err != nil {
+		return stored, err
+	}
+
+	s.observe(stored)
+	return stored, nil
+}
+
+// Prune reconciles incidents with the violations the store still retains:
+// incidents whose violations were all evicted are dropped, and the rest take
+// their count and first occurrence from what remains. It returns the number
+// of incidents dropped.
+func (s *IncidentStore) Prune(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	fingerprints := make([]string, 0, len(s.incidents))
+	for fingerprint := range s.incidents {
+		if !s.derived[fingerprint] {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	s.mu.RUnlock()
+
+	dropped := 0
+	for _, fingerprint := range fingerprints {
+		gone, err := s.reconcile(ctx, fingerprint)
+		if err != nil {
+			return dropped, fmt.Errorf("failed to prune incident %s: %w", fingerprint, err)
+		}
+		if gone {
+			dropped++
+		}
+	}
+	return dropped, nil
+}
+
+// reconcile recounts one incident from the store, reporting whether it was
+// dropped for having no violations left
+func (s *IncidentStore) reconcile(ctx context.Context, fingerprint string) (bool, error) {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
+	page, err := s.ViolationStore.QueryPage(ctx, QueryFilters{
+		Fingerprint: fingerprint,
+		Order:       OrderOldestFirst,
+		Limit:       1,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident, ok := s.incidents[fingerprint]
+	if !ok {
+		return false, nil
+	}
+	if page.TotalCount == 0 {
+		delete(s.incidents, fingerprint)
+		return true, nil
+	}
+	incident.Count = int64(page.TotalCount)
+	incident.FirstSeen = page.Violations[0].CreatedAt
+	return false, nil
+}
+
+// ListIncidents returns incidents matching filters, most recently seen first,
+// and the total number of matches
+func (s *IncidentStore) ListIncidents(filters IncidentFilters) ([]models.Incident, int) {
+	s.mu.RLock()
+	var matched []models.Incident
+	for _, incident := range s.incidents {
+		if filters.RuleID != "" && incident.RuleID != filters.RuleID {
+			continue
+		}
+		if filters.Severity != "" && incident.Severity != filters.Severity {
+			continue
+		}
+		if !filters.Since.IsZero() && incident.LastSeen.Before(filters.Since) {
+			continue
+		}
+		matched = append(matched, copyIncident(incident))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].LastSeen.Equal(matched[j].LastSeen) {
+			return matched[i].LastSeen.After(matched[j].LastSeen)
+		}
+		return matched[i].Fingerprint < matched[j].Fingerprint
+	})
+
+	total := len(matched)
+	if filters.Limit > 0 && len(matched) > filters.Limit {
+		matched = matched[:filters.Limit]
+	}
+	return matched, total
+}
+
+// GetIncident returns the incident for a fingerprint
+func (s *IncidentStore) GetIncident(fingerprint string) (*models.Incident, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	incident, ok := s.incidents[fingerprint]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrIncidentNotFound, fingerprint)
+	}
+	result := copyIncident(incident)
+	return &result, nil
+}
+
+// withFingerprint fingerprints violations stored before fingerprinting existed
+func (s *IncidentStore) withFingerprint(v models.Violation) models.Violation {
+	if v.Fingerprint == "" {
+		v.Fingerprint, v.GroupKeys = s.fingerprinter.Fingerprint(v.RuleID, v.SpanRefs, nil)
+	}
+	return v
+}
+
+// observe folds a violation into its incident
+func (s *IncidentStore) observe(v models.Violation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	incident, ok := s.incidents[v.Fingerprint]
+	if !ok {
+		incident = &models.Incident{
+			Fingerprint: v.Fingerprint,
+			RuleID:      v.RuleID,
+			GroupKeys:   v.GroupKeys,
+			FirstSeen:   v.CreatedAt,
+			LastSeen:    v.CreatedAt,
+		}
+		s.incidents[v.Fingerprint] = incident
+	}
+
+	incident.Count++
+	if v.CreatedAt.Before(incident.FirstSeen) {
+		incident.FirstSeen = v.CreatedAt
+	}
+	if v.CreatedAt.Before(incident.LastSeen) {
+		return
+	}
+
+	// Latest occurrence: refresh display fields and samples
+	incident.LastSeen = v.CreatedAt
+	incident.LatestViolationID = v.ID
+	incident.RuleName = v.RuleName
+	incident.Severity = v.Severity
+	for i := len(v.TraceIDs) - 1; i >= 0; i-- {
+		incident.SampleTraceIDs = prependDistinct(incident.SampleTraceIDs, v.TraceIDs[i], maxIncidentSamples)
+	}
+}
+
+// prependDistinct moves value to the front of values, capped at max entries
+func prependDistinct(values []string, value string, max int) []string {
+	result := make([]string, 0, max)
+	result = append(result, value)
+	for _, v := range values {
+		if v != value && len(result) < max {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func copyIncident(incident *models.Incident) models.Incident {
+	result := *incident
+	result.SampleTraceIDs = append([]string(nil), incident.SampleTraceIDs...)
+	if incident.GroupKeys != nil {
+		result.GroupKeys = make(map[string]string, len(incident.GroupKeys))
+		for k, v := range incident.GroupKeys {
+			result.GroupKeys[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func newTestIncidentStore(t *testing.T, store ViolationStore) *IncidentStore {
+	t.Helper()
+	f, err := NewFingerprinter(DefaultGroupingKeys)
+	if err != nil {
+		t.Fatalf("Failed to create fingerprinter: %v", err)
+	}
+	incidents, err := NewIncidentStore(context.Background(), store, f)
+	if err != nil {
+		t.Fatalf("Failed to create incident store: %v", err)
+	}
+	return incidents
+}
+
+func recordForService(t *testing.T, store ViolationStore, ruleID, service, traceID string) models.Violation {
+	t.Helper()
+	v, err := store.Record(context.Background(), models.Violation{
+		RuleID:   ruleID,
+		RuleName: "Rule " + ruleID,
+		Severity: "HIGH",
+		Message:  "violation",
+	}, []models.SpanRef{{TraceID: traceID, SpanID: "span-1", ServiceName: service}})
+	if err != nil {
+		t.Fatalf("Failed to record violation: %v", err)
+	}
+	return v
+}
+
+func TestIncidentStore_GroupsRepeatedViolations(t *testing.T) {
+	store := newTestIncidentStore(t, NewViolationStoreMemory("test-key"))
+
+	first := recordForService(t, store, "rule-1", "checkout", "trace-1")
+	time.Sleep(time.Millisecond)
+	recordForService(t, store, "rule-1", "checkout", "trace-2")
+	time.Sleep(time.Millisecond)
+	last := recordForService(t, store, "rule-1", "checkout", "trace-3")
+	recordForService(t, store, "rule-1", "auth", "trace-4")
+
+	if first.Fingerprint == "" {
+		t.Fatal("Expected recorded violation to carry a fingerprint")
+	}
+
+	incidents, total := store.ListIncidents(IncidentFilters{})
+	if total != 2 || len(incidents) != 2 {
+		t.Fatalf("Expected 2 incidents, got %d (total %d)", len(incidents), total)
+	}
+
+	incident, err := store.GetIncident(first.Fingerprint)
+	if err != nil {
+		t.Fatalf("Failed to get incident: %v", err)
+	}
+	if incident.Count != 3 {
+		t.Errorf("Expected count 3, got %d", incident.Count)
+	}
+	if !incident.FirstSeen.Equal(first.CreatedAt) || !incident.LastSeen.Equal(last.CreatedAt) {
+		t.Errorf("Unexpected first/last seen: %v / %v", incident.FirstSeen, incident.LastSeen)
+	}
+	if incident.LatestViolationID != last.ID {
+		t.Errorf("Expected latest violation %s, got %s", last.ID, incident.LatestViolationID)
+	}
+	if want := []string{"trace-3", "trace-2", "trace-1"}; len(incident.SampleTraceIDs) != 3 || incident.SampleTraceIDs[0] != want[0] || incident.SampleTraceIDs[2] != want[2] {
+		t.Errorf("Expected sample traces %v, got %v", want, incident.SampleTraceIDs)
+	}
+	if incident.GroupKeys[GroupByService] != "checkout" {
+		t.Errorf("Expected group key service=checkout, got %v", incident.GroupKeys)
+	}
+
+	violations, err := store.Query(context.Background(), QueryFilters{Fingerprint: first.Fingerprint})
+	if err != nil {
+		t.Fatalf("Failed to query by fingerprint: %v", err)
+	}
+	if len(violations) != 3 {
+		t.Errorf("Expected 3 violations for fingerprint, got %d", len(violations))
+	}
+}
+
+func TestIncidentStore_Filters(t *testing.T) {
+	store := newTestIncidentStore(t, NewViolationStoreMemory("test-key"))
+
+	for i := 0; i < maxIncidentSamples+5; i++ {
+		recordForService(t, store, "rule-1", "checkout", "trace-"+string(rune('a'+i)))
+	}
+	recordForService(t, store, "rule-2", "checkout", "trace-x")
+
+	incidents, total := store.ListIncidents(IncidentFilters{RuleID: "rule-1"})
+	if total != 1 || incidents[0].RuleID != "rule-1" {
+		t.Fatalf("Expected one rule-1 incident, got %v", incidents)
+	}
+	if len(incidents[0].SampleTraceIDs) != maxIncidentSamples {
+		t.Errorf("Expected samples capped at %d, got %d", maxIncidentSamples, len(incidents[0].SampleTraceIDs))
+	}
+
+	incidents, total = store.ListIncidents(IncidentFilters{Limit: 1})
+	if total != 2 || len(incidents) != 1 {
+		t.Errorf("Expected 1 of 2 incidents, got %d of %d", len(incidents), total)
+	}
+
+	if _, total := store.ListIncidents(IncidentFilters{Since: time.Now().Add(time.Hour)}); total != 0 {
+		t.Errorf("Expected no incidents seen in the future, got %d", total)
+	}
+
+	if _, err := store.GetIncident("unknown"); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("Expected ErrIncidentNotFound, got %v", err)
+	}
+}
+
+func TestIncidentStore_RebuildsFromDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	disk, err := NewViolationStoreDisk(dir, "test-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	store := newTestIncidentStore(t, disk)
+	v := recordForService(t, store, "rule-1", "checkout", "trace-1")
+	recordForService(t, store, "rule-1", "checkout", "trace-2")
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+
+	reopened, err := NewViolationStoreDisk(dir, "test-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	incident, err := newTestIncidentStore(t, reopened).GetIncident(v.Fingerprint)
+	if err != nil {
+		t.Fatalf("Expected incident to be rebuilt after restart: %v", err)
+	}
+	if incident.Count != 2 {
+		t.Errorf("Expected rebuilt count 2, got %d", incident.Count)
+	}
+}
+
+func TestIncidentStore_PrunesEvictedViolations(t *testing.T) {
+	disk, err := NewViolationStoreDisk(t.TempDir(), "test-key", storage.DiskViolationStoreOptions{MaxViolations: 2})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	store := newTestIncidentStore(t, disk)
+	defer store.Close()
+
+	checkout := recordForService(t, store, "rule-1", "checkout", "trace-1")
+	recordForService(t, store, "rule-1", "checkout", "trace-2")
+	recordForService(t, store, "rule-1", "billing", "trace-3")
+	billing := recordForService(t, store, "rule-1", "billing", "trace-4")
+	recordForService(t, store, "rule-1", "billing", "trace-5")
+
+	dropped, err := store.Prune(context.Background())
+	if err != nil || dropped != 1 {
+		t.Fatalf("Expected checkout's incident dropped, got %d (%v)", dropped, err)
+	}
+	if _, err := store.GetIncident(checkout.Fingerprint); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("Expected checkout's incident gone, got %v", err)
+	}
+	incident, err := store.GetIncident(billing.Fingerprint)
+	if err != nil {
+		t.Fatalf("GetIncident failed: %v", err)
+	}
+	if incident.Count != 2 || !incident.FirstSeen.Equal(billing.CreatedAt) {
+		t.Errorf("Expected the 2 retained violations from %v, got %d from %v", billing.CreatedAt, incident.Count, incident.FirstSeen)
+	}
+}
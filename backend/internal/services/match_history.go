@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMatchHistoryPerRule is how many recent matches MatchHistory keeps per rule
+const DefaultMatchHistoryPerRule = 1000
+
+// Outcomes of a rule whose when clause matched a trace
+const (
+	MatchViolated  = "violated"  // The rule fired
+	MatchSatisfied = "satisfied" // always/never held
+)
+
+// RuleMatch is one trace a rule's when clause matched
+type RuleMatch struct {
+	Seq       uint64    `json:"-"` // Position in the history, increasing across rules
+	RuleID    string    `json:"ruleId"`
+	TraceID   string    `json:"traceId"`
+	Service   string    `json:"service,omitempty"` // Service of the trace's root span
+	Outcome   string    `json:"outcome"`           // MatchViolated or MatchSatisfied
+	MatchedAt time.Time `json:"matchedAt"`
+}
+
+// MatchFilter selects one rule's matches; zero fields match everything
+type MatchFilter struct {
+	Outcome string
+	Since   time.Time // Inclusive
+	Until   time.Time // Exclusive
+	Limit   int       // Page size; 0 returns every match
+	Cursor  string    // NextCursor from the previous page
+}
+
+// MatchPage is one page of a rule's matches, newest first
+type MatchPage struct {
+	Matches    []RuleMatch
+	TotalCount int    // Matches across all pages
+	NextCursor string // Empty on the last page
+}
+
+// matchRing holds a rule's most recent matches, oldest first from start
+type matchRing struct {
+	matches []RuleMatch
+	start   int
+}
+
+// MatchHistory keeps a bounded, in-memory history of the traces each rule
+// applied to and whether they violated it, so rule authors can see what a
+// rule catches. The oldest matches of a rule are dropped first.
+type MatchHistory struct {
+	perRule int
+
+	mu    sync.RWMutex
+	rules map[string]*matchRing
+	seq   uint64
+}
+
+// NewMatchHistory creates a history keeping perRule matches per rule (0 uses
+// DefaultMatchHistoryPerRule)
+func NewMatchHistory(perRule int) *MatchHistory {
+	if perRule <= 0 {
+		perRule = DefaultMatchHistoryPerRule
+	}
+	return &MatchHistory{perRule: perRule, rules: make(map[string]*matchRing)}
+}
+
+// Record adds a match to its rule's history, assigning its Seq
+func (h *MatchHistory) Record(m RuleMatch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	m.Seq = h.seq
+	ring := h.rules[m.RuleID]
+	if ring == nil {
+		ring = &matchRing{}
+		h.rules[m.RuleID] = ring
+	}
+	if len(ring.matches) < h.perRule {
+		ring.matches = append(ring.matches, m)
+		return
+	}
+	ring.matches[ring.start] = m
+	ring.start = (ring.start + 1) % len(ring.matches)
+}
+
+// Forget drops a rule's history (e.g. when the rule is deleted)
+func (h *MatchHistory) Forget(ruleID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.rules, ruleID)
+}
+
+// Query returns a page of ruleID's matches selected by f, newest first
+func (h *MatchHistory) Query(ruleID string, f MatchFilter) (MatchPage, error) {
+	var before uint64 // 0 = from the newest
+	if f.Cursor != "" {
+		var err error
+		if before, err = decodeMatchCursor(f.Cursor); err != nil {
+			return MatchPage{}, err
+		}
+	}
+	if f.Outcome != "" && f.Outcome != MatchViolated && f.Outcome != MatchSatisfied {
+		return MatchPage{}, fmt.Errorf("%w: unknown outcome %q", ErrInvalidQuery, f.Outcome)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	page := MatchPage{Matches: []RuleMatch{}}
+	ring := h.rules[ruleID]
+	if ring == nil {
+		return page, nil
+	}
+
+	n := len(ring.matches)
+	for i := n - 1; i >= 0; i-- {
+		m := ring.matches[(ring.start+i)%n]
+		if (f.Outcome != "" && m.Outcome != f.Outcome) ||
+			(!f.Since.IsZero() && m.MatchedAt.Before(f.Since)) ||
+			(!f.Until.IsZero() && !m.MatchedAt.Before(f.Until)) {
+			continue
+		}
+		page.TotalCount++
+		if before != 0 && m.Seq >= before {
+			continue
+		}
+		if f.Limit > 0 && len(page.Matches) == f.Limit {
+			if page.NextCursor == "" {
+				page.NextCursor = encodeMatchCursor(page.Matches[len(page.Matches)-1].Seq)
+			}
+			continue
+		}
+		page.Matches = append(page.Matches, m)
+	}
+	return page, nil
+}
+
+// Match cursors are opaque to clients: base64url of the last match's Seq
+func encodeMatchCursor(seq uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(seq, 10)))
+}
+
+func decodeMatchCursor(s string) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	seq, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil || seq == 0 {
+		return 0, errInvalidCursor
+	}
+	return seq, nil
+}
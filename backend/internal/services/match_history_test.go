@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMatchHistory_KeepsRecentMatchesPerRule(t *testing.T) {
+	history := NewMatchHistory(3)
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		history.Record(RuleMatch{RuleID: "rule-1", TraceID: fmt.Sprintf("trace-%d", i), Outcome: MatchSatisfied, MatchedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+	history.Record(RuleMatch{RuleID: "rule-2", TraceID: "other", Outcome: MatchViolated, MatchedAt: base})
+
+	page, err := history.Query("rule-1", MatchFilter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if page.TotalCount != 3 || len(page.Matches) != 3 || page.Matches[0].TraceID != "trace-4" || page.Matches[2].TraceID != "trace-2" {
+		t.Errorf("Expected the 3 newest matches, newest first, got %+v", page.Matches)
+	}
+
+	history.Forget("rule-1")
+	if page, _ := history.Query("rule-1", MatchFilter{}); page.TotalCount != 0 {
+		t.Errorf("Expected a forgotten rule to have no matches, got %+v", page)
+	}
+	if page, _ := history.Query("rule-2", MatchFilter{}); page.TotalCount != 1 {
+		t.Errorf("Expected other rules to keep their matches, got %+v", page)
+	}
+}
+
+func TestMatchHistory_FiltersAndPages(t *testing.T) {
+	history := NewMatchHistory(0)
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 10; i++ {
+		outcome := MatchSatisfied
+		if i%2 == 0 {
+			outcome = MatchViolated
+		}
+		history.Record(RuleMatch{RuleID: "rule-1", TraceID: fmt.Sprintf("trace-%d", i), Outcome: outcome, MatchedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	page, err := history.Query("rule-1", MatchFilter{Outcome: MatchViolated, Since: base.Add(2 * time.Minute), Until: base.Add(8 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var got []string
+	for _, m := range page.Matches {
+		got = append(got, m.TraceID)
+	}
+	if fmt.Sprint(got) != "[trace-6 trace-4 trace-2]" {
+		t.Errorf("Expected violations in [2m, 8m), got %v", got)
+	}
+
+	// Walk every match in pages of 4
+	var walked []string
+	filter := MatchFilter{Limit: 4}
+	for {
+		page, err := history.Query("rule-1", filter)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if page.TotalCount != 10 {
+			t.Errorf("Expected a total of 10 on every page, got %d", page.TotalCount)
+		}
+		for _, m := range page.Matches {
+			walked = append(walked, m.TraceID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	if len(walked) != 10 || walked[0] != "trace-9" || walked[9] != "trace-0" {
+		t.Errorf("Expected all 10 matches newest first, got %v", walked)
+	}
+
+	for _, f := range []MatchFilter{{Cursor: "not-a-cursor"}, {Outcome: "matched"}} {
+		if _, err := history.Query("rule-1", f); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery for %+v, got %v", f, err)
+		}
+	}
+}
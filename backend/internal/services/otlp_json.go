@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// otlpTraceData is the OTLP/JSON encoding of an ExportTraceServiceRequest,
+// as written by the OpenTelemetry Collector's file exporter
+type otlpTraceData struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans                  []otlpScopeSpans `json:"scopeSpans"`
+		InstrumentationLibrarySpans []otlpScopeSpans `json:"instrumentationLibrarySpans"` // Before OTLP 0.15
+	} `json:"resourceSpans"`
+}
+
+type otlpScopeSpans struct {
+	Spans []struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId"`
+		Name              string         `json:"name"`
+		StartTimeUnixNano otlpInt        `json:"startTimeUnixNano"`
+		EndTimeUnixNano   otlpInt        `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes"`
+		Status            struct {
+			Code json.RawMessage `json:"code"` // 2 or "STATUS_CODE_ERROR"
+		} `json:"status"`
+	} `json:"spans"`
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string         `json:"stringValue"`
+		BoolValue   *bool           `json:"boolValue"`
+		IntValue    *otlpInt        `json:"intValue"`
+		DoubleValue *float64        `json:"doubleValue"`
+		ArrayValue  json.RawMessage `json:"arrayValue"`
+		KvlistValue json.RawMessage `json:"kvlistValue"`
+		BytesValue  *string         `json:"bytesValue"`
+	} `json:"value"`
+}
+
+// otlpInt is a 64-bit integer, which OTLP/JSON encodes as a string
+type otlpInt int64
+
+func (i *otlpInt) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = otlpInt(n)
+	return nil
+}
+
+// string formats an attribute value the way the DSL compares it
+func (kv otlpKeyValue) string() string {
+	v := kv.Value
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.ArrayValue != nil:
+		return string(v.ArrayValue)
+	case v.KvlistValue != nil:
+		return string(v.KvlistValue)
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	}
+	return ""
+}
+
+// DecodeOTLPJSON decodes spans from OTLP/JSON: one ExportTraceServiceRequest
+// document, or several concatenated or one per line. Spans take their
+// service from the service.name resource attribute.
+func DecodeOTLPJSON(r io.Reader) ([]*models.Span, error) {
+	var spans []*models.Span
+	decoder := json.NewDecoder(r)
+	for {
+		var data otlpTraceData
+		err := decoder.Decode(&data)
+		if errors.Is(err, io.EOF) {
+			return spans, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP/JSON: %w", err)
+		}
+
+		for _, rs := range data.ResourceSpans {
+			service := ""
+			for _, kv := range rs.Resource.Attributes {
+				if kv.Key == "service.name" {
+					service = kv.string()
+				}
+			}
+			for _, ss := range append(rs.ScopeSpans, rs.InstrumentationLibrarySpans...) {
+				for _, s := range ss.Spans {
+					span := &models.Span{
+						SpanID:        s.SpanID,
+						TraceID:       s.TraceID,
+						ParentSpanID:  s.ParentSpanID,
+						OperationName: s.Name,
+						ServiceName:   service,
+						StartTime:     time.Unix(0, int64(s.StartTimeUnixNano)),
+						EndTime:       time.Unix(0, int64(s.EndTimeUnixNano)),
+						Attributes:    make(map[string]string, len(s.Attributes)),
+						Status:        otlpStatus(s.Status.Code),
+					}
+					if s.EndTimeUnixNano > s.StartTimeUnixNano {
+						span.Duration = int64(s.EndTimeUnixNano - s.StartTimeUnixNano)
+					}
+					for _, kv := range s.Attributes {
+						span.Attributes[kv.Key] = kv.string()
+					}
+					spans = append(spans, span)
+				}
+			}
+		}
+	}
+}
+
+// otlpStatus maps an OTLP status code to the span status rules see
+func otlpStatus(code json.RawMessage) string {
+	switch string(bytes.Trim(code, `"`)) {
+	case "2", "STATUS_CODE_ERROR":
+		return "ERROR"
+	}
+	return "OK"
+}
+
+// GroupTraces groups spans by trace, in the order each trace first appears
+func GroupTraces(spans []*models.Span) [][]*models.Span {
+	index := make(map[string]int)
+	var traces [][]*models.Span
+	for _, span := range spans {
+		i, ok := index[span.TraceID]
+		if !ok {
+			i = len(traces)
+			index[span.TraceID] = i
+			traces = append(traces, nil)
+		}
+		traces[i] = append(traces[i], span)
+	}
+	return traces
+}
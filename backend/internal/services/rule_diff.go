@@ -0,0 +1,114 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// RuleFieldChange is a rule field that differs between two versions
+type RuleFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Diff line operations
+const (
+	DiffEqual  = " "
+	DiffDelete = "-"
+	DiffInsert = "+"
+)
+
+// DiffLine is one line of a line-by-line diff
+type DiffLine struct {
+	Op   string `json:"op"` // DiffEqual, DiffDelete or DiffInsert
+	Text string `json:"text"`
+}
+
+// DiffRules lists the fields other than the expression that differ between
+// two versions of a rule. Lists are compared as comma-separated values.
+func DiffRules(from, to models.Rule) []RuleFieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"description", from.Description, to.Description},
+		{"severity", from.Severity, to.Severity},
+		{"enabled", strconv.FormatBool(from.Enabled), strconv.FormatBool(to.Enabled)},
+		{"mode", from.EffectiveMode(), to.EffectiveMode()},
+		{"tags", strings.Join(from.Tags, ","), strings.Join(to.Tags, ",")},
+		{"controls", joinControls(from.Controls), joinControls(to.Controls)},
+	}
+
+	var changes []RuleFieldChange
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, RuleFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+// joinControls formats control mappings as "framework:control" values
+func joinControls(controls []models.ComplianceControl) string {
+	parts := make([]string, len(controls))
+	for i, c := range controls {
+		parts[i] = c.Framework
+		if c.ControlID != "" {
+			parts[i] += ":" + c.ControlID
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// DiffLines returns a minimal line-by-line diff turning from into to, or nil
+// if they are equal. Rule expressions are short, so the quadratic longest
+// common subsequence is fine.
+func DiffLines(from, to string) []DiffLine {
+	if from == to {
+		return nil
+	}
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+	return diff
+}
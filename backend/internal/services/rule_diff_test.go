@@ -0,0 +1,42 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string
+	}{
+		{"equal", "when { a }", "when { a }", "[]"},
+		{"changed line", "when { a }\nalways { b }", "when { a }\nalways { c }", "[{  when { a }} {- always { b }} {+ always { c }}]"},
+		{"added line", "when { a }", "when { a }\nnever { d }", "[{  when { a }} {+ never { d }}]"},
+		{"removed line", "when { a }\nnever { d }\nalways { b }", "when { a }\nalways { b }", "[{  when { a }} {- never { d }} {  always { b }}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(DiffLines(tt.from, tt.to)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffRules(t *testing.T) {
+	from := models.Rule{Name: "mfa", Expression: "when { a } always { b }", Severity: "HIGH", Enabled: true, Tags: []string{"auth"}}
+	to := from
+	to.Expression = "when { a } always { c }"
+	to.Enabled = false
+	to.Controls = []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}, {Framework: "hipaa"}}
+
+	changes := DiffRules(from, to)
+	expected := "[{enabled true false} {controls  soc2:CC6.1,hipaa}]"
+	if fmt.Sprint(changes) != expected {
+		t.Errorf("Expected %s (the expression is diffed separately), got %v", expected, changes)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// DefaultTraceArchiveRetention is how long TraceArchive keeps traces
+const DefaultTraceArchiveRetention = 7 * 24 * time.Hour
+
+// traceArchiveFileLayout names the file of each day's traces
+const traceArchiveFileLayout = "2006-01-02"
+
+// ArchivedTrace is one completed trace as BeTrace evaluated it
+type ArchivedTrace struct {
+	TraceID    string         `json:"traceId"`
+	ArchivedAt time.Time      `json:"archivedAt"`
+	Spans      []*models.Span `json:"spans"`
+}
+
+// TraceArchive appends the traces BeTrace evaluates to one JSON-lines file
+// per day, so rules can be backtested against real traffic. Days older than
+// the retention are deleted when a new day's file is opened.
+type TraceArchive struct {
+	dir       string
+	retention time.Duration
+
+	mu   sync.Mutex
+	day  string // Day of the open file
+	file *os.File
+}
+
+// NewTraceArchive creates an archive in dir, keeping traces for retention
+// (0 uses DefaultTraceArchiveRetention)
+func NewTraceArchive(dir string, retention time.Duration) (*TraceArchive, error) {
+	if retention <= 0 {
+		retention = DefaultTraceArchiveRetention
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace archive: %w", err)
+	}
+	return &TraceArchive{dir: dir, retention: retention}, nil
+}
+
+// Record appends a completed trace to the file of the day at falls on
+func (a *TraceArchive) Record(traceID string, spans []*models.Span, at time.Time) error {
+	line, err := json.Marshal(ArchivedTrace{TraceID: traceID, ArchivedAt: at.UTC(), Spans: spans})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if day := at.UTC().Format(traceArchiveFileLayout); day != a.day {
+		if err := a.openLocked(day); err != nil {
+			return err
+		}
+	}
+	// One write per line, so readers never see a torn trace mid-file
+	_, err = a.file.Write(line)
+	return err
+}
+
+// openLocked switches to the file of day and prunes expired days
+func (a *TraceArchive) openLocked(day string) error {
+	if a.file != nil {
+		a.file.Close()
+		a.file = nil
+	}
+	file, err := os.OpenFile(filepath.Join(a.dir, day+".jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace archive: %w", err)
+	}
+	a.file, a.day = file, day
+
+	files, err := a.days()
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().UTC().Add(-a.retention).Format(traceArchiveFileLayout)
+	for _, d := range files {
+		if d < cutoff && d != day {
+			os.Remove(filepath.Join(a.dir, d+".jsonl"))
+		}
+	}
+	return nil
+}
+
+// days lists the archived days, oldest first
+func (a *TraceArchive) days() ([]string, error) {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for _, e := range entries {
+		day, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if _, err := time.Parse(traceArchiveFileLayout, day); ok && err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// Files returns the archive files that may hold traces archived in
+// [since, until), oldest first
+func (a *TraceArchive) Files(since, until time.Time) ([]string, error) {
+	days, err := a.days()
+	if err != nil {
+		return nil, err
+	}
+	first := since.UTC().Format(traceArchiveFileLayout)
+	last := until.UTC().Format(traceArchiveFileLayout)
+	var files []string
+	for _, day := range days {
+		if day >= first && day <= last {
+			files = append(files, filepath.Join(a.dir, day+".jsonl"))
+		}
+	}
+	return files, nil
+}
+
+// Close closes the current day's file
+func (a *TraceArchive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file, a.day = nil, ""
+	return err
+}
+
+// ReadArchivedTraces calls fn with each trace of an archive file, stopping
+// at the first error. A partial last line (still being written) is skipped.
+func ReadArchivedTraces(r io.Reader, fn func(ArchivedTrace) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var trace ArchivedTrace
+		if err := json.Unmarshal(line, &trace); err != nil {
+			return fmt.Errorf("corrupt archived trace: %w", err)
+		}
+		if err := fn(trace); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// ErrSubscriberLagged ends a watch whose subscriber fell too far behind the
+// live stream. The client should reconnect with its last cursor; the missed
+// violations are replayed from the store.
+var ErrSubscriberLagged = errors.New("violation subscriber fell behind")
+
+// DefaultSubscriberBuffer is the number of live violations buffered per subscriber
+const DefaultSubscriberBuffer = 256
+
+// ViolationHub fans newly recorded violations out to live subscribers.
+//
+// Publish never blocks: a subscriber whose buffer is full is cut off (its
+// Lagged channel closes) rather than slowing down rule evaluation.
+type ViolationHub struct {
+	bufferSize int
+
+	mu   sync.RWMutex
+	subs map[*ViolationSubscription]struct{}
+}
+
+// ViolationSubscription receives live violations matching its filters
+type ViolationSubscription struct {
+	hub    *ViolationHub
+	query  storage.ViolationQuery
+	events chan models.Violation
+	lagged chan struct{}
+	once   sync.Once
+}
+
+// NewViolationHub creates a hub buffering bufferSize violations per subscriber
+func NewViolationHub(bufferSize int) *ViolationHub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultSubscriberBuffer
+	}
+	return &ViolationHub{
+		bufferSize: bufferSize,
+		subs:       make(map[*ViolationSubscription]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber for violations matching filters
+// (RuleID, Severity, Tags, ...; paging fields are ignored)
+func (h *ViolationHub) Subscribe(filters QueryFilters) *ViolationSubscription {
+	sub := &ViolationSubscription{
+		hub: h,
+		query: storage.ViolationQuery{
+			RuleID:      filters.RuleID,
+			Severity:    filters.Severity,
+			TraceID:     filters.TraceID,
+			Fingerprint: filters.Fingerprint,
+			Assignee:    filters.Assignee,
+			Status:      filters.Status,
+			ServiceName: filters.ServiceName,
+			Tags:        filters.Tags,
+		},
+		events: make(chan models.Violation, h.bufferSize),
+		lagged: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish delivers v to every matching subscriber without blocking
+func (h *ViolationHub) Publish(v models.Violation) {
+	h.mu.RLock()
+	var lagging []*ViolationSubscription
+	for sub := range h.subs {
+		if !sub.query.Matches(&v) {
+			continue
+		}
+		select {
+		case sub.events <- v:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range lagging {
+		sub.cutOff()
+	}
+}
+
+// Subscribers returns the number of active subscribers
+func (h *ViolationHub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+func (h *ViolationHub) remove(sub *ViolationSubscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+}
+
+// Events returns the subscriber's live violations
+func (s *ViolationSubscription) Events() <-chan models.Violation {
+	return s.events
+}
+
+// Lagged is closed when the subscriber was cut off for falling behind
+func (s *ViolationSubscription) Lagged() <-chan struct{} {
+	return s.lagged
+}
+
+// Close unsubscribes
+func (s *ViolationSubscription) Close() {
+	s.hub.remove(s)
+}
+
+func (s *ViolationSubscription) cutOff() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+		close(s.lagged)
+	})
+}
+
+// ViolationEvent is one violation delivered to a watcher, with the cursor to
+// resume after it
+type ViolationEvent struct {
+	Violation models.Violation
+	Cursor    string
+}
+
+// WatchViolations sends every violation matching filters to send: first
+// those recorded after filters.Cursor (if set), oldest first, then live ones
+// from hub. It returns when ctx is done, send fails, or the subscriber lags
+// (ErrSubscriberLagged).
+func WatchViolations(ctx context.Context, store ViolationStore, hub *ViolationHub, filters QueryFilters, send func(ViolationEvent) error) error {
+	// Subscribe before replaying so nothing recorded meanwhile is missed;
+	// the overlap is skipped below
+	sub := hub.Subscribe(filters)
+	defer sub.Close()
+
+	var last *storage.ViolationCursor
+	if filters.Cursor != "" {
+		replay := filters
+		replay.Order = OrderOldestFirst
+		replay.Limit = 1000
+		for {
+			page, err := store.QueryPage(ctx, replay)
+			if err != nil {
+				return err
+			}
+			for _, v := range page.Violations {
+				cursor := cursorOf(v)
+				if err := send(ViolationEvent{Violation: v, Cursor: encodeCursor(cursor)}); err != nil {
+					return err
+				}
+				last = cursor
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			replay.Cursor = page.NextCursor
+		}
+		if last == nil {
+			// Nothing new since the client's cursor: skip live events up to it
+			var err error
+			if last, err = decodeCursor(filters.Cursor); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.Lagged():
+			return ErrSubscriberLagged
+		case v := <-sub.Events():
+			cursor := cursorOf(v)
+			if last != nil && !newerThan(cursor, last) {
+				continue // Already replayed
+			}
+			if err := send(ViolationEvent{Violation: v, Cursor: encodeCursor(cursor)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func cursorOf(v models.Violation) *storage.ViolationCursor {
+	return &storage.ViolationCursor{CreatedAt: v.CreatedAt, ID: v.ID}
+}
+
+// newerThan reports whether a comes after b in oldest-first order
+func newerThan(a, b *storage.ViolationCursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
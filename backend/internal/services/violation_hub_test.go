@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestViolationHub_FiltersSubscribers(t *testing.T) {
+	hub := NewViolationHub(10)
+	high := hub.Subscribe(QueryFilters{Severity: "HIGH"})
+	defer high.Close()
+	pci := hub.Subscribe(QueryFilters{RuleID: "rule-1", Tags: []string{"pci"}})
+	defer pci.Close()
+
+	hub.Publish(models.Violation{ID: "v-1", RuleID: "rule-1", Severity: "HIGH", Tags: []string{"pci"}})
+	hub.Publish(models.Violation{ID: "v-2", RuleID: "rule-2", Severity: "HIGH"})
+	hub.Publish(models.Violation{ID: "v-3", RuleID: "rule-1", Severity: "LOW"})
+
+	if got := drainIDs(high.Events()); len(got) != 2 || got[0] != "v-1" || got[1] != "v-2" {
+		t.Errorf("Expected HIGH subscriber to get [v-1 v-2], got %v", got)
+	}
+	if got := drainIDs(pci.Events()); len(got) != 1 || got[0] != "v-1" {
+		t.Errorf("Expected pci subscriber to get [v-1], got %v", got)
+	}
+}
+
+func TestViolationHub_SlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := NewViolationHub(2)
+	slow := hub.Subscribe(QueryFilters{})
+	defer slow.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			hub.Publish(models.Violation{ID: "v"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	select {
+	case <-slow.Lagged():
+	default:
+		t.Error("Expected slow subscriber to be cut off")
+	}
+	if hub.Subscribers() != 0 {
+		t.Errorf("Expected lagged subscriber to be removed, got %d subscribers", hub.Subscribers())
+	}
+}
+
+func TestWatchViolations_ResumesFromCursor(t *testing.T) {
+	store := NewViolationStoreMemory("test-key")
+	hub := NewViolationHub(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	base := time.Now()
+	var recorded []models.Violation
+	for i := 0; i < 3; i++ {
+		v, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "m", CreatedAt: base.Add(time.Duration(i) * time.Second)}, nil)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		recorded = append(recorded, v)
+	}
+
+	// First connection sees v0 live and disconnects
+	events := make(chan ViolationEvent, 10)
+	watch := func(ctx context.Context, cursor string) chan error {
+		errc := make(chan error, 1)
+		go func() {
+			errc <- WatchViolations(ctx, store, hub, QueryFilters{RuleID: "rule-1", Cursor: cursor}, func(e ViolationEvent) error {
+				events <- e
+				return nil
+			})
+		}()
+		return errc
+	}
+
+	firstCtx, firstCancel := context.WithCancel(ctx)
+	errc := watch(firstCtx, "")
+	waitForSubscribers(t, hub, 1)
+	hub.Publish(recorded[0])
+	first := <-events
+	if first.Violation.ID != recorded[0].ID {
+		t.Fatalf("Expected live %s, got %s", recorded[0].ID, first.Violation.ID)
+	}
+	firstCancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// Reconnect: v1 and v2 are replayed, a duplicate live v2 is skipped, v3 is new
+	errc = watch(ctx, first.Cursor)
+	var got []string
+	for i := 0; i < 2; i++ {
+		got = append(got, (<-events).Violation.ID)
+	}
+	waitForSubscribers(t, hub, 1)
+	hub.Publish(recorded[2])
+	v3, _ := store.Record(ctx, models.Violation{RuleID: "rule-1", Severity: "HIGH", Message: "m", CreatedAt: base.Add(3 * time.Second)}, nil)
+	hub.Publish(v3)
+	got = append(got, (<-events).Violation.ID)
+
+	want := []string{recorded[1].ID, recorded[2].ID, v3.ID}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+	cancel()
+	<-errc
+}
+
+func TestWatchViolations_Lagged(t *testing.T) {
+	store := NewViolationStoreMemory("test-key")
+	hub := NewViolationHub(1)
+
+	block := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- WatchViolations(context.Background(), store, hub, QueryFilters{}, func(ViolationEvent) error {
+			<-block
+			return nil
+		})
+	}()
+	waitForSubscribers(t, hub, 1)
+	for i := 0; i < 10; i++ {
+		hub.Publish(models.Violation{ID: "v", CreatedAt: time.Now()})
+	}
+	close(block)
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrSubscriberLagged) {
+			t.Errorf("Expected ErrSubscriberLagged, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected lagged watcher to stop")
+	}
+}
+
+func TestWatchViolations_InvalidCursor(t *testing.T) {
+	err := WatchViolations(context.Background(), NewViolationStoreMemory("test-key"), NewViolationHub(1), QueryFilters{Cursor: "!!"}, func(ViolationEvent) error { return nil })
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery, got %v", err)
+	}
+}
+
+func drainIDs(events <-chan models.Violation) []string {
+	var ids []string
+	for {
+		select {
+		case v := <-events:
+			ids = append(ids, v.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func waitForSubscribers(t *testing.T, hub *ViolationHub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d subscribers, got %d", n, hub.Subscribers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SignatureAlgEdDSA names the violation signature algorithm as in JWS
+// (RFC 8037): Ed25519 over CanonicalViolation, publicly verifiable
+const SignatureAlgEdDSA = "EdDSA"
+
+// ErrInvalidSignature is returned when a violation's signature doesn't verify
+var ErrInvalidSignature = errors.New("violation signature verification failed")
+
+// SigningKey is the public half of one violation signing key
+type SigningKey struct {
+	ID        string // RFC 7638 JWK thumbprint of the public key
+	PublicKey ed25519.PublicKey
+	CreatedAt time.Time
+	RetiredAt *time.Time // When a newer key took over; still valid for verification
+}
+
+// JWK is an Ed25519 public key in JSON Web Key form (RFC 8037)
+type JWK struct {
+	KeyType   string `json:"kty"` // OKP
+	Curve     string `json:"crv"` // Ed25519
+	X         string `json:"x"`   // base64url public key
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"` // EdDSA
+	Use       string `json:"use"` // sig
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Keyring holds the Ed25519 keys that sign violations. The newest key signs;
+// rotated-out keys stay in the ring so older violations keep verifying.
+// Keys persist as one file per key in the keyring directory.
+type Keyring struct {
+	dir string // Empty keeps keys in memory only
+
+	mu   sync.RWMutex
+	keys []keyringEntry // Oldest first; the last one is active
+}
+
+type keyringEntry struct {
+	SigningKey
+	private ed25519.PrivateKey
+}
+
+// keyFile is the on-disk form of one signing key
+type keyFile struct {
+	ID        string    `json:"id"`
+	Seed      string    `json:"seed"` // base64url Ed25519 private key seed
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewKeyring loads the signing keys in dir, generating the first key if
+// there are none
+func NewKeyring(dir string) (*Keyring, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keyring directory: %w", err)
+	}
+	k := &Keyring{dir: dir}
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	if len(k.keys) == 0 {
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// NewEphemeralKeyring creates a keyring with one fresh key that lives only in
+// memory, for development and tests
+func NewEphemeralKeyring() *Keyring {
+	k := &Keyring{}
+	if _, err := k.Rotate(); err != nil {
+		panic(err) // Unreachable: in-memory keys can't fail to persist
+	}
+	return k
+}
+
+func (k *Keyring) load() error {
+	paths, err := filepath.Glob(filepath.Join(k.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read signing key: %w", err)
+		}
+		var file keyFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("invalid signing key %s: %w", filepath.Base(path), err)
+		}
+		seed, err := base64.RawURLEncoding.DecodeString(file.Seed)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return fmt.Errorf("invalid signing key %s: bad seed", filepath.Base(path))
+		}
+		entry := newKeyringEntry(ed25519.NewKeyFromSeed(seed), file.CreatedAt)
+		if entry.ID != file.ID {
+			return fmt.Errorf("invalid signing key %s: id doesn't match key", filepath.Base(path))
+		}
+		k.keys = append(k.keys, entry)
+	}
+
+	sort.SliceStable(k.keys, func(i, j int) bool {
+		return k.keys[i].CreatedAt.Before(k.keys[j].CreatedAt)
+	})
+	for i := 1; i < len(k.keys); i++ {
+		retiredAt := k.keys[i].CreatedAt
+		k.keys[i-1].RetiredAt = &retiredAt
+	}
+	return nil
+}
+
+func newKeyringEntry(private ed25519.PrivateKey, createdAt time.Time) keyringEntry {
+	public := private.Public().(ed25519.PublicKey)
+	return keyringEntry{
+		SigningKey: SigningKey{
+			ID:        jwkThumbprint(public),
+			PublicKey: public,
+			CreatedAt: createdAt,
+		},
+		private: private,
+	}
+}
+
+// Rotate generates a new signing key. The previous key is retired but keeps
+// verifying the violations it signed.
+func (k *Keyring) Rotate() (SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	entry := newKeyringEntry(private, time.Now().UTC())
+
+	if k.dir != "" {
+		data, err := json.Marshal(keyFile{
+			ID:        entry.ID,
+			Seed:      base64.RawURLEncoding.EncodeToString(private.Seed()),
+			CreatedAt: entry.CreatedAt,
+		})
+		if err != nil {
+			return SigningKey{}, err
+		}
+		path := filepath.Join(k.dir, entry.ID+".json")
+		if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+			return SigningKey{}, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return SigningKey{}, fmt.Errorf("failed to write signing key: %w", err)
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if n := len(k.keys); n > 0 {
+		retiredAt := entry.CreatedAt
+		k.keys[n-1].RetiredAt = &retiredAt
+	}
+	k.keys = append(k.keys, entry)
+	return entry.SigningKey, nil
+}
+
+// RotateIfOlder rotates when the active key was created more than maxAge ago.
+// It reports whether a new key was generated; maxAge <= 0 never rotates.
+func (k *Keyring) RotateIfOlder(maxAge time.Duration) (bool, error) {
+	if maxAge <= 0 || time.Since(k.Active().CreatedAt) < maxAge {
+		return false, nil
+	}
+	if _, err := k.Rotate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Active returns the key that signs new violations
+func (k *Keyring) Active() SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[len(k.keys)-1].SigningKey
+}
+
+// Keys returns every key that verifies violations, oldest first
+func (k *Keyring) Keys() []SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	keys := make([]SigningKey, len(k.keys))
+	for i, entry := range k.keys {
+		keys[i] = entry.SigningKey
+	}
+	return keys
+}
+
+// JWKS returns the public keys as a JSON Web Key Set, for verifying
+// violation signatures offline
+func (k *Keyring) JWKS() JWKS {
+	keys := k.Keys()
+	set := JWKS{Keys: make([]JWK, len(keys))}
+	for i, key := range keys {
+		set.Keys[i] = publicJWK(key.PublicKey)
+		set.Keys[i].KeyID = key.ID
+		set.Keys[i].Algorithm = SignatureAlgEdDSA
+		set.Keys[i].Use = "sig"
+	}
+	return set
+}
+
+// Verify checks an Ed25519 signature made by the key keyID in the set,
+// for verifying exports offline
+func (s JWKS) Verify(keyID string, payload []byte, signature string) error {
+	for _, key := range s.Keys {
+		if key.KeyID != keyID {
+			continue
+		}
+		public, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil || len(public) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: invalid public key %q", ErrInvalidSignature, keyID)
+		}
+		ring := &Keyring{keys: []keyringEntry{{SigningKey: SigningKey{ID: keyID, PublicKey: public}}}}
+		return ring.verify(keyID, payload, signature)
+	}
+	return fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, keyID)
+}
+
+// sign signs payload with the active key, returning its ID and the
+// base64url signature
+func (k *Keyring) sign(payload []byte) (keyID, signature string) {
+	k.mu.RLock()
+	active := k.keys[len(k.keys)-1]
+	k.mu.RUnlock()
+	return active.ID, base64.RawURLEncoding.EncodeToString(ed25519.Sign(active.private, payload))
+}
+
+// verify checks an Ed25519 signature made by the key keyID
+func (k *Keyring) verify(keyID string, payload []byte, signature string) error {
+	k.mu.RLock()
+	var public ed25519.PublicKey
+	for _, entry := range k.keys {
+		if entry.ID == keyID {
+			public = entry.PublicKey
+			break
+		}
+	}
+	k.mu.RUnlock()
+
+	if public == nil {
+		return fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, keyID)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(public, payload, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func publicJWK(public ed25519.PublicKey) JWK {
+	return JWK{KeyType: "OKP", Curve: "Ed25519", X: base64.RawURLEncoding.EncodeToString(public)}
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint of an Ed25519 public key:
+// the SHA-256 of its required JWK members in lexicographic order
+func jwkThumbprint(public ed25519.PublicKey) string {
+	jwk := publicJWK(public)
+	members := fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, jwk.Curve, jwk.KeyType, jwk.X)
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
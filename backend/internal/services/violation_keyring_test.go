@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func TestKeyring_RotationKeepsOldKeysVerifying(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := NewKeyring(dir)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	store := NewViolationStoreMemory("test-key")
+	store.keys = keys
+	ctx := context.Background()
+
+	old, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Message: "before rotation"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	first := keys.Active()
+	rotated, err := keys.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if rotated.ID == first.ID {
+		t.Fatal("Expected rotation to generate a new key")
+	}
+
+	recent, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Message: "after rotation"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if old.SignatureKeyID != first.ID || recent.SignatureKeyID != rotated.ID {
+		t.Errorf("Expected signatures by %s then %s, got %s and %s", first.ID, rotated.ID, old.SignatureKeyID, recent.SignatureKeyID)
+	}
+	for _, v := range []models.Violation{old, recent} {
+		if _, err := store.GetByID(ctx, v.ID); err != nil {
+			t.Errorf("Expected %s to verify after rotation: %v", v.ID, err)
+		}
+	}
+
+	// Keys survive a restart, the retired one marked as such
+	reloaded, err := NewKeyring(dir)
+	if err != nil {
+		t.Fatalf("Reloading keyring failed: %v", err)
+	}
+	all := reloaded.Keys()
+	if len(all) != 2 || all[0].ID != first.ID || all[0].RetiredAt == nil || reloaded.Active().ID != rotated.ID {
+		t.Errorf("Expected retired %s and active %s after reload, got %+v", first.ID, rotated.ID, all)
+	}
+}
+
+func TestKeyring_RotateIfOlder(t *testing.T) {
+	keys := NewEphemeralKeyring()
+	active := keys.Active().ID
+
+	if rotated, err := keys.RotateIfOlder(time.Hour); err != nil || rotated {
+		t.Errorf("Expected a fresh key to be kept, got rotated=%v err=%v", rotated, err)
+	}
+	if rotated, err := keys.RotateIfOlder(0); err != nil || rotated {
+		t.Errorf("Expected maxAge 0 to disable rotation, got rotated=%v err=%v", rotated, err)
+	}
+	if rotated, err := keys.RotateIfOlder(time.Nanosecond); err != nil || !rotated || keys.Active().ID == active {
+		t.Errorf("Expected an expired key to rotate, got rotated=%v err=%v", rotated, err)
+	}
+}
+
+func TestSignature_CoversEvidenceFields(t *testing.T) {
+	store := NewViolationStoreMemory("test-key")
+	stored, err := store.Record(context.Background(), models.Violation{
+		RuleID:    "rule-1",
+		RuleName:  "PII access",
+		Severity:  "HIGH",
+		Message:   "unaudited access",
+		Tags:      []string{"pci"},
+		GroupKeys: map[string]string{"service": "checkout"},
+	}, []models.SpanRef{{TraceID: "trace-1", SpanID: "span-1", ServiceName: "checkout"}})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	tampers := map[string]func(v *models.Violation){
+		"severity":   func(v *models.Violation) { v.Severity = "LOW" },
+		"span refs":  func(v *models.Violation) { v.SpanRefs = []models.SpanRef{{TraceID: "trace-1", SpanID: "span-2"}} },
+		"trace ids":  func(v *models.Violation) { v.TraceIDs = []string{"trace-2"} },
+		"created at": func(v *models.Violation) { v.CreatedAt = v.CreatedAt.Add(-time.Hour) },
+		"tags":       func(v *models.Violation) { v.Tags = nil },
+		"group keys": func(v *models.Violation) { v.GroupKeys["service"] = "auth" },
+		"key id":     func(v *models.Violation) { v.SignatureKeyID = "" },
+		"evidence":   func(v *models.Violation) { v.Evidence = &models.EvidenceSnapshot{RuleExpression: "when { other }"} },
+	}
+	for name, tamper := range tampers {
+		t.Run(name, func(t *testing.T) {
+			v := stored
+			v.GroupKeys = map[string]string{"service": "checkout"}
+			tamper(&v)
+			if err := store.verifySignature(v); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Expected tampered %s to fail verification, got %v", name, err)
+			}
+		})
+	}
+
+	// Triage state is not evidence: it may change without breaking the signature
+	triaged := stored
+	triaged.Status = models.ViolationStatusResolved
+	triaged.Assignee = "alice"
+	if err := store.verifySignature(triaged); err != nil {
+		t.Errorf("Expected triage changes to keep the signature valid: %v", err)
+	}
+
+	// The same time in another location encodes identically
+	moved := stored
+	moved.CreatedAt = stored.CreatedAt.In(time.FixedZone("UTC+2", 2*60*60))
+	if err := store.verifySignature(moved); err != nil {
+		t.Errorf("Expected the signature to be independent of time zone: %v", err)
+	}
+}
+
+func TestSignature_VerifiableWithPublicKey(t *testing.T) {
+	store := NewViolationStoreMemory("test-key")
+	stored, err := store.Record(context.Background(), models.Violation{RuleID: "rule-1", Message: "offline"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// An auditor needs only the JWKS entry and the canonical payload
+	jwks := store.Keyring().JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != stored.SignatureKeyID || jwks.Keys[0].KeyType != "OKP" || jwks.Keys[0].Curve != "Ed25519" {
+		t.Fatalf("Unexpected JWKS %+v", jwks)
+	}
+	public, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+	if err != nil {
+		t.Fatalf("Invalid x: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(stored.Signature)
+	if err != nil {
+		t.Fatalf("Invalid signature encoding: %v", err)
+	}
+	if !ed25519.Verify(public, CanonicalViolation(stored), sig) {
+		t.Error("Expected the signature to verify with the published key")
+	}
+	if got := SignatureAlgorithm(stored); got != SignatureAlgEdDSA {
+		t.Errorf("Expected %s, got %s", SignatureAlgEdDSA, got)
+	}
+}
+
+// legacyHMAC signs v the way violations were signed before Ed25519
+func legacyHMAC(v models.Violation) string {
+	mac := hmac.New(sha256.New, []byte("signature-key"))
+	mac.Write([]byte(v.ID + v.RuleID + v.Message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignature_RejectsLegacyHMAC(t *testing.T) {
+	store := NewViolationStoreMemory("signature-key")
+	legacy := models.Violation{ID: "v-1", RuleID: "rule-1", Message: "recorded before Ed25519"}
+	legacy.Signature = legacyHMAC(legacy)
+
+	if err := store.verifySignature(legacy); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected an HMAC signature without a key ID to fail, got %v", err)
+	}
+	if got := SignatureAlgorithm(legacy); got != "" {
+		t.Errorf("Expected no algorithm for a violation without a key ID, got %s", got)
+	}
+
+	// Stripping the key ID from an Ed25519-signed violation doesn't downgrade it
+	stored, err := store.Record(context.Background(), models.Violation{RuleID: "rule-1", Message: "signed"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	stored.SignatureKeyID = ""
+	stored.Signature = legacyHMAC(stored)
+	if err := store.verifySignature(stored); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a stripped key ID to fail verification, got %v", err)
+	}
+}
@@ -0,0 +1,632 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// DefaultCheckpointEvery is how many entries are appended between automatic checkpoints
+const DefaultCheckpointEvery = 1000
+
+// MaxLedgerExport caps the entries in one export
+const MaxLedgerExport = 10000
+
+// Files of a persistent ledger
+const (
+	ledgerEntriesFile     = "entries.jsonl"
+	ledgerCheckpointsFile = "checkpoints.jsonl"
+)
+
+// ErrLedgerRange is returned for an export range outside the ledger
+var ErrLedgerRange = errors.New("invalid ledger range")
+
+// LedgerEntry records one violation in the ledger. Each entry hashes the
+// previous one, so removing or reordering entries breaks the chain.
+type LedgerEntry struct {
+	Seq         uint64    `json:"seq"` // 1-based position in the ledger
+	ViolationID string    `json:"violationId"`
+	Digest      string    `json:"digest"` // Hex SHA-256 of CanonicalViolation
+	RecordedAt  time.Time `json:"recordedAt"`
+	PrevHash    string    `json:"prevHash"` // Hash of the previous entry; zeros for the first
+	Hash        string    `json:"hash"`     // See LedgerEntryHash
+}
+
+// LedgerCheckpoint commits to the first Size entries with the root of a
+// Merkle tree over their hashes, signed with the violation signing keys
+type LedgerCheckpoint struct {
+	Size      uint64    `json:"size"`
+	Root      string    `json:"root"` // Hex Merkle root (RFC 6962) over the entry hashes
+	Head      string    `json:"head"` // Hash of entry Size
+	CreatedAt time.Time `json:"createdAt"`
+	KeyID     string    `json:"keyId,omitempty"`     // JWKS kid; empty if signing is disabled
+	Signature string    `json:"signature,omitempty"` // base64url Ed25519 over CheckpointPayload
+}
+
+// LedgerExport is a range of entries with proofs that each is included in a
+// signed checkpoint, verifiable offline with VerifyLedgerExport
+type LedgerExport struct {
+	Entries    []LedgerEntry    `json:"entries"`
+	Proofs     [][]string       `json:"proofs"` // Hex audit path of each entry against Checkpoint.Root
+	Checkpoint LedgerCheckpoint `json:"checkpoint"`
+}
+
+// zeroHash is the PrevHash of the first entry
+var zeroHash = strings.Repeat("0", sha256.Size*2)
+
+// LedgerEntryHash returns the hex SHA-256 of an entry's fields, one per line:
+// seq, violation ID, digest, recorded-at (RFC 3339, UTC, nanoseconds) and
+// previous hash
+func LedgerEntryHash(e LedgerEntry) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\n%s\n%s\n%s\n%s",
+		e.Seq, e.ViolationID, e.Digest, e.RecordedAt.UTC().Format(time.RFC3339Nano), e.PrevHash)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckpointPayload returns the bytes a checkpoint's signature covers
+func CheckpointPayload(c LedgerCheckpoint) []byte {
+	return []byte(fmt.Sprintf("betrace.ledger.checkpoint.v1\n%d\n%s\n%s\n%s",
+		c.Size, c.Root, c.Head, c.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// Ledger is an append-only, hash-chained record of violations with signed
+// periodic checkpoints. Per-violation signatures show a violation wasn't
+// altered; the ledger shows none were removed or reordered.
+//
+// A persistent ledger keeps entries and checkpoints as JSON lines in its
+// directory and verifies the whole chain when opened. It holds only the
+// Merkle frontier in memory and reads entries back from disk for exports.
+type Ledger struct {
+	keys            *Keyring // nil leaves checkpoints unsigned
+	checkpointEvery int
+
+	mu          sync.Mutex
+	size        uint64
+	head        string         // Hash of the last entry
+	tree        merkleFrontier // Over the entry hashes
+	ids         map[string]uint64
+	checkpoints []LedgerCheckpoint
+	entries     []LedgerEntry // Only kept by an in-memory ledger
+	entryFile   *os.File      // nil for an in-memory ledger
+	cpFile      *os.File
+}
+
+// NewLedger opens (or creates) the ledger in dir, or an in-memory ledger if
+// dir is empty. Checkpoints are cut every checkpointEvery entries (0 uses
+// DefaultCheckpointEvery) and signed with keys.
+func NewLedger(dir string, keys *Keyring, checkpointEvery int) (*Ledger, error) {
+	if checkpointEvery <= 0 {
+		checkpointEvery = DefaultCheckpointEvery
+	}
+	l := &Ledger{keys: keys, checkpointEvery: checkpointEvery, head: zeroHash, ids: make(map[string]uint64)}
+	if dir == "" {
+		return l, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+	var err error
+	if l.entryFile, err = openLedgerFile(filepath.Join(dir, ledgerEntriesFile), func(line []byte) error {
+		var e LedgerEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		l.add(e)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if l.cpFile, err = openLedgerFile(filepath.Join(dir, ledgerCheckpointsFile), func(line []byte) error {
+		var c LedgerCheckpoint
+		if err := json.Unmarshal(line, &c); err != nil {
+			return err
+		}
+		l.checkpoints = append(l.checkpoints, c)
+		return nil
+	}); err != nil {
+		l.entryFile.Close()
+		return nil, err
+	}
+
+	if err := l.Verify(); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// openLedgerFile replays a JSON lines file into decode and opens it for
+// appending. A torn last line (a crash mid-append) is truncated away.
+func openLedgerFile(path string, decode func(line []byte) error) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				log.Printf("Ledger %s: discarding torn line at offset %d", path, offset)
+				if err := file.Truncate(offset); err != nil {
+					file.Close()
+					return nil, fmt.Errorf("failed to truncate %s: %w", path, err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := decode(bytes.TrimSuffix(line, []byte("\n"))); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("corrupt ledger %s at offset %d: %w", path, offset, err)
+		}
+		offset += int64(len(line))
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// add indexes an entry and extends the Merkle frontier with it
+func (l *Ledger) add(e LedgerEntry) {
+	l.size++
+	l.head = e.Hash
+	l.tree.append(ledgerLeaf(e))
+	l.ids[e.ViolationID] = e.Seq
+}
+
+// ledgerLeaf returns an entry's Merkle leaf hash
+func ledgerLeaf(e LedgerEntry) []byte {
+	hash, _ := hex.DecodeString(e.Hash)
+	return merkleLeaf(hash)
+}
+
+// entryScanner returns a function calling fn with entries 1..n in order,
+// read from memory or from the entries written so far. Call it with l.mu
+// held; the returned function doesn't need the lock.
+func (l *Ledger) entryScanner() (func(n uint64, fn func(LedgerEntry) error) error, error) {
+	if l.entryFile == nil {
+		entries := l.entries
+		return func(n uint64, fn func(LedgerEntry) error) error {
+			for _, e := range entries[:n] {
+				if err := fn(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	}
+
+	path := l.entryFile.Name()
+	length, err := l.entryFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	return func(n uint64, fn func(LedgerEntry) error) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to read ledger: %w", err)
+		}
+		defer file.Close()
+
+		reader := bufio.NewReader(io.LimitReader(file, length))
+		for read := uint64(0); read < n; read++ {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read ledger entry %d: %w", read+1, err)
+			}
+			var e LedgerEntry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return fmt.Errorf("corrupt ledger entry %d: %w", read+1, err)
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// appendLine writes one JSON line and syncs it (no-op in memory). On failure
+// the partial write is truncated away.
+func appendLine(file *os.File, v interface{}) error {
+	if file == nil {
+		return nil
+	}
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		file.Truncate(offset)
+		file.Seek(offset, io.SeekStart)
+	}
+	return err
+}
+
+// Append records a violation, cutting a checkpoint every checkpointEvery entries
+func (l *Ledger) Append(v models.Violation) (LedgerEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	digest := sha256.Sum256(CanonicalViolation(v))
+	e := LedgerEntry{
+		Seq:         l.size + 1,
+		ViolationID: v.ID,
+		Digest:      hex.EncodeToString(digest[:]),
+		RecordedAt:  time.Now().UTC(),
+		PrevHash:    l.head,
+	}
+	e.Hash = LedgerEntryHash(e)
+
+	if err := appendLine(l.entryFile, e); err != nil {
+		return LedgerEntry{}, fmt.Errorf("failed to append to ledger: %w", err)
+	}
+	if l.entryFile == nil {
+		l.entries = append(l.entries, e)
+	}
+	l.add(e)
+
+	if e.Seq%uint64(l.checkpointEvery) == 0 {
+		if _, err := l.checkpoint(); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// Contains reports whether a violation is in the ledger
+func (l *Ledger) Contains(violationID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.ids[violationID]
+	return ok
+}
+
+// Size returns the number of entries
+func (l *Ledger) Size() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// Checkpoint commits to every entry appended so far. It returns the latest
+// checkpoint if nothing was appended since, and nil for an empty ledger.
+func (l *Ledger) Checkpoint() (*LedgerCheckpoint, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.checkpoint()
+}
+
+func (l *Ledger) checkpoint() (*LedgerCheckpoint, error) {
+	size := l.size
+	if n := len(l.checkpoints); n > 0 && l.checkpoints[n-1].Size == size {
+		c := l.checkpoints[n-1]
+		return &c, nil
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	c := LedgerCheckpoint{
+		Size:      size,
+		Root:      hex.EncodeToString(l.tree.root()),
+		Head:      l.head,
+		CreatedAt: time.Now().UTC(),
+	}
+	if l.keys != nil {
+		c.KeyID, c.Signature = l.keys.sign(CheckpointPayload(c))
+	}
+	if err := appendLine(l.cpFile, c); err != nil {
+		return nil, fmt.Errorf("failed to write ledger checkpoint: %w", err)
+	}
+	l.checkpoints = append(l.checkpoints, c)
+	return &c, nil
+}
+
+// Checkpoints returns every checkpoint, oldest first
+func (l *Ledger) Checkpoints() []LedgerCheckpoint {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LedgerCheckpoint(nil), l.checkpoints...)
+}
+
+// Export returns entries from..to (1-based, inclusive; to 0 = latest) with
+// inclusion proofs against the first checkpoint covering to, cutting a new
+// checkpoint if none does yet. Entries are read back from disk without
+// holding up appends.
+func (l *Ledger) Export(from, to uint64) (LedgerExport, error) {
+	checkpoint, scan, err := l.exportCheckpoint(from, &to)
+	if err != nil {
+		return LedgerExport{}, err
+	}
+
+	export := LedgerExport{
+		Entries:    make([]LedgerEntry, 0, to-from+1),
+		Checkpoint: checkpoint,
+	}
+	tree := newMerkleRange(checkpoint.Size, from-1, to-1)
+	err = scan(checkpoint.Size, func(e LedgerEntry) error {
+		if e.Seq >= from && e.Seq <= to {
+			export.Entries = append(export.Entries, e)
+		}
+		tree.add(ledgerLeaf(e))
+		return nil
+	})
+	if err != nil {
+		return LedgerExport{}, err
+	}
+
+	export.Proofs = make([][]string, 0, len(export.Entries))
+	for _, path := range tree.proofs() {
+		proof := make([]string, len(path))
+		for i, hash := range path {
+			proof[i] = hex.EncodeToString(hash)
+		}
+		export.Proofs = append(export.Proofs, proof)
+	}
+	return export, nil
+}
+
+// exportCheckpoint validates an export range, resolving to 0 to the latest
+// entry, and returns the checkpoint to prove it against with a scanner over
+// the entries it covers
+func (l *Ledger) exportCheckpoint(from uint64, to *uint64) (LedgerCheckpoint, func(uint64, func(LedgerEntry) error) error, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if *to == 0 {
+		*to = l.size
+	}
+	switch {
+	case from < 1 || from > *to || *to > l.size:
+		return LedgerCheckpoint{}, nil, fmt.Errorf("%w: %d..%d of %d entries", ErrLedgerRange, from, *to, l.size)
+	case *to-from+1 > MaxLedgerExport:
+		return LedgerCheckpoint{}, nil, fmt.Errorf("%w: at most %d entries per export", ErrLedgerRange, MaxLedgerExport)
+	}
+
+	var checkpoint *LedgerCheckpoint
+	for i := range l.checkpoints {
+		if l.checkpoints[i].Size >= *to {
+			checkpoint = &l.checkpoints[i]
+			break
+		}
+	}
+	if checkpoint == nil {
+		var err error
+		if checkpoint, err = l.checkpoint(); err != nil {
+			return LedgerCheckpoint{}, nil, err
+		}
+	}
+
+	scan, err := l.entryScanner()
+	if err != nil {
+		return LedgerCheckpoint{}, nil, err
+	}
+	return *checkpoint, scan, nil
+}
+
+// Verify checks the whole chain and that every checkpoint matches the entries
+// it covers and carries a valid signature
+func (l *Ledger) Verify() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bySize := make(map[uint64][]LedgerCheckpoint, len(l.checkpoints))
+	for _, c := range l.checkpoints {
+		bySize[c.Size] = append(bySize[c.Size], c)
+	}
+
+	scan, err := l.entryScanner()
+	if err != nil {
+		return err
+	}
+	var tree merkleFrontier
+	prev := zeroHash
+	err = scan(l.size, func(e LedgerEntry) error {
+		if err := verifyLedgerEntry(e, tree.size+1, prev); err != nil {
+			return err
+		}
+		prev = e.Hash
+		tree.append(ledgerLeaf(e))
+
+		for _, c := range bySize[e.Seq] {
+			if c.Head != e.Hash || c.Root != hex.EncodeToString(tree.root()) {
+				return &LedgerError{Seq: c.Size, Reason: "checkpoint doesn't match the entries it covers"}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range l.checkpoints {
+		if c.Size == 0 || c.Size > l.size {
+			return &LedgerError{Seq: c.Size, Reason: "checkpoint beyond the last entry: entries were removed"}
+		}
+		if l.keys != nil && c.KeyID != "" {
+			if err := l.keys.verify(c.KeyID, CheckpointPayload(c), c.Signature); err != nil {
+				return &LedgerError{Seq: c.Size, Reason: "checkpoint " + err.Error()}
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the ledger files
+func (l *Ledger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var errs []error
+	for _, file := range []*os.File{l.entryFile, l.cpFile} {
+		if file != nil {
+			errs = append(errs, file.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LedgerError reports where a ledger or export fails verification
+type LedgerError struct {
+	Seq    uint64
+	Reason string
+}
+
+func (e *LedgerError) Error() string {
+	return fmt.Sprintf("ledger entry %d: %s", e.Seq, e.Reason)
+}
+
+// verifyLedgerEntry checks an entry's position, link and hash
+func verifyLedgerEntry(e LedgerEntry, seq uint64, prevHash string) error {
+	switch {
+	case e.Seq != seq:
+		return &LedgerError{Seq: seq, Reason: fmt.Sprintf("found entry %d: entries are missing or out of order", e.Seq)}
+	case prevHash != "" && e.PrevHash != prevHash:
+		return &LedgerError{Seq: seq, Reason: "previous hash doesn't match: an entry was removed, inserted or reordered"}
+	case LedgerEntryHash(e) != e.Hash:
+		return &LedgerError{Seq: seq, Reason: "entry hash doesn't match its contents"}
+	}
+	return nil
+}
+
+// VerifyLedgerExport checks an export offline: entries are consecutive and
+// chained, each is included in the checkpoint, and the checkpoint is signed
+// by a key in keys (the JWKS published by the server)
+func VerifyLedgerExport(export LedgerExport, keys JWKS) error {
+	c := export.Checkpoint
+	if err := verifyCheckpointSignature(c, keys); err != nil {
+		return err
+	}
+	if len(export.Entries) == 0 {
+		return nil
+	}
+	if len(export.Proofs) != len(export.Entries) {
+		return &LedgerError{Seq: export.Entries[0].Seq, Reason: "export has one proof per entry"}
+	}
+	root, err := hex.DecodeString(c.Root)
+	if err != nil {
+		return &LedgerError{Seq: c.Size, Reason: "invalid checkpoint root"}
+	}
+
+	first := export.Entries[0].Seq
+	prev := "" // The first entry's predecessor isn't part of the export
+	if first == 1 {
+		prev = zeroHash
+	}
+	for i, e := range export.Entries {
+		seq := first + uint64(i)
+		if err := verifyLedgerEntry(e, seq, prev); err != nil {
+			return err
+		}
+		prev = e.Hash
+
+		hash, err := hex.DecodeString(e.Hash)
+		if err != nil {
+			return &LedgerError{Seq: seq, Reason: "invalid entry hash"}
+		}
+		proof := make([][]byte, len(export.Proofs[i]))
+		for j, node := range export.Proofs[i] {
+			if proof[j], err = hex.DecodeString(node); err != nil {
+				return &LedgerError{Seq: seq, Reason: "invalid proof"}
+			}
+		}
+		if !verifyMerkleProof(merkleLeaf(hash), seq-1, c.Size, proof, root) {
+			return &LedgerError{Seq: seq, Reason: "not included in the checkpoint"}
+		}
+		if seq == c.Size && e.Hash != c.Head {
+			return &LedgerError{Seq: seq, Reason: "checkpoint head doesn't match"}
+		}
+	}
+	return nil
+}
+
+// verifyCheckpointSignature checks a checkpoint's Ed25519 signature against the JWKS key it names
+func verifyCheckpointSignature(c LedgerCheckpoint, keys JWKS) error {
+	if c.KeyID == "" {
+		return &LedgerError{Seq: c.Size, Reason: "checkpoint is not signed"}
+	}
+	if err := keys.Verify(c.KeyID, CheckpointPayload(c), c.Signature); err != nil {
+		return &LedgerError{Seq: c.Size, Reason: "checkpoint " + err.Error()}
+	}
+	return nil
+}
+
+// LedgerStore decorates a ViolationStore, appending each recorded violation
+// to a ledger
+type LedgerStore struct {
+	ViolationStore
+	ledger *Ledger
+}
+
+// NewLedgerStore wraps store, first appending retained violations missing
+// from the ledger (oldest first), e.g. after a crash between storing and
+// appending or when the ledger is enabled on an existing store
+func NewLedgerStore(ctx context.Context, store ViolationStore, ledger *Ledger) (*LedgerStore, error) {
+	filters := QueryFilters{Order: OrderOldestFirst, Limit: 1000}
+	for {
+		page, err := store.QueryPage(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to backfill ledger: %w", err)
+		}
+		for _, v := range page.Violations {
+			if ledger.Contains(v.ID) {
+				continue
+			}
+			if _, err := ledger.Append(v); err != nil {
+				return nil, err
+			}
+		}
+		if page.NextCursor == "" {
+			return &LedgerStore{ViolationStore: store, ledger: ledger}, nil
+		}
+		filters.Cursor = page.NextCursor
+	}
+}
+
+// Ledger returns the ledger violations are appended to
+func (s *LedgerStore) Ledger() *Ledger {
+	return s.ledger
+}
+
+// Record stores a violation and appends it to the ledger
+func (s *LedgerStore) Record(ctx context.Context, violation models.Violation, traceRefs []models.SpanRef) (models.Violation, error) {
+	stored, err := s.ViolationStore.Record(ctx, violation, traceRefs)
+	if err != nil {
+		return stored, err
+	}
+	if _, err := s.ledger.Append(stored); err != nil {
+		return stored, fmt.Errorf("violation %s stored but not ledgered: %w", stored.ID, err)
+	}
+	return stored, nil
+}
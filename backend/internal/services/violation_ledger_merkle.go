@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// Merkle trees over ledger entries follow RFC 6962 (Certificate
+// Transparency): leaves and interior nodes are domain-separated, and a lone
+// node at the end of a level is promoted unchanged.
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+func merkleLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func merkleNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// merkleFrontier holds the roots of the perfect subtrees covering the
+// leaves appended so far, one per set bit of size, largest first. It yields
+// the root without keeping the leaves.
+type merkleFrontier struct {
+	size  uint64
+	nodes [][]byte
+}
+
+// append adds a leaf, merging the equal-sized subtrees it completes
+func (f *merkleFrontier) append(leaf []byte) {
+	f.nodes = append(f.nodes, leaf)
+	for n := f.size; n&1 == 1; n >>= 1 {
+		last := len(f.nodes) - 1
+		f.nodes = append(f.nodes[:last-1], merkleNode(f.nodes[last-1], f.nodes[last]))
+	}
+	f.size++
+}
+
+// root returns the tree's root: the subtrees folded from the right
+func (f *merkleFrontier) root() []byte {
+	if len(f.nodes) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+	root := f.nodes[len(f.nodes)-1]
+	for i := len(f.nodes) - 2; i >= 0; i-- {
+		root = merkleNode(f.nodes[i], root)
+	}
+	return root
+}
+
+// merkleRange collects the audit paths of leaves from..to (0-based,
+// inclusive) in a tree of size leaves added in order. Node j of level l
+// covers leaves [j<<l, (j+1)<<l), and a leaf's path holds one sibling per
+// level (none where the node is promoted), so only the nodes around the
+// range are kept.
+type merkleRange struct {
+	size, from, to uint64
+	widths         []uint64   // Nodes per level, leaves first
+	lows           []uint64   // Index of the first node kept per level
+	kept           [][][]byte // Kept nodes per level, from lows
+	stack          []merkleSubtree
+	added          uint64
+}
+
+// merkleSubtree is a perfect subtree not yet merged into a larger one
+type merkleSubtree struct {
+	level int
+	index uint64
+	hash  []byte
+}
+
+func newMerkleRange(size, from, to uint64) *merkleRange {
+	r := &merkleRange{size: size, from: from, to: to}
+	for w := size; ; w = (w + 1) / 2 {
+		r.widths = append(r.widths, w)
+		if w <= 1 {
+			break
+		}
+	}
+	r.lows = make([]uint64, len(r.widths))
+	r.kept = make([][][]byte, len(r.widths))
+	for l, w := range r.widths {
+		r.lows[l] = (from >> l) &^ 1
+		high := min((to>>l)|1, w-1)
+		r.kept[l] = make([][]byte, high-r.lows[l]+1)
+	}
+	return r
+}
+
+func (r *merkleRange) keep(l int, j uint64, hash []byte) {
+	if j >= r.lows[l] && j-r.lows[l] < uint64(len(r.kept[l])) {
+		r.kept[l][j-r.lows[l]] = hash
+	}
+}
+
+// add takes the next leaf, keeping the perfect subtrees it completes
+func (r *merkleRange) add(leaf []byte) {
+	r.keep(0, r.added, leaf)
+	r.stack = append(r.stack, merkleSubtree{index: r.added, hash: leaf})
+	r.added++
+	for n := len(r.stack); n >= 2 && r.stack[n-2].level == r.stack[n-1].level; n = len(r.stack) {
+		left, right := r.stack[n-2], r.stack[n-1]
+		parent := merkleSubtree{level: left.level + 1, index: left.index / 2, hash: merkleNode(left.hash, right.hash)}
+		r.keep(parent.level, parent.index, parent.hash)
+		r.stack = append(r.stack[:n-2], parent)
+	}
+}
+
+// proofs returns the audit paths once all size leaves are added
+func (r *merkleRange) proofs() [][][]byte {
+	// The last node of a level may cover fewer leaves than a perfect
+	// subtree: it folds the trailing subtrees left on the stack
+	for l := 1; l < len(r.widths); l++ {
+		j := r.widths[l] - 1
+		if (j+1)<<l <= r.size {
+			continue
+		}
+		var hash []byte
+		for k := len(r.stack) - 1; k >= 0 && r.stack[k].index<<r.stack[k].level >= j<<l; k-- {
+			if hash == nil {
+				hash = r.stack[k].hash
+			} else {
+				hash = merkleNode(r.stack[k].hash, hash)
+			}
+		}
+		r.keep(l, j, hash)
+	}
+
+	proofs := make([][][]byte, 0, r.to-r.from+1)
+	for i := r.from; i <= r.to; i++ {
+		var proof [][]byte
+		for l := 0; l < len(r.widths)-1; l++ {
+			if sibling := (i >> l) ^ 1; sibling < r.widths[l] {
+				proof = append(proof, r.kept[l][sibling-r.lows[l]])
+			}
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs
+}
+
+// verifyMerkleProof checks that leaf is at index in a tree of size leaves
+// with the given root
+func verifyMerkleProof(leaf []byte, index, size uint64, proof [][]byte, root []byte) bool {
+	if index >= size {
+		return false
+	}
+	hash := leaf
+	for width := size; width > 1; width = (width + 1) / 2 {
+		switch {
+		case index%2 == 1:
+			if len(proof) == 0 {
+				return false
+			}
+			hash = merkleNode(proof[0], hash)
+			proof = proof[1:]
+		case index+1 < width:
+			if len(proof) == 0 {
+				return false
+			}
+			hash = merkleNode(hash, proof[0])
+			proof = proof[1:]
+		}
+		index /= 2
+	}
+	return len(proof) == 0 && bytes.Equal(hash, root)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+func appendViolations(t *testing.T, l *Ledger, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(models.Violation{ID: fmt.Sprintf("v-%d", l.Size()+1), RuleID: "rule-1"}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+}
+
+func TestMerkleProof_AllSizes(t *testing.T) {
+	for size := uint64(1); size <= 17; size++ {
+		var leaves [][]byte
+		var tree merkleFrontier
+		for i := uint64(0); i < size; i++ {
+			leaves = append(leaves, merkleLeaf([]byte{byte(i)}))
+			tree.append(leaves[i])
+		}
+		root := tree.root()
+		if size == 3 && !bytes.Equal(root, merkleNode(merkleNode(leaves[0], leaves[1]), leaves[2])) {
+			t.Errorf("size 3: expected the RFC 6962 root")
+		}
+
+		for from := uint64(0); from < size; from++ {
+			for to := from; to < size; to++ {
+				r := newMerkleRange(size, from, to)
+				for _, leaf := range leaves {
+					r.add(leaf)
+				}
+				for i, proof := range r.proofs() {
+					index := from + uint64(i)
+					if !verifyMerkleProof(leaves[index], index, size, proof, root) {
+						t.Errorf("size %d, range %d..%d: proof of leaf %d doesn't verify", size, from, to, index)
+					}
+					if size > 1 && verifyMerkleProof(leaves[(index+1)%size], index, size, proof, root) {
+						t.Errorf("size %d: proof of leaf %d verifies another leaf", size, index)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLedger_ExportVerifies(t *testing.T) {
+	keys := NewEphemeralKeyring()
+	l, err := NewLedger("", keys, 4)
+	if err != nil {
+		t.Fatalf("NewLedger failed: %v", err)
+	}
+	appendViolations(t, l, 10)
+
+	if cps := l.Checkpoints(); len(cps) != 2 || cps[0].Size != 4 || cps[1].Size != 8 {
+		t.Fatalf("Expected checkpoints every 4 entries, got %+v", cps)
+	}
+
+	// Covered by an existing checkpoint
+	export, err := l.Export(2, 3)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if export.Checkpoint.Size != 4 || len(export.Entries) != 2 || export.Entries[0].Seq != 2 {
+		t.Errorf("Expected entries 2..3 against checkpoint 4, got %+v", export)
+	}
+	if err := VerifyLedgerExport(export, keys.JWKS()); err != nil {
+		t.Errorf("Expected export to verify: %v", err)
+	}
+
+	// Beyond the last checkpoint: a new one is cut
+	export, err = l.Export(1, 0)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if export.Checkpoint.Size != 10 || len(export.Entries) != 10 {
+		t.Errorf("Expected all entries against a new checkpoint, got size %d", export.Checkpoint.Size)
+	}
+	if err := VerifyLedgerExport(export, keys.JWKS()); err != nil {
+		t.Errorf("Expected export to verify: %v", err)
+	}
+	if err := l.Verify(); err != nil {
+		t.Errorf("Expected ledger to verify: %v", err)
+	}
+
+	for _, r := range [][2]uint64{{0, 1}, {5, 4}, {1, 11}} {
+		if _, err := l.Export(r[0], r[1]); !errors.Is(err, ErrLedgerRange) {
+			t.Errorf("Expected ErrLedgerRange for %v, got %v", r, err)
+		}
+	}
+}
+
+func TestVerifyLedgerExport_DetectsTampering(t *testing.T) {
+	keys := NewEphemeralKeyring()
+	l, _ := NewLedger("", keys, 100)
+	appendViolations(t, l, 6)
+
+	tests := map[string]func(e *LedgerExport){
+		"gap": func(e *LedgerExport) {
+			e.Entries = append(e.Entries[:2], e.Entries[3:]...)
+			e.Proofs = append(e.Proofs[:2], e.Proofs[3:]...)
+		},
+		"reordered": func(e *LedgerExport) {
+			e.Entries[1], e.Entries[2] = e.Entries[2], e.Entries[1]
+			e.Proofs[1], e.Proofs[2] = e.Proofs[2], e.Proofs[1]
+		},
+		"rewritten chain": func(e *LedgerExport) {
+			// Drop entry 3 and renumber and rehash the rest: only the checkpoint catches it
+			e.Entries = append(e.Entries[:2], e.Entries[3:]...)
+			e.Proofs = append(e.Proofs[:2], e.Proofs[3:]...)
+			for i := 2; i < len(e.Entries); i++ {
+				e.Entries[i].Seq = uint64(i) + 1
+				e.Entries[i].PrevHash = e.Entries[i-1].Hash
+				e.Entries[i].Hash = LedgerEntryHash(e.Entries[i])
+			}
+		},
+		"altered digest": func(e *LedgerExport) { e.Entries[0].Digest = zeroHash },
+		"forged checkpoint": func(e *LedgerExport) {
+			e.Checkpoint.Size = 5
+		},
+		"unsigned checkpoint": func(e *LedgerExport) { e.Checkpoint.KeyID, e.Checkpoint.Signature = "", "" },
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			export, err := l.Export(1, 6)
+			if err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			tamper(&export)
+			var ledgerErr *LedgerError
+			if err := VerifyLedgerExport(export, keys.JWKS()); !errors.As(err, &ledgerErr) {
+				t.Errorf("Expected a LedgerError, got %v", err)
+			}
+		})
+	}
+
+	// Keys from another deployment don't verify the checkpoint
+	export, _ := l.Export(1, 6)
+	if err := VerifyLedgerExport(export, NewEphemeralKeyring().JWKS()); err == nil {
+		t.Error("Expected verification with unknown keys to fail")
+	}
+}
+
+func TestLedger_PersistsAndDetectsDeletion(t *testing.T) {
+	dir := t.TempDir()
+	keys := NewEphemeralKeyring()
+	l, err := NewLedger(dir, keys, 3)
+	if err != nil {
+		t.Fatalf("NewLedger failed: %v", err)
+	}
+	appendViolations(t, l, 5)
+	l.Close()
+
+	reopened, err := NewLedger(dir, keys, 3)
+	if err != nil {
+		t.Fatalf("Reopening failed: %v", err)
+	}
+	if reopened.Size() != 5 || len(reopened.Checkpoints()) != 1 || !reopened.Contains("v-5") {
+		t.Errorf("Expected 5 entries and 1 checkpoint after reopening, got %d and %d", reopened.Size(), len(reopened.Checkpoints()))
+	}
+	appendViolations(t, reopened, 1)
+
+	// Exports read entries back from disk; only the Merkle frontier stays in memory
+	export, err := reopened.Export(2, 5)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if export.Checkpoint.Size != 6 || len(export.Entries) != 4 || export.Entries[0].ViolationID != "v-2" {
+		t.Errorf("Expected entries 2..5 against checkpoint 6, got %+v", export)
+	}
+	if err := VerifyLedgerExport(export, keys.JWKS()); err != nil {
+		t.Errorf("Expected export to verify: %v", err)
+	}
+	if len(reopened.entries) != 0 || len(reopened.tree.nodes) != 2 {
+		t.Errorf("Expected no entries and 2 frontier nodes in memory, got %d and %d", len(reopened.entries), len(reopened.tree.nodes))
+	}
+	reopened.Close()
+
+	// Deleting an entry from the file breaks the chain
+	path := filepath.Join(dir, ledgerEntriesFile)
+	data, _ := os.ReadFile(path)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	tampered := bytes.Join(append(lines[:1:1], lines[2:]...), nil)
+	if err := os.WriteFile(path, tampered, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	var ledgerErr *LedgerError
+	if _, err := NewLedger(dir, keys, 3); !errors.As(err, &ledgerErr) || ledgerErr.Seq != 2 {
+		t.Errorf("Expected deletion to be detected at entry 2, got %v", err)
+	}
+}
+
+func TestLedger_TruncatesTornLine(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := NewLedger(dir, nil, 0)
+	appendViolations(t, l, 2)
+	l.Close()
+
+	file, _ := os.OpenFile(filepath.Join(dir, ledgerEntriesFile), os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"seq":3,"violat`)
+	file.Close()
+
+	reopened, err := NewLedger(dir, nil, 0)
+	if err != nil {
+		t.Fatalf("Expected a torn line to be discarded: %v", err)
+	}
+	defer reopened.Close()
+	appendViolations(t, reopened, 1)
+	if err := reopened.Verify(); err != nil || reopened.Size() != 3 {
+		t.Errorf("Expected 3 valid entries, got %d (%v)", reopened.Size(), err)
+	}
+}
+
+func TestLedgerStore_RecordsAndBackfills(t *testing.T) {
+	ctx := context.Background()
+	inner := NewViolationStoreMemory("test-key")
+	early, err := inner.Record(ctx, models.Violation{RuleID: "rule-1", Message: "before the ledger"}, nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	ledger, _ := NewLedger("", inner.Keyring(), 0)
+	store, err := NewLedgerStore(ctx, inner, ledger)
+	if err != nil {
+		t.Fatalf("NewLedgerStore failed: %v", err)
+	}
+	if !ledger.Contains(early.ID) {
+		t.Error("Expected existing violations to be backfilled")
+	}
+
+	v, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Message: "ledgered"}, []models.SpanRef{{TraceID: "t", SpanID: "s"}})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	export, err := ledger.Export(2, 2)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if export.Entries[0].ViolationID != v.ID {
+		t.Errorf("Expected entry 2 to be %s, got %s", v.ID, export.Entries[0].ViolationID)
+	}
+	if err := VerifyLedgerExport(export, inner.Keyring().JWKS()); err != nil {
+		t.Errorf("Expected export to verify: %v", err)
+	}
+
+	// Reopening over the same store doesn't append twice
+	if _, err := NewLedgerStore(ctx, inner, ledger); err != nil || ledger.Size() != 2 {
+		t.Errorf("Expected 2 entries after rewrapping, got %d (%v)", ledger.Size(), err)
+	}
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/google/uuid"
+)
+
+// ViolationStore records and queries signed violations.
+// Implementations: ViolationStoreMemory (development), ViolationStoreDisk (durable).
+type ViolationStore interface {
+	// Record stores a violation with cryptographic signature and returns the stored violation with generated ID
+	Record(ctx context.Context, violation models.Violation, traceRefs []models.SpanRef) (models.Violation, error)
+	// Query retrieves one page of violations with optional filters (newest first by default)
+	Query(ctx context.Context, filters QueryFilters) ([]models.Violation, error)
+	// QueryPage is Query plus the total match count and a cursor for the next page
+	QueryPage(ctx context.Context, filters QueryFilters) (ViolationPage, error)
+	// GetByID retrieves a single violation by ID, verifying its signature
+	GetByID(ctx context.Context, id string) (*models.Violation, error)
+	// Triage changes a violation's status, assignee or comments and returns the result
+	Triage(ctx context.Context, id string, update TriageUpdate) (models.Violation, error)
+	// Close releases the store's resources
+	Close() error
+}
+
+// ErrInvalidQuery is returned for malformed query filters (bad cursor or order)
+var ErrInvalidQuery = errors.New("invalid violation query")
+
+var errInvalidCursor = fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+
+// Result orders for QueryFilters.Order
+const (
+	OrderNewestFirst = storage.OrderNewestFirst
+	OrderOldestFirst = storage.OrderOldestFirst
+)
+
+// QueryFilters defines violation query parameters
+type QueryFilters struct {
+	RuleID      string
+	Severity    string
+	TraceID     string
+	Fingerprint string
+	Status      string // Triage status
+	Assignee    string
+	ServiceName string
+	Tags        []string // Violation must carry every tag
+	Since       time.Time
+	Until       time.Time
+	Limit       int
+
+	Order  string // OrderNewestFirst (default) or OrderOldestFirst
+	Cursor string // NextCursor from the previous page
+}
+
+// ViolationPage is one page of query results
+type ViolationPage struct {
+	Violations []models.Violation
+	TotalCount int    // Matches across all pages
+	NextCursor string // Empty on the last page
+}
+
+// Validate checks the cursor and order without running the query
+func (f QueryFilters) Validate() error {
+	_, err := f.toStorage()
+	return err
+}
+
+func (f QueryFilters) toStorage() (storage.ViolationQuery, error) {
+	q := storage.ViolationQuery{
+		RuleID:      f.RuleID,
+		Severity:    f.Severity,
+		TraceID:     f.TraceID,
+		Fingerprint: f.Fingerprint,
+		Status:      f.Status,
+		Assignee:    f.Assignee,
+		ServiceName: f.ServiceName,
+		Tags:        f.Tags,
+		Since:       f.Since,
+		Until:       f.Until,
+		Limit:       f.Limit,
+		Order:       f.Order,
+	}
+
+	switch f.Order {
+	case "", OrderNewestFirst, OrderOldestFirst:
+	default:
+		return q, fmt.Errorf("%w: order %q (want %s or %s)", ErrInvalidQuery, f.Order, OrderNewestFirst, OrderOldestFirst)
+	}
+
+	if f.Cursor != "" {
+		cursor, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return q, err
+		}
+		q.After = cursor
+	}
+	return q, nil
+}
+
+// queryPage runs a query against a storage backend
+func queryPage(ctx context.Context, filters QueryFilters, search func(context.Context, storage.ViolationQuery) (storage.ViolationPage, error)) (ViolationPage, error) {
+	q, err := filters.toStorage()
+	if err != nil {
+		return ViolationPage{}, err
+	}
+
+	page, err := search(ctx, q)
+	if err != nil {
+		return ViolationPage{}, err
+	}
+
+	result := ViolationPage{Violations: page.Violations, TotalCount: page.Total}
+	if page.Next != nil {
+		result.NextCursor = encodeCursor(page.Next)
+	}
+	return result, nil
+}
+
+// Cursors are opaque to clients: base64url("<unix nanos>:<violation ID>")
+func encodeCursor(c *storage.ViolationCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*storage.ViolationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, errInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &storage.ViolationCursor{CreatedAt: time.Unix(0, n), ID: id}, nil
+}
+
+// violationSigner signs violations with Ed25519 for provenance
+type violationSigner struct {
+	keys *Keyring // nil disables signing and verification
+}
+
+// newViolationSigner signs with an in-memory keyring. An empty signatureKey
+// disables signing.
+func newViolationSigner(signatureKey string) violationSigner {
+	if signatureKey == "" {
+		return violationSigner{}
+	}
+	return violationSigner{keys: NewEphemeralKeyring()}
+}
+
+// Keyring returns the keys violations are signed with (nil if signing is disabled)
+func (s violationSigner) Keyring() *Keyring {
+	return s.keys
+}
+
+// StoreKeyring returns the keys store signs violations with, or nil if it
+// doesn't sign them
+func StoreKeyring(store ViolationStore) *Keyring {
+	if signer, ok := store.(interface{ Keyring() *Keyring }); ok {
+		return signer.Keyring()
+	}
+	return nil
+}
+
+// prepare fills in ID, timestamp, references and signature before storage
+func (s violationSigner) prepare(violation models.Violation, traceRefs []models.SpanRef) models.Violation {
+	// Generate ID if not provided
+	if violation.ID == "" {
+		violation.ID = uuid.New().String()
+	}
+
+	// Set timestamp
+	if violation.CreatedAt.IsZero() {
+		violation.CreatedAt = time.Now()
+	}
+
+	// New violations await triage
+	if violation.Status == "" {
+		violation.Status = models.ViolationStatusOpen
+	}
+
+	// Store references
+	violation.SpanRefs = traceRefs
+	violation.TraceIDs = uniqueTraceIDs(traceRefs)
+
+	// Sign violation for provenance, last so the signature covers everything
+	if s.keys != nil {
+		violation.SignatureKeyID, violation.Signature = s.signViolation(violation)
+	}
+	return violation
+}
+
+// verified returns v if its signature checks out
+func (s violationSigner) verified(v *models.Violation) (*models.Violation, error) {
+	if s.keys == nil {
+		return v, nil
+	}
+	if err := s.verifySignature(*v); err != nil {
+		return nil, fmt.Errorf("violation %s: %w", v.ID, err)
+	}
+	return v, nil
+}
+
+// signViolation signs the canonical encoding of v with the active key
+func (s violationSigner) signViolation(v models.Violation) (keyID, signature string) {
+	return s.keys.sign(CanonicalViolation(v))
+}
+
+// verifySignature checks violation signature integrity
+func (s violationSigner) verifySignature(v models.Violation) error {
+	return s.keys.VerifyViolation(v)
+}
+
+// VerifyViolation checks v's Ed25519 signature over its canonical encoding.
+// A violation without a key ID is rejected, whatever its signature.
+func (k *Keyring) VerifyViolation(v models.Violation) error {
+	if v.SignatureKeyID == "" {
+		return fmt.Errorf("%w: no signing key ID", ErrInvalidSignature)
+	}
+	return k.verify(v.SignatureKeyID, CanonicalViolation(v), v.Signature)
+}
+
+// SignatureAlgorithm names the algorithm v was signed with ("" if unsigned)
+func SignatureAlgorithm(v models.Violation) string {
+	if v.Signature == "" || v.SignatureKeyID == "" {
+		return ""
+	}
+	return SignatureAlgEdDSA
+}
+
+// canonicalViolationType versions the signed encoding
+const canonicalViolationType = "betrace.violation.v1"
+
+// signedViolation is the signed evidence of a violation: everything fixed at
+// detection. Triage state is left out because it changes afterwards.
+type signedViolation struct {
+	Type        string                   `json:"type"`
+	ID          string                   `json:"id"`
+	RuleID      string                   `json:"ruleId"`
+	RuleName    string                   `json:"ruleName"`
+	RuleVersion int                      `json:"ruleVersion,omitempty"`
+	Severity    string                   `json:"severity"`
+	Message     string                   `json:"message"`
+	TraceIDs    []string                 `json:"traceIds,omitempty"`
+	SpanRefs    []models.SpanRef         `json:"spanReferences,omitempty"`
+	Tags        []string                 `json:"tags,omitempty"`
+	CreatedAt   string                   `json:"createdAt"` // RFC 3339, UTC, nanoseconds
+	Fingerprint string                   `json:"fingerprint,omitempty"`
+	GroupKeys   map[string]string        `json:"groupKeys,omitempty"`
+	Explanation *models.RuleExplanation  `json:"explanation,omitempty"`
+	Evidence    *models.EvidenceSnapshot `json:"evidence,omitempty"`
+}
+
+// CanonicalViolation returns the bytes a violation's Ed25519 signature
+// covers: a JSON object of its detection-time fields in fixed order, with
+// map keys sorted and empty collections omitted. Verifiers can recompute it
+// from the violation, or check the payload VerifyViolation returns.
+func CanonicalViolation(v models.Violation) []byte {
+	payload, _ := json.Marshal(signedViolation{
+		Type:        canonicalViolationType,
+		ID:          v.ID,
+		RuleID:      v.RuleID,
+		RuleName:    v.RuleName,
+		RuleVersion: v.RuleVersion,
+		Severity:    v.Severity,
+		Message:     v.Message,
+		TraceIDs:    v.TraceIDs,
+		SpanRefs:    v.SpanRefs,
+		Tags:        v.Tags,
+		CreatedAt:   v.CreatedAt.UTC().Format(time.RFC3339Nano),
+		Fingerprint: v.Fingerprint,
+		GroupKeys:   v.GroupKeys,
+		Explanation: v.Explanation,
+		Evidence:    v.Evidence,
+	})
+	return payload
+}
+
+// uniqueTraceIDs returns the distinct trace IDs of refs in first-seen order
+func uniqueTraceIDs(refs []models.SpanRef) []string {
+	traceIDs := make([]string, 0, 1)
+	seen := make(map[string]struct{}, 1)
+	for _, ref := range refs {
+		if _, ok := seen[ref.TraceID]; ok {
+			continue
+		}
+		seen[ref.TraceID] = struct{}{}
+		traceIDs = append(traceIDs, ref.TraceID)
+	}
+	return traceIDs
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// ViolationStoreDisk persists signed violations in append-only segment files
+// that survive restarts, with count- and time-based retention
+type ViolationStoreDisk struct {
+	violationSigner
+	violationTriager
+	store *storage.DiskViolationStore
+}
+
+// NewViolationStoreDisk opens (or creates) a durable violation store in dir.
+// Violations are signed with the keyring in dir/keys, generated on first use;
+// an empty signatureKey disables signing.
+func NewViolationStoreDisk(dir, signatureKey string, opts storage.DiskViolationStoreOptions) (*ViolationStoreDisk, error) {
+	var keys *Keyring
+	if signatureKey != "" {
+		var err error
+		if keys, err = NewKeyring(filepath.Join(dir, "keys")); err != nil {
+			return nil, err
+		}
+	}
+	return NewViolationStoreDiskWithKeyring(dir, keys, opts)
+}
+
+// NewViolationStoreDiskWithKeyring opens (or creates) a durable violation
+// store in dir, signing with keys shared with another store (nil disables
+// signing)
+func NewViolationStoreDiskWithKeyring(dir string, keys *Keyring, opts storage.DiskViolationStoreOptions) (*ViolationStoreDisk, error) {
+	store, err := storage.NewDiskViolationStore(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ViolationStoreDisk{
+		violationSigner: violationSigner{keys: keys},
+		store:           store,
+	}, nil
+}
+
+// Record stores a violation with cryptographic signature and returns the stored violation with generated ID
+func (s *ViolationStoreDisk) Record(ctx context.Context, violation models.Violation, traceRefs []models.SpanRef) (models.Violation, error) {
+	violation = s.prepare(violation, traceRefs)
+	err := s.store.StoreViolation(ctx, violation)
+	return violation, err
+}
+
+// Query retrieves violations with optional filters
+func (s *ViolationStoreDisk) Query(ctx context.Context, filters QueryFilters) ([]models.Violation, error) {
+	page, err := s.QueryPage(ctx, filters)
+	return page.Violations, err
+}
+
+// QueryPage retrieves one page of violations with the total match count
+func (s *ViolationStoreDisk) QueryPage(ctx context.Context, filters QueryFilters) (ViolationPage, error) {
+	return queryPage(ctx, filters, s.store.SearchViolations)
+}
+
+// GetByID retrieves a single violation by ID
+func (s *ViolationStoreDisk) GetByID(ctx context.Context, id string) (*models.Violation, error) {
+	v, err := s.store.GetViolation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.verified(v)
+}
+
+// Triage changes a violation's status, assignee or comments
+func (s *ViolationStoreDisk) Triage(ctx context.Context, id string, update TriageUpdate) (models.Violation, error) {
+	return s.triage(ctx, id, update, s.GetByID, s.store.StoreViolation)
+}
+
+// Count returns the number of retained violations
+func (s *ViolationStoreDisk) Count() int {
+	return s.store.Count()
+}
+
+// Close stops background compaction and closes segment files
+func (s *ViolationStoreDisk) Close() error {
+	return s.store.Close()
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// Both backends satisfy the interface used by the gRPC and HTTP layers
+var (
+	_ ViolationStore = (*ViolationStoreMemory)(nil)
+	_ ViolationStore = (*ViolationStoreDisk)(nil)
+)
+
+func TestViolationStoreDisk_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewViolationStoreDisk(dir, "test-signature-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	recorded, err := store.Record(ctx, models.Violation{
+		RuleID:   "rule-123",
+		RuleName: "High Error Rate",
+		Severity: "HIGH",
+		Message:  "Error rate exceeds threshold",
+	}, []models.SpanRef{
+		{TraceID: "trace-1", SpanID: "span-1", Role: models.SpanRoleTrigger},
+		{TraceID: "trace-1", SpanID: "span-2", Role: models.SpanRoleForbidden},
+	})
+	if err != nil {
+		t.Fatalf("Failed to record violation: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+
+	reopened, err := NewViolationStoreDisk(dir, "test-signature-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.GetByID(ctx, recorded.ID)
+	if err != nil {
+		t.Fatalf("Failed to get violation after restart: %v", err)
+	}
+	if got.Signature != recorded.Signature || got.Signature == "" {
+		t.Errorf("Expected signature %q to survive restart, got %q", recorded.Signature, got.Signature)
+	}
+	if len(got.SpanRefs) != 2 || got.SpanRefs[1].Role != models.SpanRoleForbidden {
+		t.Errorf("Expected span refs with roles to survive restart, got %v", got.SpanRefs)
+	}
+
+	byTrace, err := reopened.Query(ctx, QueryFilters{TraceID: "trace-1"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(byTrace) != 1 || byTrace[0].ID != recorded.ID {
+		t.Errorf("Expected trace query to return %s, got %v", recorded.ID, byTrace)
+	}
+}
+
+func TestViolationStoreDisk_RejectsForeignSignature(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewViolationStoreDisk(dir, "key-1", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	recorded, err := store.Record(ctx, models.Violation{RuleID: "rule-1", Message: "Test"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to record violation: %v", err)
+	}
+	store.Close()
+
+	// Reopening with a different keyring must not accept the stored signature
+	if err := os.RemoveAll(filepath.Join(dir, "keys")); err != nil {
+		t.Fatalf("Failed to remove keyring: %v", err)
+	}
+	other, err := NewViolationStoreDisk(dir, "key-1", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer other.Close()
+
+	if _, err := other.GetByID(ctx, recorded.ID); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected signature verification to fail with a different key, got %v", err)
+	}
+}
+
+func TestViolationStoreDisk_SharesKeyring(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewViolationStoreDisk(t.TempDir(), "key-1", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	shadow, err := NewViolationStoreDiskWithKeyring(t.TempDir(), store.Keyring(), storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open shadow store: %v", err)
+	}
+	defer shadow.Close()
+
+	// Rotating the shared keyring moves both stores to the new key
+	rotated, err := store.Keyring().Rotate()
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	recorded, err := shadow.Record(ctx, models.Violation{RuleID: "rule-1", Message: "Test"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to record violation: %v", err)
+	}
+	if recorded.SignatureKeyID != rotated.ID {
+		t.Errorf("Expected signing by the rotated key %s, got %s", rotated.ID, recorded.SignatureKeyID)
+	}
+	if _, err := shadow.GetByID(ctx, recorded.ID); err != nil {
+		t.Errorf("Expected the violation to verify, got %v", err)
+	}
+}
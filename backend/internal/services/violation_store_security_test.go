@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	"github.com/betracehq/betrace/backend/pkg/models"
@@ -39,12 +41,19 @@ func TestViolationStoreMemory_SignatureTampering(t *testing.T) {
 
 	// Manually verify signature with tampered data
 	// This simulates what happens when GetByID verifies signature
-	if store.verifySignature(tamperedViolation) {
+	if store.verifySignature(tamperedViolation) == nil {
 		t.Error("Signature verification should FAIL for tampered violation")
 	}
 
+	// The rule version is signed too
+	tamperedViolation = stored
+	tamperedViolation.RuleVersion = 7
+	if store.verifySignature(tamperedViolation) == nil {
+		t.Error("Signature verification should FAIL for a violation attributed to another rule version")
+	}
+
 	// Original (untampered) should still verify
-	if !store.verifySignature(stored) {
+	if store.verifySignature(stored) != nil {
 		t.Error("Signature verification should PASS for original violation")
 	}
 }
@@ -73,17 +82,17 @@ func TestViolationStoreMemory_SignatureWithDifferentKeys(t *testing.T) {
 	store2 := NewViolationStoreMemory("key-version-2")
 
 	// Try to verify signature with different key
-	if store2.verifySignature(stored) {
+	if store2.verifySignature(stored) == nil {
 		t.Error("Signature verification should FAIL with different key")
 	}
 
 	// Verify original store can still verify
-	if !store1.verifySignature(stored) {
+	if store1.verifySignature(stored) != nil {
 		t.Error("Original store should verify its own signature")
 	}
 
 	// Verify signatures are deterministic for same key
-	signature2 := store1.signViolation(stored)
+	_, signature2 := store1.signViolation(stored)
 	if signature2 != signatureV1 {
 		t.Error("Same violation + same key should produce same signature")
 	}
@@ -146,13 +155,13 @@ func TestViolationStoreMemory_SignatureFieldTampering(t *testing.T) {
 	tamperedViolation := stored
 	tamperedViolation.Signature = "00000000000000000000000000000000"
 
-	if store.verifySignature(tamperedViolation) {
+	if store.verifySignature(tamperedViolation) == nil {
 		t.Error("Verification should fail when signature field is tampered")
 	}
 
 	// Restore original signature - should verify again
 	tamperedViolation.Signature = originalSig
-	if !store.verifySignature(tamperedViolation) {
+	if store.verifySignature(tamperedViolation) != nil {
 		t.Error("Verification should pass with original signature")
 	}
 }
@@ -174,37 +183,37 @@ func TestViolationStoreMemory_SignatureTimingAttack(t *testing.T) {
 		t.Fatalf("Failed to record: %v", err)
 	}
 
-	// Test multiple incorrect signatures
-	// hmac.Equal (used in verifySignature) is constant-time
-	// Use deterministic incorrect signatures that can never match a valid HMAC
+	// Test multiple incorrect signatures (ed25519.Verify doesn't leak timing)
+	// Use deterministic incorrect signatures that can never match a valid signature
 	incorrectSignatures := []string{
-		"0000000000000000000000000000000000000000000000000000000000000000",
-		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
-		"1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
-		"deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+		strings.Repeat("A", 86),
+		strings.Repeat("_", 86),
+		"not-base64!",
+		stored.Signature[:43],
 	}
 
 	for _, incorrectSig := range incorrectSignatures {
 		// Create a copy and modify signature
 		tamperedViolation := models.Violation{
-			ID:        stored.ID,
-			RuleID:    stored.RuleID,
-			RuleName:  stored.RuleName,
-			Severity:  stored.Severity,
-			Message:   stored.Message,
-			TraceIDs:  stored.TraceIDs,
-			SpanRefs:  stored.SpanRefs,
-			CreatedAt: stored.CreatedAt,
-			Signature: incorrectSig, // Use incorrect signature
+			ID:             stored.ID,
+			RuleID:         stored.RuleID,
+			RuleName:       stored.RuleName,
+			Severity:       stored.Severity,
+			Message:        stored.Message,
+			TraceIDs:       stored.TraceIDs,
+			SpanRefs:       stored.SpanRefs,
+			CreatedAt:      stored.CreatedAt,
+			SignatureKeyID: stored.SignatureKeyID,
+			Signature:      incorrectSig, // Use incorrect signature
 		}
 
-		if store.verifySignature(tamperedViolation) {
+		if store.verifySignature(tamperedViolation) == nil {
 			t.Errorf("Verification should fail for incorrect signature: %s", incorrectSig)
 		}
 	}
 
 	// Correct signature should verify
-	if !store.verifySignature(stored) {
+	if store.verifySignature(stored) != nil {
 		t.Error("Verification should pass for correct signature")
 	}
 }
@@ -245,15 +254,15 @@ func TestViolationStoreMemory_SignatureReplayAttack(t *testing.T) {
 	replayedViolation := stored2
 	replayedViolation.Signature = stored1.Signature
 
-	if store.verifySignature(replayedViolation) {
+	if store.verifySignature(replayedViolation) == nil {
 		t.Error("Verification should fail when signature is replayed from different violation")
 	}
 
 	// Each violation should verify with its own signature
-	if !store.verifySignature(stored1) {
+	if store.verifySignature(stored1) != nil {
 		t.Error("Violation1 should verify with its own signature")
 	}
-	if !store.verifySignature(stored2) {
+	if store.verifySignature(stored2) != nil {
 		t.Error("Violation2 should verify with its own signature")
 	}
 }
@@ -270,9 +279,9 @@ func TestViolationStoreMemory_SignatureDeterminism(t *testing.T) {
 	}
 
 	// Generate signature multiple times
-	sig1 := store.signViolation(violation)
-	sig2 := store.signViolation(violation)
-	sig3 := store.signViolation(violation)
+	_, sig1 := store.signViolation(violation)
+	_, sig2 := store.signViolation(violation)
+	_, sig3 := store.signViolation(violation)
 
 	// All signatures should be identical
 	if sig1 != sig2 {
@@ -284,7 +293,7 @@ func TestViolationStoreMemory_SignatureDeterminism(t *testing.T) {
 
 	// Modify one field - signature should change
 	violation.Message = "Modified message"
-	sig4 := store.signViolation(violation)
+	_, sig4 := store.signViolation(violation)
 
 	if sig1 == sig4 {
 		t.Error("Signature should change when violation data changes")
@@ -302,19 +311,18 @@ func TestViolationStoreMemory_SignatureLength(t *testing.T) {
 		Message:  "Test",
 	}
 
-	signature := store.signViolation(violation)
+	keyID, signature := store.signViolation(violation)
 
-	// HMAC-SHA256 produces 32-byte hash, hex-encoded = 64 characters
-	expectedLength := 64
+	// Ed25519 produces a 64-byte signature, unpadded base64url = 86 characters
+	expectedLength := 86
 	if len(signature) != expectedLength {
 		t.Errorf("Expected signature length %d, got %d", expectedLength, len(signature))
 	}
-
-	// Verify signature is valid hex
-	for _, c := range signature {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f')) {
-			t.Errorf("Signature should be lowercase hex, found char: %c", c)
-		}
+	if _, err := base64.RawURLEncoding.DecodeString(signature); err != nil {
+		t.Errorf("Signature should be unpadded base64url: %v", err)
+	}
+	if keyID != store.keys.Active().ID {
+		t.Errorf("Expected signature by the active key %s, got %s", store.keys.Active().ID, keyID)
 	}
 }
 
@@ -418,7 +426,7 @@ func BenchmarkViolationStore_VerifySignature(b *testing.B) {
 		Message:  "Benchmark message",
 	}
 
-	violation.Signature = store.signViolation(violation)
+	violation.SignatureKeyID, violation.Signature = store.signViolation(violation)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -90,9 +90,9 @@ func TestViolationStoreMemory_Signature(t *testing.T) {
 		t.Error("Expected signature to be generated, got empty string")
 	}
 
-	// Verify signature is HMAC-SHA256 (64 hex chars)
-	if len(stored.Signature) != 64 {
-		t.Errorf("Expected signature length 64, got %d", len(stored.Signature))
+	// Verify signature is Ed25519 (86 base64url chars) by a named key
+	if len(stored.Signature) != 86 || stored.SignatureKeyID == "" {
+		t.Errorf("Expected an Ed25519 signature with key ID, got %q by %q", stored.Signature, stored.SignatureKeyID)
 	}
 }
 
@@ -299,8 +299,8 @@ func TestViolationStoreMemory_SignatureVerification(t *testing.T) {
 		t.Error("Expected signature to be present")
 	}
 
-	// Verify signature is correct by checking it matches expected HMAC
-	if !store.verifySignature(*retrieved) {
+	// Verify signature is correct
+	if err := store.verifySignature(*retrieved); err != nil {
 		t.Error("Signature verification failed")
 	}
 }
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/fsm"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// ErrInvalidTriage is returned for malformed triage updates (unknown status,
+// missing actor, oversized comment). Disallowed status changes return an
+// *fsm.InvalidViolationTransitionError instead.
+var ErrInvalidTriage = errors.New("invalid triage update")
+
+// maxCommentLength bounds a single triage comment
+const maxCommentLength = 4096
+
+// TriageUpdate changes a violation's triage state. Zero-valued fields are left unchanged.
+type TriageUpdate struct {
+	Actor    string  // Who made the change (required)
+	Status   string  // Target status (models.ViolationStatus*)
+	Reason   string  // Recorded with the status change
+	Assignee *string // Set to "" to unassign
+	Comment  string  // Appended as a comment by Actor
+}
+
+// Validate checks the update without looking at any violation
+func (u TriageUpdate) Validate() error {
+	_, err := u.validate()
+	return err
+}
+
+func (u TriageUpdate) validate() (fsm.ViolationTriageEvent, error) {
+	var event fsm.ViolationTriageEvent
+	if u.Actor == "" {
+		return event, fmt.Errorf("%w: actor is required", ErrInvalidTriage)
+	}
+	if u.Status == "" && u.Assignee == nil && u.Comment == "" {
+		return event, fmt.Errorf("%w: nothing to change", ErrInvalidTriage)
+	}
+	if len(u.Comment) > maxCommentLength {
+		return event, fmt.Errorf("%w: comment exceeds %d bytes", ErrInvalidTriage, maxCommentLength)
+	}
+	if u.Status != "" {
+		var ok bool
+		if event, ok = fsm.ViolationEventTo(u.Status); !ok {
+			return event, fmt.Errorf("%w: unknown status %q", ErrInvalidTriage, u.Status)
+		}
+	}
+	return event, nil
+}
+
+// violationTriager serializes read-modify-write triage updates so concurrent
+// changes to one violation can't lose each other's history
+type violationTriager struct {
+	mu sync.Mutex
+}
+
+// triage applies update to the violation with id, reading it with get and
+// writing the result back with put (which supersedes the stored copy)
+func (t *violationTriager) triage(ctx context.Context, id string, update TriageUpdate,
+	get func(context.Context, string) (*models.Violation, error),
+	put func(context.Context, models.Violation) error,
+) (models.Violation, error) {
+	event, err := update.validate()
+	if err != nil {
+		return models.Violation{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, err := get(ctx, id)
+	if err != nil {
+		return models.Violation{}, err
+	}
+	v := *current
+	now := time.Now()
+
+	if update.Status != "" {
+		next, err := fsm.ViolationTransition(v.ID, v.Status, event)
+		if err != nil {
+			return models.Violation{}, err
+		}
+		v.StatusHistory = append(slices.Clone(v.StatusHistory), models.StatusChange{
+			From:      statusOrOpen(v.Status),
+			To:        next,
+			Actor:     update.Actor,
+			Reason:    update.Reason,
+			ChangedAt: now,
+		})
+		v.Status = next
+	}
+	if update.Assignee != nil {
+		v.Assignee = *update.Assignee
+	}
+	if update.Comment != "" {
+		v.Comments = append(slices.Clone(v.Comments), models.ViolationComment{
+			Author:    update.Actor,
+			Text:      update.Comment,
+			CreatedAt: now,
+		})
+	}
+	v.UpdatedAt = &now
+
+	if err := put(ctx, v); err != nil {
+		return models.Violation{}, err
+	}
+	return v, nil
+}
+
+// statusOrOpen maps the empty status of violations recorded before triage existed to open
+func statusOrOpen(status string) string {
+	if status == "" {
+		return models.ViolationStatusOpen
+	}
+	return status
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/internal/storage"
+	"github.com/betracehq/betrace/backend/pkg/fsm"
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// triageBackends runs a test against both violation store backends
+func triageBackends(t *testing.T, test func(t *testing.T, store ViolationStore)) {
+	t.Run("memory", func(t *testing.T) { test(t, NewViolationStoreMemory("test-key")) })
+	t.Run("disk", func(t *testing.T) {
+		store, err := NewViolationStoreDisk(t.TempDir(), "test-key", storage.DiskViolationStoreOptions{})
+		if err != nil {
+			t.Fatalf("Failed to open store: %v", err)
+		}
+		defer store.Close()
+		test(t, store)
+	})
+}
+
+func recordTriageViolation(t *testing.T, store ViolationStore) models.Violation {
+	t.Helper()
+	v, err := store.Record(context.Background(), models.Violation{
+		RuleID:   "rule-1",
+		RuleName: "Rule 1",
+		Severity: "HIGH",
+		Message:  "violation",
+	}, []models.SpanRef{{TraceID: "trace-1", SpanID: "span-1"}})
+	if err != nil {
+		t.Fatalf("Failed to record violation: %v", err)
+	}
+	return v
+}
+
+func TestTriage_Lifecycle(t *testing.T) {
+	triageBackends(t, func(t *testing.T, store ViolationStore) {
+		ctx := context.Background()
+		v := recordTriageViolation(t, store)
+		if v.Status != models.ViolationStatusOpen {
+			t.Errorf("Expected new violation to be open, got %q", v.Status)
+		}
+
+		alice := "alice"
+		if _, err := store.Triage(ctx, v.ID, TriageUpdate{Actor: "bob", Status: models.ViolationStatusAcknowledged, Assignee: &alice}); err != nil {
+			t.Fatalf("Acknowledge failed: %v", err)
+		}
+		if _, err := store.Triage(ctx, v.ID, TriageUpdate{Actor: "alice", Comment: "Looking into it"}); err != nil {
+			t.Fatalf("Comment failed: %v", err)
+		}
+		if _, err := store.Triage(ctx, v.ID, TriageUpdate{Actor: "alice", Status: models.ViolationStatusFalsePositive, Reason: "test traffic"}); err != nil {
+			t.Fatalf("Mark false positive failed: %v", err)
+		}
+
+		got, err := store.GetByID(ctx, v.ID)
+		if err != nil {
+			t.Fatalf("GetByID failed (signature must survive triage): %v", err)
+		}
+		if got.Status != models.ViolationStatusFalsePositive || got.Assignee != "alice" {
+			t.Errorf("Expected false-positive assigned to alice, got %q/%q", got.Status, got.Assignee)
+		}
+		if len(got.StatusHistory) != 2 {
+			t.Fatalf("Expected 2 status changes, got %v", got.StatusHistory)
+		}
+		last := got.StatusHistory[1]
+		if last.From != models.ViolationStatusAcknowledged || last.To != models.ViolationStatusFalsePositive || last.Actor != "alice" || last.Reason != "test traffic" {
+			t.Errorf("Unexpected status change: %+v", last)
+		}
+		if len(got.Comments) != 1 || got.Comments[0].Author != "alice" {
+			t.Errorf("Expected one comment by alice, got %v", got.Comments)
+		}
+		if got.UpdatedAt == nil || got.UpdatedAt.Before(got.CreatedAt) {
+			t.Errorf("Expected UpdatedAt after CreatedAt, got %v", got.UpdatedAt)
+		}
+
+		// Closed violations can only be reopened
+		_, err = store.Triage(ctx, v.ID, TriageUpdate{Actor: "bob", Status: models.ViolationStatusResolved})
+		var transitionErr *fsm.InvalidViolationTransitionError
+		if !errors.As(err, &transitionErr) {
+			t.Errorf("Expected invalid transition, got %v", err)
+		}
+
+		open, err := store.Query(ctx, QueryFilters{Status: models.ViolationStatusOpen})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if len(open) != 0 {
+			t.Errorf("Expected no open violations, got %d", len(open))
+		}
+		assigned, _ := store.Query(ctx, QueryFilters{Assignee: "alice", Status: models.ViolationStatusFalsePositive})
+		if len(assigned) != 1 {
+			t.Errorf("Expected 1 violation for alice, got %d", len(assigned))
+		}
+	})
+}
+
+func TestTriage_InvalidUpdates(t *testing.T) {
+	store := NewViolationStoreMemory("test-key")
+	ctx := context.Background()
+	v := recordTriageViolation(t, store)
+
+	invalid := []TriageUpdate{
+		{Status: models.ViolationStatusResolved}, // No actor
+		{Actor: "bob"},                           // Nothing to change
+		{Actor: "bob", Status: "closed"},         // Unknown status
+		{Actor: "bob", Comment: string(make([]byte, maxCommentLength+1))},
+	}
+	for _, update := range invalid {
+		if _, err := store.Triage(ctx, v.ID, update); !errors.Is(err, ErrInvalidTriage) {
+			t.Errorf("Expected ErrInvalidTriage for %+v, got %v", update, err)
+		}
+	}
+
+	_, err := store.Triage(ctx, "missing", TriageUpdate{Actor: "bob", Status: models.ViolationStatusResolved})
+	if !errors.Is(err, storage.ErrViolationNotFound) {
+		t.Errorf("Expected ErrViolationNotFound, got %v", err)
+	}
+}
+
+func TestTriage_ConcurrentCommentsKeepHistory(t *testing.T) {
+	triageBackends(t, func(t *testing.T, store ViolationStore) {
+		ctx := context.Background()
+		v := recordTriageViolation(t, store)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.Triage(ctx, v.ID, TriageUpdate{Actor: "bot", Comment: "note"}); err != nil {
+					t.Errorf("Comment failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		got, _ := store.GetByID(ctx, v.ID)
+		if len(got.Comments) != 20 {
+			t.Errorf("Expected 20 comments, got %d", len(got.Comments))
+		}
+	})
+}
+
+func TestTriage_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewViolationStoreDisk(dir, "test-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	v := recordTriageViolation(t, store)
+	if _, err := store.Triage(ctx, v.ID, TriageUpdate{Actor: "bob", Status: models.ViolationStatusSuppressed, Reason: "known issue"}); err != nil {
+		t.Fatalf("Suppress failed: %v", err)
+	}
+	store.Close()
+
+	reopened, err := NewViolationStoreDisk(dir, "test-key", storage.DiskViolationStoreOptions{})
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.GetByID(ctx, v.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Status != models.ViolationStatusSuppressed || len(got.StatusHistory) != 1 {
+		t.Errorf("Expected suppressed status with history after restart, got %q %v", got.Status, got.StatusHistory)
+	}
+	if count := reopened.Count(); count != 1 {
+		t.Errorf("Expected triage to supersede, not duplicate, the violation; count=%d", count)
+	}
+}
@@ -38,7 +38,7 @@ func (s *MemoryStore) GetViolation(ctx context.Context, id string) (*models.Viol
 
 	v, ok := s.violations[id]
 	if !ok {
-		return nil, fmt.Errorf("violation not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrViolationNotFound, id)
 	}
 
 	return &v, nil
@@ -69,6 +69,31 @@ func (s *MemoryStore) QueryViolations(ctx context.Context, ruleID, severity stri
 	return result, nil
 }
 
+// SearchViolations returns one page of violations matching q
+func (s *MemoryStore) SearchViolations(ctx context.Context, q ViolationQuery) (ViolationPage, error) {
+	type entry struct {
+		violation models.Violation
+		meta      violationMeta
+	}
+
+	s.mu.RLock()
+	var matched []*entry
+	for _, v := range s.violations {
+		meta := metaOf(&v)
+		if q.matches(&meta) {
+			matched = append(matched, &entry{violation: v, meta: meta})
+		}
+	}
+	s.mu.RUnlock()
+
+	page, next := paginate(matched, func(e *entry) *violationMeta { return &e.meta }, q)
+	result := make([]models.Violation, len(page))
+	for i, e := range page {
+		result[i] = e.violation
+	}
+	return ViolationPage{Violations: result, Total: len(matched), Next: next}, nil
+}
+
 // HealthCheck always returns nil for in-memory store
 func (s *MemoryStore) HealthCheck(ctx context.Context) error {
 	return nil
@@ -10,13 +10,16 @@ import (
 	"github.com/betracehq/betrace/backend/pkg/models"
 )
 
-// DiskRuleStore persists rules to disk for recovery after restart
+// DiskRuleStore persists rules to disk for recovery after restart, along with
+// the version history of every rule (kept after the rule is deleted)
 type DiskRuleStore struct {
-	mu       sync.RWMutex
-	rules    map[string]models.Rule
-	dataDir  string
-	filePath string
-	fs       FileSystem // Injected filesystem for testing
+	mu           sync.RWMutex
+	rules        map[string]models.Rule
+	versions     map[string][]models.RuleVersion // Rule ID -> versions, oldest first
+	dataDir      string
+	filePath     string
+	versionsPath string
+	fs           FileSystem // Injected filesystem for testing
 }
 
 // NewDiskRuleStore creates a rule store backed by disk persistence
@@ -32,19 +35,25 @@ func NewDiskRuleStoreWithFS(dataDir string, fs FileSystem) (*DiskRuleStore, erro
 	}
 
 	store := &DiskRuleStore{
-		rules:    make(map[string]models.Rule),
-		dataDir:  dataDir,
-		filePath: filepath.Join(dataDir, "rules.json"),
-		fs:       fs,
+		rules:        make(map[string]models.Rule),
+		versions:     make(map[string][]models.RuleVersion),
+		dataDir:      dataDir,
+		filePath:     filepath.Join(dataDir, "rules.json"),
+		versionsPath: filepath.Join(dataDir, "rule_versions.json"),
+		fs:           fs,
 	}
 
-	// Load existing rules from disk
+	// Load existing rules and versions from disk. If a file doesn't exist,
+	// that's OK (fresh start).
 	if err := store.load(); err != nil {
-		// If file doesn't exist, that's OK (fresh start)
-		if _, statErr := fs.Stat(store.filePath); os.IsNotExist(statErr) {
-			return store, nil
+		if _, statErr := fs.Stat(store.filePath); !os.IsNotExist(statErr) {
+			return nil, fmt.Errorf("failed to load rules: %w", err)
+		}
+	}
+	if err := store.loadVersions(); err != nil {
+		if _, statErr := fs.Stat(store.versionsPath); !os.IsNotExist(statErr) {
+			return nil, fmt.Errorf("failed to load rule versions: %w", err)
 		}
-		return nil, fmt.Errorf("failed to load rules: %w", err)
 	}
 
 	return store, nil
@@ -115,6 +124,33 @@ func (s *DiskRuleStore) List() ([]models.Rule, error) {
 	return rules, nil
 }
 
+// AppendVersion adds the next version to a rule's history and persists it.
+// Versions are immutable: v.Version must follow the rule's latest version.
+func (s *DiskRuleStore) AppendVersion(v models.RuleVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.versions[v.RuleID]
+	if n := len(history); n > 0 && v.Version <= history[n-1].Version {
+		return fmt.Errorf("rule %s already has version %d", v.RuleID, history[n-1].Version)
+	}
+
+	s.versions[v.RuleID] = append(history, v)
+	if err := s.persistVersions(); err != nil {
+		s.versions[v.RuleID] = history
+		return err
+	}
+	return nil
+}
+
+// Versions returns a rule's version history, oldest first (empty if it has none)
+func (s *DiskRuleStore) Versions(id string) ([]models.RuleVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.RuleVersion{}, s.versions[id]...), nil
+}
+
 // persist writes all rules to disk atomically
 func (s *DiskRuleStore) persist() error {
 	// Marshal rules to JSON
@@ -137,6 +173,40 @@ func (s *DiskRuleStore) persist() error {
 	return nil
 }
 
+// persistVersions writes all version histories to disk atomically
+func (s *DiskRuleStore) persistVersions() error {
+	data, err := json.MarshalIndent(s.versions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rule versions: %w", err)
+	}
+
+	tmpPath := s.versionsPath + ".tmp"
+	if err := s.fs.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write rule versions: %w", err)
+	}
+	if err := s.fs.Rename(tmpPath, s.versionsPath); err != nil {
+		return fmt.Errorf("failed to rename rule versions file: %w", err)
+	}
+
+	return nil
+}
+
+// loadVersions reads version histories from disk
+func (s *DiskRuleStore) loadVersions() error {
+	data, err := s.fs.ReadFile(s.versionsPath)
+	if err != nil {
+		return err
+	}
+
+	versions := make(map[string][]models.RuleVersion)
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return fmt.Errorf("failed to unmarshal rule versions: %w", err)
+	}
+
+	s.versions = versions
+	return nil
+}
+
 // load reads rules from disk
 func (s *DiskRuleStore) load() error {
 	data, err := s.fs.ReadFile(s.filePath)
@@ -50,6 +50,47 @@ func TestDiskRuleStore_CreateAndRecover(t *testing.T) {
 	assert.Equal(t, rule.Severity, recoveredRule.Severity)
 }
 
+func TestDiskRuleStore_RecoversControls(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	store, err := NewDiskRuleStoreWithFS("/data", mockFS)
+	require.NoError(t, err)
+
+	controls := []models.ComplianceControl{{Framework: "soc2", ControlID: "CC6.1"}, {Framework: "hipaa"}}
+	require.NoError(t, store.Create(models.Rule{ID: "mfa", Expression: "when { admin } always { mfa }", Controls: controls}))
+
+	recoveredStore, err := NewDiskRuleStoreWithFS("/data", mockFS)
+	require.NoError(t, err)
+	recoveredRule, err := recoveredStore.Get("mfa")
+	require.NoError(t, err)
+	assert.Equal(t, controls, recoveredRule.Controls)
+}
+
+func TestDiskRuleStore_VersionsAreAppendOnlyAndRecovered(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	store, err := NewDiskRuleStoreWithFS("/data", mockFS)
+	require.NoError(t, err)
+
+	rule := models.Rule{ID: "mfa", Expression: "when { admin } always { mfa }", Version: 1}
+	require.NoError(t, store.AppendVersion(models.RuleVersion{RuleID: "mfa", Version: 1, Change: models.RuleChangeCreated, Author: "alice", Rule: rule}))
+	rule.Expression, rule.Version = "when { admin } always { mfa and audit }", 2
+	require.NoError(t, store.AppendVersion(models.RuleVersion{RuleID: "mfa", Version: 2, Change: models.RuleChangeUpdated, Author: "bob", Rule: rule}))
+	assert.Error(t, store.AppendVersion(models.RuleVersion{RuleID: "mfa", Version: 2}), "Versions must not be rewritten")
+
+	// History is kept after the rule is gone
+	recoveredStore, err := NewDiskRuleStoreWithFS("/data", mockFS)
+	require.NoError(t, err)
+	versions, err := recoveredStore.Versions("mfa")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "alice", versions[0].Author)
+	assert.Equal(t, "when { admin } always { mfa }", versions[0].Rule.Expression)
+	assert.Equal(t, rule.Expression, versions[1].Rule.Expression)
+
+	none, err := recoveredStore.Versions("other")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
 func TestDiskRuleStore_Update(t *testing.T) {
 	mockFS := NewMockFileSystem()
 	store, err := NewDiskRuleStoreWithFS("/data", mockFS)
@@ -0,0 +1,718 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// DiskViolationStore persists violations to append-only segment files.
+//
+// Layout: <dir>/segment-<seq>.log, each a sequence of frames
+//
+//	[4-byte payload length][4-byte CRC-32C of payload][JSON violation]
+//
+// Writes append to the newest (active) segment and are fsynced before
+// returning; the active segment rolls over once it reaches SegmentBytes.
+// Storing a violation whose ID already exists appends a new version that
+// supersedes the old one.
+//
+// Only metadata (rule, severity, traces, services, tags, fingerprint, time,
+// file offset) is kept in memory, indexed by rule, severity, trace and
+// fingerprint and ordered by time; payloads are read from disk on query.
+//
+// Recovery: on open every segment is replayed in order (later versions win).
+// A torn or corrupt frame truncates its segment at that frame, discarding
+// the incomplete write. Retention is re-applied after replay, so evicted
+// violations still present in uncompacted segments are evicted again.
+//
+// Compaction rewrites sealed segments that are mostly dead (superseded or
+// evicted) by appending their live frames to the active segment, fsyncing,
+// and only then deleting the old file. A crash mid-compaction leaves
+// duplicate copies, which replay resolves.
+type DiskViolationStore struct {
+	mu   sync.RWMutex
+	dir  string
+	opts DiskViolationStoreOptions
+
+	segments []*violationSegment // Ordered by seq; the last is active
+	records  map[string]*violationRecord
+
+	byRule        map[string]map[string]*violationRecord
+	bySeverity    map[string]map[string]*violationRecord
+	byTrace       map[string]map[string]*violationRecord
+	byFingerprint map[string]map[string]*violationRecord
+	byTime        []*violationRecord // Oldest first
+
+	stop     chan struct{} // nil without background maintenance
+	stopOnce sync.Once
+	done     chan struct{}
+	closed   bool
+}
+
+// DiskViolationStoreOptions configures retention and compaction
+type DiskViolationStoreOptions struct {
+	MaxViolations int           // Count-based retention: oldest evicted first (0 = unlimited)
+	Retention     time.Duration // Time-based retention by CreatedAt (0 = keep forever)
+	SegmentBytes  int64         // Active segment rollover size (default 8MB)
+
+	// CompactionThreshold is the live-bytes ratio below which a sealed
+	// segment is rewritten (default 0.5)
+	CompactionThreshold float64
+	// MaintenanceInterval is how often retention and compaction run in the
+	// background (0 = only on write and on explicit Compact calls)
+	MaintenanceInterval time.Duration
+}
+
+const (
+	defaultSegmentBytes        = 8 * 1024 * 1024
+	defaultCompactionThreshold = 0.5
+	frameHeaderSize            = 8
+	maxFramePayload            = 64 * 1024 * 1024 // Larger lengths mean a corrupt header
+	segmentPrefix              = "segment-"
+	segmentSuffix              = ".log"
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type violationSegment struct {
+	seq       uint64
+	path      string
+	file      *os.File
+	size      int64
+	liveBytes int64
+	live      int
+}
+
+type violationRecord struct {
+	violationMeta
+
+	segment *violationSegment
+	offset  int64 // Frame start
+	length  int64 // Frame length including header
+}
+
+// DiskViolationStoreStats describes the store's on-disk state
+type DiskViolationStoreStats struct {
+	Violations int
+	Segments   int
+	DiskBytes  int64
+	LiveBytes  int64
+}
+
+// NewDiskViolationStore opens (or creates) a violation store in dir,
+// recovering any existing segments
+func NewDiskViolationStore(dir string, opts DiskViolationStoreOptions) (*DiskViolationStore, error) {
+	if opts.SegmentBytes <= 0 {
+		opts.SegmentBytes = defaultSegmentBytes
+	}
+	if opts.CompactionThreshold <= 0 {
+		opts.CompactionThreshold = defaultCompactionThreshold
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create violation directory: %w", err)
+	}
+
+	s := &DiskViolationStore{
+		dir:           dir,
+		opts:          opts,
+		records:       make(map[string]*violationRecord),
+		byRule:        make(map[string]map[string]*violationRecord),
+		bySeverity:    make(map[string]map[string]*violationRecord),
+		byTrace:       make(map[string]map[string]*violationRecord),
+		byFingerprint: make(map[string]map[string]*violationRecord),
+	}
+
+	if err := s.recover(); err != nil {
+		s.closeFiles()
+		return nil, err
+	}
+
+	if opts.MaintenanceInterval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.maintain(opts.MaintenanceInterval)
+	}
+
+	return s, nil
+}
+
+// StoreViolation appends a violation, superseding any stored version with
+// the same ID, then applies retention
+func (s *DiskViolationStore) StoreViolation(ctx context.Context, v models.Violation) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal violation: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("violation store is closed")
+	}
+
+	segment, offset, err := s.appendFrame(payload)
+	if err != nil {
+		return err
+	}
+
+	s.index(&violationRecord{
+		violationMeta: metaOf(&v),
+		segment:       segment,
+		offset:        offset,
+		length:        int64(frameHeaderSize + len(payload)),
+	})
+
+	s.applyRetention(time.Now())
+	return s.dropEmptySegments()
+}
+
+// GetViolation retrieves a violation by ID
+func (s *DiskViolationStore) GetViolation(ctx context.Context, id string) (*models.Violation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.records[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrViolationNotFound, id)
+	}
+
+	v, err := s.read(rec)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SearchViolations returns one page of violations matching q
+func (s *DiskViolationStore) SearchViolations(ctx context.Context, q ViolationQuery) (ViolationPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []*violationRecord
+	window := s.timeWindow(q)
+	if candidates, ok := s.smallestIndex(q); ok && len(candidates) < len(window) {
+		for _, rec := range candidates {
+			if q.matches(&rec.violationMeta) {
+				matched = append(matched, rec)
+			}
+		}
+	} else {
+		for _, rec := range window {
+			if q.matches(&rec.violationMeta) {
+				matched = append(matched, rec)
+			}
+		}
+	}
+
+	page, next := paginate(matched, func(rec *violationRecord) *violationMeta { return &rec.violationMeta }, q)
+	result := make([]models.Violation, 0, len(page))
+	for _, rec := range page {
+		v, err := s.read(rec)
+		if err != nil {
+			return ViolationPage{}, err
+		}
+		result = append(result, v)
+	}
+	return ViolationPage{Violations: result, Total: len(matched), Next: next}, nil
+}
+
+// Count returns the number of stored violations
+func (s *DiskViolationStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records)
+}
+
+// Stats reports segment and byte counts
+func (s *DiskViolationStore) Stats() DiskViolationStoreStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := DiskViolationStoreStats{Violations: len(s.records), Segments: len(s.segments)}
+	for _, seg := range s.segments {
+		stats.DiskBytes += seg.size
+		stats.LiveBytes += seg.liveBytes
+	}
+	return stats
+}
+
+// Compact applies retention and rewrites sealed segments whose live ratio
+// is below CompactionThreshold
+func (s *DiskViolationStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("violation store is closed")
+	}
+
+	s.applyRetention(time.Now())
+	if err := s.dropEmptySegments(); err != nil {
+		return err
+	}
+
+	// Snapshot sealed candidates first: compaction appends to (and may roll)
+	// the active segment
+	var candidates []*violationSegment
+	for _, seg := range s.segments[:len(s.segments)-1] {
+		if seg.size > 0 && float64(seg.liveBytes)/float64(seg.size) < s.opts.CompactionThreshold {
+			candidates = append(candidates, seg)
+		}
+	}
+
+	for _, seg := range candidates {
+		if err := s.rewriteSegment(seg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HealthCheck verifies the store is open
+func (s *DiskViolationStore) HealthCheck(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("violation store is closed")
+	}
+	return nil
+}
+
+// Close stops background maintenance and closes segment files. It is safe
+// to call more than once, including concurrently.
+func (s *DiskViolationStore) Close() error {
+	if s.stop != nil {
+		s.stopOnce.Do(func() { close(s.stop) })
+		<-s.done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.closeFiles()
+}
+
+func (s *DiskViolationStore) maintain(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Compact(); err != nil {
+				log.Printf("Violation store maintenance failed: %v", err)
+			}
+		}
+	}
+}
+
+// recover replays existing segments into the index
+func (s *DiskViolationStore) recover() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read violation directory: %w", err)
+	}
+
+	var seqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	for _, seq := range seqs {
+		seg, err := s.openSegment(seq)
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, seg)
+		if err := s.replay(seg); err != nil {
+			return err
+		}
+	}
+
+	if len(s.segments) == 0 || s.active().size >= s.opts.SegmentBytes {
+		if err := s.roll(); err != nil {
+			return err
+		}
+	}
+
+	s.applyRetention(time.Now())
+	return s.dropEmptySegments()
+}
+
+// replay indexes a segment's frames, truncating it at the first bad frame
+func (s *DiskViolationStore) replay(seg *violationSegment) error {
+	reader := bufio.NewReader(io.NewSectionReader(seg.file, 0, seg.size))
+	header := make([]byte, frameHeaderSize)
+	var offset int64
+
+	for offset < seg.size {
+		payload, err := readFrame(reader, header)
+		if err != nil {
+			log.Printf("Violation segment %s: discarding %d bytes after offset %d: %v",
+				seg.path, seg.size-offset, offset, err)
+			if err := seg.file.Truncate(offset); err != nil {
+				return fmt.Errorf("failed to truncate %s: %w", seg.path, err)
+			}
+			if err := seg.file.Sync(); err != nil {
+				return fmt.Errorf("failed to sync %s: %w", seg.path, err)
+			}
+			seg.size = offset
+			break
+		}
+
+		var v models.Violation
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return fmt.Errorf("failed to decode violation in %s at offset %d: %w", seg.path, offset, err)
+		}
+
+		length := int64(frameHeaderSize + len(payload))
+		s.index(&violationRecord{
+			violationMeta: metaOf(&v),
+			segment:       seg,
+			offset:        offset,
+			length:        length,
+		})
+		offset += length
+	}
+	return nil
+}
+
+// readFrame reads one frame's payload, verifying its checksum
+func readFrame(r io.Reader, header []byte) ([]byte, error) {
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("torn frame header: %w", err)
+	}
+
+	length := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+	if length == 0 || length > maxFramePayload {
+		return nil, fmt.Errorf("invalid frame length %d", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("torn frame payload: %w", err)
+	}
+	if crc32.Checksum(payload, crcTable) != checksum {
+		return nil, errors.New("frame checksum mismatch")
+	}
+	return payload, nil
+}
+
+// appendFrame writes a frame to the active segment (rolling if full) and
+// fsyncs it. On failure the partial write is truncated away.
+func (s *DiskViolationStore) appendFrame(payload []byte) (*violationSegment, int64, error) {
+	frameLen := int64(frameHeaderSize + len(payload))
+	if active := s.active(); active.size > 0 && active.size+frameLen > s.opts.SegmentBytes {
+		if err := s.roll(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	seg := s.active()
+	frame := make([]byte, frameLen)
+	binary.LittleEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:8], crc32.Checksum(payload, crcTable))
+	copy(frame[frameHeaderSize:], payload)
+
+	offset := seg.size
+	if _, err := seg.file.WriteAt(frame, offset); err != nil {
+		seg.file.Truncate(offset)
+		return nil, 0, fmt.Errorf("failed to write violation: %w", err)
+	}
+	if err := seg.file.Sync(); err != nil {
+		seg.file.Truncate(offset)
+		return nil, 0, fmt.Errorf("failed to sync violation: %w", err)
+	}
+
+	seg.size += frameLen
+	return seg, offset, nil
+}
+
+// read loads a record's payload from disk
+func (s *DiskViolationStore) read(rec *violationRecord) (models.Violation, error) {
+	frame := make([]byte, rec.length)
+	if _, err := rec.segment.file.ReadAt(frame, rec.offset); err != nil {
+		return models.Violation{}, fmt.Errorf("failed to read violation %s: %w", rec.id, err)
+	}
+
+	payload := frame[frameHeaderSize:]
+	if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(frame[4:8]) {
+		return models.Violation{}, fmt.Errorf("violation %s failed checksum verification", rec.id)
+	}
+
+	var v models.Violation
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return models.Violation{}, fmt.Errorf("failed to decode violation %s: %w", rec.id, err)
+	}
+	return v, nil
+}
+
+// rewriteSegment moves a sealed segment's live frames to the active segment
+// and deletes it
+func (s *DiskViolationStore) rewriteSegment(seg *violationSegment) error {
+	var live []*violationRecord
+	for _, rec := range s.records {
+		if rec.segment == seg {
+			live = append(live, rec)
+		}
+	}
+	// Preserve write order so replay resolves versions the same way
+	sort.Slice(live, func(i, j int) bool { return live[i].offset < live[j].offset })
+
+	for _, rec := range live {
+		frame := make([]byte, rec.length)
+		if _, err := seg.file.ReadAt(frame, rec.offset); err != nil {
+			return fmt.Errorf("failed to read violation %s for compaction: %w", rec.id, err)
+		}
+
+		dest, offset, err := s.appendFrame(frame[frameHeaderSize:])
+		if err != nil {
+			return err
+		}
+
+		seg.live--
+		seg.liveBytes -= rec.length
+		dest.live++
+		dest.liveBytes += rec.length
+		rec.segment = dest
+		rec.offset = offset
+	}
+
+	return s.removeSegment(seg)
+}
+
+// index adds a record, superseding any record with the same ID
+func (s *DiskViolationStore) index(rec *violationRecord) {
+	if old, ok := s.records[rec.id]; ok {
+		s.unindex(old)
+	}
+
+	s.records[rec.id] = rec
+	addToIndex(s.byRule, rec.ruleID, rec)
+	addToIndex(s.bySeverity, rec.severity, rec)
+	for _, traceID := range rec.traceIDs {
+		addToIndex(s.byTrace, traceID, rec)
+	}
+	addToIndex(s.byFingerprint, rec.fingerprint, rec)
+
+	// Violations mostly arrive in time order, so this is usually an append
+	i := sort.Search(len(s.byTime), func(i int) bool {
+		return newerThan(s.byTime[i].createdAt, s.byTime[i].id, rec.createdAt, rec.id)
+	})
+	s.byTime = append(s.byTime, nil)
+	copy(s.byTime[i+1:], s.byTime[i:])
+	s.byTime[i] = rec
+
+	rec.segment.live++
+	rec.segment.liveBytes += rec.length
+}
+
+// unindex removes a record from every index
+func (s *DiskViolationStore) unindex(rec *violationRecord) {
+	delete(s.records, rec.id)
+	removeFromIndex(s.byRule, rec.ruleID, rec.id)
+	removeFromIndex(s.bySeverity, rec.severity, rec.id)
+	for _, traceID := range rec.traceIDs {
+		removeFromIndex(s.byTrace, traceID, rec.id)
+	}
+	removeFromIndex(s.byFingerprint, rec.fingerprint, rec.id)
+
+	i := sort.Search(len(s.byTime), func(i int) bool {
+		return !newerThan(rec.createdAt, rec.id, s.byTime[i].createdAt, s.byTime[i].id)
+	})
+	if i < len(s.byTime) && s.byTime[i] == rec {
+		s.byTime = append(s.byTime[:i], s.byTime[i+1:]...)
+	}
+
+	rec.segment.live--
+	rec.segment.liveBytes -= rec.length
+}
+
+// applyRetention evicts the oldest violations beyond the count and age limits
+func (s *DiskViolationStore) applyRetention(now time.Time) {
+	for len(s.byTime) > 0 {
+		oldest := s.byTime[0]
+		overCount := s.opts.MaxViolations > 0 && len(s.byTime) > s.opts.MaxViolations
+		expired := s.opts.Retention > 0 && oldest.createdAt.Before(now.Add(-s.opts.Retention))
+		if !overCount && !expired {
+			return
+		}
+		s.unindex(oldest)
+	}
+}
+
+// timeWindow returns the records created in q's [Since, Until) range,
+// oldest first
+func (s *DiskViolationStore) timeWindow(q ViolationQuery) []*violationRecord {
+	lo, hi := 0, len(s.byTime)
+	if !q.Since.IsZero() {
+		lo = sort.Search(len(s.byTime), func(i int) bool {
+			return !s.byTime[i].createdAt.Before(q.Since)
+		})
+	}
+	if !q.Until.IsZero() {
+		hi = sort.Search(len(s.byTime), func(i int) bool {
+			return !s.byTime[i].createdAt.Before(q.Until)
+		})
+	}
+	if hi < lo {
+		return nil
+	}
+	return s.byTime[lo:hi]
+}
+
+// smallestIndex returns the smallest index bucket selected by q's indexed
+// filters, or false if q has none
+func (s *DiskViolationStore) smallestIndex(q ViolationQuery) (map[string]*violationRecord, bool) {
+	var best map[string]*violationRecord
+	found := false
+	consider := func(index map[string]map[string]*violationRecord, key string) {
+		if key == "" {
+			return
+		}
+		bucket := index[key]
+		if !found || len(bucket) < len(best) {
+			best = bucket
+			found = true
+		}
+	}
+	consider(s.byRule, q.RuleID)
+	consider(s.bySeverity, q.Severity)
+	consider(s.byTrace, q.TraceID)
+	consider(s.byFingerprint, q.Fingerprint)
+	return best, found
+}
+
+// dropEmptySegments deletes sealed segments with no live records
+func (s *DiskViolationStore) dropEmptySegments() error {
+	for _, seg := range append([]*violationSegment(nil), s.segments[:len(s.segments)-1]...) {
+		if seg.live == 0 {
+			if err := s.removeSegment(seg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *DiskViolationStore) removeSegment(seg *violationSegment) error {
+	for i, candidate := range s.segments {
+		if candidate == seg {
+			s.segments = append(s.segments[:i], s.segments[i+1:]...)
+			break
+		}
+	}
+	seg.file.Close()
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove segment %s: %w", seg.path, err)
+	}
+	return nil
+}
+
+// roll seals the active segment and starts a new one
+func (s *DiskViolationStore) roll() error {
+	var seq uint64 = 1
+	if len(s.segments) > 0 {
+		seq = s.active().seq + 1
+	}
+
+	seg, err := s.openSegment(seq)
+	if err != nil {
+		return err
+	}
+	s.segments = append(s.segments, seg)
+	return syncDir(s.dir)
+}
+
+func (s *DiskViolationStore) openSegment(seq uint64) (*violationSegment, error) {
+	path := filepath.Join(s.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, seq, segmentSuffix))
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat segment %s: %w", path, err)
+	}
+
+	return &violationSegment{seq: seq, path: path, file: file, size: info.Size()}, nil
+}
+
+func (s *DiskViolationStore) active() *violationSegment {
+	return s.segments[len(s.segments)-1]
+}
+
+func (s *DiskViolationStore) closeFiles() error {
+	var firstErr error
+	for _, seg := range s.segments {
+		if err := seg.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// syncDir fsyncs a directory so new and removed segment files are durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func addToIndex(index map[string]map[string]*violationRecord, key string, rec *violationRecord) {
+	bucket, ok := index[key]
+	if !ok {
+		bucket = make(map[string]*violationRecord)
+		index[key] = bucket
+	}
+	bucket[rec.id] = rec
+}
+
+func removeFromIndex(index map[string]map[string]*violationRecord, key, id string) {
+	bucket := index[key]
+	delete(bucket, id)
+	if len(bucket) == 0 {
+		delete(index, key)
+	}
+}
@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var diskTestEpoch = time.Now().Add(-time.Hour).Truncate(time.Second)
+
+func diskViolation(i int, ruleID, severity, traceID string) models.Violation {
+	return models.Violation{
+		ID:        fmt.Sprintf("v-%04d", i),
+		RuleID:    ruleID,
+		Severity:  severity,
+		Message:   fmt.Sprintf("violation %d", i),
+		TraceIDs:  []string{traceID},
+		SpanRefs:  []models.SpanRef{{TraceID: traceID, SpanID: fmt.Sprintf("span-%d", i), Role: models.SpanRoleTrigger}},
+		CreatedAt: diskTestEpoch.Add(time.Duration(i) * time.Second),
+	}
+}
+
+func openDiskStore(t *testing.T, dir string, opts DiskViolationStoreOptions) *DiskViolationStore {
+	t.Helper()
+	store, err := NewDiskViolationStore(dir, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func violationIDs(violations []models.Violation) []string {
+	ids := make([]string, len(violations))
+	for i, v := range violations {
+		ids[i] = v.ID
+	}
+	return ids
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"+segmentSuffix))
+	require.NoError(t, err)
+	return files
+}
+
+func TestDiskViolationStore_StoreAndSearch(t *testing.T) {
+	store := openDiskStore(t, t.TempDir(), DiskViolationStoreOptions{})
+	ctx := context.Background()
+
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(1, "rule-A", "HIGH", "trace-1")))
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(2, "rule-B", "LOW", "trace-1")))
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(3, "rule-A", "LOW", "trace-2")))
+
+	tests := []struct {
+		name  string
+		query ViolationQuery
+		want  []string
+	}{
+		{"all newest first", ViolationQuery{}, []string{"v-0003", "v-0002", "v-0001"}},
+		{"by rule", ViolationQuery{RuleID: "rule-A"}, []string{"v-0003", "v-0001"}},
+		{"by severity", ViolationQuery{Severity: "LOW"}, []string{"v-0003", "v-0002"}},
+		{"by trace", ViolationQuery{TraceID: "trace-1"}, []string{"v-0002", "v-0001"}},
+		{"combined", ViolationQuery{RuleID: "rule-A", Severity: "LOW"}, []string{"v-0003"}},
+		{"unknown rule", ViolationQuery{RuleID: "rule-Z"}, []string{}},
+		{"since", ViolationQuery{Since: diskTestEpoch.Add(2 * time.Second)}, []string{"v-0003", "v-0002"}},
+		{"until", ViolationQuery{Until: diskTestEpoch.Add(2 * time.Second)}, []string{"v-0001"}},
+		{"window", ViolationQuery{Since: diskTestEpoch.Add(2 * time.Second), Until: diskTestEpoch.Add(3 * time.Second)}, []string{"v-0002"}},
+		{"empty window", ViolationQuery{Since: diskTestEpoch.Add(3 * time.Second), Until: diskTestEpoch.Add(2 * time.Second)}, []string{}},
+		{"window and rule", ViolationQuery{RuleID: "rule-A", Since: diskTestEpoch.Add(2 * time.Second)}, []string{"v-0003"}},
+		{"limit", ViolationQuery{Limit: 2}, []string{"v-0003", "v-0002"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := store.SearchViolations(ctx, tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, violationIDs(results.Violations))
+		})
+	}
+
+	// Time-bounded searches only visit records inside the window
+	assert.Len(t, store.timeWindow(ViolationQuery{Since: diskTestEpoch.Add(2 * time.Second), Until: diskTestEpoch.Add(3 * time.Second)}), 1)
+
+	got, err := store.GetViolation(ctx, "v-0002")
+	require.NoError(t, err)
+	assert.Equal(t, diskViolation(2, "rule-B", "LOW", "trace-1").SpanRefs, got.SpanRefs)
+
+	_, err = store.GetViolation(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestDiskViolationStore_RecoversAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewDiskViolationStore(dir, DiskViolationStoreOptions{SegmentBytes: 1024})
+	require.NoError(t, err)
+	for i := 1; i <= 20; i++ {
+		require.NoError(t, store.StoreViolation(ctx, diskViolation(i, "rule-A", "HIGH", "trace-1")))
+	}
+	require.NoError(t, store.Close())
+	assert.Greater(t, len(segmentFiles(t, dir)), 1, "small segments should roll over")
+
+	reopened := openDiskStore(t, dir, DiskViolationStoreOptions{SegmentBytes: 1024})
+	assert.Equal(t, 20, reopened.Count())
+
+	results, err := reopened.SearchViolations(ctx, ViolationQuery{RuleID: "rule-A", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results.Violations, 1)
+	assert.Equal(t, "v-0020", results.Violations[0].ID)
+	assert.True(t, results.Violations[0].CreatedAt.Equal(diskTestEpoch.Add(20*time.Second)))
+}
+
+func TestDiskViolationStore_SupersedesByID(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store := openDiskStore(t, dir, DiskViolationStoreOptions{})
+
+	v := diskViolation(1, "rule-A", "HIGH", "trace-1")
+	require.NoError(t, store.StoreViolation(ctx, v))
+	v.Severity = "LOW"
+	require.NoError(t, store.StoreViolation(ctx, v))
+
+	assert.Equal(t, 1, store.Count())
+	results, err := store.SearchViolations(ctx, ViolationQuery{Severity: "HIGH"})
+	require.NoError(t, err)
+	assert.Empty(t, results.Violations, "old version must leave the severity index")
+
+	require.NoError(t, store.Close())
+	reopened := openDiskStore(t, dir, DiskViolationStoreOptions{})
+	got, err := reopened.GetViolation(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "LOW", got.Severity, "latest version wins on replay")
+}
+
+func TestDiskViolationStore_TornWriteRecovery(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewDiskViolationStore(dir, DiskViolationStoreOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(1, "rule-A", "HIGH", "trace-1")))
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(2, "rule-A", "HIGH", "trace-1")))
+	require.NoError(t, store.Close())
+
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	intactSize := info.Size()
+
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, path string)
+	}{
+		{"partial header", func(t *testing.T, path string) {
+			appendBytes(t, path, []byte{0x10, 0x00})
+		}},
+		{"partial payload", func(t *testing.T, path string) {
+			appendBytes(t, path, []byte{0x40, 0x00, 0x00, 0x00, 0xde, 0xad, 0xbe, 0xef, '{', '"'})
+		}},
+		{"checksum mismatch", func(t *testing.T, path string) {
+			appendBytes(t, path, []byte{0x02, 0x00, 0x00, 0x00, 0xde, 0xad, 0xbe, 0xef, '{', '}'})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.corrupt(t, files[0])
+
+			reopened, err := NewDiskViolationStore(dir, DiskViolationStoreOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, 2, reopened.Count())
+
+			info, err := os.Stat(files[0])
+			require.NoError(t, err)
+			assert.Equal(t, intactSize, info.Size(), "torn frame should be truncated")
+
+			// Writes resume cleanly after the truncation point
+			require.NoError(t, reopened.StoreViolation(ctx, diskViolation(3, "rule-A", "HIGH", "trace-1")))
+			require.NoError(t, reopened.Close())
+
+			verify := openDiskStore(t, dir, DiskViolationStoreOptions{})
+			assert.Equal(t, 3, verify.Count())
+			require.NoError(t, verify.Close())
+
+			// Reset to the two intact frames for the next case
+			require.NoError(t, os.Truncate(files[0], intactSize))
+		})
+	}
+}
+
+func appendBytes(t *testing.T, path string, data []byte) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestDiskViolationStore_CountRetention(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	opts := DiskViolationStoreOptions{MaxViolations: 5, SegmentBytes: 1024}
+	store := openDiskStore(t, dir, opts)
+
+	for i := 1; i <= 30; i++ {
+		require.NoError(t, store.StoreViolation(ctx, diskViolation(i, "rule-A", "HIGH", fmt.Sprintf("trace-%d", i))))
+	}
+
+	assert.Equal(t, 5, store.Count())
+	results, err := store.SearchViolations(ctx, ViolationQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v-0030", "v-0029", "v-0028", "v-0027", "v-0026"}, violationIDs(results.Violations))
+
+	results, err = store.SearchViolations(ctx, ViolationQuery{TraceID: "trace-1"})
+	require.NoError(t, err)
+	assert.Empty(t, results.Violations, "evicted violations must leave the trace index")
+
+	// Fully evicted segments are deleted as retention drains them
+	assert.LessOrEqual(t, store.Stats().Segments, 3)
+
+	require.NoError(t, store.Close())
+	reopened := openDiskStore(t, dir, opts)
+	assert.Equal(t, 5, reopened.Count())
+}
+
+func TestDiskViolationStore_TimeRetention(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store := openDiskStore(t, dir, DiskViolationStoreOptions{})
+
+	old := diskViolation(1, "rule-A", "HIGH", "trace-1")
+	old.CreatedAt = time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.StoreViolation(ctx, old))
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(2, "rule-A", "HIGH", "trace-2")))
+	require.NoError(t, store.Close())
+
+	// Retention applies on recovery
+	reopened := openDiskStore(t, dir, DiskViolationStoreOptions{Retention: 24 * time.Hour})
+	assert.Equal(t, 1, reopened.Count())
+	_, err := reopened.GetViolation(ctx, old.ID)
+	assert.Error(t, err)
+}
+
+func TestDiskViolationStore_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	opts := DiskViolationStoreOptions{SegmentBytes: 2048}
+	store := openDiskStore(t, dir, opts)
+
+	// v-0001 is written once; the rest are rewritten repeatedly, leaving
+	// sealed segments whose only live frame is v-0001
+	require.NoError(t, store.StoreViolation(ctx, diskViolation(1, "rule-A", "HIGH", "trace-1")))
+	for round := 0; round < 10; round++ {
+		for i := 2; i <= 5; i++ {
+			v := diskViolation(i, "rule-A", "HIGH", "trace-1")
+			v.Message = fmt.Sprintf("round %d", round)
+			require.NoError(t, store.StoreViolation(ctx, v))
+		}
+	}
+
+	before := store.Stats()
+	require.NoError(t, store.Compact())
+	after := store.Stats()
+
+	assert.Equal(t, 5, after.Violations)
+	assert.Less(t, after.DiskBytes, before.DiskBytes)
+	assert.Less(t, after.Segments, before.Segments)
+	assert.Len(t, segmentFiles(t, dir), after.Segments)
+
+	first, err := store.GetViolation(ctx, "v-0001")
+	require.NoError(t, err)
+	assert.Equal(t, "violation 1", first.Message)
+	for i := 2; i <= 5; i++ {
+		got, err := store.GetViolation(ctx, fmt.Sprintf("v-%04d", i))
+		require.NoError(t, err)
+		assert.Equal(t, "round 9", got.Message)
+	}
+
+	require.NoError(t, store.Close())
+	reopened := openDiskStore(t, dir, opts)
+	assert.Equal(t, 5, reopened.Count())
+	got, err := reopened.GetViolation(ctx, "v-0001")
+	require.NoError(t, err)
+	assert.Equal(t, "violation 1", got.Message)
+	got, err = reopened.GetViolation(ctx, "v-0005")
+	require.NoError(t, err)
+	assert.Equal(t, "round 9", got.Message)
+}
+
+func TestDiskViolationStore_BackgroundMaintenance(t *testing.T) {
+	ctx := context.Background()
+	store := openDiskStore(t, t.TempDir(), DiskViolationStoreOptions{
+		Retention:           time.Hour,
+		MaintenanceInterval: 10 * time.Millisecond,
+	})
+
+	v := diskViolation(1, "rule-A", "HIGH", "trace-1")
+	v.CreatedAt = time.Now().Add(-time.Hour + 50*time.Millisecond)
+	require.NoError(t, store.StoreViolation(ctx, v))
+	assert.Equal(t, 1, store.Count())
+
+	assert.Eventually(t, func() bool { return store.Count() == 0 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestDiskViolationStore_ConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	store := openDiskStore(t, t.TempDir(), DiskViolationStoreOptions{SegmentBytes: 4096, MaxViolations: 50})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				id := w*100 + i
+				assert.NoError(t, store.StoreViolation(ctx, diskViolation(id, fmt.Sprintf("rule-%d", w), "HIGH", "trace-1")))
+				_, err := store.SearchViolations(ctx, ViolationQuery{RuleID: fmt.Sprintf("rule-%d", w), Limit: 5})
+				assert.NoError(t, err)
+			}
+		}(w)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, store.Compact())
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, 50, store.Count())
+}
+
+func TestDiskViolationStore_ClosedStoreRejectsWrites(t *testing.T) {
+	store, err := NewDiskViolationStore(t.TempDir(), DiskViolationStoreOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	assert.Error(t, store.StoreViolation(context.Background(), diskViolation(1, "rule-A", "HIGH", "trace-1")))
+	assert.Error(t, store.HealthCheck(context.Background()))
+	assert.NoError(t, store.Close(), "Close is idempotent")
+}
+
+func TestDiskViolationStore_ConcurrentClose(t *testing.T) {
+	store, err := NewDiskViolationStore(t.TempDir(), DiskViolationStoreOptions{MaintenanceInterval: time.Hour})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Close())
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// ErrViolationNotFound is returned for an unknown violation ID
+var ErrViolationNotFound = errors.New("violation not found")
+
+// Result orders for ViolationQuery.Order
+const (
+	OrderNewestFirst = "newest" // Default
+	OrderOldestFirst = "oldest"
+)
+
+// ViolationQuery filters violations. Zero-valued fields match everything.
+type ViolationQuery struct {
+	RuleID      string
+	Severity    string
+	TraceID     string
+	Fingerprint string
+	Assignee    string
+	Status      string    // Triage status; "open" also matches violations never triaged
+	ServiceName string    // Any span reference from this service
+	Tags        []string  // Violation must carry every tag
+	Since       time.Time // Inclusive
+	Until       time.Time // Exclusive
+
+	Order string           // OrderNewestFirst (default) or OrderOldestFirst
+	After *ViolationCursor // Resume after this position in Order
+	Limit int              // 0 = no limit
+}
+
+// ViolationCursor is a position in the stable (CreatedAt, ID) sort order
+type ViolationCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ViolationPage is one page of query results
+type ViolationPage struct {
+	Violations []models.Violation
+	Total      int              // Matches across all pages
+	Next       *ViolationCursor // nil on the last page
+}
+
+// violationMeta is the indexed subset of a violation used for filtering
+type violationMeta struct {
+	id          string
+	ruleID      string
+	severity    string
+	traceIDs    []string
+	fingerprint string
+	status      string
+	assignee    string
+	services    []string
+	tags        []string
+	createdAt   time.Time
+}
+
+func metaOf(v *models.Violation) violationMeta {
+	return violationMeta{
+		id:          v.ID,
+		ruleID:      v.RuleID,
+		severity:    v.Severity,
+		traceIDs:    v.TraceIDs,
+		fingerprint: v.Fingerprint,
+		status:      v.Status,
+		assignee:    v.Assignee,
+		services:    serviceNames(v.SpanRefs),
+		tags:        v.Tags,
+		createdAt:   v.CreatedAt,
+	}
+}
+
+func (m *violationMeta) cursor() ViolationCursor {
+	return ViolationCursor{CreatedAt: m.createdAt, ID: m.id}
+}
+
+// Matches reports whether v satisfies every filter in q (ignoring paging)
+func (q ViolationQuery) Matches(v *models.Violation) bool {
+	meta := metaOf(v)
+	return q.matches(&meta)
+}
+
+func (q ViolationQuery) matches(m *violationMeta) bool {
+	if q.RuleID != "" && m.ruleID != q.RuleID {
+		return false
+	}
+	if q.Severity != "" && m.severity != q.Severity {
+		return false
+	}
+	if !q.Since.IsZero() && m.createdAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !m.createdAt.Before(q.Until) {
+		return false
+	}
+	if q.TraceID != "" && !contains(m.traceIDs, q.TraceID) {
+		return false
+	}
+	if q.Fingerprint != "" && m.fingerprint != q.Fingerprint {
+		return false
+	}
+	if q.Status != "" && m.status != q.Status && !(m.status == "" && q.Status == models.ViolationStatusOpen) {
+		return false
+	}
+	if q.Assignee != "" && m.assignee != q.Assignee {
+		return false
+	}
+	if q.ServiceName != "" && !contains(m.services, q.ServiceName) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !contains(m.tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// paginate sorts items into q's order and returns the page after q's cursor
+func paginate[T any](items []T, meta func(T) *violationMeta, q ViolationQuery) ([]T, *ViolationCursor) {
+	oldestFirst := q.Order == OrderOldestFirst
+	before := func(a, b *violationMeta) bool {
+		if oldestFirst {
+			return newerThan(b.createdAt, b.id, a.createdAt, a.id)
+		}
+		return newerThan(a.createdAt, a.id, b.createdAt, b.id)
+	}
+	sort.Slice(items, func(i, j int) bool { return before(meta(items[i]), meta(items[j])) })
+
+	start := 0
+	if q.After != nil {
+		after := &violationMeta{id: q.After.ID, createdAt: q.After.CreatedAt}
+		start = sort.Search(len(items), func(i int) bool { return before(after, meta(items[i])) })
+	}
+
+	end := len(items)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	var next *ViolationCursor
+	if end < len(items) && end > start {
+		cursor := meta(items[end-1]).cursor()
+		next = &cursor
+	}
+	return items[start:end], next
+}
+
+func newerThan(aTime time.Time, aID string, bTime time.Time, bID string) bool {
+	if !aTime.Equal(bTime) {
+		return aTime.After(bTime)
+	}
+	return aID > bID
+}
+
+// serviceNames returns the distinct services of refs
+func serviceNames(refs []models.SpanRef) []string {
+	var names []string
+	for _, ref := range refs {
+		if ref.ServiceName != "" && !contains(names, ref.ServiceName) {
+			names = append(names, ref.ServiceName)
+		}
+	}
+	return names
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type violationSearcher interface {
+	StoreViolation(ctx context.Context, v models.Violation) error
+	SearchViolations(ctx context.Context, q ViolationQuery) (ViolationPage, error)
+}
+
+// queryBackends runs a test against both violation storage backends
+func queryBackends(t *testing.T, test func(t *testing.T, store violationSearcher)) {
+	t.Run("memory", func(t *testing.T) { test(t, NewMemoryStore()) })
+	t.Run("disk", func(t *testing.T) { test(t, openDiskStore(t, t.TempDir(), DiskViolationStoreOptions{})) })
+}
+
+func TestSearchViolations_Pagination(t *testing.T) {
+	queryBackends(t, func(t *testing.T, store violationSearcher) {
+		ctx := context.Background()
+		// Pairs of violations share a timestamp, so ordering relies on the ID tie-breaker
+		for i := 1; i <= 25; i++ {
+			v := diskViolation(i, "rule-A", "HIGH", "trace-1")
+			v.CreatedAt = diskTestEpoch.Add(time.Duration(i/2) * time.Second)
+			require.NoError(t, store.StoreViolation(ctx, v))
+		}
+		require.NoError(t, store.StoreViolation(ctx, diskViolation(99, "rule-B", "HIGH", "trace-1")))
+
+		for _, order := range []string{"", OrderOldestFirst} {
+			t.Run("order="+order, func(t *testing.T) {
+				var seen []string
+				q := ViolationQuery{RuleID: "rule-A", Order: order, Limit: 10}
+				for pages := 0; ; pages++ {
+					require.Less(t, pages, 10, "pagination must terminate")
+
+					page, err := store.SearchViolations(ctx, q)
+					require.NoError(t, err)
+					assert.Equal(t, 25, page.Total, "total counts every match, not just the page")
+					seen = append(seen, violationIDs(page.Violations)...)
+
+					if page.Next == nil {
+						break
+					}
+					q.After = page.Next
+				}
+
+				var want []string
+				for i := 1; i <= 25; i++ {
+					want = append(want, fmt.Sprintf("v-%04d", i))
+				}
+				if order != OrderOldestFirst {
+					for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+						want[i], want[j] = want[j], want[i]
+					}
+				}
+				assert.Equal(t, want, seen)
+			})
+		}
+	})
+}
+
+func TestSearchViolations_ServiceAndTagFilters(t *testing.T) {
+	queryBackends(t, func(t *testing.T, store violationSearcher) {
+		ctx := context.Background()
+
+		v1 := diskViolation(1, "rule-A", "HIGH", "trace-1")
+		v1.SpanRefs = []models.SpanRef{{TraceID: "trace-1", SpanID: "s1", ServiceName: "checkout"}}
+		v1.Tags = []string{"pci", "payments"}
+		v2 := diskViolation(2, "rule-B", "LOW", "trace-2")
+		v2.SpanRefs = []models.SpanRef{{TraceID: "trace-2", SpanID: "s2", ServiceName: "auth"}}
+		v2.Tags = []string{"pci"}
+		require.NoError(t, store.StoreViolation(ctx, v1))
+		require.NoError(t, store.StoreViolation(ctx, v2))
+
+		tests := []struct {
+			name  string
+			query ViolationQuery
+			want  []string
+		}{
+			{"service", ViolationQuery{ServiceName: "checkout"}, []string{"v-0001"}},
+			{"one tag", ViolationQuery{Tags: []string{"pci"}}, []string{"v-0002", "v-0001"}},
+			{"all tags", ViolationQuery{Tags: []string{"pci", "payments"}}, []string{"v-0001"}},
+			{"unknown tag", ViolationQuery{Tags: []string{"hipaa"}}, []string{}},
+			{"service and severity", ViolationQuery{ServiceName: "auth", Severity: "HIGH"}, []string{}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := store.SearchViolations(ctx, tt.query)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, violationIDs(page.Violations))
+				assert.Equal(t, len(tt.want), page.Total)
+				assert.Nil(t, page.Next)
+			})
+		}
+	})
+}
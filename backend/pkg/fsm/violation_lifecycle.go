@@ -0,0 +1,121 @@
+package fsm
+
+import (
+	"fmt"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// ViolationTriageEvent represents a triage action that changes a violation's status.
+// Violation statuses are the models.ViolationStatus* strings; the current
+// status lives on the stored violation, so the transition table is stateless.
+type ViolationTriageEvent int
+
+const (
+	// EventAcknowledge: someone is looking at the violation
+	EventAcknowledge ViolationTriageEvent = iota
+	// EventResolve: the underlying problem was fixed
+	EventResolve
+	// EventMarkFalsePositive: the rule fired on behaviour that is acceptable
+	EventMarkFalsePositive
+	// EventSuppress: known and accepted, hide from triage queues
+	EventSuppress
+	// EventReopen: return a triaged violation to open
+	EventReopen
+)
+
+// String returns the string representation of the event
+func (e ViolationTriageEvent) String() string {
+	switch e {
+	case EventAcknowledge:
+		return "acknowledge"
+	case EventResolve:
+		return "resolve"
+	case EventMarkFalsePositive:
+		return "mark_false_positive"
+	case EventSuppress:
+		return "suppress"
+	case EventReopen:
+		return "reopen"
+	default:
+		return fmt.Sprintf("unknown_event(%d)", e)
+	}
+}
+
+// InvalidViolationTransitionError indicates an illegal status change
+type InvalidViolationTransitionError struct {
+	ViolationID string
+	From        string
+	Event       ViolationTriageEvent
+}
+
+func (e *InvalidViolationTransitionError) Error() string {
+	return fmt.Sprintf("violation %s: invalid transition from %s via event %s",
+		e.ViolationID, e.From, e.Event)
+}
+
+// ViolationTransition returns the status reached from status from via event.
+// An empty from is treated as open (violations recorded before triage existed).
+func ViolationTransition(violationID, from string, event ViolationTriageEvent) (string, error) {
+	if from == "" {
+		from = models.ViolationStatusOpen
+	}
+
+	next, valid := violationTransitions()[from][event]
+	if !valid {
+		return "", &InvalidViolationTransitionError{
+			ViolationID: violationID,
+			From:        from,
+			Event:       event,
+		}
+	}
+	return next, nil
+}
+
+// ViolationEventTo returns the event that moves a violation into status
+func ViolationEventTo(status string) (ViolationTriageEvent, bool) {
+	switch status {
+	case models.ViolationStatusAcknowledged:
+		return EventAcknowledge, true
+	case models.ViolationStatusResolved:
+		return EventResolve, true
+	case models.ViolationStatusFalsePositive:
+		return EventMarkFalsePositive, true
+	case models.ViolationStatusSuppressed:
+		return EventSuppress, true
+	case models.ViolationStatusOpen:
+		return EventReopen, true
+	default:
+		return 0, false
+	}
+}
+
+// violationTransitions defines the triage transition table
+// Maps: CurrentStatus -> Event -> NextStatus
+func violationTransitions() map[string]map[ViolationTriageEvent]string {
+	return map[string]map[ViolationTriageEvent]string{
+		models.ViolationStatusOpen: {
+			EventAcknowledge:       models.ViolationStatusAcknowledged,
+			EventResolve:           models.ViolationStatusResolved,
+			EventMarkFalsePositive: models.ViolationStatusFalsePositive,
+			EventSuppress:          models.ViolationStatusSuppressed,
+		},
+		models.ViolationStatusAcknowledged: {
+			EventResolve:           models.ViolationStatusResolved,
+			EventMarkFalsePositive: models.ViolationStatusFalsePositive,
+			EventSuppress:          models.ViolationStatusSuppressed,
+			EventReopen:            models.ViolationStatusOpen,
+		},
+		// Closed statuses can only be reopened, so every decision is recorded
+		// as its own transition rather than overwritten
+		models.ViolationStatusResolved: {
+			EventReopen: models.ViolationStatusOpen,
+		},
+		models.ViolationStatusFalsePositive: {
+			EventReopen: models.ViolationStatusOpen,
+		},
+		models.ViolationStatusSuppressed: {
+			EventReopen: models.ViolationStatusOpen,
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/betracehq/betrace/backend/pkg/models"
+)
+
+// TestViolationTransition_Table checks every status/event pair against the triage rules
+func TestViolationTransition_Table(t *testing.T) {
+	tests := []struct {
+		from  string
+		event ViolationTriageEvent
+		want  string // "" = invalid
+	}{
+		{models.ViolationStatusOpen, EventAcknowledge, models.ViolationStatusAcknowledged},
+		{models.ViolationStatusOpen, EventResolve, models.ViolationStatusResolved},
+		{models.ViolationStatusOpen, EventMarkFalsePositive, models.ViolationStatusFalsePositive},
+		{models.ViolationStatusOpen, EventSuppress, models.ViolationStatusSuppressed},
+		{models.ViolationStatusOpen, EventReopen, ""},
+		{"", EventAcknowledge, models.ViolationStatusAcknowledged}, // Never triaged = open
+		{models.ViolationStatusAcknowledged, EventAcknowledge, ""},
+		{models.ViolationStatusAcknowledged, EventResolve, models.ViolationStatusResolved},
+		{models.ViolationStatusAcknowledged, EventReopen, models.ViolationStatusOpen},
+		{models.ViolationStatusResolved, EventReopen, models.ViolationStatusOpen},
+		{models.ViolationStatusResolved, EventSuppress, ""},
+		{models.ViolationStatusFalsePositive, EventResolve, ""},
+		{models.ViolationStatusFalsePositive, EventReopen, models.ViolationStatusOpen},
+		{models.ViolationStatusSuppressed, EventAcknowledge, ""},
+		{models.ViolationStatusSuppressed, EventReopen, models.ViolationStatusOpen},
+	}
+
+	for _, tt := range tests {
+		got, err := ViolationTransition("v-1", tt.from, tt.event)
+		if tt.want == "" {
+			var transitionErr *InvalidViolationTransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Errorf("%q via %s: expected InvalidViolationTransitionError, got %q, %v", tt.from, tt.event, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q via %s: expected %s, got %q, %v", tt.from, tt.event, tt.want, got, err)
+		}
+	}
+}
+
+// TestViolationEventTo maps every status to the event that reaches it
+func TestViolationEventTo(t *testing.T) {
+	for _, status := range []string{
+		models.ViolationStatusOpen,
+		models.ViolationStatusAcknowledged,
+		models.ViolationStatusResolved,
+		models.ViolationStatusFalsePositive,
+		models.ViolationStatusSuppressed,
+	} {
+		event, ok := ViolationEventTo(status)
+		if !ok {
+			t.Errorf("Expected an event for status %s", status)
+			continue
+		}
+		from := models.ViolationStatusAcknowledged
+		if status != models.ViolationStatusOpen {
+			from = models.ViolationStatusOpen
+		}
+		if got, err := ViolationTransition("v-1", from, event); err != nil || got != status {
+			t.Errorf("Expected %s via %s to reach %s, got %q, %v", from, event, status, got, err)
+		}
+	}
+
+	if _, ok := ViolationEventTo("closed"); ok {
+		t.Error("Expected no event for unknown status")
+	}
+}
+
+// TestInvalidViolationTransitionError_Message tests error formatting
+func TestInvalidViolationTransitionError_Message(t *testing.T) {
+	_, err := ViolationTransition("v-42", models.ViolationStatusResolved, EventSuppress)
+	want := "violation v-42: invalid transition from resolved via event suppress"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected %q, got %v", want, err)
+	}
+}
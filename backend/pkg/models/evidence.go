@@ -0,0 +1,25 @@
+package models
+
+// RedactedValue replaces the values of redacted attributes in evidence snapshots
+const RedactedValue = "[REDACTED]"
+
+// EvidenceSnapshot preserves what a violation was detected on: the rule as
+// it was evaluated and a bounded copy of the trace's spans. Violations are
+// reproducible from it after the trace has expired from the tracing backend.
+type EvidenceSnapshot struct {
+	RuleExpression   string `json:"ruleExpression"`
+	ExpressionDigest string `json:"expressionDigest"` // sha256:<hex> of RuleExpression
+
+	// Spans are the spans the violation references first, then their
+	// ancestors, then the rest of the trace, up to the snapshot limit.
+	// Ordered by start time.
+	Spans []Span `json:"spans"`
+	// TotalSpans is the size of the trace at detection; more than
+	// len(Spans) when the snapshot was cut to its limit
+	TotalSpans int `json:"totalSpans"`
+	// TruncatedAttributes counts attribute values cut to the length limit
+	TruncatedAttributes int `json:"truncatedAttributes,omitempty"`
+	// RedactedAttributes names the attributes whose values were replaced
+	// with RedactedValue
+	RedactedAttributes []string `json:"redactedAttributes,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// RuleExplanation describes why a rule did or did not fire on a trace
+type RuleExplanation struct {
+	Violated bool             `json:"violated"`
+	When     *ExplanationNode `json:"when"`
+	Always   *ExplanationNode `json:"always,omitempty"`
+	Never    *ExplanationNode `json:"never,omitempty"`
+
+	// WhenSpanIDs are the spans that satisfied the when clause
+	WhenSpanIDs []string `json:"whenSpanIds,omitempty"`
+	// UnsatisfiedAlways are the always sub-conditions that had no match
+	UnsatisfiedAlways []string `json:"unsatisfiedAlways,omitempty"`
+	// MatchedNever are the never sub-conditions that matched
+	MatchedNever []string `json:"matchedNever,omitempty"`
+}
+
+// ExplanationNode is one node of a clause's evaluation tree
+type ExplanationNode struct {
+	Kind       string `json:"kind"`       // or, and, not, has, count
+	Expression string `json:"expression"` // DSL source of this node
+	Result     bool   `json:"result"`
+	Error      string `json:"error,omitempty"`
+	Operation  string `json:"operation,omitempty"` // span checks only: operation name checked
+
+	// Span checks only: spans that satisfied the check (capped) and their total
+	MatchedSpanIDs   []string `json:"matchedSpanIds,omitempty"`
+	MatchedSpanCount int      `json:"matchedSpanCount,omitempty"`
+
+	Children []*ExplanationNode `json:"children,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Incident groups violations sharing a fingerprint (same rule and grouping
+// key values), so a burst of identical violations shows up as one record
+type Incident struct {
+	Fingerprint string            `json:"fingerprint"`
+	RuleID      string            `json:"ruleId"`
+	RuleName    string            `json:"ruleName"`
+	Severity    string            `json:"severity"`
+	GroupKeys   map[string]string `json:"groupKeys,omitempty"`
+
+	FirstSeen         time.Time `json:"firstSeen"`
+	LastSeen          time.Time `json:"lastSeen"`
+	Count             int64     `json:"count"`
+	LatestViolationID string    `json:"latestViolationId"`
+
+	// SampleTraceIDs are the most recent distinct traces, newest first
+	SampleTraceIDs []string `json:"sampleTraceIds"`
+}
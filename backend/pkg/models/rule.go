@@ -15,10 +15,60 @@ type Rule struct {
 	LuaCode     string    `json:"luaCode"`     // Compiled Lua code
 	Enabled     bool      `json:"enabled"`
 	Tags        []string  `json:"tags"`
+	Controls    []ComplianceControl `json:"controls,omitempty"` // Compliance controls the rule is evidence for
+	Version     int       `json:"version,omitempty"`  // Incremented by every change; 0 for rules predating versioning
+	Mode        string    `json:"mode,omitempty"`     // See RuleMode*; empty = enforcing
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// ComplianceControl maps a rule to a compliance control it monitors
+type ComplianceControl struct {
+	Framework string `json:"framework"`            // soc2, hipaa, gdpr, fedramp
+	ControlID string `json:"control_id,omitempty"` // e.g. CC6.1; empty maps the whole framework
+}
+
+// Rule modes. Shadow rules are evaluated like enforcing ones, but their
+// violations are kept apart from the real violation store and stream.
+const (
+	RuleModeEnforcing = "enforcing"
+	RuleModeShadow    = "shadow"
+)
+
+// EffectiveMode returns the rule's mode, defaulting to enforcing
+func (r *Rule) EffectiveMode() string {
+	if r.Mode == "" {
+		return RuleModeEnforcing
+	}
+	return r.Mode
+}
+
+// IsShadow reports whether the rule runs in shadow (dry-run) mode
+func (r *Rule) IsShadow() bool {
+	return r.Mode == RuleModeShadow
+}
+
+// Changes that record a new rule version
+const (
+	RuleChangeCreated    = "created"
+	RuleChangeUpdated    = "updated"
+	RuleChangeEnabled    = "enabled"
+	RuleChangeDisabled   = "disabled"
+	RuleChangePromoted   = "promoted" // Shadow to enforcing
+	RuleChangeRolledBack = "rolled_back"
+)
+
+// RuleVersion is an immutable snapshot of a rule, recorded by every change
+type RuleVersion struct {
+	RuleID          string    `json:"ruleId"`
+	Version         int       `json:"version"`
+	Change          string    `json:"change"` // See RuleChange*
+	Author          string    `json:"author"` // Authenticated user who made the change
+	CreatedAt       time.Time `json:"createdAt"`
+	RestoredVersion int       `json:"restoredVersion,omitempty"` // Version a rollback restored
+	Rule            Rule      `json:"rule"`                      // The rule as of this version
+}
+
 // RuleLimits defines validation limits for rules
 type RuleLimits struct {
 	MaxExpressionLength  int
@@ -43,5 +93,9 @@ func (r *Rule) Validate(limits RuleLimits) error {
 		return fmt.Errorf("rule description length %d exceeds limit of %d bytes", len(r.Description), limits.MaxDescriptionLength)
 	}
 
+	if r.Mode != "" && r.Mode != RuleModeEnforcing && r.Mode != RuleModeShadow {
+		return fmt.Errorf("unknown rule mode %q (want %s or %s)", r.Mode, RuleModeEnforcing, RuleModeShadow)
+	}
+
 	return nil
 }
@@ -155,6 +155,17 @@ func TestRule_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "shadow mode",
+			rule:    Rule{Name: "rule", Expression: "expr", Mode: RuleModeShadow},
+			wantErr: false,
+		},
+		{
+			name:    "unknown mode",
+			rule:    Rule{Name: "rule", Expression: "expr", Mode: "dry-run"},
+			wantErr: true,
+			errMsg:  "unknown rule mode",
+		},
 	}
 
 	for _, tt := range tests {
@@ -7,12 +7,61 @@ type Violation struct {
 	ID          string    `json:"id"`
 	RuleID      string    `json:"ruleId"`
 	RuleName    string    `json:"ruleName"`
-	Severity    string    `json:"severity"` // HIGH, MEDIUM, LOW
+	RuleVersion int       `json:"ruleVersion,omitempty"` // Version of the rule that produced it (0 if unknown)
+	Severity    string    `json:"severity"`              // HIGH, MEDIUM, LOW
 	Message     string    `json:"message"`
 	TraceIDs    []string  `json:"traceIds"`
 	SpanRefs    []SpanRef `json:"spanReferences"`
+	Tags        []string  `json:"tags,omitempty"` // Copied from the rule
 	CreatedAt   time.Time `json:"createdAt"`
-	Signature   string    `json:"signature"` // HMAC-SHA256
+	// Signature is the base64url Ed25519 signature of the violation's
+	// canonical encoding by key SignatureKeyID
+	Signature      string `json:"signature"`
+	SignatureKeyID string `json:"signatureKeyId,omitempty"`
+
+	// Fingerprint groups repeats of the same problem into an incident:
+	// a hash of the rule ID and the GroupKeys values
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	GroupKeys   map[string]string `json:"groupKeys,omitempty"`
+
+	// Explanation records why the rule fired (nil if not captured)
+	Explanation *RuleExplanation `json:"explanation,omitempty"`
+	// Evidence snapshots the rule and spans the violation was detected on
+	// (nil if not captured)
+	Evidence *EvidenceSnapshot `json:"evidence,omitempty"`
+
+	// Triage state. Not covered by Signature: it changes after detection,
+	// and StatusHistory records who changed it and when.
+	Status        string             `json:"status,omitempty"` // See ViolationStatus*; empty = open
+	Assignee      string             `json:"assignee,omitempty"`
+	Comments      []ViolationComment `json:"comments,omitempty"`
+	StatusHistory []StatusChange     `json:"statusHistory,omitempty"`
+	UpdatedAt     *time.Time         `json:"updatedAt,omitempty"` // Last triage change
+}
+
+// Violation statuses (transitions are validated by pkg/fsm)
+const (
+	ViolationStatusOpen          = "open"
+	ViolationStatusAcknowledged  = "acknowledged"
+	ViolationStatusResolved      = "resolved"
+	ViolationStatusFalsePositive = "false-positive"
+	ViolationStatusSuppressed    = "suppressed"
+)
+
+// ViolationComment is a note left on a violation during triage
+type ViolationComment struct {
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// StatusChange records one status transition of a violation
+type StatusChange struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
 }
 
 // SpanRef references a specific span involved in the violation
@@ -20,4 +69,16 @@ type SpanRef struct {
 	TraceID     string `json:"traceId"`
 	SpanID      string `json:"spanId"`
 	ServiceName string `json:"serviceName"`
+	Role        string `json:"role,omitempty"` // trigger, forbidden, missing-expected
 }
+
+// Span roles in a violation
+const (
+	// SpanRoleTrigger marks a span that satisfied the rule's when clause
+	SpanRoleTrigger = "trigger"
+	// SpanRoleForbidden marks a span that matched a condition that must not hold
+	SpanRoleForbidden = "forbidden"
+	// SpanRoleMissingExpected marks a span of an expected operation that didn't
+	// satisfy the expected condition (e.g. failed its .where() filter)
+	SpanRoleMissingExpected = "missing-expected"
+)